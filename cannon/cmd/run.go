@@ -21,14 +21,14 @@ import (
 var (
 	RunInputFlag = &cli.PathFlag{
 		Name:      "input",
-		Usage:     "path of input JSON state. Stdin if left empty.",
+		Usage:     "path of input state, in JSON or binary format. Stdin if left empty.",
 		TakesFile: true,
 		Value:     "state.json",
 		Required:  true,
 	}
 	RunOutputFlag = &cli.PathFlag{
 		Name:      "output",
-		Usage:     "path of output JSON state. Stdout if left empty.",
+		Usage:     "path of output state. Written in binary format if the path ends in .bin or .bin.gz, otherwise JSON. Stdout if left empty.",
 		TakesFile: true,
 		Value:     "out.json",
 		Required:  false,
@@ -54,7 +54,7 @@ var (
 	}
 	RunSnapshotFmtFlag = &cli.StringFlag{
 		Name:     "snapshot-fmt",
-		Usage:    "format for snapshot output file names.",
+		Usage:    "format for snapshot output file names. Snapshots are written in binary format if the name ends in .bin or .bin.gz, otherwise JSON.",
 		Value:    "state-%d.json",
 		Required: false,
 	}
@@ -80,6 +80,24 @@ var (
 		Name:  "pprof.cpu",
 		Usage: "enable pprof cpu profiling",
 	}
+	RunProgramProfileFlag = &cli.PathFlag{
+		Name:      "program-profile",
+		Usage:     "path to write a pprof profile of the executed program to, attributing steps to the symbols of the metadata. Counts of syscalls and pre-image reads are logged on completion. Disabled if empty.",
+		TakesFile: true,
+		Required:  false,
+	}
+	RunTraceFlag = &cli.PathFlag{
+		Name:      "trace",
+		Usage:     "path to write an instruction trace to, with a line of step, pc, instruction and symbol per traced step. Disabled if empty.",
+		TakesFile: true,
+		Required:  false,
+	}
+	RunTraceAtFlag = &cli.GenericFlag{
+		Name:     "trace-at",
+		Usage:    "step pattern to write instruction trace lines at: " + patternHelp,
+		Value:    MustStepMatcherFlag("always"),
+		Required: false,
+	}
 )
 
 type Proof struct {
@@ -208,7 +226,7 @@ func Run(ctx *cli.Context) error {
 		defer profile.Start(profile.NoShutdownHook, profile.ProfilePath("."), profile.CPUProfile).Stop()
 	}
 
-	state, err := loadJSON[mipsevm.State](ctx.Path(RunInputFlag.Name))
+	state, err := loadState(ctx.Path(RunInputFlag.Name))
 	if err != nil {
 		return err
 	}
@@ -259,6 +277,28 @@ func Run(ctx *cli.Context) error {
 		}
 	}
 
+	var profiler *mipsevm.Profiler
+	profilePath := ctx.Path(RunProgramProfileFlag.Name)
+	if profilePath != "" {
+		profiler = mipsevm.NewProfiler(meta)
+	}
+
+	var tracer *mipsevm.InstructionTracer
+	traceAt := ctx.Generic(RunTraceAtFlag.Name).(*StepMatcherFlag).Matcher()
+	if tracePath := ctx.Path(RunTraceFlag.Name); tracePath != "" {
+		f, err := os.Create(tracePath)
+		if err != nil {
+			return fmt.Errorf("failed to create instruction trace file: %w", err)
+		}
+		defer f.Close()
+		tracer = mipsevm.NewInstructionTracer(f, meta)
+		defer func() {
+			if err := tracer.Flush(); err != nil {
+				l.Error("failed to flush instruction trace", "err", err)
+			}
+		}()
+	}
+
 	us := mipsevm.NewInstrumentedState(state, po, outLog, errLog)
 	proofFmt := ctx.String(RunProofFmtFlag.Name)
 	snapshotFmt := ctx.String(RunSnapshotFmtFlag.Name)
@@ -304,8 +344,18 @@ func Run(ctx *cli.Context) error {
 			break
 		}
 
+		if profiler != nil {
+			profiler.Observe(state)
+		}
+
+		if tracer != nil && traceAt(state) {
+			if err := tracer.Trace(state); err != nil {
+				return fmt.Errorf("failed to write instruction trace: %w", err)
+			}
+		}
+
 		if snapshotAt(state) {
-			if err := writeJSON(fmt.Sprintf(snapshotFmt, step), state, false); err != nil {
+			if err := writeState(fmt.Sprintf(snapshotFmt, step), state, false); err != nil {
 				return fmt.Errorf("failed to write state snapshot: %w", err)
 			}
 		}
@@ -346,12 +396,32 @@ func Run(ctx *cli.Context) error {
 		}
 	}
 
-	if err := writeJSON(ctx.Path(RunOutputFlag.Name), state, true); err != nil {
+	if profiler != nil {
+		if err := writeProgramProfile(profilePath, profiler); err != nil {
+			return fmt.Errorf("failed to write program profile: %w", err)
+		}
+		stats := profiler.Stats()
+		l.Info("program profile", "steps", stats.Steps, "syscalls", stats.Syscalls, "preimageReads", stats.PreimageReads)
+	}
+
+	if err := writeState(ctx.Path(RunOutputFlag.Name), state, true); err != nil {
 		return fmt.Errorf("failed to write state output: %w", err)
 	}
 	return nil
 }
 
+func writeProgramProfile(path string, profiler *mipsevm.Profiler) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := profiler.WriteProfile(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
 var RunCommand = &cli.Command{
 	Name:        "run",
 	Usage:       "Run VM step(s) and generate proof data to replicate onchain.",
@@ -368,5 +438,8 @@ var RunCommand = &cli.Command{
 		RunMetaFlag,
 		RunInfoAtFlag,
 		RunPProfCPU,
+		RunProgramProfileFlag,
+		RunTraceFlag,
+		RunTraceAtFlag,
 	},
 }
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+)
+
+// loadState reads a VM state from inputPath.
+// The binary and JSON state formats are detected automatically and the file is decompressed if it has a .gz suffix.
+func loadState(inputPath string) (*mipsevm.State, error) {
+	if inputPath == "" {
+		return nil, errors.New("no path specified")
+	}
+	var f io.ReadCloser
+	f, err := os.OpenFile(inputPath, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %w", inputPath, err)
+	}
+	defer f.Close()
+	if isGzip(inputPath) {
+		f, err = gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("create gzip reader: %w", err)
+		}
+		defer f.Close()
+	}
+	state, err := mipsevm.ReadState(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode state %q: %w", inputPath, err)
+	}
+	return state, nil
+}
+
+// writeState writes the VM state to outputPath.
+// The binary state format is used if the path has a .bin suffix, optionally followed by .gz, otherwise JSON is used.
+func writeState(outputPath string, state *mipsevm.State, outIfEmpty bool) error {
+	if !isBinary(outputPath) {
+		return writeJSON(outputPath, state, outIfEmpty)
+	}
+	f, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer f.Close()
+	var out io.Writer = f
+	if isGzip(outputPath) {
+		g := gzip.NewWriter(f)
+		defer g.Close()
+		out = g
+	}
+	if err := state.Serialize(out); err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	return nil
+}
+
+func isBinary(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, ".gz"), ".bin")
+}
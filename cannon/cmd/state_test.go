@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTripState(t *testing.T) {
+	for _, name := range []string{"state.json", "state.json.gz", "state.bin", "state.bin.gz"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), name)
+			state := testState()
+			require.NoError(t, writeState(file, state, false))
+
+			result, err := loadState(file)
+			require.NoError(t, err)
+			require.Equal(t, state.EncodeWitness(), result.EncodeWitness())
+		})
+	}
+}
+
+func TestWriteStateFormat(t *testing.T) {
+	dir := t.TempDir()
+	binFile := filepath.Join(dir, "state.bin")
+	require.NoError(t, writeState(binFile, testState(), false))
+	data, err := os.ReadFile(binFile)
+	require.NoError(t, err)
+	require.True(t, mipsevm.IsBinaryState(data))
+
+	jsonFile := filepath.Join(dir, "state.json")
+	require.NoError(t, writeState(jsonFile, testState(), false))
+	data, err = os.ReadFile(jsonFile)
+	require.NoError(t, err)
+	require.False(t, mipsevm.IsBinaryState(data))
+}
+
+func TestLoadStateDetectsFormat(t *testing.T) {
+	// Binary states are detected by content rather than by file name
+	file := filepath.Join(t.TempDir(), "state.json")
+	state := testState()
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, state.Serialize(f))
+	require.NoError(t, f.Close())
+
+	result, err := loadState(file)
+	require.NoError(t, err)
+	require.Equal(t, state.EncodeWitness(), result.EncodeWitness())
+}
+
+func testState() *mipsevm.State {
+	state := &mipsevm.State{Memory: mipsevm.NewMemory(), PC: 4, NextPC: 8, Step: 42}
+	state.Memory.SetMemory(0x1000, 0xaabbccdd)
+	return state
+}
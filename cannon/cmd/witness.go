@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/urfave/cli/v2"
 )
@@ -12,7 +11,7 @@ import (
 var (
 	WitnessInputFlag = &cli.PathFlag{
 		Name:      "input",
-		Usage:     "path of input JSON state.",
+		Usage:     "path of input state, in JSON or binary format.",
 		TakesFile: true,
 		Required:  true,
 	}
@@ -26,7 +25,7 @@ var (
 func Witness(ctx *cli.Context) error {
 	input := ctx.Path(WitnessInputFlag.Name)
 	output := ctx.Path(WitnessOutputFlag.Name)
-	state, err := loadJSON[mipsevm.State](input)
+	state, err := loadState(input)
 	if err != nil {
 		return fmt.Errorf("invalid input state (%v): %w", input, err)
 	}
@@ -43,8 +42,8 @@ func Witness(ctx *cli.Context) error {
 
 var WitnessCommand = &cli.Command{
 	Name:        "witness",
-	Usage:       "Convert a Cannon JSON state into a binary witness",
-	Description: "Convert a Cannon JSON state into a binary witness. The hash of the witness is written to stdout",
+	Usage:       "Convert a Cannon state into a binary witness",
+	Description: "Convert a Cannon state, in JSON or binary format, into a binary witness. The hash of the witness is written to stdout",
 	Action:      Witness,
 	Flags: []cli.Flag{
 		WitnessInputFlag,
@@ -0,0 +1,134 @@
+package mipsevm
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// Profiler attributes executed steps to the program symbols of the metadata,
+// and counts the syscalls and pre-image reads made by the program.
+//
+// Attribution is flat: each step is attributed to the symbol containing the PC it executes,
+// no call stacks are reconstructed.
+type Profiler struct {
+	meta *Metadata
+
+	start time.Time
+
+	// steps executed, by PC
+	steps map[uint32]int64
+	// syscalls made, by syscall number
+	syscalls map[uint32]uint64
+	// pre-image read syscalls made, by pre-image key type
+	preimageReads map[byte]uint64
+}
+
+// ProfileStats summarizes the syscalls and pre-image reads observed by a Profiler.
+type ProfileStats struct {
+	Steps         uint64            `json:"steps"`
+	Syscalls      map[uint32]uint64 `json:"syscalls"`
+	PreimageReads map[byte]uint64   `json:"preimageReads"`
+}
+
+func NewProfiler(meta *Metadata) *Profiler {
+	return &Profiler{
+		meta:          meta,
+		start:         time.Now(),
+		steps:         make(map[uint32]int64),
+		syscalls:      make(map[uint32]uint64),
+		preimageReads: make(map[byte]uint64),
+	}
+}
+
+// Observe records the instruction that is about to be executed by the next step of the state.
+func (p *Profiler) Observe(state *State) {
+	if state.Exited {
+		return
+	}
+	p.steps[state.PC]++
+	if !isSyscall(state.Memory.GetMemory(state.PC)) {
+		return
+	}
+	syscallNum := state.Registers[2] // v0
+	p.syscalls[syscallNum]++
+	if syscallNum == sysRead && state.Registers[4] == fdPreimageRead { // a0 = fd
+		p.preimageReads[state.PreimageKey[0]]++
+	}
+}
+
+// Stats returns the counts of steps, syscalls and pre-image reads observed so far.
+func (p *Profiler) Stats() ProfileStats {
+	out := ProfileStats{
+		Syscalls:      make(map[uint32]uint64, len(p.syscalls)),
+		PreimageReads: make(map[byte]uint64, len(p.preimageReads)),
+	}
+	for _, n := range p.steps {
+		out.Steps += uint64(n)
+	}
+	for k, v := range p.syscalls {
+		out.Syscalls[k] = v
+	}
+	for k, v := range p.preimageReads {
+		out.PreimageReads[k] = v
+	}
+	return out
+}
+
+// Profile builds a pprof profile of the observed steps. Each sample is the number of steps executed at an address,
+// with the address mapped to the function of the symbol it falls in.
+func (p *Profiler) Profile() *profile.Profile {
+	pcs := make([]uint32, 0, len(p.steps))
+	for pc := range p.steps {
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool {
+		return pcs[i] < pcs[j]
+	})
+
+	out := &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "steps", Unit: "count"}},
+		PeriodType:    &profile.ValueType{Type: "steps", Unit: "count"},
+		Period:        1,
+		TimeNanos:     p.start.UnixNano(),
+		DurationNanos: time.Since(p.start).Nanoseconds(),
+	}
+	functions := make(map[string]*profile.Function)
+	for _, pc := range pcs {
+		name := p.meta.LookupSymbol(pc)
+		fn, ok := functions[name]
+		if !ok {
+			fn = &profile.Function{ID: uint64(len(out.Function) + 1), Name: name, SystemName: name}
+			functions[name] = fn
+			out.Function = append(out.Function, fn)
+		}
+		loc := &profile.Location{
+			ID:      uint64(len(out.Location) + 1),
+			Address: uint64(pc),
+			Line:    []profile.Line{{Function: fn}},
+		}
+		out.Location = append(out.Location, loc)
+		out.Sample = append(out.Sample, &profile.Sample{
+			Location: []*profile.Location{loc},
+			Value:    []int64{p.steps[pc]},
+		})
+	}
+	return out
+}
+
+// WriteProfile writes the gzip-compressed pprof profile of the observed steps to w.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	prof := p.Profile()
+	if err := prof.CheckValid(); err != nil {
+		return fmt.Errorf("invalid profile: %w", err)
+	}
+	return prof.Write(w)
+}
+
+// isSyscall returns true if the instruction is a syscall (SPECIAL opcode with the syscall function code)
+func isSyscall(insn uint32) bool {
+	return insn>>26 == 0 && insn&0x3f == 0xC
+}
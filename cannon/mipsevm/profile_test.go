@@ -0,0 +1,93 @@
+package mipsevm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiler(t *testing.T) {
+	state, meta := profileTestProgram()
+	profiler := NewProfiler(meta)
+	runProfileTestProgram(t, state, func(st *State) {
+		profiler.Observe(st)
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		stats := profiler.Stats()
+		require.Equal(t, uint64(9), stats.Steps)
+		require.Equal(t, map[uint32]uint64{sysRead: 1, sysExitGroup: 1}, stats.Syscalls)
+		require.Equal(t, map[byte]uint64{2: 1}, stats.PreimageReads)
+	})
+
+	t.Run("Profile", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, profiler.WriteProfile(&buf))
+		prof, err := profile.Parse(&buf)
+		require.NoError(t, err)
+
+		steps := make(map[string]int64)
+		for _, sample := range prof.Sample {
+			require.Len(t, sample.Location, 1)
+			steps[sample.Location[0].Line[0].Function.Name] += sample.Value[0]
+		}
+		require.Equal(t, map[string]int64{"main.read": 7, "main.exit": 2}, steps)
+	})
+}
+
+func TestInstructionTracer(t *testing.T) {
+	state, meta := profileTestProgram()
+	var buf bytes.Buffer
+	tracer := NewInstructionTracer(&buf, meta)
+	runProfileTestProgram(t, state, func(st *State) {
+		require.NoError(t, tracer.Trace(st))
+	})
+	require.NoError(t, tracer.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 9)
+	require.Equal(t, "0 00000000 24020fa3 main.read", lines[0])
+	require.Equal(t, "8 00000104 0000000c main.exit", lines[8])
+}
+
+// profileTestProgram creates a program that reads a keccak256 pre-image and then exits,
+// with a symbol for each of the two parts.
+func profileTestProgram() (*State, *Metadata) {
+	state := &State{
+		Memory:      NewMemory(),
+		PC:          0,
+		NextPC:      4,
+		PreimageKey: [32]byte{2},
+	}
+	state.Memory.SetMemory(0, 0x24020fa3)     // addiu $v0, $zero, 4003 (read)
+	state.Memory.SetMemory(4, 0x24040005)     // addiu $a0, $zero, 5 (pre-image read fd)
+	state.Memory.SetMemory(8, 0x24051000)     // addiu $a1, $zero, 0x1000 (buffer)
+	state.Memory.SetMemory(12, 0x24060004)    // addiu $a2, $zero, 4 (count)
+	state.Memory.SetMemory(16, 0x0000000c)    // syscall
+	state.Memory.SetMemory(20, 0x08000040)    // j 0x100, followed by a nop in the delay slot
+	state.Memory.SetMemory(0x100, 0x24021096) // addiu $v0, $zero, 4246 (exit_group)
+	state.Memory.SetMemory(0x104, 0x0000000c) // syscall
+	meta := &Metadata{Symbols: []Symbol{
+		{Name: "main.read", Start: 0, Size: 0x100},
+		{Name: "main.exit", Start: 0x100, Size: 0x100},
+	}}
+	return state, meta
+}
+
+func runProfileTestProgram(t *testing.T, state *State, observe func(st *State)) {
+	oracle := &testOracle{
+		getPreimage: func(key [32]byte) []byte {
+			return []byte{0xaa}
+		},
+	}
+	vm := NewInstrumentedState(state, oracle, nil, nil)
+	for i := 0; i < 100 && !state.Exited; i++ {
+		observe(state)
+		_, err := vm.Step(false)
+		require.NoError(t, err)
+	}
+	require.True(t, state.Exited)
+}
@@ -0,0 +1,284 @@
+package mipsevm
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Binary state format
+//
+// The binary format is a compact alternative to the JSON encoding of State.
+// All integers are big-endian. The layout is:
+//
+//	magic           [4]byte  "CNST"
+//	version         uint8    StateFormatVersion
+//	preimageKey     [32]byte
+//	preimageOffset  uint32
+//	pc, nextPC      uint32
+//	lo, hi, heap    uint32
+//	exitCode        uint8
+//	exited          uint8    0 or 1
+//	step            uint64
+//	registers       [32]uint32
+//	lastHintLen     uint32
+//	lastHint        [lastHintLen]byte
+//	pageCount       uint32
+//	pages           [pageCount]pageRecord, sorted by page index
+//
+// Each page record starts with its uint32 page index and a one byte kind:
+//
+//	pageKindZero    the page is entirely zero, no data follows
+//	pageKindData    PageSize bytes of page data follow
+//	pageKindCopy    a uint32 follows, referencing the position of an earlier data record with identical content
+//
+// Deduplicating identical pages keeps snapshots of large programs small, as much of the memory is typically zero or
+// repeated.
+const StateFormatVersion = uint8(1)
+
+var stateFormatMagic = [4]byte{'C', 'N', 'S', 'T'}
+
+const (
+	pageKindZero = uint8(0)
+	pageKindData = uint8(1)
+	pageKindCopy = uint8(2)
+)
+
+var (
+	ErrInvalidStateFormat     = errors.New("invalid binary state format")
+	ErrUnsupportedStateFormat = errors.New("unsupported binary state format version")
+)
+
+// maxLastHintSize bounds the hint data read when decoding to avoid allocating excessive memory for corrupt input.
+const maxLastHintSize = 1 << 24
+
+var zeroPage Page
+
+// IsBinaryState returns true if the data starts with the binary state format magic bytes.
+func IsBinaryState(data []byte) bool {
+	return len(data) >= len(stateFormatMagic) && bytes.Equal(data[:len(stateFormatMagic)], stateFormatMagic[:])
+}
+
+// Serialize writes the state to w in the binary state format.
+func (s *State) Serialize(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(stateFormatMagic[:]); err != nil {
+		return err
+	}
+	out := make([]byte, 0, 256)
+	out = append(out, StateFormatVersion)
+	out = append(out, s.PreimageKey[:]...)
+	out = binary.BigEndian.AppendUint32(out, s.PreimageOffset)
+	out = binary.BigEndian.AppendUint32(out, s.PC)
+	out = binary.BigEndian.AppendUint32(out, s.NextPC)
+	out = binary.BigEndian.AppendUint32(out, s.LO)
+	out = binary.BigEndian.AppendUint32(out, s.HI)
+	out = binary.BigEndian.AppendUint32(out, s.Heap)
+	out = append(out, s.ExitCode)
+	if s.Exited {
+		out = append(out, 1)
+	} else {
+		out = append(out, 0)
+	}
+	out = binary.BigEndian.AppendUint64(out, s.Step)
+	for _, r := range s.Registers {
+		out = binary.BigEndian.AppendUint32(out, r)
+	}
+	out = binary.BigEndian.AppendUint32(out, uint32(len(s.LastHint)))
+	out = append(out, s.LastHint...)
+	if _, err := bw.Write(out); err != nil {
+		return err
+	}
+	if err := s.Memory.Serialize(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Deserialize reads a state in the binary state format from r, replacing the current contents of s.
+func (s *State) Deserialize(r io.Reader) error {
+	br := bufio.NewReader(r)
+	var header [4 + 1 + 32 + 6*4 + 2 + 8 + 32*4 + 4]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return fmt.Errorf("%w: read header: %v", ErrInvalidStateFormat, err)
+	}
+	if !IsBinaryState(header[:]) {
+		return fmt.Errorf("%w: missing magic bytes", ErrInvalidStateFormat)
+	}
+	data := header[4:]
+	if version := data[0]; version != StateFormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedStateFormat, version)
+	}
+	data = data[1:]
+	var state State
+	copy(state.PreimageKey[:], data[:32])
+	data = data[32:]
+	next32 := func() uint32 {
+		v := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		return v
+	}
+	state.PreimageOffset = next32()
+	state.PC = next32()
+	state.NextPC = next32()
+	state.LO = next32()
+	state.HI = next32()
+	state.Heap = next32()
+	state.ExitCode = data[0]
+	switch data[1] {
+	case 0:
+		state.Exited = false
+	case 1:
+		state.Exited = true
+	default:
+		return fmt.Errorf("%w: invalid exited value %d", ErrInvalidStateFormat, data[1])
+	}
+	data = data[2:]
+	state.Step = binary.BigEndian.Uint64(data[:8])
+	data = data[8:]
+	for i := range state.Registers {
+		state.Registers[i] = next32()
+	}
+	hintLen := next32()
+	if hintLen > maxLastHintSize {
+		return fmt.Errorf("%w: last hint too large (%d bytes)", ErrInvalidStateFormat, hintLen)
+	}
+	if hintLen > 0 {
+		state.LastHint = make([]byte, hintLen)
+		if _, err := io.ReadFull(br, state.LastHint); err != nil {
+			return fmt.Errorf("%w: read last hint: %v", ErrInvalidStateFormat, err)
+		}
+	}
+	state.Memory = NewMemory()
+	if err := state.Memory.Deserialize(br); err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
+
+// Serialize writes the pages of memory to w, deduplicating pages with identical content.
+func (m *Memory) Serialize(w io.Writer) error {
+	indices := make([]uint32, 0, len(m.pages))
+	for k := range m.pages {
+		indices = append(indices, k)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i] < indices[j]
+	})
+	var buf [4 + 1 + 4]byte
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(indices)))
+	if _, err := w.Write(buf[:4]); err != nil {
+		return err
+	}
+	// Position of the first data record with the given content, by content hash.
+	seen := make(map[[32]byte]uint32)
+	for i, pageIndex := range indices {
+		data := m.pages[pageIndex].Data
+		binary.BigEndian.PutUint32(buf[:4], pageIndex)
+		if *data == zeroPage {
+			buf[4] = pageKindZero
+			if _, err := w.Write(buf[:5]); err != nil {
+				return err
+			}
+			continue
+		}
+		hash := sha256.Sum256(data[:])
+		if pos, ok := seen[hash]; ok {
+			buf[4] = pageKindCopy
+			binary.BigEndian.PutUint32(buf[5:9], pos)
+			if _, err := w.Write(buf[:9]); err != nil {
+				return err
+			}
+			continue
+		}
+		seen[hash] = uint32(i)
+		buf[4] = pageKindData
+		if _, err := w.Write(buf[:5]); err != nil {
+			return err
+		}
+		if _, err := w.Write(data[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deserialize reads pages written by [Memory.Serialize] from r, replacing the current contents of m.
+func (m *Memory) Deserialize(r io.Reader) error {
+	var buf [4 + 1 + 4]byte
+	if _, err := io.ReadFull(r, buf[:4]); err != nil {
+		return fmt.Errorf("%w: read page count: %v", ErrInvalidStateFormat, err)
+	}
+	count := binary.BigEndian.Uint32(buf[:4])
+	if count > MaxPageCount {
+		return fmt.Errorf("%w: too many pages (%d)", ErrInvalidStateFormat, count)
+	}
+	*m = *NewMemory()
+	// Pages read so far, by record position, so copies can be resolved.
+	records := make([]*Page, 0, count)
+	for i := uint32(0); i < count; i++ {
+		if _, err := io.ReadFull(r, buf[:5]); err != nil {
+			return fmt.Errorf("%w: read page record %d: %v", ErrInvalidStateFormat, i, err)
+		}
+		pageIndex := binary.BigEndian.Uint32(buf[:4])
+		if pageIndex >= MaxPageCount {
+			return fmt.Errorf("%w: invalid page index %d", ErrInvalidStateFormat, pageIndex)
+		}
+		if _, ok := m.pages[pageIndex]; ok {
+			return fmt.Errorf("%w: duplicate page index %d", ErrInvalidStateFormat, pageIndex)
+		}
+		page := m.AllocPage(pageIndex).Data
+		switch buf[4] {
+		case pageKindZero:
+		case pageKindData:
+			if _, err := io.ReadFull(r, page[:]); err != nil {
+				return fmt.Errorf("%w: read page %d: %v", ErrInvalidStateFormat, pageIndex, err)
+			}
+		case pageKindCopy:
+			if _, err := io.ReadFull(r, buf[5:9]); err != nil {
+				return fmt.Errorf("%w: read page %d reference: %v", ErrInvalidStateFormat, pageIndex, err)
+			}
+			pos := binary.BigEndian.Uint32(buf[5:9])
+			if pos >= uint32(len(records)) || records[pos] == nil {
+				return fmt.Errorf("%w: page %d references invalid record %d", ErrInvalidStateFormat, pageIndex, pos)
+			}
+			// Copy the data as pages are modified in place during execution
+			*page = *records[pos]
+		default:
+			return fmt.Errorf("%w: unknown page kind %d", ErrInvalidStateFormat, buf[4])
+		}
+		if buf[4] == pageKindData {
+			records = append(records, page)
+		} else {
+			records = append(records, nil)
+		}
+	}
+	return nil
+}
+
+// ReadState reads a state from r, detecting whether it is in the binary or JSON format.
+func ReadState(r io.Reader) (*State, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(stateFormatMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	var state State
+	if IsBinaryState(magic) {
+		if err := state.Deserialize(br); err != nil {
+			return nil, err
+		}
+		return &state, nil
+	}
+	if err := json.NewDecoder(br).Decode(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -0,0 +1,126 @@
+package mipsevm
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSerializeRoundTrip(t *testing.T) {
+	state := serializeTestState()
+	var buf bytes.Buffer
+	require.NoError(t, state.Serialize(&buf))
+
+	var result State
+	require.NoError(t, result.Deserialize(&buf))
+	require.Equal(t, state.EncodeWitness(), result.EncodeWitness())
+	require.Equal(t, state.LastHint, result.LastHint)
+	require.Equal(t, state.Memory.PageCount(), result.Memory.PageCount())
+	for _, addr := range []uint32{0x1000, 0x2000, 0x3000, 0x5000, 0x10000} {
+		require.Equal(t, state.Memory.GetMemory(addr), result.Memory.GetMemory(addr))
+	}
+}
+
+func TestStateSerializeDeduplicatesPages(t *testing.T) {
+	state := serializeTestState()
+	var buf bytes.Buffer
+	require.NoError(t, state.Serialize(&buf))
+	// Pages 0x2000 and 0x3000 are identical and 0x5000 is zero so only two pages of data are stored
+	require.Less(t, buf.Len(), 3*PageSize)
+
+	var result State
+	require.NoError(t, result.Deserialize(&buf))
+	// Modifying a deduplicated page must not affect its copies
+	result.Memory.SetMemory(0x3000, 0x42)
+	require.Equal(t, uint32(0x42), result.Memory.GetMemory(0x3000))
+	require.Equal(t, uint32(0xbbbbbbbb), result.Memory.GetMemory(0x2000))
+}
+
+func TestStateSerializeEmpty(t *testing.T) {
+	state := &State{Memory: NewMemory()}
+	var buf bytes.Buffer
+	require.NoError(t, state.Serialize(&buf))
+
+	var result State
+	require.NoError(t, result.Deserialize(&buf))
+	require.Equal(t, state.EncodeWitness(), result.EncodeWitness())
+	require.Nil(t, result.LastHint)
+}
+
+func TestStateDeserializeInvalid(t *testing.T) {
+	valid := func(t *testing.T) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, serializeTestState().Serialize(&buf))
+		return buf.Bytes()
+	}
+
+	t.Run("MissingMagic", func(t *testing.T) {
+		data := valid(t)
+		data[0] = 'X'
+		require.ErrorIs(t, new(State).Deserialize(bytes.NewReader(data)), ErrInvalidStateFormat)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		data := valid(t)
+		data[4] = StateFormatVersion + 1
+		require.ErrorIs(t, new(State).Deserialize(bytes.NewReader(data)), ErrUnsupportedStateFormat)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		data := valid(t)
+		require.ErrorIs(t, new(State).Deserialize(bytes.NewReader(data[:len(data)-10])), ErrInvalidStateFormat)
+	})
+}
+
+func TestReadState(t *testing.T) {
+	state := serializeTestState()
+
+	t.Run("Binary", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, state.Serialize(&buf))
+		result, err := ReadState(&buf)
+		require.NoError(t, err)
+		require.Equal(t, state.EncodeWitness(), result.EncodeWitness())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(state)
+		require.NoError(t, err)
+		result, err := ReadState(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, state.EncodeWitness(), result.EncodeWitness())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := ReadState(bytes.NewReader(nil))
+		require.Error(t, err)
+	})
+}
+
+func serializeTestState() *State {
+	state := &State{
+		Memory:         NewMemory(),
+		PreimageKey:    common.Hash{0xaa},
+		PreimageOffset: 12,
+		PC:             0x1000,
+		NextPC:         0x1004,
+		LO:             1,
+		HI:             2,
+		Heap:           0x20000000,
+		ExitCode:       3,
+		Exited:         true,
+		Step:           123456789,
+		LastHint:       []byte{0, 0, 0, 2, 0xab},
+	}
+	for i := range state.Registers {
+		state.Registers[i] = uint32(i * 7)
+	}
+	state.Memory.SetMemory(0x1000, 0xaaaaaaaa)
+	state.Memory.SetMemory(0x2000, 0xbbbbbbbb)
+	state.Memory.SetMemory(0x3000, 0xbbbbbbbb)
+	state.Memory.AllocPage(0x5000 >> PageAddrSize)
+	return state
+}
@@ -0,0 +1,38 @@
+package mipsevm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// InstructionTracer writes a line per traced step to the underlying writer,
+// with the step number, PC, instruction and the symbol the PC falls in:
+//
+//	<step> <pc> <insn> <symbol>
+//
+// The output is buffered, Flush must be called once tracing completes.
+type InstructionTracer struct {
+	meta *Metadata
+	w    *bufio.Writer
+}
+
+func NewInstructionTracer(w io.Writer, meta *Metadata) *InstructionTracer {
+	return &InstructionTracer{
+		meta: meta,
+		w:    bufio.NewWriter(w),
+	}
+}
+
+// Trace records the instruction that is about to be executed by the next step of the state.
+func (t *InstructionTracer) Trace(state *State) error {
+	if state.Exited {
+		return nil
+	}
+	_, err := fmt.Fprintf(t.w, "%d %08x %08x %s\n", state.Step, state.PC, state.Memory.GetMemory(state.PC), t.meta.LookupSymbol(state.PC))
+	return err
+}
+
+func (t *InstructionTracer) Flush() error {
+	return t.w.Flush()
+}
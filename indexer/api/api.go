@@ -2,7 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/indexer/database"
 	"github.com/ethereum/go-ethereum/common"
@@ -16,56 +19,184 @@ type PaginationResponse struct {
 	HasNextPage bool        `json:"hasNextPage"`
 }
 
+// WithdrawalStatus is the stage of the multistep (bedrock) withdrawal process
+type WithdrawalStatus string
+
+const (
+	WithdrawalStatusInitiated       WithdrawalStatus = "initiated"
+	WithdrawalStatusReadyToProve    WithdrawalStatus = "ready_to_prove"
+	WithdrawalStatusProven          WithdrawalStatus = "proven"
+	WithdrawalStatusReadyToFinalize WithdrawalStatus = "ready_to_finalize"
+	WithdrawalStatusFinalized       WithdrawalStatus = "finalized"
+)
+
+type WithdrawalStatusResponse struct {
+	Status     WithdrawalStatus                                  `json:"status"`
+	Withdrawal *database.L2BridgeWithdrawalWithTransactionHashes `json:"withdrawal"`
+}
+
 func (a *Api) L1DepositsHandler(w http.ResponseWriter, r *http.Request) {
 	bv := a.BridgeTransfersView
-	address := common.HexToAddress(chi.URLParam(r, "address"))
+	address, err := addressFromURL(r, "address")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// limit := getIntFromQuery(r, "limit", 10)
-	// cursor := r.URL.Query().Get("cursor")
-	// sortDirection := r.URL.Query().Get("sortDirection")
+	filter, err := bridgeTransfersFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	deposits, err := bv.L1BridgeDepositsByAddress(address)
+	deposits, err := bv.L1BridgeDepositsByAddress(address, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// This is not the shape of the response we want!!!
-	// will add in the individual features in future prs 1 by 1
 	response := PaginationResponse{
-		Data: deposits,
-		// Cursor:      nextCursor,
-		HasNextPage: false,
+		Data:        deposits.Deposits,
+		HasNextPage: deposits.HasNextPage,
+	}
+	if deposits.HasNextPage {
+		response.Cursor = deposits.Cursor.String()
 	}
 
 	jsonResponse(w, response, http.StatusOK)
 }
 
+func (a *Api) L1DepositHandler(w http.ResponseWriter, r *http.Request) {
+	bv := a.BridgeTransfersView
+	txHash, err := hashFromURL(r, "txHash")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deposit, err := bv.L1BridgeDepositByTransactionHash(txHash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if deposit == nil {
+		http.Error(w, "deposit not found", http.StatusNotFound)
+		return
+	}
+
+	jsonResponse(w, deposit, http.StatusOK)
+}
+
 func (a *Api) L2WithdrawalsHandler(w http.ResponseWriter, r *http.Request) {
 	bv := a.BridgeTransfersView
-	address := common.HexToAddress(chi.URLParam(r, "address"))
+	address, err := addressFromURL(r, "address")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// limit := getIntFromQuery(r, "limit", 10)
-	// cursor := r.URL.Query().Get("cursor")
-	// sortDirection := r.URL.Query().Get("sortDirection")
+	filter, err := bridgeTransfersFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	withdrawals, err := bv.L2BridgeWithdrawalsByAddress(address)
+	withdrawals, err := bv.L2BridgeWithdrawalsByAddress(address, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// This is not the shape of the response we want!!!
-	// will add in the individual features in future prs 1 by 1
 	response := PaginationResponse{
-		Data: withdrawals,
-		// Cursor:      nextCursor,
-		HasNextPage: false,
+		Data:        withdrawals.Withdrawals,
+		HasNextPage: withdrawals.HasNextPage,
+	}
+	if withdrawals.HasNextPage {
+		response.Cursor = withdrawals.Cursor.String()
 	}
 
 	jsonResponse(w, response, http.StatusOK)
 }
 
+func (a *Api) L2WithdrawalHandler(w http.ResponseWriter, r *http.Request) {
+	bv := a.BridgeTransfersView
+	txHash, err := hashFromURL(r, "txHash")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	withdrawal, err := bv.L2BridgeWithdrawalByTransactionHash(txHash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if withdrawal == nil {
+		http.Error(w, "withdrawal not found", http.StatusNotFound)
+		return
+	}
+
+	jsonResponse(w, withdrawal, http.StatusOK)
+}
+
+func (a *Api) L2WithdrawalStatusHandler(w http.ResponseWriter, r *http.Request) {
+	bv := a.BridgeTransfersView
+	txHash, err := hashFromURL(r, "txHash")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	withdrawal, err := bv.L2BridgeWithdrawalByTransactionHash(txHash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if withdrawal == nil {
+		http.Error(w, "withdrawal not found", http.StatusNotFound)
+		return
+	}
+
+	latestOutput, err := a.BlocksView.LatestCheckpointedOutput()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := WithdrawalStatusResponse{
+		Status:     a.withdrawalStatus(withdrawal, latestOutput),
+		Withdrawal: withdrawal,
+	}
+
+	jsonResponse(w, response, http.StatusOK)
+}
+
+// withdrawalStatus derives the stage of a withdrawal. A withdrawal can be proven once an output
+// has been proposed for its L2 block, and finalized once the finalization period has elapsed
+func (a *Api) withdrawalStatus(withdrawal *database.L2BridgeWithdrawalWithTransactionHashes, latestOutput *database.OutputProposal) WithdrawalStatus {
+	switch {
+	case withdrawal.FinalizedL1TransactionHash != (common.Hash{}):
+		return WithdrawalStatusFinalized
+	case withdrawal.ProvenL1TransactionHash != (common.Hash{}):
+		finalizableAt := time.Unix(int64(withdrawal.ProvenL1Timestamp), 0).Add(a.FinalizationPeriod)
+		if !a.now().Before(finalizableAt) {
+			return WithdrawalStatusReadyToFinalize
+		}
+		return WithdrawalStatusProven
+	case latestOutput != nil && latestOutput.L2BlockNumber.Int.Cmp(withdrawal.L2BlockNumber.Int) >= 0:
+		return WithdrawalStatusReadyToProve
+	default:
+		return WithdrawalStatusInitiated
+	}
+}
+
+func (a *Api) TokensHandler(w http.ResponseWriter, r *http.Request) {
+	tokens, err := a.BridgedTokensView.BridgedTokens()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, tokens, http.StatusOK)
+}
+
 func (a *Api) HealthzHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, "ok", http.StatusOK)
 }
@@ -78,21 +209,90 @@ func jsonResponse(w http.ResponseWriter, data interface{}, statusCode int) {
 	}
 }
 
+func addressFromURL(r *http.Request, key string) (common.Address, error) {
+	param := chi.URLParam(r, key)
+	if !common.IsHexAddress(param) {
+		return common.Address{}, fmt.Errorf("invalid %s: %s", key, param)
+	}
+	return common.HexToAddress(param), nil
+}
+
+func hashFromURL(r *http.Request, key string) (common.Hash, error) {
+	var hash common.Hash
+	if err := hash.UnmarshalText([]byte(chi.URLParam(r, key))); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return hash, nil
+}
+
+// bridgeTransfersFilterFromQuery parses the `limit`, `cursor`, `l1TokenAddress`, `l2TokenAddress`,
+// `fromTimestamp` and `toTimestamp` query parameters. All of them are optional
+func bridgeTransfersFilterFromQuery(r *http.Request) (database.BridgeTransfersFilter, error) {
+	var filter database.BridgeTransfersFilter
+	query := r.URL.Query()
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > database.MaxBridgeTransfersPageLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", database.MaxBridgeTransfersPageLimit)
+		}
+		filter.Limit = value
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		if err := filter.Cursor.UnmarshalText([]byte(cursor)); err != nil {
+			return filter, fmt.Errorf("invalid cursor: %w", err)
+		}
+	}
+
+	for key, token := range map[string]*common.Address{"l1TokenAddress": &filter.TokenPair.L1TokenAddress, "l2TokenAddress": &filter.TokenPair.L2TokenAddress} {
+		if value := query.Get(key); value != "" {
+			if !common.IsHexAddress(value) {
+				return filter, fmt.Errorf("invalid %s: %s", key, value)
+			}
+			*token = common.HexToAddress(value)
+		}
+	}
+
+	for key, timestamp := range map[string]*uint64{"fromTimestamp": &filter.FromTimestamp, "toTimestamp": &filter.ToTimestamp} {
+		if value := query.Get(key); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %s", key, value)
+			}
+			*timestamp = parsed
+		}
+	}
+
+	return filter, nil
+}
+
 type Api struct {
 	Router              *chi.Mux
 	BridgeTransfersView database.BridgeTransfersView
+	BlocksView          database.BlocksView
+	BridgedTokensView   database.BridgedTokensView
+
+	// FinalizationPeriod proven withdrawals wait for before they can be finalized
+	FinalizationPeriod time.Duration
+
+	now func() time.Time
 }
 
-func NewApi(bv database.BridgeTransfersView) *Api {
+func NewApi(bv database.BridgeTransfersView, blv database.BlocksView, tv database.BridgedTokensView, finalizationPeriod time.Duration) *Api {
 	r := chi.NewRouter()
 
-	api := &Api{Router: r, BridgeTransfersView: bv}
+	api := &Api{Router: r, BridgeTransfersView: bv, BlocksView: blv, BridgedTokensView: tv, FinalizationPeriod: finalizationPeriod, now: time.Now}
 
 	// these regex are .+ because I wasn't sure what they should be
 	// don't want a regex for addresses because would prefer to validate the address
 	// with go-ethereum and throw a friendly error message
 	r.Get("/api/v0/deposits/{address:.+}", api.L1DepositsHandler)
+	r.Get("/api/v0/deposit/{txHash}", api.L1DepositHandler)
 	r.Get("/api/v0/withdrawals/{address:.+}", api.L2WithdrawalsHandler)
+	r.Get("/api/v0/withdrawal/{txHash}", api.L2WithdrawalHandler)
+	r.Get("/api/v0/withdrawal/{txHash}/status", api.L2WithdrawalStatusHandler)
+	r.Get("/api/v0/tokens", api.TokensHandler)
 	r.Get("/healthz", api.HealthzHandler)
 
 	return api
@@ -1,18 +1,24 @@
 package api
 
 import (
+	"encoding/json"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/indexer/database"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // MockBridgeTransfersView mocks the BridgeTransfersView interface
-type MockBridgeTransfersView struct{}
+type MockBridgeTransfersView struct {
+	// last filter queried with
+	filter database.BridgeTransfersFilter
+}
 
 var (
 	deposit = database.L1BridgeDeposit{
@@ -38,12 +44,28 @@ func (mbv *MockBridgeTransfersView) L1BridgeDepositByCrossDomainMessengerNonce(n
 	return &deposit, nil
 }
 
-func (mbv *MockBridgeTransfersView) L1BridgeDepositsByAddress(address common.Address) ([]*database.L1BridgeDepositWithTransactionHashes, error) {
-	return []*database.L1BridgeDepositWithTransactionHashes{
-		{
-			L1BridgeDeposit:   deposit,
-			L1TransactionHash: common.HexToHash("0x123"),
+func (mbv *MockBridgeTransfersView) L1BridgeDepositByTransactionHash(txHash common.Hash) (*database.L1BridgeDepositWithTransactionHashes, error) {
+	if txHash != common.HexToHash("0x123") {
+		return nil, nil
+	}
+
+	return &database.L1BridgeDepositWithTransactionHashes{
+		L1BridgeDeposit:   deposit,
+		L1TransactionHash: common.HexToHash("0x123"),
+	}, nil
+}
+
+func (mbv *MockBridgeTransfersView) L1BridgeDepositsByAddress(address common.Address, filter database.BridgeTransfersFilter) (*database.L1BridgeDepositsResponse, error) {
+	mbv.filter = filter
+	return &database.L1BridgeDepositsResponse{
+		Deposits: []*database.L1BridgeDepositWithTransactionHashes{
+			{
+				L1BridgeDeposit:   deposit,
+				L1TransactionHash: common.HexToHash("0x123"),
+			},
 		},
+		Cursor:      deposit.TransactionSourceHash,
+		HasNextPage: true,
 	}, nil
 }
 
@@ -55,17 +77,58 @@ func (mbv *MockBridgeTransfersView) L2BridgeWithdrawalByCrossDomainMessengerNonc
 	return &withdrawal, nil
 }
 
-func (mbv *MockBridgeTransfersView) L2BridgeWithdrawalsByAddress(address common.Address) ([]*database.L2BridgeWithdrawalWithTransactionHashes, error) {
-	return []*database.L2BridgeWithdrawalWithTransactionHashes{
+func (mbv *MockBridgeTransfersView) L2BridgeWithdrawalByTransactionHash(txHash common.Hash) (*database.L2BridgeWithdrawalWithTransactionHashes, error) {
+	if txHash != common.HexToHash("0x789") {
+		return nil, nil
+	}
+
+	return &database.L2BridgeWithdrawalWithTransactionHashes{
+		L2BridgeWithdrawal: withdrawal,
+		L2TransactionHash:  common.HexToHash("0x789"),
+		L2BlockNumber:      database.U256{Int: big.NewInt(10)},
+	}, nil
+}
+
+func (mbv *MockBridgeTransfersView) L2BridgeWithdrawalsByAddress(address common.Address, filter database.BridgeTransfersFilter) (*database.L2BridgeWithdrawalsResponse, error) {
+	mbv.filter = filter
+	return &database.L2BridgeWithdrawalsResponse{
+		Withdrawals: []*database.L2BridgeWithdrawalWithTransactionHashes{
+			{
+				L2BridgeWithdrawal: withdrawal,
+				L2TransactionHash:  common.HexToHash("0x789"),
+			},
+		},
+	}, nil
+}
+
+// MockBlocksView mocks the BlocksView interface
+type MockBlocksView struct {
+	database.BlocksView
+	latestOutput *database.OutputProposal
+}
+
+func (mbv *MockBlocksView) LatestCheckpointedOutput() (*database.OutputProposal, error) {
+	return mbv.latestOutput, nil
+}
+
+// MockBridgedTokensView mocks the BridgedTokensView interface
+type MockBridgedTokensView struct {
+	database.BridgedTokensView
+}
+
+func (mtv *MockBridgedTokensView) BridgedTokens() ([]*database.BridgedToken, error) {
+	return []*database.BridgedToken{
 		{
-			L2BridgeWithdrawal: withdrawal,
-			L2TransactionHash:  common.HexToHash("0x789"),
+			TokenPair: database.TokenPair{L1TokenAddress: common.HexToAddress("0x1"), L2TokenAddress: common.HexToAddress("0x2")},
+			L1Token:   database.TokenMetadata{Name: "Token", Symbol: "TKN", Decimals: 18},
+			L2Token:   database.TokenMetadata{Name: "Token", Symbol: "TKN", Decimals: 18},
+			Verified:  true,
 		},
 	}, nil
 }
 
 func TestHealthz(t *testing.T) {
-	api := NewApi(&MockBridgeTransfersView{})
+	api := NewApi(&MockBridgeTransfersView{}, &MockBlocksView{}, &MockBridgedTokensView{}, time.Hour)
 	request, err := http.NewRequest("GET", "/healthz", nil)
 	assert.Nil(t, err)
 
@@ -76,8 +139,8 @@ func TestHealthz(t *testing.T) {
 }
 
 func TestL1BridgeDepositsHandler(t *testing.T) {
-	api := NewApi(&MockBridgeTransfersView{})
-	request, err := http.NewRequest("GET", "/api/v0/deposits/0x123", nil)
+	api := NewApi(&MockBridgeTransfersView{}, &MockBlocksView{}, &MockBridgedTokensView{}, time.Hour)
+	request, err := http.NewRequest("GET", "/api/v0/deposits/0x0000000000000000000000000000000000000123", nil)
 	assert.Nil(t, err)
 
 	responseRecorder := httptest.NewRecorder()
@@ -87,8 +150,8 @@ func TestL1BridgeDepositsHandler(t *testing.T) {
 }
 
 func TestL2BridgeWithdrawalsByAddressHandler(t *testing.T) {
-	api := NewApi(&MockBridgeTransfersView{})
-	request, err := http.NewRequest("GET", "/api/v0/withdrawals/0x123", nil)
+	api := NewApi(&MockBridgeTransfersView{}, &MockBlocksView{}, &MockBridgedTokensView{}, time.Hour)
+	request, err := http.NewRequest("GET", "/api/v0/withdrawals/0x0000000000000000000000000000000000000123", nil)
 	assert.Nil(t, err)
 
 	responseRecorder := httptest.NewRecorder()
@@ -96,3 +159,123 @@ func TestL2BridgeWithdrawalsByAddressHandler(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, responseRecorder.Code)
 }
+
+func TestBridgeTransfersFilter(t *testing.T) {
+	bv := &MockBridgeTransfersView{}
+	api := NewApi(bv, &MockBlocksView{}, &MockBridgedTokensView{}, time.Hour)
+
+	url := "/api/v0/deposits/0x0000000000000000000000000000000000000123?limit=10&cursor=" + deposit.TransactionSourceHash.String() +
+		"&l1TokenAddress=0x0000000000000000000000000000000000000001&l2TokenAddress=0x0000000000000000000000000000000000000002&fromTimestamp=100&toTimestamp=200"
+	request, err := http.NewRequest("GET", url, nil)
+	require.NoError(t, err)
+	responseRecorder := httptest.NewRecorder()
+	api.Router.ServeHTTP(responseRecorder, request)
+	require.Equal(t, http.StatusOK, responseRecorder.Code)
+
+	require.Equal(t, database.BridgeTransfersFilter{
+		Cursor: deposit.TransactionSourceHash,
+		Limit:  10,
+		TokenPair: database.TokenPair{
+			L1TokenAddress: common.HexToAddress("0x1"),
+			L2TokenAddress: common.HexToAddress("0x2"),
+		},
+		FromTimestamp: 100,
+		ToTimestamp:   200,
+	}, bv.filter)
+
+	var response PaginationResponse
+	require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+	require.True(t, response.HasNextPage)
+	require.Equal(t, deposit.TransactionSourceHash.String(), response.Cursor)
+
+	for _, query := range []string{"limit=0", "limit=101", "cursor=0x1", "l1TokenAddress=0x1", "fromTimestamp=-1"} {
+		request, err := http.NewRequest("GET", "/api/v0/withdrawals/0x0000000000000000000000000000000000000123?"+query, nil)
+		require.NoError(t, err)
+		responseRecorder := httptest.NewRecorder()
+		api.Router.ServeHTTP(responseRecorder, request)
+		require.Equal(t, http.StatusBadRequest, responseRecorder.Code, query)
+	}
+
+	request, err = http.NewRequest("GET", "/api/v0/withdrawals/0x123", nil)
+	require.NoError(t, err)
+	responseRecorder = httptest.NewRecorder()
+	api.Router.ServeHTTP(responseRecorder, request)
+	require.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+}
+
+func TestBridgeTransferByTransactionHashHandlers(t *testing.T) {
+	api := NewApi(&MockBridgeTransfersView{}, &MockBlocksView{}, &MockBridgedTokensView{}, time.Hour)
+
+	tests := []struct {
+		url  string
+		code int
+	}{
+		{"/api/v0/deposit/" + common.HexToHash("0x123").String(), http.StatusOK},
+		{"/api/v0/deposit/" + common.HexToHash("0x456").String(), http.StatusNotFound},
+		{"/api/v0/withdrawal/" + common.HexToHash("0x789").String(), http.StatusOK},
+		{"/api/v0/withdrawal/" + common.HexToHash("0x456").String(), http.StatusNotFound},
+		{"/api/v0/withdrawal/0x789", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		request, err := http.NewRequest("GET", test.url, nil)
+		require.NoError(t, err)
+		responseRecorder := httptest.NewRecorder()
+		api.Router.ServeHTTP(responseRecorder, request)
+		require.Equal(t, test.code, responseRecorder.Code, test.url)
+	}
+}
+
+func TestL2WithdrawalStatusHandler(t *testing.T) {
+	blv := &MockBlocksView{}
+	api := NewApi(&MockBridgeTransfersView{}, blv, &MockBridgedTokensView{}, time.Hour)
+	request, err := http.NewRequest("GET", "/api/v0/withdrawal/"+common.HexToHash("0x789").String()+"/status", nil)
+	require.NoError(t, err)
+
+	responseRecorder := httptest.NewRecorder()
+	api.Router.ServeHTTP(responseRecorder, request)
+	require.Equal(t, http.StatusOK, responseRecorder.Code)
+
+	var response WithdrawalStatusResponse
+	require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+	require.Equal(t, WithdrawalStatusInitiated, response.Status)
+	require.Equal(t, common.HexToHash("0x789"), response.Withdrawal.L2TransactionHash)
+}
+
+func TestWithdrawalStatus(t *testing.T) {
+	now := time.Unix(10_000, 0)
+	api := NewApi(&MockBridgeTransfersView{}, &MockBlocksView{}, &MockBridgedTokensView{}, time.Hour)
+	api.now = func() time.Time { return now }
+
+	withdrawal := &database.L2BridgeWithdrawalWithTransactionHashes{L2BlockNumber: database.U256{Int: big.NewInt(10)}}
+	require.Equal(t, WithdrawalStatusInitiated, api.withdrawalStatus(withdrawal, nil))
+	require.Equal(t, WithdrawalStatusInitiated, api.withdrawalStatus(withdrawal, &database.OutputProposal{L2BlockNumber: database.U256{Int: big.NewInt(9)}}))
+
+	output := &database.OutputProposal{L2BlockNumber: database.U256{Int: big.NewInt(10)}}
+	require.Equal(t, WithdrawalStatusReadyToProve, api.withdrawalStatus(withdrawal, output))
+
+	withdrawal.ProvenL1TransactionHash = common.HexToHash("0x1")
+	withdrawal.ProvenL1Timestamp = uint64(now.Add(-time.Hour).Unix()) + 1
+	require.Equal(t, WithdrawalStatusProven, api.withdrawalStatus(withdrawal, output))
+
+	withdrawal.ProvenL1Timestamp = uint64(now.Add(-time.Hour).Unix())
+	require.Equal(t, WithdrawalStatusReadyToFinalize, api.withdrawalStatus(withdrawal, output))
+
+	withdrawal.FinalizedL1TransactionHash = common.HexToHash("0x2")
+	require.Equal(t, WithdrawalStatusFinalized, api.withdrawalStatus(withdrawal, output))
+}
+
+func TestTokensHandler(t *testing.T) {
+	api := NewApi(&MockBridgeTransfersView{}, &MockBlocksView{}, &MockBridgedTokensView{}, time.Hour)
+	request, err := http.NewRequest("GET", "/api/v0/tokens", nil)
+	require.NoError(t, err)
+
+	responseRecorder := httptest.NewRecorder()
+	api.Router.ServeHTTP(responseRecorder, request)
+	require.Equal(t, http.StatusOK, responseRecorder.Code)
+
+	var tokens []*database.BridgedToken
+	require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &tokens))
+	require.Len(t, tokens, 1)
+	require.Equal(t, "TKN", tokens[0].L1Token.Symbol)
+	require.True(t, tokens[0].Verified)
+}
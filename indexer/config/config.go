@@ -18,6 +18,11 @@ type Config struct {
 	API     APIConfig
 	Metrics MetricsConfig
 	Logger  log.Logger `toml:"-"`
+
+	// User-defined handlers of the events emitted by contracts on either chain. These
+	// are supplied programmatically when embedding the indexer
+	L1ContractEventHandlers []processor.ContractEventHandler `toml:"-"`
+	L2ContractEventHandlers []processor.ContractEventHandler `toml:"-"`
 }
 
 // ChainConfig configures of the chain being indexed
@@ -25,6 +30,14 @@ type ChainConfig struct {
 	// Configure known chains with the l2 chain id
 	Preset      int
 	L1Contracts processor.L1Contracts
+
+	// Index up to the latest block while tracking the "finalized" block tag. Indexed data
+	// is marked finalized once its block is, or rolled back if it's reorged out. Otherwise
+	// the latest block is indexed as if it were finalized
+	FollowUnsafeHead bool `toml:"follow-unsafe-head"`
+
+	// Backfill the historical blocks of either chain with concurrent workers on startup
+	Backfill processor.BackfillConfig
 }
 
 // RPCsConfig configures the RPC urls
@@ -16,6 +16,11 @@ func TestLoadConfig(t *testing.T) {
 	testData := `
 		[chain]
 		preset = 1234
+		follow-unsafe-head = true
+
+		[chain.backfill]
+		workers = 4
+		chunk-size = 1000
 
 		[rpcs]
 		l1-rpc = "https://l1.example.com"
@@ -49,6 +54,9 @@ func TestLoadConfig(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, conf.Chain.Preset, 1234)
+	require.True(t, conf.Chain.FollowUnsafeHead)
+	require.Equal(t, conf.Chain.Backfill.Workers, 4)
+	require.Equal(t, conf.Chain.Backfill.ChunkSize, uint64(1000))
 	require.Equal(t, conf.RPCs.L1RPC, "https://l1.example.com")
 	require.Equal(t, conf.RPCs.L2RPC, "https://l2.example.com")
 	require.Equal(t, conf.DB.Host, "127.0.0.1")
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 /**
@@ -23,6 +24,10 @@ type BlockHeader struct {
 	Number     U256
 	Timestamp  uint64
 
+	// Headers past the finalized height, indexed when following
+	// the unsafe head, are not finalized and may be reorged out
+	Finalized bool
+
 	GethHeader *GethHeader `gorm:"serializer:rlp;column:rlp_bytes"`
 }
 
@@ -45,6 +50,32 @@ type L2BlockHeader struct {
 	BlockHeader
 }
 
+// Chains, as identified in the backfill markers
+const (
+	L1Chain = "l1"
+	L2Chain = "l2"
+)
+
+// BackfillMarker is the last header committed by the backfill of a chain. Blocks without
+// data of interest are not indexed, so the progress is tracked separately from the headers
+type BackfillMarker struct {
+	Chain  string      `gorm:"primaryKey"`
+	Hash   common.Hash `gorm:"serializer:json"`
+	Number U256
+
+	GethHeader *GethHeader `gorm:"serializer:rlp;column:rlp_bytes"`
+}
+
+func BackfillMarkerFromGethHeader(chain string, header *types.Header) *BackfillMarker {
+	return &BackfillMarker{
+		Chain:  chain,
+		Hash:   header.Hash(),
+		Number: U256{Int: header.Number},
+
+		GethHeader: (*GethHeader)(header),
+	}
+}
+
 type LegacyStateBatch struct {
 	// `default:0` is added since gorm would interepret 0 as NULL
 	// violating the primary key constraint.
@@ -67,12 +98,18 @@ type OutputProposal struct {
 type BlocksView interface {
 	L1BlockHeader(*big.Int) (*L1BlockHeader, error)
 	LatestL1BlockHeader() (*L1BlockHeader, error)
+	LatestFinalizedL1BlockHeader() (*L1BlockHeader, error)
+	UnfinalizedL1BlockHeaders() ([]*L1BlockHeader, error)
 
 	LatestCheckpointedOutput() (*OutputProposal, error)
 	OutputProposal(index *big.Int) (*OutputProposal, error)
 
 	L2BlockHeader(*big.Int) (*L2BlockHeader, error)
 	LatestL2BlockHeader() (*L2BlockHeader, error)
+	LatestFinalizedL2BlockHeader() (*L2BlockHeader, error)
+	UnfinalizedL2BlockHeaders() ([]*L2BlockHeader, error)
+
+	BackfillMarker(chain string) (*BackfillMarker, error)
 }
 
 type BlocksDB interface {
@@ -83,6 +120,8 @@ type BlocksDB interface {
 
 	StoreLegacyStateBatches([]*LegacyStateBatch) error
 	StoreOutputProposals([]*OutputProposal) error
+
+	StoreBackfillMarker(*BackfillMarker) error
 }
 
 /**
@@ -142,6 +181,31 @@ func (db *blocksDB) LatestL1BlockHeader() (*L1BlockHeader, error) {
 	return &l1Header, nil
 }
 
+func (db *blocksDB) LatestFinalizedL1BlockHeader() (*L1BlockHeader, error) {
+	var l1Header L1BlockHeader
+	result := db.gorm.Where("finalized").Order("number DESC").Take(&l1Header)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, result.Error
+	}
+
+	return &l1Header, nil
+}
+
+// UnfinalizedL1BlockHeaders retrieves the headers indexed past the finalized height, ordered from the latest
+func (db *blocksDB) UnfinalizedL1BlockHeaders() ([]*L1BlockHeader, error) {
+	l1Headers := []*L1BlockHeader{}
+	result := db.gorm.Where("NOT finalized").Order("number DESC").Find(&l1Headers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return l1Headers, nil
+}
+
 func (db *blocksDB) LatestCheckpointedOutput() (*OutputProposal, error) {
 	var outputProposal OutputProposal
 	result := db.gorm.Order("l2_output_index DESC").Take(&outputProposal)
@@ -205,3 +269,49 @@ func (db *blocksDB) LatestL2BlockHeader() (*L2BlockHeader, error) {
 	result.Logger.Info(context.Background(), "number ", l2Header.Number)
 	return &l2Header, nil
 }
+
+func (db *blocksDB) LatestFinalizedL2BlockHeader() (*L2BlockHeader, error) {
+	var l2Header L2BlockHeader
+	result := db.gorm.Where("finalized").Order("number DESC").Take(&l2Header)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, result.Error
+	}
+
+	return &l2Header, nil
+}
+
+// UnfinalizedL2BlockHeaders retrieves the headers indexed past the finalized height, ordered from the latest
+func (db *blocksDB) UnfinalizedL2BlockHeaders() ([]*L2BlockHeader, error) {
+	l2Headers := []*L2BlockHeader{}
+	result := db.gorm.Where("NOT finalized").Order("number DESC").Find(&l2Headers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return l2Headers, nil
+}
+
+// Backfill
+
+func (db *blocksDB) BackfillMarker(chain string) (*BackfillMarker, error) {
+	var marker BackfillMarker
+	result := db.gorm.Where(&BackfillMarker{Chain: chain}).Take(&marker)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, result.Error
+	}
+
+	return &marker, nil
+}
+
+func (db *blocksDB) StoreBackfillMarker(marker *BackfillMarker) error {
+	result := db.gorm.Clauses(clause.OnConflict{UpdateAll: true}).Create(marker)
+	return result.Error
+}
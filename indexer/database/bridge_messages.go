@@ -23,8 +23,9 @@ type BridgeMessage struct {
 	SentMessageEventGUID    uuid.UUID
 	RelayedMessageEventGUID *uuid.UUID
 
-	Tx       Transaction `gorm:"embedded"`
-	GasLimit U256
+	Tx        Transaction `gorm:"embedded"`
+	GasLimit  U256
+	Finalized bool
 }
 
 type L1BridgeMessage struct {
@@ -32,8 +32,9 @@ type L1TransactionDeposit struct {
 	Version    U256
 	OpaqueData hexutil.Bytes `gorm:"serializer:json"`
 
-	Tx       Transaction `gorm:"embedded"`
-	GasLimit U256
+	Tx        Transaction `gorm:"embedded"`
+	GasLimit  U256
+	Finalized bool
 }
 
 type L2TransactionWithdrawal struct {
@@ -46,8 +47,9 @@ type L2TransactionWithdrawal struct {
 	FinalizedL1EventGUID *uuid.UUID
 	Succeeded            *bool
 
-	Tx       Transaction `gorm:"embedded"`
-	GasLimit U256
+	Tx        Transaction `gorm:"embedded"`
+	GasLimit  U256
+	Finalized bool
 }
 
 type BridgeTransactionsView interface {
@@ -13,6 +13,9 @@ import (
  * Types
  */
 
+// MaxBridgeTransfersPageLimit is the maximum, and default, number of transfers in a page
+const MaxBridgeTransfersPageLimit = 100
+
 type TokenPair struct {
 	L1TokenAddress common.Address `gorm:"serializer:json"`
 	L2TokenAddress common.Address `gorm:"serializer:json"`
@@ -25,6 +28,7 @@ type L1BridgeDeposit struct {
 
 	Tx        Transaction `gorm:"embedded"`
 	TokenPair TokenPair   `gorm:"embedded"`
+	Finalized bool
 }
 
 type L1BridgeDepositWithTransactionHashes struct {
@@ -41,24 +45,62 @@ type L2BridgeWithdrawal struct {
 
 	Tx        Transaction `gorm:"embedded"`
 	TokenPair TokenPair   `gorm:"embedded"`
+	Finalized bool
+}
+
+type L1BridgeDepositsResponse struct {
+	Deposits []*L1BridgeDepositWithTransactionHashes
+
+	// Cursor of the next page, set when HasNextPage
+	Cursor      common.Hash
+	HasNextPage bool
 }
 
 type L2BridgeWithdrawalWithTransactionHashes struct {
 	L2BridgeWithdrawal L2BridgeWithdrawal `gorm:"embedded"`
 	L2TransactionHash  common.Hash        `gorm:"serializer:json"`
+	L2BlockNumber      U256
 
 	ProvenL1TransactionHash    common.Hash `gorm:"serializer:json"`
 	FinalizedL1TransactionHash common.Hash `gorm:"serializer:json"`
+
+	// Timestamp of the L1 block the withdrawal was proven in, 0 if not proven
+	ProvenL1Timestamp uint64
+}
+
+type L2BridgeWithdrawalsResponse struct {
+	Withdrawals []*L2BridgeWithdrawalWithTransactionHashes
+
+	// Cursor of the next page, set when HasNextPage
+	Cursor      common.Hash
+	HasNextPage bool
+}
+
+// BridgeTransfersFilter pages through and filters the transfers of an address. Transfers are
+// ordered from the latest and zero valued fields are ignored
+type BridgeTransfersFilter struct {
+	// Hash of the last transfer of the previous page, its transaction
+	// source hash for deposits and withdrawal hash for withdrawals
+	Cursor common.Hash
+	Limit  int
+
+	TokenPair TokenPair
+
+	// Inclusive bounds on the transfer timestamps
+	FromTimestamp uint64
+	ToTimestamp   uint64
 }
 
 type BridgeTransfersView interface {
 	L1BridgeDeposit(common.Hash) (*L1BridgeDeposit, error)
 	L1BridgeDepositByCrossDomainMessengerNonce(*big.Int) (*L1BridgeDeposit, error)
-	L1BridgeDepositsByAddress(common.Address) ([]*L1BridgeDepositWithTransactionHashes, error)
+	L1BridgeDepositByTransactionHash(common.Hash) (*L1BridgeDepositWithTransactionHashes, error)
+	L1BridgeDepositsByAddress(common.Address, BridgeTransfersFilter) (*L1BridgeDepositsResponse, error)
 
 	L2BridgeWithdrawal(common.Hash) (*L2BridgeWithdrawal, error)
 	L2BridgeWithdrawalByCrossDomainMessengerNonce(*big.Int) (*L2BridgeWithdrawal, error)
-	L2BridgeWithdrawalsByAddress(common.Address) ([]*L2BridgeWithdrawalWithTransactionHashes, error)
+	L2BridgeWithdrawalByTransactionHash(common.Hash) (*L2BridgeWithdrawalWithTransactionHashes, error)
+	L2BridgeWithdrawalsByAddress(common.Address, BridgeTransfersFilter) (*L2BridgeWithdrawalsResponse, error)
 }
 
 type BridgeTransfersDB interface {
@@ -117,9 +159,7 @@ func (db *bridgeTransfersDB) L1BridgeDepositByCrossDomainMessengerNonce(nonce *b
 	return &deposit, nil
 }
 
-// L1BridgeDepositsByAddress retrieves a list of deposits intiated by the specified address, coupled with the L1/L2 transaction
-// hashes that complete the bridge transaction.
-func (db *bridgeTransfersDB) L1BridgeDepositsByAddress(address common.Address) ([]*L1BridgeDepositWithTransactionHashes, error) {
+func (db *bridgeTransfersDB) l1BridgeDepositsQuery() *gorm.DB {
 	depositsQuery := db.gorm.Table("l1_bridge_deposits").Select(`
 l1_bridge_deposits.*,
 l1_contract_events.transaction_hash AS l1_transaction_hash,
@@ -127,12 +167,17 @@ l1_transaction_deposits.l2_transaction_hash`)
 
 	depositsQuery = depositsQuery.Joins("INNER JOIN l1_transaction_deposits ON l1_bridge_deposits.transaction_source_hash = l1_transaction_deposits.source_hash")
 	depositsQuery = depositsQuery.Joins("INNER JOIN l1_contract_events ON l1_transaction_deposits.initiated_l1_event_guid = l1_contract_events.guid")
+	return depositsQuery
+}
 
-	// add in cursoring options
-	filteredQuery := depositsQuery.Where(&Transaction{FromAddress: address}).Order("l1_bridge_deposits.timestamp DESC").Limit(100)
+// L1BridgeDepositByTransactionHash retrieves the first deposit initiated by the specified L1 transaction, coupled
+// with the L1/L2 transaction hashes that complete the bridge transaction.
+func (db *bridgeTransfersDB) L1BridgeDepositByTransactionHash(txHash common.Hash) (*L1BridgeDepositWithTransactionHashes, error) {
+	eventsQuery := db.gorm.Model(&L1ContractEvent{}).Select("guid").Where(&ContractEvent{TransactionHash: txHash})
+	depositQuery := db.l1BridgeDepositsQuery().Where("l1_transaction_deposits.initiated_l1_event_guid IN (?)", eventsQuery)
 
-	deposits := []*L1BridgeDepositWithTransactionHashes{}
-	result := filteredQuery.Scan(&deposits)
+	var deposit L1BridgeDepositWithTransactionHashes
+	result := depositQuery.Order("l1_contract_events.log_index ASC").Take(&deposit)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -140,7 +185,37 @@ l1_transaction_deposits.l2_transaction_hash`)
 		return nil, result.Error
 	}
 
-	return deposits, nil
+	return &deposit, nil
+}
+
+// L1BridgeDepositsByAddress retrieves a page of deposits intiated by the specified address, coupled with the L1/L2 transaction
+// hashes that complete the bridge transaction.
+func (db *bridgeTransfersDB) L1BridgeDepositsByAddress(address common.Address, filter BridgeTransfersFilter) (*L1BridgeDepositsResponse, error) {
+	filteredQuery := db.l1BridgeDepositsQuery().Where(&Transaction{FromAddress: address}).Where(&filter.TokenPair)
+	filteredQuery = filterTimestamps(filteredQuery, "l1_bridge_deposits", filter)
+	if filter.Cursor != (common.Hash{}) {
+		cursorQuery := db.gorm.Model(&L1BridgeDeposit{}).Select("timestamp, transaction_source_hash").Where(&L1BridgeDeposit{TransactionSourceHash: filter.Cursor})
+		filteredQuery = filteredQuery.Where("(l1_bridge_deposits.timestamp, l1_bridge_deposits.transaction_source_hash) < (?)", cursorQuery)
+	}
+
+	// fetch an extra row to detect the next page
+	limit := pageLimit(filter)
+	filteredQuery = filteredQuery.Order("l1_bridge_deposits.timestamp DESC, l1_bridge_deposits.transaction_source_hash DESC").Limit(limit + 1)
+
+	deposits := []*L1BridgeDepositWithTransactionHashes{}
+	result := filteredQuery.Scan(&deposits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	response := &L1BridgeDepositsResponse{Deposits: deposits}
+	if len(deposits) > limit {
+		response.Deposits = deposits[:limit]
+		response.Cursor = deposits[limit-1].L1BridgeDeposit.TransactionSourceHash
+		response.HasNextPage = true
+	}
+
+	return response, nil
 }
 
 /**
@@ -180,25 +255,31 @@ func (db *bridgeTransfersDB) L2BridgeWithdrawalByCrossDomainMessengerNonce(nonce
 	return &withdrawal, nil
 }
 
-// L2BridgeDepositsByAddress retrieves a list of deposits intiated by the specified address, coupled with the L1/L2 transaction hashes
-// that complete the bridge transaction. The hashes that correspond to with the Bedrock multistep withdrawal process are also surfaced
-func (db *bridgeTransfersDB) L2BridgeWithdrawalsByAddress(address common.Address) ([]*L2BridgeWithdrawalWithTransactionHashes, error) {
+func (db *bridgeTransfersDB) l2BridgeWithdrawalsQuery() *gorm.DB {
 	withdrawalsQuery := db.gorm.Table("l2_bridge_withdrawals").Select(`
 l2_bridge_withdrawals.*,
 l2_contract_events.transaction_hash AS l2_transaction_hash,
+l2_block_headers.number AS l2_block_number,
 proven_l1_contract_events.transaction_hash AS proven_l1_transaction_hash,
+COALESCE(proven_l1_contract_events.timestamp, 0) AS proven_l1_timestamp,
 finalized_l1_contract_events.transaction_hash AS finalized_l1_transaction_hash`)
 
 	withdrawalsQuery = withdrawalsQuery.Joins("INNER JOIN l2_transaction_withdrawals ON l2_bridge_withdrawals.transaction_withdrawal_hash = l2_transaction_withdrawals.withdrawal_hash")
 	withdrawalsQuery = withdrawalsQuery.Joins("INNER JOIN l2_contract_events ON l2_transaction_withdrawals.initiated_l2_event_guid = l2_contract_events.guid")
+	withdrawalsQuery = withdrawalsQuery.Joins("INNER JOIN l2_block_headers ON l2_contract_events.block_hash = l2_block_headers.hash")
 	withdrawalsQuery = withdrawalsQuery.Joins("LEFT JOIN l1_contract_events AS proven_l1_contract_events ON l2_transaction_withdrawals.proven_l1_event_guid = proven_l1_contract_events.guid")
 	withdrawalsQuery = withdrawalsQuery.Joins("LEFT JOIN l1_contract_events AS finalized_l1_contract_events ON l2_transaction_withdrawals.finalized_l1_event_guid = finalized_l1_contract_events.guid")
+	return withdrawalsQuery
+}
 
-	// add in cursoring options
-	filteredQuery := withdrawalsQuery.Where(&Transaction{FromAddress: address}).Order("l2_bridge_withdrawals.timestamp DESC").Limit(100)
+// L2BridgeWithdrawalByTransactionHash retrieves the first withdrawal initiated by the specified L2 transaction, coupled with the
+// L1/L2 transaction hashes that complete the bridge transaction.
+func (db *bridgeTransfersDB) L2BridgeWithdrawalByTransactionHash(txHash common.Hash) (*L2BridgeWithdrawalWithTransactionHashes, error) {
+	eventsQuery := db.gorm.Model(&L2ContractEvent{}).Select("guid").Where(&ContractEvent{TransactionHash: txHash})
+	withdrawalQuery := db.l2BridgeWithdrawalsQuery().Where("l2_transaction_withdrawals.initiated_l2_event_guid IN (?)", eventsQuery)
 
-	withdrawals := []*L2BridgeWithdrawalWithTransactionHashes{}
-	result := filteredQuery.Scan(&withdrawals)
+	var withdrawal L2BridgeWithdrawalWithTransactionHashes
+	result := withdrawalQuery.Order("l2_contract_events.log_index ASC").Take(&withdrawal)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -206,5 +287,52 @@ finalized_l1_contract_events.transaction_hash AS finalized_l1_transaction_hash`)
 		return nil, result.Error
 	}
 
-	return withdrawals, nil
+	return &withdrawal, nil
+}
+
+// L2BridgeWithdrawalsByAddress retrieves a page of withdrawals intiated by the specified address, coupled with the L1/L2 transaction
+// hashes that complete the bridge transaction. The hashes that correspond to with the Bedrock multistep withdrawal process are also surfaced
+func (db *bridgeTransfersDB) L2BridgeWithdrawalsByAddress(address common.Address, filter BridgeTransfersFilter) (*L2BridgeWithdrawalsResponse, error) {
+	filteredQuery := db.l2BridgeWithdrawalsQuery().Where(&Transaction{FromAddress: address}).Where(&filter.TokenPair)
+	filteredQuery = filterTimestamps(filteredQuery, "l2_bridge_withdrawals", filter)
+	if filter.Cursor != (common.Hash{}) {
+		cursorQuery := db.gorm.Model(&L2BridgeWithdrawal{}).Select("timestamp, transaction_withdrawal_hash").Where(&L2BridgeWithdrawal{TransactionWithdrawalHash: filter.Cursor})
+		filteredQuery = filteredQuery.Where("(l2_bridge_withdrawals.timestamp, l2_bridge_withdrawals.transaction_withdrawal_hash) < (?)", cursorQuery)
+	}
+
+	// fetch an extra row to detect the next page
+	limit := pageLimit(filter)
+	filteredQuery = filteredQuery.Order("l2_bridge_withdrawals.timestamp DESC, l2_bridge_withdrawals.transaction_withdrawal_hash DESC").Limit(limit + 1)
+
+	withdrawals := []*L2BridgeWithdrawalWithTransactionHashes{}
+	result := filteredQuery.Scan(&withdrawals)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	response := &L2BridgeWithdrawalsResponse{Withdrawals: withdrawals}
+	if len(withdrawals) > limit {
+		response.Withdrawals = withdrawals[:limit]
+		response.Cursor = withdrawals[limit-1].L2BridgeWithdrawal.TransactionWithdrawalHash
+		response.HasNextPage = true
+	}
+
+	return response, nil
+}
+
+func filterTimestamps(query *gorm.DB, table string, filter BridgeTransfersFilter) *gorm.DB {
+	if filter.FromTimestamp > 0 {
+		query = query.Where(table+".timestamp >= ?", filter.FromTimestamp)
+	}
+	if filter.ToTimestamp > 0 {
+		query = query.Where(table+".timestamp <= ?", filter.ToTimestamp)
+	}
+	return query
+}
+
+func pageLimit(filter BridgeTransfersFilter) int {
+	if filter.Limit <= 0 || filter.Limit > MaxBridgeTransfersPageLimit {
+		return MaxBridgeTransfersPageLimit
+	}
+	return filter.Limit
 }
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * Types
+ */
+
+type TokenMetadata struct {
+	Name     string
+	Symbol   string
+	Decimals uint8
+}
+
+// BridgedToken is the resolved ERC20 metadata of a token pair bridged through the StandardBridge
+type BridgedToken struct {
+	TokenPair TokenPair `gorm:"embedded"`
+
+	L1Token TokenMetadata `gorm:"embedded;embeddedPrefix:l1_"`
+	L2Token TokenMetadata `gorm:"embedded;embeddedPrefix:l2_"`
+
+	// Set when either token is an OptimismMintableERC20 bridged from the other token of the pair.
+	// Unverified pairs are mismatched and may be impersonating a well known token
+	Verified bool
+}
+
+type BridgedTokensView interface {
+	BridgedToken(TokenPair) (*BridgedToken, error)
+	BridgedTokens() ([]*BridgedToken, error)
+}
+
+type BridgedTokensDB interface {
+	BridgedTokensView
+
+	StoreBridgedTokens([]*BridgedToken) error
+	UnresolvedTokenPairs(limit int) ([]TokenPair, error)
+}
+
+/**
+ * Implementation
+ */
+
+type bridgedTokensDB struct {
+	gorm *gorm.DB
+}
+
+func newBridgedTokensDB(db *gorm.DB) BridgedTokensDB {
+	return &bridgedTokensDB{gorm: db}
+}
+
+// StoreBridgedTokens stores the resolved token pairs. Pairs that have already been resolved are ignored
+func (db *bridgedTokensDB) StoreBridgedTokens(tokens []*BridgedToken) error {
+	result := db.gorm.Clauses(clause.OnConflict{DoNothing: true}).Create(&tokens)
+	return result.Error
+}
+
+func (db *bridgedTokensDB) BridgedToken(tokenPair TokenPair) (*BridgedToken, error) {
+	var token BridgedToken
+	result := db.gorm.Where(&BridgedToken{TokenPair: tokenPair}).Take(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	return &token, nil
+}
+
+func (db *bridgedTokensDB) BridgedTokens() ([]*BridgedToken, error) {
+	tokens := []*BridgedToken{}
+	result := db.gorm.Order("l1_symbol ASC, l1_token_address ASC, l2_token_address ASC").Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return tokens, nil
+}
+
+// UnresolvedTokenPairs retrieves the token pairs, bridged in either direction, whose metadata has yet to be resolved
+func (db *bridgedTokensDB) UnresolvedTokenPairs(limit int) ([]TokenPair, error) {
+	tokenPairsQuery := db.gorm.Raw(`
+SELECT l1_token_address, l2_token_address FROM l1_bridge_deposits
+UNION
+SELECT l1_token_address, l2_token_address FROM l2_bridge_withdrawals`)
+
+	resolvedQuery := db.gorm.Model(&BridgedToken{}).Select("1").Where(`
+bridged_tokens.l1_token_address = token_pairs.l1_token_address AND
+bridged_tokens.l2_token_address = token_pairs.l2_token_address`)
+
+	tokenPairs := []TokenPair{}
+	result := db.gorm.Table("(?) AS token_pairs", tokenPairsQuery).Where("NOT EXISTS (?)", resolvedQuery).Limit(limit).Find(&tokenPairs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return tokenPairs, nil
+}
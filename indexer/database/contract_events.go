@@ -24,6 +24,7 @@ type ContractEvent struct {
 	EventSignature common.Hash `gorm:"serializer:json"`
 	LogIndex       uint64
 	Timestamp      uint64
+	Finalized      bool
 
 	GethLog *types.Log `gorm:"serializer:rlp;column:rlp_bytes"`
 }
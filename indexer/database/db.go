@@ -2,6 +2,9 @@
 package database
 
 import (
+	"io/fs"
+	"path"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -15,6 +18,7 @@ type DB struct {
 	BridgeTransfers    BridgeTransfersDB
 	BridgeMessages     BridgeMessagesDB
 	BridgeTransactions BridgeTransactionsDB
+	BridgedTokens      BridgedTokensDB
 }
 
 func NewDB(dsn string) (*DB, error) {
@@ -39,6 +43,7 @@ func NewDB(dsn string) (*DB, error) {
 		BridgeTransfers:    newBridgeTransfersDB(gorm),
 		BridgeMessages:     newBridgeMessagesDB(gorm),
 		BridgeTransactions: newBridgeTransactionsDB(gorm),
+		BridgedTokens:      newBridgedTokensDB(gorm),
 	}
 
 	return db, nil
@@ -52,6 +57,30 @@ func (db *DB) Transaction(fn func(db *DB) error) error {
 	})
 }
 
+// Gorm returns the underlying gorm handle, scoped to the transaction when within one. This is
+// intended for user-defined contract event handlers that manage their own tables
+func (db *DB) Gorm() *gorm.DB {
+	return db.gorm
+}
+
+// ExecuteSQLMigrations executes the `.sql` files of the supplied file system in lexical order
+func (db *DB) ExecuteSQLMigrations(migrations fs.FS) error {
+	return fs.WalkDir(migrations, ".", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if entry.IsDir() || path.Ext(file) != ".sql" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(migrations, file)
+		if err != nil {
+			return err
+		}
+
+		return db.gorm.Exec(string(data)).Error
+	})
+}
+
 func (db *DB) Close() error {
 	sql, err := db.gorm.DB()
 	if err != nil {
@@ -69,5 +98,6 @@ func dbFromGormTx(tx *gorm.DB) *DB {
 		BridgeTransfers:    newBridgeTransfersDB(tx),
 		BridgeMessages:     newBridgeMessagesDB(tx),
 		BridgeTransactions: newBridgeTransactionsDB(tx),
+		BridgedTokens:      newBridgedTokensDB(tx),
 	}
 }
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"math/big"
+)
+
+/**
+ * Finality of the indexed data. Rows are stored as not finalized and marked as such
+ * once their block is finalized. When following the unsafe head, rows indexed from
+ * blocks that are reorged out are rolled back.
+ *
+ * The rows of a block are selected through its contract events. These operations span
+ * multiple tables, in the reverse order of their dependencies, and should be run within
+ * a transaction
+ */
+
+// FinalizeL1Blocks marks the L1 blocks up to the supplied height, and all the rows indexed from them, as finalized
+func (db *DB) FinalizeL1Blocks(height *big.Int) error {
+	events := "SELECT guid FROM l1_contract_events WHERE block_hash IN (SELECT hash FROM l1_block_headers WHERE NOT finalized AND number <= @height)"
+	return db.exec([]string{
+		"UPDATE l1_bridge_deposits SET finalized = TRUE WHERE transaction_source_hash IN (SELECT source_hash FROM l1_transaction_deposits WHERE initiated_l1_event_guid IN (" + events + "))",
+		"UPDATE l1_bridge_messages SET finalized = TRUE WHERE sent_message_event_guid IN (" + events + ")",
+		"UPDATE l1_transaction_deposits SET finalized = TRUE WHERE initiated_l1_event_guid IN (" + events + ")",
+		"UPDATE l1_contract_events SET finalized = TRUE WHERE guid IN (" + events + ")",
+		"UPDATE l1_block_headers SET finalized = TRUE WHERE NOT finalized AND number <= @height",
+	}, height)
+}
+
+// RollbackL1Blocks removes all the rows indexed from the non-finalized L1 blocks starting at the supplied height.
+// References made by L2 rows to the removed L1 events are cleared such that they are re-applied once re-indexed
+func (db *DB) RollbackL1Blocks(fromHeight *big.Int) error {
+	events := "SELECT guid FROM l1_contract_events WHERE block_hash IN (SELECT hash FROM l1_block_headers WHERE NOT finalized AND number >= @height)"
+	return db.exec([]string{
+		// L1 events referenced by L2 rows
+		"UPDATE l2_transaction_withdrawals SET proven_l1_event_guid = NULL WHERE proven_l1_event_guid IN (" + events + ")",
+		"UPDATE l2_transaction_withdrawals SET finalized_l1_event_guid = NULL, succeeded = NULL WHERE finalized_l1_event_guid IN (" + events + ")",
+		"UPDATE l2_bridge_messages SET relayed_message_event_guid = NULL WHERE relayed_message_event_guid IN (" + events + ")",
+
+		"DELETE FROM l1_bridge_deposits WHERE transaction_source_hash IN (SELECT source_hash FROM l1_transaction_deposits WHERE initiated_l1_event_guid IN (" + events + "))",
+		"DELETE FROM l1_bridge_messages WHERE sent_message_event_guid IN (" + events + ")",
+		"DELETE FROM l1_transaction_deposits WHERE initiated_l1_event_guid IN (" + events + ")",
+		"DELETE FROM output_proposals WHERE l1_contract_event_guid IN (" + events + ")",
+		"DELETE FROM legacy_state_batches WHERE l1_contract_event_guid IN (" + events + ")",
+		"DELETE FROM l1_contract_events WHERE guid IN (" + events + ")",
+		"DELETE FROM l1_block_headers WHERE NOT finalized AND number >= @height",
+	}, fromHeight)
+}
+
+// FinalizeL2Blocks marks the L2 blocks up to the supplied height, and all the rows indexed from them, as finalized
+func (db *DB) FinalizeL2Blocks(height *big.Int) error {
+	events := "SELECT guid FROM l2_contract_events WHERE block_hash IN (SELECT hash FROM l2_block_headers WHERE NOT finalized AND number <= @height)"
+	return db.exec([]string{
+		"UPDATE l2_bridge_withdrawals SET finalized = TRUE WHERE transaction_withdrawal_hash IN (SELECT withdrawal_hash FROM l2_transaction_withdrawals WHERE initiated_l2_event_guid IN (" + events + "))",
+		"UPDATE l2_bridge_messages SET finalized = TRUE WHERE sent_message_event_guid IN (" + events + ")",
+		"UPDATE l2_transaction_withdrawals SET finalized = TRUE WHERE initiated_l2_event_guid IN (" + events + ")",
+		"UPDATE l2_contract_events SET finalized = TRUE WHERE guid IN (" + events + ")",
+		"UPDATE l2_block_headers SET finalized = TRUE WHERE NOT finalized AND number <= @height",
+	}, height)
+}
+
+// RollbackL2Blocks removes all the rows indexed from the non-finalized L2 blocks starting at the supplied height.
+// References made by L1 rows to the removed L2 events are cleared such that they are re-applied once re-indexed
+func (db *DB) RollbackL2Blocks(fromHeight *big.Int) error {
+	events := "SELECT guid FROM l2_contract_events WHERE block_hash IN (SELECT hash FROM l2_block_headers WHERE NOT finalized AND number >= @height)"
+	return db.exec([]string{
+		// L2 events referenced by L1 rows
+		"UPDATE l1_bridge_messages SET relayed_message_event_guid = NULL WHERE relayed_message_event_guid IN (" + events + ")",
+
+		"DELETE FROM l2_bridge_withdrawals WHERE transaction_withdrawal_hash IN (SELECT withdrawal_hash FROM l2_transaction_withdrawals WHERE initiated_l2_event_guid IN (" + events + "))",
+		"DELETE FROM l2_bridge_messages WHERE sent_message_event_guid IN (" + events + ")",
+		"DELETE FROM l2_transaction_withdrawals WHERE initiated_l2_event_guid IN (" + events + ")",
+		"DELETE FROM l2_contract_events WHERE guid IN (" + events + ")",
+		"DELETE FROM l2_block_headers WHERE NOT finalized AND number >= @height",
+	}, fromHeight)
+}
+
+func (db *DB) exec(statements []string, height *big.Int) error {
+	args := map[string]interface{}{"height": U256{Int: height}}
+	for _, statement := range statements {
+		result := db.gorm.Exec(statement, args)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	return nil
+}
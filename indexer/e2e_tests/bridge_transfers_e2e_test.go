@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/indexer/database"
 	e2etest_utils "github.com/ethereum-optimism/optimism/indexer/e2e_tests/utils"
 	"github.com/ethereum-optimism/optimism/indexer/processor"
 	op_e2e "github.com/ethereum-optimism/optimism/op-e2e"
@@ -49,13 +50,17 @@ func TestE2EBridgeTransfersStandardBridgeETHDeposit(t *testing.T) {
 		return l1Header != nil && l1Header.Number.Uint64() >= depositReceipt.BlockNumber.Uint64(), nil
 	}))
 
-	aliceDeposits, err := testSuite.DB.BridgeTransfers.L1BridgeDepositsByAddress(aliceAddr)
+	aliceDeposits, err := testSuite.DB.BridgeTransfers.L1BridgeDepositsByAddress(aliceAddr, database.BridgeTransfersFilter{})
 	require.NoError(t, err)
-	require.Len(t, aliceDeposits, 1)
-	require.Equal(t, depositTx.Hash(), aliceDeposits[0].L1TransactionHash)
-	require.Equal(t, types.NewTx(depositInfo.DepositTx).Hash(), aliceDeposits[0].L2TransactionHash)
+	require.Len(t, aliceDeposits.Deposits, 1)
+	require.Equal(t, depositTx.Hash(), aliceDeposits.Deposits[0].L1TransactionHash)
+	require.Equal(t, types.NewTx(depositInfo.DepositTx).Hash(), aliceDeposits.Deposits[0].L2TransactionHash)
 
-	deposit := aliceDeposits[0].L1BridgeDeposit
+	txDeposit, err := testSuite.DB.BridgeTransfers.L1BridgeDepositByTransactionHash(depositTx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, aliceDeposits.Deposits[0], txDeposit)
+
+	deposit := aliceDeposits.Deposits[0].L1BridgeDeposit
 	require.Equal(t, depositInfo.DepositTx.SourceHash, deposit.TransactionSourceHash)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, deposit.TokenPair.L1TokenAddress)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, deposit.TokenPair.L2TokenAddress)
@@ -111,12 +116,16 @@ func TestE2EBridgeTransfersOptimismPortalETHReceive(t *testing.T) {
 		return l1Header != nil && l1Header.Number.Uint64() >= portalDepositReceipt.BlockNumber.Uint64(), nil
 	}))
 
-	aliceDeposits, err := testSuite.DB.BridgeTransfers.L1BridgeDepositsByAddress(aliceAddr)
+	aliceDeposits, err := testSuite.DB.BridgeTransfers.L1BridgeDepositsByAddress(aliceAddr, database.BridgeTransfersFilter{})
+	require.NoError(t, err)
+	require.Equal(t, portalDepositTx.Hash(), aliceDeposits.Deposits[0].L1TransactionHash)
+	require.Equal(t, types.NewTx(depositInfo.DepositTx).Hash(), aliceDeposits.Deposits[0].L2TransactionHash)
+
+	txDeposit, err := testSuite.DB.BridgeTransfers.L1BridgeDepositByTransactionHash(portalDepositTx.Hash())
 	require.NoError(t, err)
-	require.Equal(t, portalDepositTx.Hash(), aliceDeposits[0].L1TransactionHash)
-	require.Equal(t, types.NewTx(depositInfo.DepositTx).Hash(), aliceDeposits[0].L2TransactionHash)
+	require.Equal(t, aliceDeposits.Deposits[0], txDeposit)
 
-	deposit := aliceDeposits[0].L1BridgeDeposit
+	deposit := aliceDeposits.Deposits[0].L1BridgeDeposit
 	require.Equal(t, depositInfo.DepositTx.SourceHash, deposit.TransactionSourceHash)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, deposit.TokenPair.L1TokenAddress)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, deposit.TokenPair.L2TokenAddress)
@@ -167,17 +176,18 @@ func TestE2EBridgeTransfersStandardBridgeETHWithdrawal(t *testing.T) {
 		return l2Header != nil && l2Header.Number.Uint64() >= withdrawReceipt.BlockNumber.Uint64(), nil
 	}))
 
-	aliceWithdrawals, err := testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr)
+	aliceWithdrawals, err := testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr, database.BridgeTransfersFilter{})
 	require.NoError(t, err)
-	require.Len(t, aliceWithdrawals, 1)
-	require.Equal(t, withdrawTx.Hash(), aliceWithdrawals[0].L2TransactionHash)
+	require.Len(t, aliceWithdrawals.Withdrawals, 1)
+	require.Equal(t, withdrawTx.Hash(), aliceWithdrawals.Withdrawals[0].L2TransactionHash)
+	require.Equal(t, withdrawReceipt.BlockNumber, aliceWithdrawals.Withdrawals[0].L2BlockNumber.Int)
 
 	msgPassed, err := withdrawals.ParseMessagePassed(withdrawReceipt)
 	require.NoError(t, err)
 	withdrawalHash, err := withdrawals.WithdrawalHash(msgPassed)
 	require.NoError(t, err)
 
-	withdrawal := aliceWithdrawals[0].L2BridgeWithdrawal
+	withdrawal := aliceWithdrawals.Withdrawals[0].L2BridgeWithdrawal
 	require.Equal(t, withdrawalHash, withdrawal.TransactionWithdrawalHash)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, withdrawal.TokenPair.L1TokenAddress)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, withdrawal.TokenPair.L2TokenAddress)
@@ -193,8 +203,8 @@ func TestE2EBridgeTransfersStandardBridgeETHWithdrawal(t *testing.T) {
 	require.Zero(t, nonce.Uint64())
 
 	// (2) Test Withdrawal Proven/Finalized. Test the sql join queries to populate the right transaction
-	require.Empty(t, aliceWithdrawals[0].ProvenL1TransactionHash)
-	require.Empty(t, aliceWithdrawals[0].FinalizedL1TransactionHash)
+	require.Empty(t, aliceWithdrawals.Withdrawals[0].ProvenL1TransactionHash)
+	require.Empty(t, aliceWithdrawals.Withdrawals[0].FinalizedL1TransactionHash)
 
 	// wait for processor catchup
 	proveReceipt, finalizeReceipt := op_e2e.ProveAndFinalizeWithdrawal(t, *testSuite.OpCfg, testSuite.L1Client, testSuite.OpSys.Nodes["sequencer"], testSuite.OpCfg.Secrets.Alice, withdrawReceipt)
@@ -203,10 +213,15 @@ func TestE2EBridgeTransfersStandardBridgeETHWithdrawal(t *testing.T) {
 		return l1Header != nil && l1Header.Number.Uint64() >= finalizeReceipt.BlockNumber.Uint64(), nil
 	}))
 
-	aliceWithdrawals, err = testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr)
+	aliceWithdrawals, err = testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr, database.BridgeTransfersFilter{})
+	require.NoError(t, err)
+	require.Equal(t, proveReceipt.TxHash, aliceWithdrawals.Withdrawals[0].ProvenL1TransactionHash)
+	require.Equal(t, finalizeReceipt.TxHash, aliceWithdrawals.Withdrawals[0].FinalizedL1TransactionHash)
+	require.NotZero(t, aliceWithdrawals.Withdrawals[0].ProvenL1Timestamp)
+
+	txWithdrawal, err := testSuite.DB.BridgeTransfers.L2BridgeWithdrawalByTransactionHash(withdrawTx.Hash())
 	require.NoError(t, err)
-	require.Equal(t, proveReceipt.TxHash, aliceWithdrawals[0].ProvenL1TransactionHash)
-	require.Equal(t, finalizeReceipt.TxHash, aliceWithdrawals[0].FinalizedL1TransactionHash)
+	require.Equal(t, aliceWithdrawals.Withdrawals[0], txWithdrawal)
 }
 
 func TestE2EBridgeTransfersL2ToL1MessagePasserReceive(t *testing.T) {
@@ -244,16 +259,16 @@ func TestE2EBridgeTransfersL2ToL1MessagePasserReceive(t *testing.T) {
 		return l2Header != nil && l2Header.Number.Uint64() >= l2ToL1WithdrawReceipt.BlockNumber.Uint64(), nil
 	}))
 
-	aliceWithdrawals, err := testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr)
+	aliceWithdrawals, err := testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr, database.BridgeTransfersFilter{})
 	require.NoError(t, err)
-	require.Equal(t, l2ToL1MessagePasserWithdrawTx.Hash(), aliceWithdrawals[0].L2TransactionHash)
+	require.Equal(t, l2ToL1MessagePasserWithdrawTx.Hash(), aliceWithdrawals.Withdrawals[0].L2TransactionHash)
 
 	msgPassed, err := withdrawals.ParseMessagePassed(l2ToL1WithdrawReceipt)
 	require.NoError(t, err)
 	withdrawalHash, err := withdrawals.WithdrawalHash(msgPassed)
 	require.NoError(t, err)
 
-	withdrawal := aliceWithdrawals[0].L2BridgeWithdrawal
+	withdrawal := aliceWithdrawals.Withdrawals[0].L2BridgeWithdrawal
 	require.Equal(t, withdrawalHash, withdrawal.TransactionWithdrawalHash)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, withdrawal.TokenPair.L1TokenAddress)
 	require.Equal(t, predeploys.LegacyERC20ETHAddr, withdrawal.TokenPair.L2TokenAddress)
@@ -266,8 +281,8 @@ func TestE2EBridgeTransfersL2ToL1MessagePasserReceive(t *testing.T) {
 	require.Nil(t, withdrawal.CrossDomainMessengerNonce)
 
 	// (2) Test Withdrawal Proven/Finalized. Test the sql join queries to populate the right transaction
-	require.Empty(t, aliceWithdrawals[0].ProvenL1TransactionHash)
-	require.Empty(t, aliceWithdrawals[0].FinalizedL1TransactionHash)
+	require.Empty(t, aliceWithdrawals.Withdrawals[0].ProvenL1TransactionHash)
+	require.Empty(t, aliceWithdrawals.Withdrawals[0].FinalizedL1TransactionHash)
 
 	// wait for processor catchup
 	proveReceipt, finalizeReceipt := op_e2e.ProveAndFinalizeWithdrawal(t, *testSuite.OpCfg, testSuite.L1Client, testSuite.OpSys.Nodes["sequencer"], testSuite.OpCfg.Secrets.Alice, l2ToL1WithdrawReceipt)
@@ -276,8 +291,8 @@ func TestE2EBridgeTransfersL2ToL1MessagePasserReceive(t *testing.T) {
 		return l1Header != nil && l1Header.Number.Uint64() >= finalizeReceipt.BlockNumber.Uint64(), nil
 	}))
 
-	aliceWithdrawals, err = testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr)
+	aliceWithdrawals, err = testSuite.DB.BridgeTransfers.L2BridgeWithdrawalsByAddress(aliceAddr, database.BridgeTransfersFilter{})
 	require.NoError(t, err)
-	require.Equal(t, proveReceipt.TxHash, aliceWithdrawals[0].ProvenL1TransactionHash)
-	require.Equal(t, finalizeReceipt.TxHash, aliceWithdrawals[0].FinalizedL1TransactionHash)
+	require.Equal(t, proveReceipt.TxHash, aliceWithdrawals.Withdrawals[0].ProvenL1TransactionHash)
+	require.Equal(t, finalizeReceipt.TxHash, aliceWithdrawals.Withdrawals[0].FinalizedL1TransactionHash)
 }
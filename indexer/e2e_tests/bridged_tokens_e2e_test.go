@@ -0,0 +1,55 @@
+package e2e_tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/indexer/database"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EBridgedTokensUnresolvedWithdrawalTokenPair(t *testing.T) {
+	db := setupTestDB(t)
+
+	// A token pair that has only been bridged through a withdrawal
+	tokenPair := database.TokenPair{L1TokenAddress: common.HexToAddress("0x1"), L2TokenAddress: common.HexToAddress("0x2")}
+	storeL2BridgeWithdrawal(t, db, big.NewInt(1), common.HexToHash("0xaa"), tokenPair)
+
+	tokenPairs, err := db.BridgedTokens.UnresolvedTokenPairs(10)
+	require.NoError(t, err)
+	require.Equal(t, []database.TokenPair{tokenPair}, tokenPairs)
+
+	// Resolved pairs are no longer returned
+	require.NoError(t, db.BridgedTokens.StoreBridgedTokens([]*database.BridgedToken{{TokenPair: tokenPair, Verified: true}}))
+	tokenPairs, err = db.BridgedTokens.UnresolvedTokenPairs(10)
+	require.NoError(t, err)
+	require.Empty(t, tokenPairs)
+}
+
+// storeL2BridgeWithdrawal stores a non-finalized L2 block containing a single bridge withdrawal of the token pair
+func storeL2BridgeWithdrawal(t *testing.T, db *database.DB, number *big.Int, withdrawalHash common.Hash, tokenPair database.TokenPair) {
+	header := &types.Header{Number: number, Time: number.Uint64() + 1}
+	l2Header := &database.L2BlockHeader{BlockHeader: database.BlockHeaderFromGethHeader(header)}
+	require.NoError(t, db.Blocks.StoreL2BlockHeaders([]*database.L2BlockHeader{l2Header}))
+
+	log := &types.Log{BlockHash: header.Hash(), TxHash: withdrawalHash}
+	event := &database.L2ContractEvent{ContractEvent: database.ContractEventFromGethLog(log, header.Time)}
+	require.NoError(t, db.ContractEvents.StoreL2ContractEvents([]*database.L2ContractEvent{event}))
+
+	tx := database.Transaction{Amount: database.U256{Int: big.NewInt(100)}, Data: []byte{}, Timestamp: header.Time}
+	require.NoError(t, db.BridgeTransactions.StoreL2TransactionWithdrawals([]*database.L2TransactionWithdrawal{{
+		WithdrawalHash:       withdrawalHash,
+		Nonce:                database.U256{Int: number},
+		InitiatedL2EventGUID: event.GUID,
+		Tx:                   tx,
+		GasLimit:             database.U256{Int: big.NewInt(0)},
+	}}))
+	require.NoError(t, db.BridgeTransfers.StoreL2BridgeWithdrawals([]*database.L2BridgeWithdrawal{{
+		TransactionWithdrawalHash: withdrawalHash,
+		Tx:                        tx,
+		TokenPair:                 tokenPair,
+	}}))
+}
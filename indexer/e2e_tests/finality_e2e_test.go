@@ -0,0 +1,104 @@
+package e2e_tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/indexer/database"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2EFinalityL2Blocks(t *testing.T) {
+	db := setupTestDB(t)
+
+	tokenPair := database.TokenPair{L1TokenAddress: common.HexToAddress("0x1"), L2TokenAddress: common.HexToAddress("0x2")}
+	withdrawalHashes := []common.Hash{common.HexToHash("0xaa"), common.HexToHash("0xbb"), common.HexToHash("0xcc")}
+	for i, withdrawalHash := range withdrawalHashes {
+		storeL2BridgeWithdrawal(t, db, big.NewInt(int64(i+1)), withdrawalHash, tokenPair)
+	}
+
+	// Blocks up to the finalized height are marked as such alongside their rows
+	require.NoError(t, db.FinalizeL2Blocks(big.NewInt(1)))
+
+	header, err := db.Blocks.LatestFinalizedL2BlockHeader()
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1), header.Number.Int)
+
+	withdrawal, err := db.BridgeTransfers.L2BridgeWithdrawal(withdrawalHashes[0])
+	require.NoError(t, err)
+	require.True(t, withdrawal.Finalized)
+	txWithdrawal, err := db.BridgeTransactions.L2TransactionWithdrawal(withdrawalHashes[0])
+	require.NoError(t, err)
+	require.True(t, txWithdrawal.Finalized)
+
+	withdrawal, err = db.BridgeTransfers.L2BridgeWithdrawal(withdrawalHashes[1])
+	require.NoError(t, err)
+	require.False(t, withdrawal.Finalized)
+
+	// Rows from the reorged height onwards are removed
+	require.NoError(t, db.Transaction(func(db *database.DB) error {
+		return db.RollbackL2Blocks(big.NewInt(2))
+	}))
+
+	for _, height := range []int64{2, 3} {
+		header, err := db.Blocks.L2BlockHeader(big.NewInt(height))
+		require.NoError(t, err)
+		require.Nil(t, header)
+	}
+	for _, withdrawalHash := range withdrawalHashes[1:] {
+		withdrawal, err := db.BridgeTransfers.L2BridgeWithdrawal(withdrawalHash)
+		require.NoError(t, err)
+		require.Nil(t, withdrawal)
+		txWithdrawal, err := db.BridgeTransactions.L2TransactionWithdrawal(withdrawalHash)
+		require.NoError(t, err)
+		require.Nil(t, txWithdrawal)
+	}
+
+	unfinalized, err := db.Blocks.UnfinalizedL2BlockHeaders()
+	require.NoError(t, err)
+	require.Empty(t, unfinalized)
+
+	// Finalized rows are never rolled back
+	require.NoError(t, db.RollbackL2Blocks(big.NewInt(0)))
+	header, err = db.Blocks.L2BlockHeader(big.NewInt(1))
+	require.NoError(t, err)
+	require.NotNil(t, header)
+	withdrawal, err = db.BridgeTransfers.L2BridgeWithdrawal(withdrawalHashes[0])
+	require.NoError(t, err)
+	require.NotNil(t, withdrawal)
+}
+
+func TestE2EFinalityL1BlocksRollbackClearsL2References(t *testing.T) {
+	db := setupTestDB(t)
+
+	withdrawalHash := common.HexToHash("0xaa")
+	storeL2BridgeWithdrawal(t, db, big.NewInt(1), withdrawalHash, database.TokenPair{})
+
+	// An unsafe L1 block proving the withdrawal
+	header := &types.Header{Number: big.NewInt(5), Time: 6}
+	l1Header := &database.L1BlockHeader{BlockHeader: database.BlockHeaderFromGethHeader(header)}
+	require.NoError(t, db.Blocks.StoreL1BlockHeaders([]*database.L1BlockHeader{l1Header}))
+	event := &database.L1ContractEvent{ContractEvent: database.ContractEventFromGethLog(&types.Log{BlockHash: header.Hash()}, header.Time)}
+	require.NoError(t, db.ContractEvents.StoreL1ContractEvents([]*database.L1ContractEvent{event}))
+	require.NoError(t, db.BridgeTransactions.MarkL2TransactionWithdrawalProvenEvent(withdrawalHash, event.GUID))
+
+	require.NoError(t, db.Transaction(func(db *database.DB) error {
+		return db.RollbackL1Blocks(big.NewInt(5))
+	}))
+
+	l1BlockHeader, err := db.Blocks.L1BlockHeader(big.NewInt(5))
+	require.NoError(t, err)
+	require.Nil(t, l1BlockHeader)
+	l1Event, err := db.ContractEvents.L1ContractEvent(event.GUID)
+	require.NoError(t, err)
+	require.Nil(t, l1Event)
+
+	// The withdrawal is kept, pending to be proven again once the L1 block is re-indexed
+	txWithdrawal, err := db.BridgeTransactions.L2TransactionWithdrawal(withdrawalHash)
+	require.NoError(t, err)
+	require.NotNil(t, txWithdrawal)
+	require.Nil(t, txWithdrawal.ProvenL1EventGUID)
+}
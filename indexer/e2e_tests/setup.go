@@ -71,6 +71,8 @@ func createE2ETestSuite(t *testing.T) E2ETestSuite {
 			L2RPC: opSys.Nodes["sequencer"].HTTPEndpoint(),
 		},
 		Chain: config.ChainConfig{
+			// Finality lags behind on the devnet. Follow the unsafe head to avoid waiting on it
+			FollowUnsafeHead: true,
 			L1Contracts: processor.L1Contracts{
 				OptimismPortal:         opCfg.L1Deployments.OptimismPortalProxy,
 				L2OutputOracle:         opCfg.L1Deployments.L2OutputOracleProxy,
@@ -114,6 +116,18 @@ func createE2ETestSuite(t *testing.T) E2ETestSuite {
 	}
 }
 
+// setupTestDB creates a migrated test database, without the rollup system, for tests
+// that exercise the database queries directly
+func setupTestDB(t *testing.T) *database.DB {
+	dbUser := os.Getenv("DB_USER")
+	dbName := setupTestDatabase(t)
+
+	db, err := database.NewDB(fmt.Sprintf("postgres://%s@localhost:5432/%s?sslmode=disable", dbUser, dbName))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
 func setupTestDatabase(t *testing.T) string {
 	user := os.Getenv("DB_USER")
 	require.NotEmpty(t, user, "DB_USER env variable expected to instantiate test database")
@@ -19,8 +19,9 @@ type Indexer struct {
 	db  *database.DB
 	log log.Logger
 
-	L1Processor *processor.L1Processor
-	L2Processor *processor.L2Processor
+	L1Processor            *processor.L1Processor
+	L2Processor            *processor.L2Processor
+	BridgedTokensProcessor *processor.BridgedTokensProcessor
 }
 
 // NewIndexer initializes an instance of the Indexer
@@ -38,12 +39,28 @@ func NewIndexer(cfg config.Config) (*Indexer, error) {
 		return nil, err
 	}
 
+	// Schema of the user-defined contract event handlers
+	for _, handlers := range [][]processor.ContractEventHandler{cfg.L1ContractEventHandlers, cfg.L2ContractEventHandlers} {
+		for _, handler := range handlers {
+			if handler.Migrations == nil {
+				continue
+			}
+
+			cfg.Logger.Info("running contract event handler migrations", "handler", handler.Name)
+			if err := db.ExecuteSQLMigrations(handler.Migrations); err != nil {
+				return nil, fmt.Errorf("unable to run migrations of contract event handler %s: %w", handler.Name, err)
+			}
+		}
+	}
+
+	processorCfg := processor.Config{FollowUnsafeHead: cfg.Chain.FollowUnsafeHead, Backfill: cfg.Chain.Backfill}
+
 	l1Contracts := cfg.Chain.L1Contracts
 	l1EthClient, err := node.DialEthClient(cfg.RPCs.L1RPC)
 	if err != nil {
 		return nil, err
 	}
-	l1Processor, err := processor.NewL1Processor(cfg.Logger, l1EthClient, db, l1Contracts)
+	l1Processor, err := processor.NewL1Processor(cfg.Logger, l1EthClient, db, l1Contracts, cfg.L1ContractEventHandlers, processorCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -54,16 +71,20 @@ func NewIndexer(cfg config.Config) (*Indexer, error) {
 	if err != nil {
 		return nil, err
 	}
-	l2Processor, err := processor.NewL2Processor(cfg.Logger, l2EthClient, db, l2Contracts)
+	l2Processor, err := processor.NewL2Processor(cfg.Logger, l2EthClient, db, l2Contracts, cfg.L2ContractEventHandlers, processorCfg)
 	if err != nil {
 		return nil, err
 	}
 
+	// Metadata of the tokens bridged in either direction
+	bridgedTokensProcessor := processor.NewBridgedTokensProcessor(cfg.Logger, db, l1EthClient, l2EthClient)
+
 	indexer := &Indexer{
-		db:          db,
-		log:         cfg.Logger,
-		L1Processor: l1Processor,
-		L2Processor: l2Processor,
+		db:                     db,
+		log:                    cfg.Logger,
+		L1Processor:            l1Processor,
+		L2Processor:            l2Processor,
+		BridgedTokensProcessor: bridgedTokensProcessor,
 	}
 
 	return indexer, nil
@@ -71,10 +92,11 @@ func NewIndexer(cfg config.Config) (*Indexer, error) {
 
 // Start starts the indexing service on L1 and L2 chains
 func (i *Indexer) Run(ctx context.Context) error {
+	// Every processor reports once halted, only the first report is read
 	var wg sync.WaitGroup
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 2)
 
-	// If either processor errors out, we stop
+	// If any processor errors out, we stop
 	processorCtx, cancel := context.WithCancel(ctx)
 	run := func(start func(ctx context.Context) error) {
 		wg.Add(1)
@@ -101,9 +123,10 @@ func (i *Indexer) Run(ctx context.Context) error {
 	// Kick off the processors
 	go run(i.L1Processor.Start)
 	go run(i.L2Processor.Start)
+	go run(i.BridgedTokensProcessor.Start)
 	err := <-errCh
 
-	// ensure both processors have halted before returning
+	// ensure all processors have halted before returning
 	wg.Wait()
 	return err
 }
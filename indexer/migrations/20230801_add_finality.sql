@@ -0,0 +1,30 @@
+/**
+ * FINALITY
+ *
+ * Rows indexed past the finalized height, when following the unsafe head, are not
+ * finalized until their block is and are rolled back if their block is reorged out.
+ * Rows indexed before this migration only contain finalized data.
+ */
+
+ALTER TABLE l1_block_headers ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE l2_block_headers ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE l1_contract_events ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE l2_contract_events ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE l1_transaction_deposits    ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE l2_transaction_withdrawals ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE l1_bridge_messages ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE l2_bridge_messages ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE l1_bridge_deposits    ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE l2_bridge_withdrawals ADD COLUMN IF NOT EXISTS finalized BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Finalization & rollbacks only operate on the most recent rows
+CREATE INDEX IF NOT EXISTS l1_block_headers_unfinalized ON l1_block_headers(number) WHERE NOT finalized;
+CREATE INDEX IF NOT EXISTS l2_block_headers_unfinalized ON l2_block_headers(number) WHERE NOT finalized;
+CREATE INDEX IF NOT EXISTS l1_contract_events_block_hash ON l1_contract_events(block_hash);
+CREATE INDEX IF NOT EXISTS l2_contract_events_block_hash ON l2_contract_events(block_hash);
+CREATE INDEX IF NOT EXISTS l1_transaction_deposits_initiated_l1_event_guid ON l1_transaction_deposits(initiated_l1_event_guid);
+CREATE INDEX IF NOT EXISTS l2_transaction_withdrawals_initiated_l2_event_guid ON l2_transaction_withdrawals(initiated_l2_event_guid);
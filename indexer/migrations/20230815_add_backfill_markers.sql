@@ -0,0 +1,15 @@
+/**
+ * BACKFILL
+ *
+ * The last finalized header committed by the backfill of either chain, such that an
+ * interrupted backfill resumes past the blocks that were not indexed for lack of data
+ */
+
+CREATE TABLE IF NOT EXISTS backfill_markers (
+	chain  VARCHAR NOT NULL PRIMARY KEY,
+	hash   VARCHAR NOT NULL,
+	number UINT256 NOT NULL,
+
+    -- Raw Data
+	rlp_bytes VARCHAR NOT NULL
+);
@@ -0,0 +1,24 @@
+/**
+ * BRIDGED TOKENS
+ *
+ * ERC20 metadata of the token pairs bridged through the StandardBridge. Resolved
+ * asynchronously from the indexed bridge transfers
+ */
+
+CREATE TABLE IF NOT EXISTS bridged_tokens (
+	l1_token_address VARCHAR NOT NULL,
+	l2_token_address VARCHAR NOT NULL,
+
+	l1_name     VARCHAR NOT NULL,
+	l1_symbol   VARCHAR NOT NULL,
+	l1_decimals SMALLINT NOT NULL CHECK (l1_decimals >= 0 AND l1_decimals <= 255),
+
+	l2_name     VARCHAR NOT NULL,
+	l2_symbol   VARCHAR NOT NULL,
+	l2_decimals SMALLINT NOT NULL CHECK (l2_decimals >= 0 AND l2_decimals <= 255),
+
+	-- An OptimismMintableERC20 token of the pair bridged from the other token
+	verified BOOLEAN NOT NULL,
+
+	PRIMARY KEY (l1_token_address, l2_token_address)
+);
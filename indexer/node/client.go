@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -26,11 +27,14 @@ const (
 
 type EthClient interface {
 	FinalizedBlockHeight() (*big.Int, error)
+	LatestBlockHeight() (*big.Int, error)
 
 	BlockHeadersByRange(*big.Int, *big.Int) ([]*types.Header, error)
 	BlockHeaderByHash(common.Hash) (*types.Header, error)
+	BlockHeaderByNumber(*big.Int) (*types.Header, error)
 
 	StorageHash(common.Address, *big.Int) (common.Hash, error)
+	FilterLogs(ethereum.FilterQuery) ([]types.Log, error)
 
 	RawRpcClient() *rpc.Client
 }
@@ -65,8 +69,25 @@ func (c *client) FinalizedBlockHeight() (*big.Int, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
 
-	// Local devnet is having issues with the "finalized" block tag. Switch to "latest"
-	// to iterate faster locally but this needs to be updated
+	// Indexing against a local devnet, where finality lags behind, can follow the
+	// unsafe head instead to iterate faster
+	var header *types.Header
+	err := c.rpcClient.CallContext(ctxwt, &header, "eth_getBlockByNumber", "finalized", false)
+	if err != nil {
+		return nil, err
+	} else if header == nil {
+		// nothing has been finalized yet
+		return nil, ethereum.NotFound
+	}
+
+	return header.Number, nil
+}
+
+// LatestBlockHeight retrieves the height of the unsafe head, which may be reorged out
+func (c *client) LatestBlockHeight() (*big.Int, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+
 	header := new(types.Header)
 	err := c.rpcClient.CallContext(ctxwt, header, "eth_getBlockByNumber", "latest", false)
 	if err != nil {
@@ -94,6 +115,25 @@ func (c *client) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
 	return header, nil
 }
 
+// BlockHeaderByNumber retrieves the canonical block header at the supplied height. `ethereum.NotFound`
+// is returned when the height is past the latest block
+func (c *client) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+
+	header, err := ethclient.NewClient(c.rpcClient).HeaderByNumber(ctxwt, number)
+	if err != nil {
+		return nil, err
+	}
+
+	// sanity check on the data returned
+	if header.Number.Cmp(number) != 0 {
+		return nil, errors.New("header mismatch")
+	}
+
+	return header, nil
+}
+
 // BlockHeadersByRange will retrieve block headers within the specified range -- includsive. No restrictions
 // are placed on the range such as blocks in the "latest", "safe" or "finalized" states. If the specified
 // range is too large, `endHeight > latest`, the resulting list is truncated to the available headers
@@ -157,6 +197,14 @@ func (c *client) StorageHash(address common.Address, blockNumber *big.Int) (comm
 	return proof.StorageHash, nil
 }
 
+// FilterLogs returns the logs matching the supplied query
+func (c *client) FilterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+
+	return ethclient.NewClient(c.rpcClient).FilterLogs(ctxwt, query)
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
@@ -24,6 +24,17 @@ func NewHeaderTraversal(ethClient EthClient, fromHeader *types.Header) *HeaderTr
 	return &HeaderTraversal{ethClient: ethClient, lastHeader: fromHeader}
 }
 
+// LastHeader returns the last header traversed, nil if the traversal starts from genesis
+func (f *HeaderTraversal) LastHeader() *types.Header {
+	return f.lastHeader
+}
+
+// Reset rewinds the traversal such that the next set of headers build on the supplied header,
+// or start from genesis if nil. Used to resume traversal from a common ancestor after a reorg
+func (f *HeaderTraversal) Reset(header *types.Header) {
+	f.lastHeader = header
+}
+
 // NextFinalizedHeaders retrives the next set of headers that have been
 // marked as finalized by the connected client, bounded by the supplied size
 func (f *HeaderTraversal) NextFinalizedHeaders(maxSize uint64) ([]*types.Header, error) {
@@ -41,12 +52,42 @@ func (f *HeaderTraversal) NextFinalizedHeaders(maxSize uint64) ([]*types.Header,
 		}
 	}
 
+	headers, err := f.nextHeaders(finalizedBlockHeight, maxSize)
+	if err == ErrHeaderTraversalAndProviderMismatchedState {
+		// The indexer's state is in an irrecoverable state relative to the provider. This
+		// should never happen since the indexer is dealing with only finalized blocks.
+		return nil, err
+	}
+
+	return headers, err
+}
+
+// NextUnsafeHeaders retrieves the next set of headers up to the latest block of the connected
+// client, bounded by the supplied size. These headers can be reorged out, in which case
+// ErrHeaderTraversalAndProviderMismatchedState is returned and the traversal should be `Reset`
+// to the common ancestor of the indexed and canonical chains
+func (f *HeaderTraversal) NextUnsafeHeaders(maxSize uint64) ([]*types.Header, error) {
+	latestBlockHeight, err := f.ethClient.LatestBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider may lag behind or have reorged to a shorter chain. In both cases, wait
+	// for the next block which will either extend the traversed chain or reveal the reorg
+	if f.lastHeader != nil && f.lastHeader.Number.Cmp(latestBlockHeight) >= 0 {
+		return nil, nil
+	}
+
+	return f.nextHeaders(latestBlockHeight, maxSize)
+}
+
+func (f *HeaderTraversal) nextHeaders(targetHeight *big.Int, maxSize uint64) ([]*types.Header, error) {
 	nextHeight := bigZero
 	if f.lastHeader != nil {
 		nextHeight = new(big.Int).Add(f.lastHeader.Number, bigOne)
 	}
 
-	endHeight := clampBigInt(nextHeight, finalizedBlockHeight, maxSize)
+	endHeight := clampBigInt(nextHeight, targetHeight, maxSize)
 	headers, err := f.ethClient.BlockHeadersByRange(nextHeight, endHeight)
 	if err != nil {
 		return nil, err
@@ -56,8 +97,6 @@ func (f *HeaderTraversal) NextFinalizedHeaders(maxSize uint64) ([]*types.Header,
 	if numHeaders == 0 {
 		return nil, nil
 	} else if f.lastHeader != nil && headers[0].ParentHash != f.lastHeader.Hash() {
-		// The indexer's state is in an irrecoverable state relative to the provider. This
-		// should never happen since the indexer is dealing with only finalized blocks.
 		return nil, ErrHeaderTraversalAndProviderMismatchedState
 	}
 
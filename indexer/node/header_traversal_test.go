@@ -116,3 +116,59 @@ func TestHeaderTraversalMismatchedProviderStateError(t *testing.T) {
 	require.Nil(t, headers)
 	require.Equal(t, ErrHeaderTraversalAndProviderMismatchedState, err)
 }
+
+func TestHeaderTraversalNextUnsafeHeaders(t *testing.T) {
+	client := new(MockEthClient)
+
+	// start from genesis
+	headerTraversal := NewHeaderTraversal(client, nil)
+
+	// blocks [0..4], past the finalized height
+	headers := makeHeaders(5, nil)
+	client.On("LatestBlockHeight").Return(big.NewInt(4), nil).Times(1)
+	client.On("BlockHeadersByRange", mock.MatchedBy(bigIntMatcher(0)), mock.MatchedBy(bigIntMatcher(4))).Return(headers, nil)
+	headers, err := headerTraversal.NextUnsafeHeaders(5)
+	require.NoError(t, err)
+	require.Len(t, headers, 5)
+	require.Equal(t, headers[4], headerTraversal.LastHeader())
+
+	// no new headers when the provider lags behind or reorged to a shorter chain
+	client.On("LatestBlockHeight").Return(big.NewInt(3), nil).Times(1)
+	headers, err = headerTraversal.NextUnsafeHeaders(5)
+	require.NoError(t, err)
+	require.Empty(t, headers)
+}
+
+func TestHeaderTraversalNextUnsafeHeadersReorg(t *testing.T) {
+	client := new(MockEthClient)
+
+	// start from genesis
+	headerTraversal := NewHeaderTraversal(client, nil)
+
+	// blocks [0..4]
+	headers := makeHeaders(5, nil)
+	client.On("LatestBlockHeight").Return(big.NewInt(4), nil).Times(1)
+	client.On("BlockHeadersByRange", mock.MatchedBy(bigIntMatcher(0)), mock.MatchedBy(bigIntMatcher(4))).Return(headers, nil).Times(1)
+	headers, err := headerTraversal.NextUnsafeHeaders(5)
+	require.NoError(t, err)
+	require.Len(t, headers, 5)
+
+	// blocks [3..4] are reorged out
+	ancestor := headers[2]
+	reorgedHeaders := makeHeaders(1, ancestor)
+	reorgedHeaders[0].Extra = []byte("reorg")
+	reorgedHeaders = append(reorgedHeaders, makeHeaders(2, reorgedHeaders[0])...)
+	client.On("LatestBlockHeight").Return(big.NewInt(5), nil)
+	client.On("BlockHeadersByRange", mock.MatchedBy(bigIntMatcher(5)), mock.MatchedBy(bigIntMatcher(5))).Return(reorgedHeaders[2:], nil)
+	headers, err = headerTraversal.NextUnsafeHeaders(5)
+	require.Nil(t, headers)
+	require.Equal(t, ErrHeaderTraversalAndProviderMismatchedState, err)
+
+	// resume from the common ancestor
+	headerTraversal.Reset(ancestor)
+	client.On("BlockHeadersByRange", mock.MatchedBy(bigIntMatcher(3)), mock.MatchedBy(bigIntMatcher(5))).Return(reorgedHeaders, nil)
+	headers, err = headerTraversal.NextUnsafeHeaders(5)
+	require.NoError(t, err)
+	require.Len(t, headers, 3)
+	require.Equal(t, ancestor.Hash(), headers[0].ParentHash)
+}
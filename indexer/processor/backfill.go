@@ -0,0 +1,211 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/indexer/database"
+	"github.com/ethereum-optimism/optimism/indexer/node"
+	"github.com/ethereum-optimism/optimism/op-service/backoff"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultBackfillChunkSize = 500
+	backfillFetchAttempts    = 5
+)
+
+// BackfillConfig configures the backfill of the historical, finalized, blocks on startup. Chunks of
+// blocks are fetched concurrently by the workers and committed in order. Disabled without workers
+type BackfillConfig struct {
+	Workers   int    `toml:"workers"`
+	ChunkSize uint64 `toml:"chunk-size"`
+}
+
+// backfillChunk is a range of headers and the logs emitted within them
+type backfillChunk struct {
+	headers []*types.Header
+	logs    []types.Log
+	err     error
+}
+
+// backfill indexes the finalized blocks past the latest processed header until within a chunk of the
+// finalized height, after which the regular processing loop takes over. Progress is committed alongside
+// every chunk such that an interrupted backfill resumes from the last committed chunk
+func (p *processor) backfill(ctx context.Context) error {
+	chunkSize := p.backfillCfg.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultBackfillChunkSize
+	}
+
+	for ctx.Err() == nil {
+		finalizedHeight, err := p.ethClient.FinalizedBlockHeight()
+		if err != nil {
+			return err
+		}
+
+		fromHeight := nextHeight(p.latestProcessedHeader)
+		if new(big.Int).Sub(finalizedHeight, fromHeight).Cmp(new(big.Int).SetUint64(chunkSize)) < 0 {
+			p.processLog.Info("backfill complete", "finalized_height", finalizedHeight)
+			return nil
+		}
+
+		p.processLog.Info("backfilling blocks", "from", fromHeight, "to", finalizedHeight, "workers", p.backfillCfg.Workers, "chunk_size", chunkSize)
+		if err := p.backfillRange(ctx, fromHeight, finalizedHeight, chunkSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retryBackfill runs the backfill until it completes or the context is cancelled, waiting between failed
+// attempts. The L1 backfill is expected to fail while the L2 state it depends upon has not been indexed yet
+func retryBackfill(ctx context.Context, log log.Logger, strategy backoff.Strategy, backfill func(context.Context) error) {
+	for attempt := 0; ; attempt++ {
+		err := backfill(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		delay := strategy.Duration(attempt)
+		log.Warn("error backfilling, retrying", "err", err, "attempt", attempt+1, "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *processor) backfillRange(ctx context.Context, fromHeight, toHeight *big.Int, chunkSize uint64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunks := fetchChunks(ctx, fromHeight, toHeight, chunkSize, p.backfillCfg.Workers, p.fetchChunk)
+	for chunk := range chunks {
+		if chunk.err != nil {
+			return chunk.err
+		} else if err := p.commitChunk(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchChunk retrieves the headers and logs within the supplied range, inclusive
+func (p *processor) fetchChunk(ctx context.Context, fromHeight, toHeight *big.Int) *backfillChunk {
+	headers, logs, err := backoff.Do2(ctx, backfillFetchAttempts, backoff.Exponential(), func() ([]*types.Header, []types.Log, error) {
+		headers, err := p.ethClient.BlockHeadersByRange(fromHeight, toHeight)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// the range is finalized, all of the headers are expected
+		count := new(big.Int).Sub(toHeight, fromHeight).Uint64() + 1
+		if uint64(len(headers)) != count {
+			return nil, nil, fmt.Errorf("expected %d headers from %d, got %d", count, fromHeight, len(headers))
+		}
+
+		logs, err := p.filterLogs(fromHeight, toHeight)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return headers, logs, nil
+	})
+
+	return &backfillChunk{headers: headers, logs: logs, err: err}
+}
+
+// commitChunk indexes and finalizes the chunk, recording it as the backfill progress
+func (p *processor) commitChunk(chunk *backfillChunk) error {
+	firstHeader, lastHeader := chunk.headers[0], chunk.headers[len(chunk.headers)-1]
+	if p.latestProcessedHeader != nil && firstHeader.ParentHash != p.latestProcessedHeader.Hash() {
+		return node.ErrHeaderTraversalAndProviderMismatchedState
+	}
+
+	chunkLog := p.processLog.New("chunk_start_block_number", firstHeader.Number, "chunk_end_block_number", lastHeader.Number)
+	err := p.db.Transaction(func(db *database.DB) error {
+		if err := p.processFn(db, chunk.headers, chunk.logs); err != nil {
+			return err
+		} else if err := p.finality.finalize(db, lastHeader.Number); err != nil {
+			return err
+		}
+
+		return p.finality.storeBackfillMarker(db, lastHeader)
+	})
+	if err != nil {
+		return err
+	}
+
+	chunkLog.Info("committed backfill chunk")
+	p.latestProcessedHeader = lastHeader
+	p.headerTraversal.Reset(lastHeader)
+	return nil
+}
+
+// fetchChunks splits the supplied range, inclusive, into chunks fetched concurrently by the
+// workers. The chunks are delivered in order and at most `workers` chunks are fetched or pending
+// delivery at once. The returned channel is closed once all chunks are delivered or the context is
+// cancelled. A chunk that fails to be fetched is delivered with its error
+func fetchChunks(ctx context.Context, fromHeight, toHeight *big.Int, chunkSize uint64, workers int, fetch func(ctx context.Context, fromHeight, toHeight *big.Int) *backfillChunk) <-chan *backfillChunk {
+	chunks := make(chan *backfillChunk)
+	pending := make(chan chan *backfillChunk, workers)
+	slots := make(chan struct{}, workers)
+
+	// dispatch the chunks to the workers
+	go func() {
+		defer close(pending)
+
+		size := new(big.Int).SetUint64(chunkSize)
+		for start := new(big.Int).Set(fromHeight); start.Cmp(toHeight) <= 0; start = new(big.Int).Add(start, size) {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			end := new(big.Int).Add(start, size)
+			end.Sub(end, big.NewInt(1))
+			if end.Cmp(toHeight) > 0 {
+				end.Set(toHeight)
+			}
+
+			result := make(chan *backfillChunk, 1)
+			pending <- result
+			go func(start, end *big.Int) {
+				result <- fetch(ctx, start, end)
+			}(start, end)
+		}
+	}()
+
+	// deliver the chunks in the order they were dispatched
+	go func() {
+		defer close(chunks)
+
+		for result := range pending {
+			var chunk *backfillChunk
+			select {
+			case chunk = <-result:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+
+			<-slots
+		}
+	}()
+
+	return chunks
+}
@@ -0,0 +1,112 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/backoff"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchChunks(t *testing.T) {
+	var inflight, maxInflight int32
+	fetch := func(ctx context.Context, fromHeight, toHeight *big.Int) *backfillChunk {
+		n := atomic.AddInt32(&inflight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInflight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+				break
+			}
+		}
+
+		// finish out of order
+		time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+		atomic.AddInt32(&inflight, -1)
+		return &backfillChunk{headers: []*types.Header{{Number: fromHeight}, {Number: toHeight}}}
+	}
+
+	var ranges [][2]uint64
+	for chunk := range fetchChunks(context.Background(), big.NewInt(5), big.NewInt(104), 10, 3, fetch) {
+		require.NoError(t, chunk.err)
+		ranges = append(ranges, [2]uint64{chunk.headers[0].Number.Uint64(), chunk.headers[1].Number.Uint64()})
+	}
+
+	require.Len(t, ranges, 10)
+	for i, r := range ranges {
+		require.Equal(t, [2]uint64{uint64(5 + 10*i), uint64(14 + 10*i)}, r)
+	}
+	require.LessOrEqual(t, maxInflight, int32(3))
+
+	// the last chunk is truncated to the end of the range
+	ranges = nil
+	for chunk := range fetchChunks(context.Background(), big.NewInt(0), big.NewInt(24), 10, 2, fetch) {
+		ranges = append(ranges, [2]uint64{chunk.headers[0].Number.Uint64(), chunk.headers[1].Number.Uint64()})
+	}
+	require.Equal(t, [][2]uint64{{0, 9}, {10, 19}, {20, 24}}, ranges)
+}
+
+func TestFetchChunksErrorAndCancellation(t *testing.T) {
+	fetchErr := errors.New("boom")
+	fetch := func(ctx context.Context, fromHeight, toHeight *big.Int) *backfillChunk {
+		if fromHeight.Uint64() == 20 {
+			return &backfillChunk{err: fetchErr}
+		}
+		return &backfillChunk{headers: []*types.Header{{Number: fromHeight}}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := fetchChunks(ctx, big.NewInt(0), big.NewInt(1_000_000), 10, 4, fetch)
+	require.NoError(t, (<-chunks).err)
+	require.NoError(t, (<-chunks).err)
+	require.ErrorIs(t, (<-chunks).err, fetchErr)
+
+	// the channel is closed once cancelled
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		for range chunks {
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("chunks were not closed on cancellation")
+	}
+}
+
+func TestRetryBackfill(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlInfo)
+
+	attempts := 0
+	retryBackfill(context.Background(), logger, backoff.Fixed(time.Millisecond), func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("waiting for L2Processor to catch up")
+		}
+		return nil
+	})
+	require.Equal(t, 3, attempts)
+
+	// stops retrying once the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts = 0
+	retryBackfill(ctx, logger, backoff.Fixed(time.Millisecond), func(ctx context.Context) error {
+		attempts++
+		if attempts == 2 {
+			cancel()
+		}
+		return errors.New("boom")
+	})
+	require.Equal(t, 2, attempts)
+}
@@ -0,0 +1,238 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/indexer/database"
+	"github.com/ethereum-optimism/optimism/indexer/node"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	defaultBridgedTokensBatchSize = 100
+	defaultTokenRequestTimeout    = 10 * time.Second
+)
+
+var (
+	// ERC165 interface ids checked by the StandardBridge when bridging mintable tokens
+	legacyMintableERC20InterfaceID   = interfaceID("l1Token()", "mint(address,uint256)", "burn(address,uint256)")
+	optimismMintableERC20InterfaceID = interfaceID("remoteToken()", "bridge()", "mint(address,uint256)", "burn(address,uint256)")
+
+	etherMetadata = database.TokenMetadata{Name: "Ether", Symbol: "ETH", Decimals: 18}
+)
+
+// BridgedTokensProcessor resolves the ERC20 metadata of the token pairs bridged through the
+// StandardBridge, once indexed by the L1 & L2 processors
+type BridgedTokensProcessor struct {
+	processLog log.Logger
+	db         *database.DB
+
+	l1Caller bind.ContractCaller
+	l2Caller bind.ContractCaller
+}
+
+func NewBridgedTokensProcessor(logger log.Logger, db *database.DB, l1EthClient, l2EthClient node.EthClient) *BridgedTokensProcessor {
+	return &BridgedTokensProcessor{
+		processLog: logger.New("processor", "bridged_tokens"),
+		db:         db,
+		l1Caller:   ethclient.NewClient(l1EthClient.RawRpcClient()),
+		l2Caller:   ethclient.NewClient(l2EthClient.RawRpcClient()),
+	}
+}
+
+// Start kicks off the resolution loop. This is a blocking operation until the supplied context is cancelled.
+// Token pairs that fail to resolve are retried on the next iteration
+func (p *BridgedTokensProcessor) Start(ctx context.Context) error {
+	done := ctx.Done()
+	pollTicker := time.NewTicker(defaultLoopInterval)
+	defer pollTicker.Stop()
+
+	p.processLog.Info("starting processor...")
+	for {
+		select {
+		case <-done:
+			p.processLog.Info("stopping processor")
+			return nil
+
+		case <-pollTicker.C:
+			if err := p.resolve(ctx); err != nil {
+				p.processLog.Error("error resolving bridged tokens", "err", err)
+			}
+		}
+	}
+}
+
+func (p *BridgedTokensProcessor) resolve(ctx context.Context) error {
+	tokenPairs, err := p.db.BridgedTokens.UnresolvedTokenPairs(defaultBridgedTokensBatchSize)
+	if err != nil || len(tokenPairs) == 0 {
+		return err
+	}
+
+	tokens := make([]*database.BridgedToken, 0, len(tokenPairs))
+	for _, tokenPair := range tokenPairs {
+		token, err := ResolveBridgedToken(ctx, p.l1Caller, p.l2Caller, tokenPair)
+		if err != nil {
+			p.processLog.Warn("unable to resolve bridged token", "l1_token_address", tokenPair.L1TokenAddress, "l2_token_address", tokenPair.L2TokenAddress, "err", err)
+			continue
+		} else if !token.Verified {
+			p.processLog.Warn("detected mismatched token pair", "l1_token_address", tokenPair.L1TokenAddress, "l2_token_address", tokenPair.L2TokenAddress)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	p.processLog.Info("resolved bridged tokens", "size", len(tokens))
+	return p.db.BridgedTokens.StoreBridgedTokens(tokens)
+}
+
+// ResolveBridgedToken resolves the ERC20 metadata of both tokens of the pair and verifies that either
+// token is an OptimismMintableERC20 bridged from the other. Errors are only returned when the tokens
+// could not be queried, metadata not implemented by a token is left empty
+func ResolveBridgedToken(ctx context.Context, l1Caller, l2Caller bind.ContractCaller, tokenPair database.TokenPair) (*database.BridgedToken, error) {
+	if tokenPair.L1TokenAddress == predeploys.LegacyERC20ETHAddr && tokenPair.L2TokenAddress == predeploys.LegacyERC20ETHAddr {
+		return &database.BridgedToken{TokenPair: tokenPair, L1Token: etherMetadata, L2Token: etherMetadata, Verified: true}, nil
+	}
+
+	l1Token, l1IsContract, err := tokenMetadata(ctx, l1Caller, tokenPair.L1TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	l2Token, l2IsContract, err := tokenMetadata(ctx, l2Caller, tokenPair.L2TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := false
+	if l1IsContract && l2IsContract {
+		verified, err = isCorrectTokenPair(ctx, l2Caller, tokenPair.L2TokenAddress, tokenPair.L1TokenAddress)
+		if err != nil {
+			return nil, err
+		} else if !verified {
+			verified, err = isCorrectTokenPair(ctx, l1Caller, tokenPair.L1TokenAddress, tokenPair.L2TokenAddress)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &database.BridgedToken{TokenPair: tokenPair, L1Token: l1Token, L2Token: l2Token, Verified: verified}, nil
+}
+
+// tokenMetadata queries the ERC20 metadata of the token, if deployed
+func tokenMetadata(ctx context.Context, caller bind.ContractCaller, token common.Address) (database.TokenMetadata, bool, error) {
+	ctxwt, cancel := context.WithTimeout(ctx, defaultTokenRequestTimeout)
+	defer cancel()
+
+	var metadata database.TokenMetadata
+	code, err := caller.CodeAt(ctxwt, token, nil)
+	if err != nil {
+		return metadata, false, err
+	} else if len(code) == 0 {
+		return metadata, false, nil
+	}
+
+	erc20, err := bindings.NewERC20Caller(token, caller)
+	if err != nil {
+		return metadata, false, err
+	}
+
+	// non-compliant tokens may not implement, or differ in the types of, the optional metadata
+	opts := &bind.CallOpts{Context: ctxwt}
+	name, err := erc20.Name(opts)
+	if err != nil && callError(err) != nil {
+		return metadata, false, err
+	}
+	symbol, err := erc20.Symbol(opts)
+	if err != nil && callError(err) != nil {
+		return metadata, false, err
+	}
+	decimals, err := erc20.Decimals(opts)
+	if err != nil && callError(err) != nil {
+		return metadata, false, err
+	}
+
+	metadata = database.TokenMetadata{Name: name, Symbol: symbol, Decimals: decimals}
+	return metadata, true, nil
+}
+
+// isCorrectTokenPair mirrors the StandardBridge check of a mintable token being bridged from the other token
+func isCorrectTokenPair(ctx context.Context, caller bind.ContractCaller, mintableToken, otherToken common.Address) (bool, error) {
+	ctxwt, cancel := context.WithTimeout(ctx, defaultTokenRequestTimeout)
+	defer cancel()
+
+	token, err := bindings.NewOptimismMintableERC20Caller(mintableToken, caller)
+	if err != nil {
+		return false, err
+	}
+
+	// tokens not implementing ERC165, or reverting, are not mintable
+	opts := &bind.CallOpts{Context: ctxwt}
+	remoteToken := common.Address{}
+	if supported, err := token.SupportsInterface(opts, legacyMintableERC20InterfaceID); err != nil {
+		return false, callError(err)
+	} else if supported {
+		remoteToken, err = token.L1Token(opts)
+		if err != nil {
+			return false, callError(err)
+		}
+	} else if supported, err := token.SupportsInterface(opts, optimismMintableERC20InterfaceID); err != nil {
+		return false, callError(err)
+	} else if supported {
+		remoteToken, err = token.RemoteToken(opts)
+		if err != nil {
+			return false, callError(err)
+		}
+	}
+
+	return remoteToken != (common.Address{}) && remoteToken == otherToken, nil
+}
+
+// callError discards deterministic call failures, leaving the errors of calls that could not be made
+func callError(err error) error {
+	if isCallFailure(err) {
+		return nil
+	}
+	return err
+}
+
+// isCallFailure indicates the call was executed but failed, reverted or returned malformed data, as
+// opposed to the node being unreachable. Call failures are deterministic and are not retried
+func isCallFailure(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// execution errors, including reverts, are reported with these codes
+		return rpcErr.ErrorCode() == 3 || rpcErr.ErrorCode() == -32000
+	}
+
+	var httpErr rpc.HTTPError
+	var netErr net.Error
+	return !errors.As(err, &httpErr) && !errors.As(err, &netErr) &&
+		!errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled)
+}
+
+// interfaceID computes the ERC165 interface id of the supplied function signatures
+func interfaceID(signatures ...string) [4]byte {
+	var id [4]byte
+	for _, signature := range signatures {
+		selector := crypto.Keccak256([]byte(signature))[:4]
+		for i := range id {
+			id[i] ^= selector[i]
+		}
+	}
+	return id
+}
@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/indexer/database"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// revertError is reported by a node when the call reverts
+type revertError struct{}
+
+func (revertError) Error() string  { return "execution reverted" }
+func (revertError) ErrorCode() int { return 3 }
+
+// timeoutError is reported when the node can't be reached
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+type mockToken struct {
+	database.TokenMetadata
+
+	// mintable tokens implementing either interface
+	legacy      bool
+	mintable    bool
+	remoteToken common.Address
+}
+
+type mockContractCaller struct {
+	tokens map[common.Address]*mockToken
+	err    error
+}
+
+func (m *mockContractCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	} else if _, ok := m.tokens[contract]; !ok {
+		return nil, nil
+	}
+	return []byte{0x1}, nil
+}
+
+func (m *mockContractCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	tokenAbi, err := bindings.OptimismMintableERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := tokenAbi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, revertError{}
+	}
+
+	token := m.tokens[*call.To]
+	switch method.Name {
+	case "name":
+		if token.Name == "" {
+			return nil, revertError{}
+		}
+		return method.Outputs.Pack(token.Name)
+	case "symbol":
+		return method.Outputs.Pack(token.Symbol)
+	case "decimals":
+		return method.Outputs.Pack(token.Decimals)
+	case "supportsInterface":
+		args, err := method.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+
+		interfaceID := args[0].([4]byte)
+		supported := (token.legacy && interfaceID == legacyMintableERC20InterfaceID) || (token.mintable && interfaceID == optimismMintableERC20InterfaceID)
+		return method.Outputs.Pack(supported)
+	case "l1Token":
+		if !token.legacy {
+			return nil, revertError{}
+		}
+		return method.Outputs.Pack(token.remoteToken)
+	case "remoteToken":
+		if !token.mintable {
+			return nil, revertError{}
+		}
+		return method.Outputs.Pack(token.remoteToken)
+	}
+
+	return nil, revertError{}
+}
+
+func TestInterfaceIDs(t *testing.T) {
+	// type(ILegacyMintableERC20).interfaceId & type(IOptimismMintableERC20).interfaceId
+	require.Equal(t, [4]byte{0x1d, 0x1d, 0x8b, 0x63}, legacyMintableERC20InterfaceID)
+	require.Equal(t, [4]byte{0xec, 0x4f, 0xc8, 0xe3}, optimismMintableERC20InterfaceID)
+}
+
+func TestResolveBridgedToken(t *testing.T) {
+	metadata := database.TokenMetadata{Name: "Token", Symbol: "TKN", Decimals: 6}
+	l1Token, l2Token, l2LegacyToken, l2NativeToken, l1RemoteToken := common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3"), common.HexToAddress("0x4"), common.HexToAddress("0x5")
+	l1Caller := &mockContractCaller{tokens: map[common.Address]*mockToken{
+		l1Token:       {TokenMetadata: metadata},
+		l1RemoteToken: {TokenMetadata: metadata, mintable: true, remoteToken: l2NativeToken},
+	}}
+	l2Caller := &mockContractCaller{tokens: map[common.Address]*mockToken{
+		l2Token:       {TokenMetadata: metadata, mintable: true, remoteToken: l1Token},
+		l2LegacyToken: {TokenMetadata: database.TokenMetadata{Symbol: "LGC", Decimals: 18}, legacy: true, remoteToken: l1Token},
+		l2NativeToken: {TokenMetadata: metadata},
+	}}
+
+	tests := []struct {
+		name      string
+		tokenPair database.TokenPair
+		verified  bool
+		l2Token   database.TokenMetadata
+	}{
+		{"OptimismMintableERC20", database.TokenPair{L1TokenAddress: l1Token, L2TokenAddress: l2Token}, true, metadata},
+		{"LegacyMintableERC20", database.TokenPair{L1TokenAddress: l1Token, L2TokenAddress: l2LegacyToken}, true, database.TokenMetadata{Symbol: "LGC", Decimals: 18}},
+		{"L2NativeToken", database.TokenPair{L1TokenAddress: l1RemoteToken, L2TokenAddress: l2NativeToken}, true, metadata},
+		{"MismatchedRemoteToken", database.TokenPair{L1TokenAddress: l1RemoteToken, L2TokenAddress: l2Token}, false, metadata},
+		{"NonMintableTokens", database.TokenPair{L1TokenAddress: l1Token, L2TokenAddress: l2NativeToken}, false, metadata},
+		{"NotAContract", database.TokenPair{L1TokenAddress: l1Token, L2TokenAddress: common.HexToAddress("0x6")}, false, database.TokenMetadata{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := ResolveBridgedToken(context.Background(), l1Caller, l2Caller, test.tokenPair)
+			require.NoError(t, err)
+			require.Equal(t, test.tokenPair, token.TokenPair)
+			require.Equal(t, test.verified, token.Verified)
+			require.Equal(t, test.l2Token, token.L2Token)
+		})
+	}
+
+	t.Run("ETH", func(t *testing.T) {
+		tokenPair := database.TokenPair{L1TokenAddress: predeploys.LegacyERC20ETHAddr, L2TokenAddress: predeploys.LegacyERC20ETHAddr}
+		token, err := ResolveBridgedToken(context.Background(), &mockContractCaller{err: timeoutError{}}, &mockContractCaller{err: timeoutError{}}, tokenPair)
+		require.NoError(t, err)
+		require.True(t, token.Verified)
+		require.Equal(t, "ETH", token.L1Token.Symbol)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		tokenPair := database.TokenPair{L1TokenAddress: l1Token, L2TokenAddress: l2Token}
+		_, err := ResolveBridgedToken(context.Background(), l1Caller, &mockContractCaller{err: timeoutError{}}, tokenPair)
+		require.True(t, errors.Is(err, timeoutError{}))
+	})
+}
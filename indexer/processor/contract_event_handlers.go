@@ -0,0 +1,137 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/ethereum-optimism/optimism/indexer/database"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ContractEventHandler is a user-defined handler of the events emitted by a set of contracts. The events
+// of every processed batch are decoded with the supplied ABI and handed to `Handle` within the same database
+// transaction as the contract events themselves. Events not present in the ABI are ignored.
+//
+// Rows stored by a handler should reference the `guid` of their contract event with `ON DELETE CASCADE`
+// such that they are removed alongside the event when its block is rolled back. Finality is inherited
+// from the `finalized` column of the referenced contract event
+type ContractEventHandler struct {
+	Name      string
+	Addresses []common.Address
+	ABI       *abi.ABI
+
+	Handle func(db *database.DB, events []*DecodedContractEvent) error
+
+	// Optional schema for the handler's tables, executed on startup. Migration files
+	// are executed in lexical order and must be idempotent (`IF NOT EXISTS`)
+	Migrations fs.FS
+}
+
+// DecodedContractEvent is a contract event decoded with the ABI of its handler. `Args`
+// contains both the indexed and non-indexed arguments of the event
+type DecodedContractEvent struct {
+	Name     string
+	Args     map[string]interface{}
+	RawEvent *database.ContractEvent
+}
+
+type contractEventHandlers struct {
+	handlers          []ContractEventHandler
+	handlersByAddress map[common.Address][]int
+}
+
+func newContractEventHandlers(handlers []ContractEventHandler) (*contractEventHandlers, error) {
+	handlersByAddress := make(map[common.Address][]int)
+	for i, handler := range handlers {
+		if handler.Name == "" {
+			return nil, errors.New("contract event handler must have a name")
+		} else if len(handler.Addresses) == 0 {
+			return nil, fmt.Errorf("contract event handler %s has no contract addresses", handler.Name)
+		} else if handler.ABI == nil {
+			return nil, fmt.Errorf("contract event handler %s has no ABI", handler.Name)
+		} else if handler.Handle == nil {
+			return nil, fmt.Errorf("contract event handler %s has no handle function", handler.Name)
+		}
+
+		for _, addr := range handler.Addresses {
+			handlersByAddress[addr] = append(handlersByAddress[addr], i)
+		}
+	}
+
+	return &contractEventHandlers{handlers: handlers, handlersByAddress: handlersByAddress}, nil
+}
+
+// addresses returns the set of contracts watched by the handlers
+func (h *contractEventHandlers) addresses() []common.Address {
+	addrs := make([]common.Address, 0, len(h.handlersByAddress))
+	for addr := range h.handlersByAddress {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// handle decodes & forwards the contract events, in order, to the handlers watching the contract they
+// were emitted from. A failing handler fails the batch, rolling back all of its database operations
+func (h *contractEventHandlers) handle(processLog log.Logger, db *database.DB, events []*database.ContractEvent) error {
+	decodedEvents := make([][]*DecodedContractEvent, len(h.handlers))
+	for _, event := range events {
+		for _, i := range h.handlersByAddress[event.ContractAddress] {
+			decodedEvent, err := decodeContractEvent(event, h.handlers[i].ABI)
+			if err != nil {
+				return fmt.Errorf("unable to decode contract event for handler %s: %w", h.handlers[i].Name, err)
+			} else if decodedEvent != nil {
+				decodedEvents[i] = append(decodedEvents[i], decodedEvent)
+			}
+		}
+	}
+
+	for i, handler := range h.handlers {
+		if len(decodedEvents[i]) == 0 {
+			continue
+		}
+
+		processLog.Info("forwarding contract events to handler", "handler", handler.Name, "size", len(decodedEvents[i]))
+		if err := handler.Handle(db, decodedEvents[i]); err != nil {
+			return fmt.Errorf("contract event handler %s failed: %w", handler.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeContractEvent decodes the contract event with the supplied ABI. Nil is returned for
+// anonymous events or events not present in the ABI
+func decodeContractEvent(event *database.ContractEvent, contractAbi *abi.ABI) (*DecodedContractEvent, error) {
+	log := event.GethLog
+	if len(log.Topics) == 0 {
+		return nil, nil
+	}
+
+	eventAbi, err := contractAbi.EventByID(log.Topics[0])
+	if err != nil {
+		return nil, nil
+	}
+
+	args := make(map[string]interface{})
+	if err := contractAbi.UnpackIntoMap(args, eventAbi.Name, log.Data); err != nil {
+		return nil, err
+	}
+
+	var indexedArgs abi.Arguments
+	for _, arg := range eventAbi.Inputs {
+		if arg.Indexed {
+			indexedArgs = append(indexedArgs, arg)
+		}
+	}
+
+	// The first topic (event signature) is omitted
+	if err := abi.ParseTopicsIntoMap(args, indexedArgs, log.Topics[1:]); err != nil {
+		return nil, err
+	}
+
+	return &DecodedContractEvent{Name: eventAbi.Name, Args: args, RawEvent: event}, nil
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/indexer/database"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+const transferEventAbi = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
+func transferEvent(t *testing.T, contractAbi *abi.ABI, contract, from, to common.Address, value int64) *database.ContractEvent {
+	data, err := contractAbi.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(value))
+	require.NoError(t, err)
+
+	event := database.ContractEventFromGethLog(&types.Log{
+		Address: contract,
+		Topics:  []common.Hash{contractAbi.Events["Transfer"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    data,
+	}, 1)
+	return &event
+}
+
+func TestContractEventHandlers(t *testing.T) {
+	contractAbi, err := abi.JSON(strings.NewReader(transferEventAbi))
+	require.NoError(t, err)
+
+	tokenA, tokenB := common.HexToAddress("0xa"), common.HexToAddress("0xb")
+	alice, bob := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+
+	var handled []*DecodedContractEvent
+	handlers, err := newContractEventHandlers([]ContractEventHandler{{
+		Name:      "transfers",
+		Addresses: []common.Address{tokenA},
+		ABI:       &contractAbi,
+		Handle: func(db *database.DB, events []*DecodedContractEvent) error {
+			handled = append(handled, events...)
+			return nil
+		},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{tokenA}, handlers.addresses())
+
+	unknownEvent := database.ContractEventFromGethLog(&types.Log{Address: tokenA, Topics: []common.Hash{common.HexToHash("0x1")}}, 1)
+	anonymousEvent := database.ContractEventFromGethLog(&types.Log{Address: tokenA}, 1)
+	events := []*database.ContractEvent{
+		transferEvent(t, &contractAbi, tokenA, alice, bob, 10),
+		transferEvent(t, &contractAbi, tokenB, alice, bob, 20),
+		&unknownEvent,
+		&anonymousEvent,
+		transferEvent(t, &contractAbi, tokenA, bob, alice, 30),
+	}
+
+	logger := testlog.Logger(t, log.LvlInfo)
+	require.NoError(t, handlers.handle(logger, nil, events))
+	require.Len(t, handled, 2)
+	require.Equal(t, "Transfer", handled[0].Name)
+	require.Equal(t, events[0], handled[0].RawEvent)
+	require.Equal(t, alice, handled[0].Args["from"])
+	require.Equal(t, bob, handled[0].Args["to"])
+	require.Equal(t, big.NewInt(10), handled[0].Args["value"])
+	require.Equal(t, events[4], handled[1].RawEvent)
+	require.Equal(t, big.NewInt(30), handled[1].Args["value"])
+
+	// no events, no invocation
+	handled = nil
+	require.NoError(t, handlers.handle(logger, nil, events[1:2]))
+	require.Nil(t, handled)
+
+	// handler failures fail the batch
+	handlers.handlers[0].Handle = func(db *database.DB, events []*DecodedContractEvent) error { return errors.New("boom") }
+	require.ErrorContains(t, handlers.handle(logger, nil, events), "boom")
+}
+
+func TestContractEventHandlersValidation(t *testing.T) {
+	contractAbi, err := abi.JSON(strings.NewReader(transferEventAbi))
+	require.NoError(t, err)
+
+	handle := func(db *database.DB, events []*DecodedContractEvent) error { return nil }
+	addrs := []common.Address{common.HexToAddress("0xa")}
+
+	_, err = newContractEventHandlers([]ContractEventHandler{{Addresses: addrs, ABI: &contractAbi, Handle: handle}})
+	require.Error(t, err)
+	_, err = newContractEventHandlers([]ContractEventHandler{{Name: "a", ABI: &contractAbi, Handle: handle}})
+	require.Error(t, err)
+	_, err = newContractEventHandlers([]ContractEventHandler{{Name: "a", Addresses: addrs, Handle: handle}})
+	require.Error(t, err)
+	_, err = newContractEventHandlers([]ContractEventHandler{{Name: "a", Addresses: addrs, ABI: &contractAbi}})
+	require.Error(t, err)
+
+	handlers, err := newContractEventHandlers(nil)
+	require.NoError(t, err)
+	require.Empty(t, handlers.addresses())
+	require.NoError(t, handlers.handle(nil, nil, []*database.ContractEvent{}))
+}
@@ -2,7 +2,6 @@ package processor
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -15,7 +14,6 @@ import (
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -58,10 +56,16 @@ type L1Processor struct {
 	processor
 }
 
-func NewL1Processor(logger log.Logger, ethClient node.EthClient, db *database.DB, l1Contracts L1Contracts) (*L1Processor, error) {
+func NewL1Processor(logger log.Logger, ethClient node.EthClient, db *database.DB, l1Contracts L1Contracts, handlers []ContractEventHandler, cfg Config) (*L1Processor, error) {
 	l1ProcessLog := logger.New("processor", "l1")
 	l1ProcessLog.Info("initializing processor")
 
+	eventHandlers, err := newContractEventHandlers(handlers)
+	if err != nil {
+		l1ProcessLog.Error("invalid contract event handlers", "err", err)
+		return nil, err
+	}
+
 	l2OutputOracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
 	if err != nil {
 		l1ProcessLog.Error("unable to generate L2OutputOracle ABI", "err", err)
@@ -74,44 +78,62 @@ func NewL1Processor(logger log.Logger, ethClient node.EthClient, db *database.DB
 	}
 	checkpointAbi := checkpointAbi{l2OutputOracle: l2OutputOracleABI, legacyStateCommitmentChain: legacyStateCommitmentChainABI}
 
-	latestHeader, err := db.Blocks.LatestL1BlockHeader()
+	contractAddrs := append(l1Contracts.ToSlice(), eventHandlers.addresses()...)
+	processor, err := newProcessor(l1ProcessLog, ethClient, db, contractAddrs, l1ProcessFn(l1ProcessLog, ethClient, l1Contracts, checkpointAbi, eventHandlers), l1FinalityFns(), cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	var fromL1Header *types.Header
-	if latestHeader != nil {
-		l1ProcessLog.Info("detected last indexed block", "height", latestHeader.Number.Int, "hash", latestHeader.Hash)
-		l1Header, err := ethClient.BlockHeaderByHash(latestHeader.Hash)
-		if err != nil {
-			l1ProcessLog.Error("unable to fetch header for last indexed block", "hash", latestHeader.Hash, "err", err)
-			return nil, err
-		}
+	return &L1Processor{processor: *processor}, nil
+}
 
-		fromL1Header = l1Header
-	} else {
-		// we shouldn't start from genesis with l1. Need a "genesis" L1 height provided for the rollup
-		l1ProcessLog.Info("no indexed state, starting from genesis")
-		fromL1Header = nil
-	}
+func l1FinalityFns() finalityFns {
+	return finalityFns{
+		finalize: func(db *database.DB, height *big.Int) error { return db.FinalizeL1Blocks(height) },
+		rollback: func(db *database.DB, fromHeight *big.Int) error { return db.RollbackL1Blocks(fromHeight) },
+		latestFinalizedHeader: func(db *database.DB) (*types.Header, error) {
+			header, err := db.Blocks.LatestFinalizedL1BlockHeader()
+			if err != nil || header == nil {
+				return nil, err
+			}
+			return (*types.Header)(header.GethHeader), nil
+		},
+		unfinalizedHeaders: func(db *database.DB) ([]*types.Header, error) {
+			headers, err := db.Blocks.UnfinalizedL1BlockHeaders()
+			if err != nil {
+				return nil, err
+			}
 
-	l1Processor := &L1Processor{
-		processor: processor{
-			headerTraversal: node.NewHeaderTraversal(ethClient, fromL1Header),
-			db:              db,
-			processFn:       l1ProcessFn(l1ProcessLog, ethClient, l1Contracts, checkpointAbi),
-			processLog:      l1ProcessLog,
+			gethHeaders := make([]*types.Header, len(headers))
+			for i, header := range headers {
+				gethHeaders[i] = (*types.Header)(header.GethHeader)
+			}
+			return gethHeaders, nil
+		},
+		backfillMarker: func(db *database.DB) (*types.Header, error) {
+			marker, err := db.Blocks.BackfillMarker(database.L1Chain)
+			if err != nil || marker == nil {
+				return nil, err
+			}
+			return (*types.Header)(marker.GethHeader), nil
+		},
+		storeBackfillMarker: func(db *database.DB, header *types.Header) error {
+			return db.Blocks.StoreBackfillMarker(database.BackfillMarkerFromGethHeader(database.L1Chain, header))
 		},
 	}
-
-	return l1Processor, nil
 }
 
-func l1ProcessFn(processLog log.Logger, ethClient node.EthClient, l1Contracts L1Contracts, checkpointAbi checkpointAbi) ProcessFn {
-	rawEthClient := ethclient.NewClient(ethClient.RawRpcClient())
-
-	contractAddrs := l1Contracts.ToSlice()
+func l1ProcessFn(processLog log.Logger, ethClient node.EthClient, l1Contracts L1Contracts, checkpointAbi checkpointAbi, eventHandlers *contractEventHandlers) ProcessFn {
 	processLog.Info("processor configured with contracts", "contracts", l1Contracts)
+	if len(eventHandlers.handlers) > 0 {
+		processLog.Info("processor configured with contract event handlers", "size", len(eventHandlers.handlers), "contracts", eventHandlers.addresses())
+	}
+
+	// only events emitted by the optimism contracts are forwarded to the bridge processors
+	optimismContracts := make(map[common.Address]bool)
+	for _, addr := range l1Contracts.ToSlice() {
+		optimismContracts[addr] = true
+	}
 
 	outputProposedEventName := "OutputProposed"
 	outputProposedEventSig := checkpointAbi.l2OutputOracle.Events[outputProposedEventName].ID
@@ -119,7 +141,7 @@ func l1ProcessFn(processLog log.Logger, ethClient node.EthClient, l1Contracts L1
 	legacyStateBatchAppendedEventName := "StateBatchAppended"
 	legacyStateBatchAppendedEventSig := checkpointAbi.legacyStateCommitmentChain.Events[legacyStateBatchAppendedEventName].ID
 
-	return func(db *database.DB, headers []*types.Header) error {
+	return func(db *database.DB, headers []*types.Header, logs []types.Log) error {
 		headerMap := make(map[common.Hash]*types.Header)
 		for _, header := range headers {
 			headerMap[header.Hash()] = header
@@ -127,17 +149,12 @@ func l1ProcessFn(processLog log.Logger, ethClient node.EthClient, l1Contracts L1
 
 		/** Watch for all Optimism Contract Events **/
 
-		logFilter := ethereum.FilterQuery{FromBlock: headers[0].Number, ToBlock: headers[len(headers)-1].Number, Addresses: contractAddrs}
-		logs, err := rawEthClient.FilterLogs(context.Background(), logFilter) // []types.Log
-		if err != nil {
-			return err
-		}
-
 		// L2 checkpoints posted on L1
 		outputProposals := []*database.OutputProposal{}
 		legacyStateBatches := []*database.LegacyStateBatch{}
 
 		l1HeadersOfInterest := make(map[common.Hash]bool)
+		contractEvents := make([]*database.ContractEvent, len(logs))
 		l1ContractEvents := make([]*database.L1ContractEvent, len(logs))
 
 		processedContractEvents := NewProcessedContractEvents()
@@ -149,8 +166,16 @@ func l1ProcessFn(processLog log.Logger, ethClient node.EthClient, l1Contracts L1
 				return errors.New("parsed log with a block hash not in this batch")
 			}
 
-			contractEvent := processedContractEvents.AddLog(log, header.Time)
 			l1HeadersOfInterest[log.BlockHash] = true
+			if !optimismContracts[log.Address] {
+				userContractEvent := database.ContractEventFromGethLog(log, header.Time)
+				contractEvents[i] = &userContractEvent
+				l1ContractEvents[i] = &database.L1ContractEvent{ContractEvent: userContractEvent}
+				continue
+			}
+
+			contractEvent := processedContractEvents.AddLog(log, header.Time)
+			contractEvents[i] = contractEvent
 			l1ContractEvents[i] = &database.L1ContractEvent{ContractEvent: *contractEvent}
 
 			// Track Checkpoint Events for L2
@@ -205,7 +230,7 @@ func l1ProcessFn(processLog log.Logger, ethClient node.EthClient, l1Contracts L1
 		numIndexedL1Headers := len(indexedL1Headers)
 		if numIndexedL1Headers > 0 {
 			processLog.Info("saving l1 blocks with optimism logs", "size", numIndexedL1Headers, "batch_size", len(headers))
-			err = db.Blocks.StoreL1BlockHeaders(indexedL1Headers)
+			err := db.Blocks.StoreL1BlockHeaders(indexedL1Headers)
 			if err != nil {
 				return err
 			}
@@ -252,6 +277,12 @@ func l1ProcessFn(processLog log.Logger, ethClient node.EthClient, l1Contracts L1
 			if err != nil {
 				return err
 			}
+
+			// forward along contract events to the user-defined handlers
+			err = eventHandlers.handle(processLog, db, contractEvents)
+			if err != nil {
+				return err
+			}
 		} else {
 			processLog.Info("no l1 blocks of interest within batch")
 		}
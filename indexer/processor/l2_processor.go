@@ -2,9 +2,9 @@ package processor
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 
 	"github.com/ethereum-optimism/optimism/indexer/database"
@@ -12,7 +12,6 @@ import (
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -57,48 +56,74 @@ type L2Processor struct {
 	processor
 }
 
-func NewL2Processor(logger log.Logger, ethClient node.EthClient, db *database.DB, l2Contracts L2Contracts) (*L2Processor, error) {
+func NewL2Processor(logger log.Logger, ethClient node.EthClient, db *database.DB, l2Contracts L2Contracts, handlers []ContractEventHandler, cfg Config) (*L2Processor, error) {
 	l2ProcessLog := logger.New("processor", "l2")
 	l2ProcessLog.Info("initializing processor")
 
-	latestHeader, err := db.Blocks.LatestL2BlockHeader()
+	eventHandlers, err := newContractEventHandlers(handlers)
 	if err != nil {
+		l2ProcessLog.Error("invalid contract event handlers", "err", err)
 		return nil, err
 	}
 
-	var fromL2Header *types.Header
-	if latestHeader != nil {
-		l2ProcessLog.Info("detected last indexed block", "height", latestHeader.Number.Int, "hash", latestHeader.Hash)
-		l2Header, err := ethClient.BlockHeaderByHash(latestHeader.Hash)
-		if err != nil {
-			l2ProcessLog.Error("unable to fetch header for last indexed block", "hash", latestHeader.Hash, "err", err)
-			return nil, err
-		}
-
-		fromL2Header = l2Header
-	} else {
-		l2ProcessLog.Info("no indexed state, starting from genesis")
-		fromL2Header = nil
+	contractAddrs := append(l2Contracts.ToSlice(), eventHandlers.addresses()...)
+	processor, err := newProcessor(l2ProcessLog, ethClient, db, contractAddrs, l2ProcessFn(l2ProcessLog, ethClient, l2Contracts, eventHandlers), l2FinalityFns(), cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	l2Processor := &L2Processor{
-		processor: processor{
-			headerTraversal: node.NewHeaderTraversal(ethClient, fromL2Header),
-			db:              db,
-			processFn:       l2ProcessFn(l2ProcessLog, ethClient, l2Contracts),
-			processLog:      l2ProcessLog,
+	return &L2Processor{processor: *processor}, nil
+}
+
+func l2FinalityFns() finalityFns {
+	return finalityFns{
+		finalize: func(db *database.DB, height *big.Int) error { return db.FinalizeL2Blocks(height) },
+		rollback: func(db *database.DB, fromHeight *big.Int) error { return db.RollbackL2Blocks(fromHeight) },
+		latestFinalizedHeader: func(db *database.DB) (*types.Header, error) {
+			header, err := db.Blocks.LatestFinalizedL2BlockHeader()
+			if err != nil || header == nil {
+				return nil, err
+			}
+			return (*types.Header)(header.GethHeader), nil
 		},
-	}
+		unfinalizedHeaders: func(db *database.DB) ([]*types.Header, error) {
+			headers, err := db.Blocks.UnfinalizedL2BlockHeaders()
+			if err != nil {
+				return nil, err
+			}
 
-	return l2Processor, nil
+			gethHeaders := make([]*types.Header, len(headers))
+			for i, header := range headers {
+				gethHeaders[i] = (*types.Header)(header.GethHeader)
+			}
+			return gethHeaders, nil
+		},
+		backfillMarker: func(db *database.DB) (*types.Header, error) {
+			marker, err := db.Blocks.BackfillMarker(database.L2Chain)
+			if err != nil || marker == nil {
+				return nil, err
+			}
+			return (*types.Header)(marker.GethHeader), nil
+		},
+		storeBackfillMarker: func(db *database.DB, header *types.Header) error {
+			return db.Blocks.StoreBackfillMarker(database.BackfillMarkerFromGethHeader(database.L2Chain, header))
+		},
+	}
 }
 
-func l2ProcessFn(processLog log.Logger, ethClient node.EthClient, l2Contracts L2Contracts) ProcessFn {
-	rawEthClient := ethclient.NewClient(ethClient.RawRpcClient())
-
-	contractAddrs := l2Contracts.ToSlice()
+func l2ProcessFn(processLog log.Logger, ethClient node.EthClient, l2Contracts L2Contracts, eventHandlers *contractEventHandlers) ProcessFn {
 	processLog.Info("processor configured with contracts", "contracts", l2Contracts)
-	return func(db *database.DB, headers []*types.Header) error {
+	if len(eventHandlers.handlers) > 0 {
+		processLog.Info("processor configured with contract event handlers", "size", len(eventHandlers.handlers), "contracts", eventHandlers.addresses())
+	}
+
+	// only events emitted by the optimism contracts are forwarded to the bridge processors
+	optimismContracts := make(map[common.Address]bool)
+	for _, addr := range l2Contracts.ToSlice() {
+		optimismContracts[addr] = true
+	}
+
+	return func(db *database.DB, headers []*types.Header, logs []types.Log) error {
 		numHeaders := len(headers)
 
 		/** Index all L2 blocks **/
@@ -112,12 +137,7 @@ func l2ProcessFn(processLog log.Logger, ethClient node.EthClient, l2Contracts L2
 
 		/** Watch for Contract Events **/
 
-		logFilter := ethereum.FilterQuery{FromBlock: headers[0].Number, ToBlock: headers[numHeaders-1].Number, Addresses: contractAddrs}
-		logs, err := rawEthClient.FilterLogs(context.Background(), logFilter)
-		if err != nil {
-			return err
-		}
-
+		contractEvents := make([]*database.ContractEvent, len(logs))
 		l2ContractEvents := make([]*database.L2ContractEvent, len(logs))
 		processedContractEvents := NewProcessedContractEvents()
 		for i := range logs {
@@ -128,14 +148,22 @@ func l2ProcessFn(processLog log.Logger, ethClient node.EthClient, l2Contracts L2
 				return errors.New("parsed log with a block hash not in this batch")
 			}
 
-			contractEvent := processedContractEvents.AddLog(log, header.Time)
+			var contractEvent *database.ContractEvent
+			if optimismContracts[log.Address] {
+				contractEvent = processedContractEvents.AddLog(log, header.Time)
+			} else {
+				userContractEvent := database.ContractEventFromGethLog(log, header.Time)
+				contractEvent = &userContractEvent
+			}
+
+			contractEvents[i] = contractEvent
 			l2ContractEvents[i] = &database.L2ContractEvent{ContractEvent: *contractEvent}
 		}
 
 		/** Update Database **/
 
 		processLog.Info("saving l2 blocks", "size", numHeaders)
-		err = db.Blocks.StoreL2BlockHeaders(l2Headers)
+		err := db.Blocks.StoreL2BlockHeaders(l2Headers)
 		if err != nil {
 			return err
 		}
@@ -164,6 +192,12 @@ func l2ProcessFn(processLog log.Logger, ethClient node.EthClient, l2Contracts L2
 			if err != nil {
 				return err
 			}
+
+			// forward along contract events to the user-defined handlers
+			err = eventHandlers.handle(processLog, db, contractEvents)
+			if err != nil {
+				return err
+			}
 		}
 
 		// a-ok!
@@ -358,7 +392,8 @@ func l2ProcessContractEventsStandardBridge(processLog log.Logger, db *database.D
 		withdrawals[i] = &database.L2BridgeWithdrawal{
 			TransactionWithdrawalHash: msgPassedEvent.WithdrawalHash,
 			CrossDomainMessengerNonce: &database.U256{Int: initiatedBridgeEvent.CrossDomainMessengerNonce},
-			TokenPair:                 database.TokenPair{L1TokenAddress: initiatedBridgeEvent.LocalToken, L2TokenAddress: initiatedBridgeEvent.RemoteToken},
+			// The local token of a withdrawal initiated on L2 is the L2 token
+			TokenPair: database.TokenPair{L1TokenAddress: initiatedBridgeEvent.RemoteToken, L2TokenAddress: initiatedBridgeEvent.LocalToken},
 			Tx: database.Transaction{
 				FromAddress: initiatedBridgeEvent.From,
 				ToAddress:   initiatedBridgeEvent.To,
@@ -2,11 +2,16 @@ package processor
 
 import (
 	"context"
+	"errors"
+	"math/big"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/indexer/database"
 	"github.com/ethereum-optimism/optimism/indexer/node"
+	"github.com/ethereum-optimism/optimism/op-service/backoff"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -16,21 +21,127 @@ const (
 	defaultHeaderBufferSize = 500
 )
 
-// ProcessFn is the the entrypoint for processing a batch of headers.
-// In the event of failure, database operations are rolled back
-type ProcessFn func(*database.DB, []*types.Header) error
+// ProcessFn is the the entrypoint for processing a batch of headers and the logs emitted
+// within them. In the event of failure, database operations are rolled back
+type ProcessFn func(*database.DB, []*types.Header, []types.Log) error
+
+// Config configures how a processor traverses its chain
+type Config struct {
+	// Index past the finalized height, up to the latest block. Otherwise
+	// the latest block is indexed as if it were finalized
+	FollowUnsafeHead bool
+
+	Backfill BackfillConfig
+}
+
+// finalityFns are the chain specific database operations used to track the finality
+// of the indexed data and roll back the data of the blocks that are reorged out, as
+// well as the progress of the backfill
+type finalityFns struct {
+	finalize func(*database.DB, *big.Int) error
+	rollback func(*database.DB, *big.Int) error
+
+	latestFinalizedHeader func(*database.DB) (*types.Header, error)
+	unfinalizedHeaders    func(*database.DB) ([]*types.Header, error)
+
+	backfillMarker      func(*database.DB) (*types.Header, error)
+	storeBackfillMarker func(*database.DB, *types.Header) error
+}
+
+// latestHeadClient reports the latest block height as the finalized one. Unless following
+// the unsafe head, the processor indexes up to the latest block as if it were finalized
+type latestHeadClient struct {
+	node.EthClient
+}
+
+func (c latestHeadClient) FinalizedBlockHeight() (*big.Int, error) {
+	return c.LatestBlockHeight()
+}
 
 type processor struct {
 	headerTraversal *node.HeaderTraversal
+	ethClient       node.EthClient
+	contractAddrs   []common.Address
 
 	db         *database.DB
 	processFn  ProcessFn
 	processLog log.Logger
 
+	backfillCfg BackfillConfig
+
+	// When following the unsafe head, indexed data is finalized as the
+	// finalized height advances or rolled back when reorged out
+	followUnsafeHead bool
+	finality         finalityFns
+	finalizedHeight  *big.Int
+
 	paused                bool
 	latestProcessedHeader *types.Header
 }
 
+// newProcessor instantiates a processor resuming from the latest finalized indexed, or backfilled, header. Data
+// indexed past it may have been reorged out while the indexer was not running and is rolled back
+func newProcessor(processLog log.Logger, ethClient node.EthClient, db *database.DB, contractAddrs []common.Address, processFn ProcessFn, finality finalityFns, cfg Config) (*processor, error) {
+	if cfg.Backfill.Workers < 0 {
+		return nil, errors.New("backfill workers must not be negative")
+	}
+
+	var fromHeader *types.Header
+	latestHeader, err := finality.latestFinalizedHeader(db)
+	if err != nil {
+		return nil, err
+	}
+
+	backfilledHeader, err := finality.backfillMarker(db)
+	if err != nil {
+		return nil, err
+	} else if backfilledHeader != nil && (latestHeader == nil || backfilledHeader.Number.Cmp(latestHeader.Number) > 0) {
+		latestHeader = backfilledHeader
+	}
+
+	if latestHeader != nil {
+		processLog.Info("detected last indexed block", "height", latestHeader.Number, "hash", latestHeader.Hash())
+		header, err := ethClient.BlockHeaderByHash(latestHeader.Hash())
+		if err != nil {
+			processLog.Error("unable to fetch header for last indexed block", "hash", latestHeader.Hash(), "err", err)
+			return nil, err
+		}
+
+		fromHeader = header
+	} else {
+		processLog.Info("no indexed state, starting from genesis")
+	}
+
+	err = db.Transaction(func(db *database.DB) error {
+		return finality.rollback(db, nextHeight(fromHeader))
+	})
+	if err != nil {
+		processLog.Error("unable to roll back unfinalized indexed state", "err", err)
+		return nil, err
+	}
+
+	if cfg.FollowUnsafeHead {
+		processLog.Info("following the unsafe head")
+	} else {
+		ethClient = latestHeadClient{ethClient}
+	}
+
+	processor := &processor{
+		headerTraversal:       node.NewHeaderTraversal(ethClient, fromHeader),
+		ethClient:             ethClient,
+		contractAddrs:         contractAddrs,
+		db:                    db,
+		processFn:             processFn,
+		processLog:            processLog,
+		backfillCfg:           cfg.Backfill,
+		followUnsafeHead:      cfg.FollowUnsafeHead,
+		finality:              finality,
+		latestProcessedHeader: fromHeader,
+	}
+
+	return processor, nil
+}
+
 // Start kicks off the processing loop. This is a block operation
 // unless the processor encountering an error, abrupting the loop,
 // or the supplied context is cancelled.
@@ -40,6 +151,10 @@ func (p *processor) Start(ctx context.Context) error {
 	defer pollTicker.Stop()
 
 	p.processLog.Info("starting processor...")
+	if p.backfillCfg.Workers > 0 {
+		retryBackfill(ctx, p.processLog, backoff.Exponential(), p.backfill)
+	}
+
 	var unprocessedHeaders []*types.Header
 	for {
 		select {
@@ -53,14 +168,31 @@ func (p *processor) Start(ctx context.Context) error {
 				continue
 			}
 
+			if p.followUnsafeHead {
+				if err := p.finalize(); err != nil {
+					// indexed data remains unfinalized until the next successful attempt
+					p.processLog.Warn("error finalizing indexed state", "err", err)
+				}
+			}
+
 			if len(unprocessedHeaders) == 0 {
-				newHeaders, err := p.headerTraversal.NextFinalizedHeaders(defaultHeaderBufferSize)
+				newHeaders, err := p.nextHeaders()
 				if err != nil {
+					if p.followUnsafeHead && errors.Is(err, node.ErrHeaderTraversalAndProviderMismatchedState) {
+						p.processLog.Warn("detected reorg", "last_header", p.headerTraversal.LastHeader().Number)
+						if err := p.rollback(); err != nil {
+							p.processLog.Error("error rolling back reorged state", "err", err)
+						}
+						continue
+					}
+
 					p.processLog.Error("error querying for headers", "err", err)
 					continue
 				} else if len(newHeaders) == 0 {
-					// Logged as an error since this loop should be operating at a longer interval than the provider
-					p.processLog.Error("no new headers. processor unexpectedly at head...")
+					if !p.followUnsafeHead {
+						// Logged as an error since this loop should be operating at a longer interval than the provider
+						p.processLog.Error("no new headers. processor unexpectedly at head...")
+					}
 					continue
 				}
 
@@ -74,13 +206,35 @@ func (p *processor) Start(ctx context.Context) error {
 			batchLog := p.processLog.New("batch_start_block_number", firstHeader.Number, "batch_end_block_number", lastHeader.Number)
 			err := p.db.Transaction(func(db *database.DB) error {
 				batchLog.Info("processing batch")
-				return p.processFn(db, unprocessedHeaders)
+				logs, err := p.filterLogs(firstHeader.Number, lastHeader.Number)
+				if err != nil {
+					return err
+				}
+
+				err = p.processFn(db, unprocessedHeaders, logs)
+				if err != nil {
+					return err
+				}
+
+				finalizedHeight := lastHeader.Number
+				if p.followUnsafeHead {
+					finalizedHeight = p.finalizedHeight
+					if finalizedHeight == nil {
+						return nil
+					}
+				}
+				return p.finality.finalize(db, finalizedHeight)
 			})
 
 			// Eventually, we want to halt the processor on any error rather than rely
 			// on this loop for retry functionality.
 			if err != nil {
 				batchLog.Warn("error processing batch. no operations committed", "err", err)
+				if p.followUnsafeHead {
+					// the batch may have been reorged out in the meantime. fetch it again
+					p.headerTraversal.Reset(p.latestProcessedHeader)
+					unprocessedHeaders = nil
+				}
 			} else {
 				batchLog.Info("fully committed batch")
 
@@ -91,10 +245,97 @@ func (p *processor) Start(ctx context.Context) error {
 	}
 }
 
+func (p *processor) nextHeaders() ([]*types.Header, error) {
+	if p.followUnsafeHead {
+		return p.headerTraversal.NextUnsafeHeaders(defaultHeaderBufferSize)
+	}
+
+	return p.headerTraversal.NextFinalizedHeaders(defaultHeaderBufferSize)
+}
+
+// filterLogs retrieves the logs emitted by the watched contracts within the supplied range, inclusive
+func (p *processor) filterLogs(fromHeight, toHeight *big.Int) ([]types.Log, error) {
+	return p.ethClient.FilterLogs(ethereum.FilterQuery{FromBlock: fromHeight, ToBlock: toHeight, Addresses: p.contractAddrs})
+}
+
+// finalize marks the indexed data up to the finalized height as finalized
+func (p *processor) finalize() error {
+	finalizedHeight, err := p.ethClient.FinalizedBlockHeight()
+	if err != nil {
+		return err
+	} else if p.finalizedHeight != nil && finalizedHeight.Cmp(p.finalizedHeight) <= 0 {
+		return nil
+	}
+
+	err = p.db.Transaction(func(db *database.DB) error {
+		return p.finality.finalize(db, finalizedHeight)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.finalizedHeight = finalizedHeight
+	return nil
+}
+
+// rollback removes the indexed data of the blocks that have been reorged out and resumes
+// the traversal from the latest indexed header that is still canonical
+func (p *processor) rollback() error {
+	var ancestor *types.Header
+	err := p.db.Transaction(func(db *database.DB) error {
+		headers, err := p.finality.unfinalizedHeaders(db)
+		if err != nil {
+			return err
+		}
+
+		for _, header := range headers {
+			canonicalHeader, err := p.ethClient.BlockHeaderByNumber(header.Number)
+			if err != nil && !errors.Is(err, ethereum.NotFound) {
+				return err
+			} else if canonicalHeader != nil && canonicalHeader.Hash() == header.Hash() {
+				ancestor = header
+				break
+			}
+		}
+
+		if ancestor == nil {
+			// all of the unfinalized data has been reorged out
+			ancestor, err = p.finality.latestFinalizedHeader(db)
+			if err != nil {
+				return err
+			}
+		}
+
+		return p.finality.rollback(db, nextHeight(ancestor))
+	})
+	if err != nil {
+		return err
+	}
+
+	if ancestor != nil {
+		p.processLog.Info("rolled back reorged state", "common_ancestor", ancestor.Number, "hash", ancestor.Hash())
+	} else {
+		p.processLog.Info("rolled back reorged state to genesis")
+	}
+
+	p.headerTraversal.Reset(ancestor)
+	p.latestProcessedHeader = ancestor
+	return nil
+}
+
 func (p processor) LatestProcessedHeader() *types.Header {
 	return p.latestProcessedHeader
 }
 
+// nextHeight returns the height of the block following the supplied header, genesis if nil
+func nextHeight(header *types.Header) *big.Int {
+	if header == nil {
+		return big.NewInt(0)
+	}
+
+	return new(big.Int).Add(header.Number, big.NewInt(1))
+}
+
 // Useful ONLY for tests!
 
 func (p *processor) PauseForTest() {
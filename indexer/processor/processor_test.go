@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/indexer/node"
+
+	"github.com/stretchr/testify/require"
+)
+
+type heightsEthClient struct {
+	node.EthClient
+	latest, finalized *big.Int
+}
+
+func (c *heightsEthClient) LatestBlockHeight() (*big.Int, error) {
+	return c.latest, nil
+}
+
+func (c *heightsEthClient) FinalizedBlockHeight() (*big.Int, error) {
+	return c.finalized, nil
+}
+
+func TestLatestHeadClient(t *testing.T) {
+	client := latestHeadClient{&heightsEthClient{latest: big.NewInt(10), finalized: big.NewInt(4)}}
+
+	height, err := client.FinalizedBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(10), height)
+}
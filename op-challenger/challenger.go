@@ -15,6 +15,11 @@ func Main(ctx context.Context, logger log.Logger, cfg *config.Config) error {
 	if err != nil {
 		return fmt.Errorf("failed to create the fault service: %w", err)
 	}
+	defer func() {
+		if err := service.Close(); err != nil {
+			logger.Error("Failed to close the fault service", "err", err)
+		}
+	}()
 
-	return service.MonitorGame(ctx)
+	return service.MonitorGames(ctx)
 }
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
@@ -15,7 +17,7 @@ import (
 
 var (
 	l1EthRpc                = "http://example.com:8545"
-	gameAddressValue        = "0xaa00000000000000000000000000000000000000"
+	gameFactoryAddressValue = "0xbb00000000000000000000000000000000000000"
 	cannonNetwork           = chaincfg.AvailableNetworks()[0]
 	otherCannonNetwork      = chaincfg.AvailableNetworks()[1]
 	cannonBin               = "./bin/cannon"
@@ -24,7 +26,7 @@ var (
 	cannonDatadir           = "./test_data"
 	cannonL2                = "http://example.com:9545"
 	alphabetTrace           = "abcdefghijz"
-	agreeWithProposedOutput = "true"
+	rollupRpc               = "http://example.com:8555"
 )
 
 func TestLogLevel(t *testing.T) {
@@ -44,14 +46,14 @@ func TestLogLevel(t *testing.T) {
 
 func TestDefaultCLIOptionsMatchDefaultConfig(t *testing.T) {
 	cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
-	defaultCfg := config.NewConfig(l1EthRpc, common.HexToAddress(gameAddressValue), config.TraceTypeAlphabet, true)
+	defaultCfg := config.NewConfig(l1EthRpc, common.HexToAddress(gameFactoryAddressValue), config.TraceTypeAlphabet)
 	// Add in the extra CLI options required when using alphabet trace type
 	defaultCfg.AlphabetTrace = alphabetTrace
 	require.Equal(t, defaultCfg, cfg)
 }
 
 func TestDefaultConfigIsValid(t *testing.T) {
-	cfg := config.NewConfig(l1EthRpc, common.HexToAddress(gameAddressValue), config.TraceTypeAlphabet, true)
+	cfg := config.NewConfig(l1EthRpc, common.HexToAddress(gameFactoryAddressValue), config.TraceTypeAlphabet)
 	// Add in options that are required based on the specific trace type
 	// To avoid needing to specify unused options, these aren't included in the params for NewConfig
 	cfg.AlphabetTrace = alphabetTrace
@@ -89,19 +91,101 @@ func TestTraceType(t *testing.T) {
 	})
 }
 
-func TestGameAddress(t *testing.T) {
+func TestGameFactoryAddress(t *testing.T) {
 	t.Run("Required", func(t *testing.T) {
-		verifyArgsInvalid(t, "flag game-address is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address"))
+		verifyArgsInvalid(t, "flag game-factory-address is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-factory-address"))
 	})
 
 	t.Run("Valid", func(t *testing.T) {
 		addr := common.Address{0xbb, 0xcc, 0xdd}
-		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address", "--game-address="+addr.Hex()))
-		require.Equal(t, addr, cfg.GameAddress)
+		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-factory-address", "--game-factory-address="+addr.Hex()))
+		require.Equal(t, addr, cfg.GameFactoryAddress)
 	})
 
 	t.Run("Invalid", func(t *testing.T) {
-		verifyArgsInvalid(t, "invalid address: foo", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address", "--game-address=foo"))
+		verifyArgsInvalid(t, "invalid address: foo", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-factory-address", "--game-factory-address=foo"))
+	})
+}
+
+func TestGameAllowlist(t *testing.T) {
+	t.Run("Optional", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-allowlist"))
+		require.Empty(t, cfg.GameAllowlist)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		addr1 := common.Address{0xbb, 0xcc, 0xdd}
+		addr2 := common.Address{0xcc, 0xdd, 0xee}
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--game-allowlist="+addr1.Hex()+","+addr2.Hex()))
+		require.Equal(t, []common.Address{addr1, addr2}, cfg.GameAllowlist)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		verifyArgsInvalid(t, "invalid address: foo", addRequiredArgs(config.TraceTypeAlphabet, "--game-allowlist=foo"))
+	})
+}
+
+func TestGameTypes(t *testing.T) {
+	t.Run("Optional", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Empty(t, cfg.GameTypes)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--game-types=0,255"))
+		require.Equal(t, []uint8{0, 255}, cfg.GameTypes)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		verifyArgsInvalid(t, "invalid game type: 256", addRequiredArgs(config.TraceTypeAlphabet, "--game-types=256"))
+	})
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	t.Run("UsesDefault", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Equal(t, config.DefaultMaxConcurrency, cfg.MaxConcurrency)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--max-concurrency=42"))
+		require.Equal(t, uint(42), cfg.MaxConcurrency)
+	})
+}
+
+func TestPollInterval(t *testing.T) {
+	t.Run("UsesDefault", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Equal(t, config.DefaultPollInterval, cfg.PollInterval)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--poll-interval=5s"))
+		require.Equal(t, 5*time.Second, cfg.PollInterval)
+	})
+}
+
+func TestMaxSpendPerGame(t *testing.T) {
+	t.Run("DefaultsToNoLimit", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Zero(t, cfg.MaxSpendPerGame.Sign())
+	})
+
+	t.Run("ConvertsGweiToWei", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--max-spend-per-game=25"))
+		require.Equal(t, big.NewInt(25_000_000_000), cfg.MaxSpendPerGame)
+	})
+}
+
+func TestDatadir(t *testing.T) {
+	t.Run("DefaultsToMemoryOnly", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Equal(t, "", cfg.Datadir)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--datadir=/foo/challenger"))
+		require.Equal(t, "/foo/challenger", cfg.Datadir)
 	})
 }
 
@@ -111,24 +195,39 @@ func TestTxManagerFlagsSupported(t *testing.T) {
 	require.Equal(t, uint64(7), cfg.TxMgrConfig.NumConfirmations)
 }
 
-func TestAgreeWithProposedOutput(t *testing.T) {
-	t.Run("MustBeProvided", func(t *testing.T) {
-		verifyArgsInvalid(t, "flag agree-with-proposed-output is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--agree-with-proposed-output"))
-	})
-	t.Run("Enabled", func(t *testing.T) {
-		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--agree-with-proposed-output"))
-		require.True(t, cfg.AgreeWithProposedOutput)
+func TestRollupRpc(t *testing.T) {
+	t.Run("NotRequiredForAlphabetTrace", func(t *testing.T) {
+		configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--rollup-rpc"))
 	})
-	t.Run("EnabledWithArg", func(t *testing.T) {
-		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--agree-with-proposed-output=true"))
-		require.True(t, cfg.AgreeWithProposedOutput)
+
+	t.Run("RequiredForCannonTrace", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag rollup-rpc is required", addRequiredArgsExcept(config.TraceTypeCannon, "--rollup-rpc"))
 	})
-	t.Run("Disabled", func(t *testing.T) {
-		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--agree-with-proposed-output=false"))
-		require.False(t, cfg.AgreeWithProposedOutput)
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Equal(t, rollupRpc, cfg.RollupRpc)
 	})
 }
 
+func TestMetricsFlagsSupported(t *testing.T) {
+	cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--metrics.enabled", "--metrics.port=9999"))
+	require.True(t, cfg.MetricsConfig.Enabled)
+	require.Equal(t, 9999, cfg.MetricsConfig.ListenPort)
+}
+
+func TestRPCFlagsSupported(t *testing.T) {
+	cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--rpc.enabled", "--rpc.addr=127.0.0.1", "--rpc.port=9998"))
+	require.True(t, cfg.RPCEnabled)
+	require.Equal(t, "127.0.0.1", cfg.RPCConfig.ListenAddr)
+	require.Equal(t, 9998, cfg.RPCConfig.ListenPort)
+}
+
+func TestRPCDisabledByDefault(t *testing.T) {
+	cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+	require.False(t, cfg.RPCEnabled)
+}
+
 func TestCannonBin(t *testing.T) {
 	t.Run("NotRequiredForAlphabetTrace", func(t *testing.T) {
 		configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--cannon-bin"))
@@ -138,6 +237,10 @@ func TestCannonBin(t *testing.T) {
 		verifyArgsInvalid(t, "flag cannon-bin is required", addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-bin"))
 	})
 
+	t.Run("NotRequiredWhenInProcess", func(t *testing.T) {
+		configForArgs(t, addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-bin", "--cannon-in-process"))
+	})
+
 	t.Run("Valid", func(t *testing.T) {
 		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-bin", "--cannon-bin=./cannon"))
 		require.Equal(t, "./cannon", cfg.CannonBin)
@@ -153,12 +256,28 @@ func TestCannonServer(t *testing.T) {
 		verifyArgsInvalid(t, "flag cannon-server is required", addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-server"))
 	})
 
+	t.Run("NotRequiredWhenInProcess", func(t *testing.T) {
+		configForArgs(t, addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-server", "--cannon-in-process"))
+	})
+
 	t.Run("Valid", func(t *testing.T) {
 		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-server", "--cannon-server=./op-program"))
 		require.Equal(t, "./op-program", cfg.CannonServer)
 	})
 }
 
+func TestCannonInProcess(t *testing.T) {
+	t.Run("DefaultsToFalse", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.False(t, cfg.CannonInProcess)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon, "--cannon-in-process"))
+		require.True(t, cfg.CannonInProcess)
+	})
+}
+
 func TestCannonAbsolutePrestate(t *testing.T) {
 	t.Run("NotRequiredForAlphabetTrace", func(t *testing.T) {
 		configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--cannon-prestate"))
@@ -216,6 +335,57 @@ func TestCannonSnapshotFreq(t *testing.T) {
 	})
 }
 
+func TestCannonMaxSnapshots(t *testing.T) {
+	t.Run("UsesDefault", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Equal(t, config.DefaultCannonMaxSnapshots, cfg.CannonMaxSnapshots)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon, "--cannon-max-snapshots=0"))
+		require.Equal(t, uint(0), cfg.CannonMaxSnapshots)
+	})
+}
+
+func TestCannonWorkers(t *testing.T) {
+	t.Run("UsesDefault", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Equal(t, config.DefaultCannonWorkers, cfg.CannonWorkers)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon, "--cannon-workers=8"))
+		require.Equal(t, uint(8), cfg.CannonWorkers)
+	})
+}
+
+func TestCannonProofCacheSize(t *testing.T) {
+	t.Run("UsesDefault", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Equal(t, config.DefaultCannonProofCacheSize, cfg.CannonProofCacheSize)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon, "--cannon-proof-cache-size=25"))
+		require.Equal(t, uint(25), cfg.CannonProofCacheSize)
+	})
+}
+
+func TestOutputSplitDepth(t *testing.T) {
+	t.Run("NotRequiredForCannonTrace", func(t *testing.T) {
+		configForArgs(t, addRequiredArgsExcept(config.TraceTypeCannon, "--output-split-depth"))
+	})
+
+	t.Run("RequiredForOutputCannonTrace", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag output-split-depth is required", addRequiredArgsExcept(config.TraceTypeOutputCannon, "--output-split-depth"))
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeOutputCannon, "--output-split-depth", "--output-split-depth=14"))
+		require.Equal(t, uint64(14), cfg.OutputSplitDepth)
+	})
+}
+
 func TestRequireEitherCannonNetworkOrRollupAndGenesis(t *testing.T) {
 	verifyArgsInvalid(
 		t,
@@ -314,15 +484,15 @@ func addRequiredArgsExcept(traceType config.TraceType, name string, optionalArgs
 
 func requiredArgs(traceType config.TraceType) map[string]string {
 	args := map[string]string{
-		"--agree-with-proposed-output": agreeWithProposedOutput,
-		"--l1-eth-rpc":                 l1EthRpc,
-		"--game-address":               gameAddressValue,
-		"--trace-type":                 traceType.String(),
+		"--l1-eth-rpc":           l1EthRpc,
+		"--game-factory-address": gameFactoryAddressValue,
+		"--trace-type":           traceType.String(),
 	}
 	switch traceType {
 	case config.TraceTypeAlphabet:
 		args["--alphabet"] = alphabetTrace
-	case config.TraceTypeCannon:
+	case config.TraceTypeCannon, config.TraceTypeOutputCannon:
+		args["--rollup-rpc"] = rollupRpc
 		args["--cannon-network"] = cannonNetwork
 		args["--cannon-bin"] = cannonBin
 		args["--cannon-server"] = cannonServer
@@ -330,6 +500,9 @@ func requiredArgs(traceType config.TraceType) map[string]string {
 		args["--cannon-datadir"] = cannonDatadir
 		args["--cannon-l2"] = cannonL2
 	}
+	if traceType == config.TraceTypeOutputCannon {
+		args["--output-split-depth"] = "20"
+	}
 	return args
 }
 
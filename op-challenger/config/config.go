@@ -3,8 +3,12 @@ package config
 import (
 	"errors"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
+	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -18,23 +22,30 @@ var (
 	ErrMissingCannonAbsolutePreState = errors.New("missing cannon absolute pre-state")
 	ErrMissingAlphabetTrace          = errors.New("missing alphabet trace")
 	ErrMissingL1EthRPC               = errors.New("missing l1 eth rpc url")
-	ErrMissingGameAddress            = errors.New("missing game address")
+	ErrMissingRollupRpc              = errors.New("missing rollup rpc url")
+	ErrMissingGameFactoryAddress     = errors.New("missing game factory address")
+	ErrMaxConcurrencyZero            = errors.New("max concurrency must not be 0")
+	ErrMissingPollInterval           = errors.New("missing poll interval")
 	ErrMissingCannonSnapshotFreq     = errors.New("missing cannon snapshot freq")
+	ErrCannonWorkersZero             = errors.New("cannon workers must not be 0")
+	ErrCannonProofCacheSizeZero      = errors.New("cannon proof cache size must not be 0")
 	ErrMissingCannonRollupConfig     = errors.New("missing cannon network or rollup config path")
 	ErrMissingCannonL2Genesis        = errors.New("missing cannon network or l2 genesis path")
 	ErrCannonNetworkAndRollupConfig  = errors.New("only specify one of network or rollup config path")
 	ErrCannonNetworkAndL2Genesis     = errors.New("only specify one of network or l2 genesis path")
 	ErrCannonNetworkUnknown          = errors.New("unknown cannon network")
+	ErrMissingOutputSplitDepth       = errors.New("missing output split depth")
 )
 
 type TraceType string
 
 const (
-	TraceTypeAlphabet TraceType = "alphabet"
-	TraceTypeCannon   TraceType = "cannon"
+	TraceTypeAlphabet     TraceType = "alphabet"
+	TraceTypeCannon       TraceType = "cannon"
+	TraceTypeOutputCannon TraceType = "output_cannon"
 )
 
-var TraceTypes = []TraceType{TraceTypeAlphabet, TraceTypeCannon}
+var TraceTypes = []TraceType{TraceTypeAlphabet, TraceTypeCannon, TraceTypeOutputCannon}
 
 func (t TraceType) String() string {
 	return string(t)
@@ -58,15 +69,28 @@ func ValidTraceType(value TraceType) bool {
 	return false
 }
 
-const DefaultCannonSnapshotFreq = uint(1_000_000_000)
+const (
+	DefaultCannonSnapshotFreq   = uint(1_000_000_000)
+	DefaultCannonMaxSnapshots   = uint(100)
+	DefaultCannonWorkers        = uint(2)
+	DefaultCannonProofCacheSize = uint(1_000)
+	DefaultMaxConcurrency       = uint(4)
+	DefaultPollInterval         = 12 * time.Second
+)
 
 // Config is a well typed config that is parsed from the CLI params.
 // This also contains config options for auxiliary services.
 // It is used to initialize the challenger.
 type Config struct {
-	L1EthRpc                string         // L1 RPC Url
-	GameAddress             common.Address // Address of the fault game
-	AgreeWithProposedOutput bool           // Temporary config if we agree or disagree with the posted output
+	L1EthRpc           string           // L1 RPC Url
+	RollupRpc          string           // L2 Rollup RPC Url, used to determine the correct output root for games
+	GameFactoryAddress common.Address   // Address of the dispute game factory
+	GameAllowlist      []common.Address // Allowlist of fault game addresses to play. Empty allows all games
+	GameTypes          []uint8          // Allowlist of game types to play. Empty allows all game types
+	MaxConcurrency     uint             // Maximum number of games to progress concurrently
+	PollInterval       time.Duration    // Frequency to poll the factory for new games and progress existing ones
+	MaxSpendPerGame    *big.Int         // Maximum wei to spend on moves and steps in a single game. Nil or zero for no limit
+	Datadir            string           // Directory to store challenger state in so it can resume after a restart. Empty to keep state in memory only
 
 	TraceType TraceType // Type of trace
 
@@ -74,8 +98,9 @@ type Config struct {
 	AlphabetTrace string // String for the AlphabetTraceProvider
 
 	// Specific to the cannon trace provider
-	CannonBin              string // Path to the cannon executable to run when generating trace data
-	CannonServer           string // Path to the op-program executable that provides the pre-image oracle server
+	CannonInProcess        bool   // Execute cannon and the pre-image oracle server within the challenger process
+	CannonBin              string // Path to the cannon executable to run when generating trace data. Not required when CannonInProcess is set
+	CannonServer           string // Path to the op-program executable that provides the pre-image oracle server. Not required when CannonInProcess is set
 	CannonAbsolutePreState string // File to load the absolute pre-state for Cannon traces from
 	CannonNetwork          string
 	CannonRollupConfigPath string
@@ -83,27 +108,43 @@ type Config struct {
 	CannonDatadir          string // Cannon Data Directory
 	CannonL2               string // L2 RPC Url
 	CannonSnapshotFreq     uint   // Frequency of snapshots to create when executing cannon (in VM instructions)
+	CannonMaxSnapshots     uint   // Maximum number of snapshots to retain per game. 0 to retain all snapshots
+	CannonWorkers          uint   // Maximum number of concurrent cannon executions per game
+	CannonProofCacheSize   uint   // Number of proofs to keep in memory and on disk per game
 
-	TxMgrConfig txmgr.CLIConfig
+	// Specific to the output cannon trace provider
+	OutputSplitDepth uint64 // Depth of the game at which claims switch from output roots to cannon execution traces
+
+	TxMgrConfig   txmgr.CLIConfig
+	MetricsConfig opmetrics.CLIConfig
+	RPCEnabled    bool // Start the RPC server that reports the state of the games being played
+	RPCConfig     oprpc.CLIConfig
 }
 
 func NewConfig(
 	l1EthRpc string,
-	gameAddress common.Address,
+	gameFactoryAddress common.Address,
 	traceType TraceType,
-	agreeWithProposedOutput bool,
 ) Config {
 	return Config{
-		L1EthRpc:    l1EthRpc,
-		GameAddress: gameAddress,
+		L1EthRpc:           l1EthRpc,
+		GameFactoryAddress: gameFactoryAddress,
+
+		MaxConcurrency: DefaultMaxConcurrency,
+		PollInterval:   DefaultPollInterval,
 
-		AgreeWithProposedOutput: agreeWithProposedOutput,
+		MaxSpendPerGame: new(big.Int),
 
 		TraceType: traceType,
 
-		TxMgrConfig: txmgr.NewCLIConfig(l1EthRpc),
+		TxMgrConfig:   txmgr.NewCLIConfig(l1EthRpc),
+		MetricsConfig: opmetrics.DefaultCLIConfig(),
+		RPCConfig:     oprpc.DefaultCLIConfig(),
 
-		CannonSnapshotFreq: DefaultCannonSnapshotFreq,
+		CannonSnapshotFreq:   DefaultCannonSnapshotFreq,
+		CannonMaxSnapshots:   DefaultCannonMaxSnapshots,
+		CannonWorkers:        DefaultCannonWorkers,
+		CannonProofCacheSize: DefaultCannonProofCacheSize,
 	}
 }
 
@@ -111,17 +152,26 @@ func (c Config) Check() error {
 	if c.L1EthRpc == "" {
 		return ErrMissingL1EthRPC
 	}
-	if c.GameAddress == (common.Address{}) {
-		return ErrMissingGameAddress
+	if c.GameFactoryAddress == (common.Address{}) {
+		return ErrMissingGameFactoryAddress
+	}
+	if c.MaxConcurrency == 0 {
+		return ErrMaxConcurrencyZero
+	}
+	if c.PollInterval == 0 {
+		return ErrMissingPollInterval
 	}
 	if c.TraceType == "" {
 		return ErrMissingTraceType
 	}
-	if c.TraceType == TraceTypeCannon {
-		if c.CannonBin == "" {
+	if c.TraceType == TraceTypeCannon || c.TraceType == TraceTypeOutputCannon {
+		if c.RollupRpc == "" {
+			return ErrMissingRollupRpc
+		}
+		if !c.CannonInProcess && c.CannonBin == "" {
 			return ErrMissingCannonBin
 		}
-		if c.CannonServer == "" {
+		if !c.CannonInProcess && c.CannonServer == "" {
 			return ErrMissingCannonServer
 		}
 		if c.CannonNetwork == "" {
@@ -154,6 +204,15 @@ func (c Config) Check() error {
 		if c.CannonSnapshotFreq == 0 {
 			return ErrMissingCannonSnapshotFreq
 		}
+		if c.CannonWorkers == 0 {
+			return ErrCannonWorkersZero
+		}
+		if c.CannonProofCacheSize == 0 {
+			return ErrCannonProofCacheSizeZero
+		}
+	}
+	if c.TraceType == TraceTypeOutputCannon && c.OutputSplitDepth == 0 {
+		return ErrMissingOutputSplitDepth
 	}
 	if c.TraceType == TraceTypeAlphabet && c.AlphabetTrace == "" {
 		return ErrMissingAlphabetTrace
@@ -161,5 +220,13 @@ func (c Config) Check() error {
 	if err := c.TxMgrConfig.Check(); err != nil {
 		return err
 	}
+	if err := c.MetricsConfig.Check(); err != nil {
+		return err
+	}
+	if c.RPCEnabled {
+		if err := c.RPCConfig.Check(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
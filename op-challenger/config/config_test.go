@@ -10,7 +10,7 @@ import (
 
 var (
 	validL1EthRpc              = "http://localhost:8545"
-	validGameAddress           = common.HexToAddress("0x7bdd3b028C4796eF0EAf07d11394d0d9d8c24139")
+	validGameFactoryAddress    = common.HexToAddress("0x7bdd3b028C4796eF0EAf07d11394d0d9d8c24139")
 	validAlphabetTrace         = "abcdefgh"
 	validCannonBin             = "./bin/cannon"
 	validCannonOpProgramBin    = "./bin/op-program"
@@ -18,15 +18,17 @@ var (
 	validCannonAbsolutPreState = "pre.json"
 	validCannonDatadir         = "/tmp/cannon"
 	validCannonL2              = "http://localhost:9545"
-	agreeWithProposedOutput    = true
+	validRollupRpc             = "http://localhost:8555"
+	validOutputSplitDepth      = uint64(20)
 )
 
 func validConfig(traceType TraceType) Config {
-	cfg := NewConfig(validL1EthRpc, validGameAddress, traceType, agreeWithProposedOutput)
+	cfg := NewConfig(validL1EthRpc, validGameFactoryAddress, traceType)
 	switch traceType {
 	case TraceTypeAlphabet:
 		cfg.AlphabetTrace = validAlphabetTrace
-	case TraceTypeCannon:
+	case TraceTypeCannon, TraceTypeOutputCannon:
+		cfg.RollupRpc = validRollupRpc
 		cfg.CannonBin = validCannonBin
 		cfg.CannonServer = validCannonOpProgramBin
 		cfg.CannonAbsolutePreState = validCannonAbsolutPreState
@@ -34,6 +36,9 @@ func validConfig(traceType TraceType) Config {
 		cfg.CannonL2 = validCannonL2
 		cfg.CannonNetwork = validCannonNetwork
 	}
+	if traceType == TraceTypeOutputCannon {
+		cfg.OutputSplitDepth = validOutputSplitDepth
+	}
 	return cfg
 }
 
@@ -62,10 +67,41 @@ func TestL1EthRpcRequired(t *testing.T) {
 	require.ErrorIs(t, config.Check(), ErrMissingL1EthRPC)
 }
 
-func TestGameAddressRequired(t *testing.T) {
+func TestRollupRpcRequiredForCannon(t *testing.T) {
+	config := validConfig(TraceTypeCannon)
+	config.RollupRpc = ""
+	require.ErrorIs(t, config.Check(), ErrMissingRollupRpc)
+}
+
+func TestRollupRpcNotRequiredForAlphabet(t *testing.T) {
+	config := validConfig(TraceTypeAlphabet)
+	config.RollupRpc = ""
+	require.NoError(t, config.Check())
+}
+
+func TestGameFactoryAddressRequired(t *testing.T) {
 	config := validConfig(TraceTypeCannon)
-	config.GameAddress = common.Address{}
-	require.ErrorIs(t, config.Check(), ErrMissingGameAddress)
+	config.GameFactoryAddress = common.Address{}
+	require.ErrorIs(t, config.Check(), ErrMissingGameFactoryAddress)
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	t.Run("Required", func(t *testing.T) {
+		config := validConfig(TraceTypeAlphabet)
+		config.MaxConcurrency = 0
+		require.ErrorIs(t, config.Check(), ErrMaxConcurrencyZero)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		config := validConfig(TraceTypeAlphabet)
+		require.EqualValues(t, DefaultMaxConcurrency, config.MaxConcurrency)
+	})
+}
+
+func TestPollIntervalRequired(t *testing.T) {
+	config := validConfig(TraceTypeAlphabet)
+	config.PollInterval = 0
+	require.ErrorIs(t, config.Check(), ErrMissingPollInterval)
 }
 
 func TestAlphabetTraceRequired(t *testing.T) {
@@ -86,6 +122,14 @@ func TestCannonServerRequired(t *testing.T) {
 	require.ErrorIs(t, config.Check(), ErrMissingCannonServer)
 }
 
+func TestCannonBinAndServerNotRequiredInProcess(t *testing.T) {
+	config := validConfig(TraceTypeCannon)
+	config.CannonInProcess = true
+	config.CannonBin = ""
+	config.CannonServer = ""
+	require.NoError(t, config.Check())
+}
+
 func TestCannonAbsolutePreStateRequired(t *testing.T) {
 	config := validConfig(TraceTypeCannon)
 	config.CannonAbsolutePreState = ""
@@ -112,6 +156,28 @@ func TestCannonSnapshotFreq(t *testing.T) {
 	})
 }
 
+func TestCannonWorkers(t *testing.T) {
+	t.Run("MustNotBeZero", func(t *testing.T) {
+		cfg := validConfig(TraceTypeCannon)
+		cfg.CannonWorkers = 0
+		require.ErrorIs(t, cfg.Check(), ErrCannonWorkersZero)
+	})
+}
+
+func TestCannonProofCacheSize(t *testing.T) {
+	t.Run("MustNotBeZero", func(t *testing.T) {
+		cfg := validConfig(TraceTypeCannon)
+		cfg.CannonProofCacheSize = 0
+		require.ErrorIs(t, cfg.Check(), ErrCannonProofCacheSizeZero)
+	})
+}
+
+func TestCannonMaxSnapshotsMayBeZero(t *testing.T) {
+	cfg := validConfig(TraceTypeCannon)
+	cfg.CannonMaxSnapshots = 0
+	require.NoError(t, cfg.Check())
+}
+
 func TestCannonNetworkOrRollupConfigRequired(t *testing.T) {
 	cfg := validConfig(TraceTypeCannon)
 	cfg.CannonNetwork = ""
@@ -149,3 +215,19 @@ func TestNetworkMustBeValid(t *testing.T) {
 	cfg.CannonNetwork = "unknown"
 	require.ErrorIs(t, cfg.Check(), ErrCannonNetworkUnknown)
 }
+
+func TestOutputSplitDepthRequiredForOutputCannon(t *testing.T) {
+	cfg := validConfig(TraceTypeOutputCannon)
+	cfg.OutputSplitDepth = 0
+	require.ErrorIs(t, cfg.Check(), ErrMissingOutputSplitDepth)
+}
+
+func TestCannonOptionsRequiredForOutputCannon(t *testing.T) {
+	cfg := validConfig(TraceTypeOutputCannon)
+	cfg.RollupRpc = ""
+	require.ErrorIs(t, cfg.Check(), ErrMissingRollupRpc)
+
+	cfg = validConfig(TraceTypeOutputCannon)
+	cfg.CannonBin = ""
+	require.ErrorIs(t, cfg.Check(), ErrMissingCannonBin)
+}
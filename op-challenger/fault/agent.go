@@ -4,12 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
+	"sort"
+	"sync"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/solver"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
 )
 
+// maxConcurrentSolves is the maximum number of claims the agent solves for concurrently.
+// The trace provider may limit the number of concurrent trace executions further.
+const maxConcurrentSolves = 16
+
 // Responder takes a response action & executes.
 // For full op-challenger this means executing the transaction on chain.
 type Responder interface {
@@ -17,6 +26,21 @@ type Responder interface {
 	Resolve(ctx context.Context) error
 	Respond(ctx context.Context, response types.Claim) error
 	Step(ctx context.Context, stepData types.StepCallData) error
+	// TotalSpent returns the total amount of wei spent on transactions sent by the responder.
+	TotalSpent() *big.Int
+}
+
+// L1HeaderSource is a minimal interface to fetch the latest L1 header.
+// The timestamp of the latest L1 block is used to evaluate claim clocks the same way the contract does.
+type L1HeaderSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+}
+
+// AgentMetricer records the actions performed by the agent.
+type AgentMetricer interface {
+	RecordMove()
+	RecordStep()
+	RecordOracleUpdate()
 }
 
 type Agent struct {
@@ -24,24 +48,51 @@ type Agent struct {
 	loader                  Loader
 	responder               Responder
 	updater                 types.OracleUpdater
+	l1                      L1HeaderSource
 	maxDepth                int
+	gameDuration            uint64
+	maxSpend                *big.Int
 	agreeWithProposedOutput bool
+	metrics                 AgentMetricer
 	log                     log.Logger
+
+	summaryLock sync.Mutex
+	summary     []types.ClaimSummary
 }
 
-func NewAgent(loader Loader, maxDepth int, trace types.TraceProvider, responder Responder, updater types.OracleUpdater, agreeWithProposedOutput bool, log log.Logger) *Agent {
+// NewAgent creates a new [Agent].
+// gameDuration is the total duration of the game in seconds. Each side of the game has half of that time available.
+// maxSpend is the maximum amount of wei to spend on moves and steps in the game. A nil or zero value disables the limit.
+func NewAgent(
+	loader Loader,
+	maxDepth int,
+	gameDuration uint64,
+	trace types.TraceProvider,
+	responder Responder,
+	updater types.OracleUpdater,
+	l1 L1HeaderSource,
+	maxSpend *big.Int,
+	agreeWithProposedOutput bool,
+	m AgentMetricer,
+	log log.Logger,
+) *Agent {
 	return &Agent{
 		solver:                  solver.NewSolver(maxDepth, trace),
 		loader:                  loader,
 		responder:               responder,
 		updater:                 updater,
+		l1:                      l1,
 		maxDepth:                maxDepth,
+		gameDuration:            gameDuration,
+		maxSpend:                maxSpend,
 		agreeWithProposedOutput: agreeWithProposedOutput,
+		metrics:                 m,
 		log:                     log,
 	}
 }
 
 // Act iterates the game & performs all of the next actions.
+// Claims are responded to in order of their clock deadlines so the claims closest to expiring are handled first.
 func (a *Agent) Act(ctx context.Context) error {
 	if a.tryResolve(ctx) {
 		return nil
@@ -50,21 +101,195 @@ func (a *Agent) Act(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("create game from contracts: %w", err)
 	}
+	header, err := a.l1.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("fetch l1 head: %w", err)
+	}
+	now := header.Time
+	claims := a.sortByDeadline(game.Claims())
+	actions := make(map[int]types.Action, len(claims))
+	defer a.recordSummary(game, claims, actions)
+	// Check the spend limit before solving to avoid generating traces for responses that won't be sent
+	if a.spendLimitReached() {
+		a.recordSpendLimit(game, claims, actions)
+		return nil
+	}
+	plans := a.planResponses(ctx, game, claims, now)
 	// Create counter claims
-	for _, claim := range game.Claims() {
-		if err := a.move(ctx, claim, game); err != nil && !errors.Is(err, types.ErrGameDepthReached) {
+	for i, claim := range claims {
+		if a.spendLimitReached() {
+			a.recordSpendLimit(game, claims[i:], actions)
+			return nil
+		}
+		action, err := a.move(ctx, claim.Claim, game, plans[claim.ContractIndex], claim.deadline)
+		if err != nil && !errors.Is(err, types.ErrGameDepthReached) {
 			log.Error("Failed to move", "err", err)
 		}
+		actions[claim.ContractIndex] = action
 	}
 	// Step on all leaf claims
-	for _, claim := range game.Claims() {
-		if err := a.step(ctx, claim, game); err != nil {
+	for i, claim := range claims {
+		if a.spendLimitReached() {
+			for _, remaining := range claims[i:] {
+				if remaining.Depth() == a.maxDepth && !remaining.Countered && !game.AgreeWithClaimLevel(remaining.Claim) {
+					actions[remaining.ContractIndex] = types.ActionSpendLimit
+				}
+			}
+			return nil
+		}
+		action, err := a.step(ctx, claim.Claim, game, plans[claim.ContractIndex])
+		if err != nil {
 			log.Error("Failed to step", "err", err)
 		}
+		if action != types.ActionNone {
+			actions[claim.ContractIndex] = action
+		}
 	}
 	return nil
 }
 
+// plannedResponse is the result of solving for the response to a single claim.
+type plannedResponse struct {
+	// expired is true if the clock for moving against the claim has expired, in which case no move is solved for.
+	expired bool
+	move    *types.Claim
+	moveErr error
+	step    solver.StepData
+	stepErr error
+}
+
+// planResponses solves for the move and, for leaf claims, the step against every claim concurrently.
+// Solving may require generating trace data which can be slow so it is done in parallel up front, allowing the
+// trace provider to execute multiple traces at once. Responses are then sent in deadline order.
+// No move is solved for claims whose clock has already expired at the time now, as it could not be sent.
+func (a *Agent) planResponses(ctx context.Context, game types.Game, claims []timedClaim, now uint64) map[int]*plannedResponse {
+	plans := make(map[int]*plannedResponse, len(claims))
+	for _, claim := range claims {
+		plans[claim.ContractIndex] = &plannedResponse{}
+	}
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentSolves)
+	for _, claim := range claims {
+		deadline := claim.deadline
+		claim := claim.Claim
+		plan := plans[claim.ContractIndex]
+		agree := game.AgreeWithClaimLevel(claim)
+		if !agree && now > deadline {
+			plan.expired = true
+		} else {
+			g.Go(func() error {
+				plan.move, plan.moveErr = a.solver.NextMove(ctx, claim, agree)
+				return nil
+			})
+		}
+		// Steps are not subject to the chess clock
+		if claim.Depth() == a.maxDepth && !agree && !claim.Countered {
+			g.Go(func() error {
+				plan.step, plan.stepErr = a.solver.AttemptStep(ctx, claim, agree)
+				return nil
+			})
+		}
+	}
+	_ = g.Wait()
+	return plans
+}
+
+// Summary returns the claims in the game as of the last time the agent acted.
+func (a *Agent) Summary() []types.ClaimSummary {
+	a.summaryLock.Lock()
+	defer a.summaryLock.Unlock()
+	return a.summary
+}
+
+// recordSummary stores the summary of each claim and the action taken against it so it can be reported.
+func (a *Agent) recordSummary(game types.Game, claims []timedClaim, actions map[int]types.Action) {
+	summary := make([]types.ClaimSummary, 0, len(claims))
+	for _, claim := range claims {
+		action, ok := actions[claim.ContractIndex]
+		if !ok {
+			action = types.ActionNone
+		}
+		summary = append(summary, types.ClaimSummary{
+			ContractIndex:       claim.ContractIndex,
+			ParentContractIndex: claim.ParentContractIndex,
+			Depth:               claim.Depth(),
+			IndexAtDepth:        claim.IndexAtDepth(),
+			TraceIndex:          claim.TraceIndex(a.maxDepth),
+			Value:               claim.Value,
+			Countered:           claim.Countered,
+			Agree:               game.AgreeWithClaimLevel(claim.Claim),
+			Deadline:            claim.deadline,
+			NextAction:          action,
+		})
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].ContractIndex < summary[j].ContractIndex
+	})
+	a.summaryLock.Lock()
+	defer a.summaryLock.Unlock()
+	a.summary = summary
+}
+
+// timedClaim is a claim along with the latest L1 timestamp at which a move can be made against it.
+type timedClaim struct {
+	types.Claim
+	deadline uint64
+}
+
+// sortByDeadline returns the claims ordered by their response deadline, earliest first.
+func (a *Agent) sortByDeadline(claims []types.Claim) []timedClaim {
+	durations := make(map[int]uint64, len(claims))
+	for _, claim := range claims {
+		durations[claim.ContractIndex] = claim.Clock.Duration
+	}
+	timed := make([]timedClaim, 0, len(claims))
+	for _, claim := range claims {
+		timed = append(timed, timedClaim{Claim: claim, deadline: a.responseDeadline(claim, durations)})
+	}
+	sort.SliceStable(timed, func(i, j int) bool {
+		return timed[i].deadline < timed[j].deadline
+	})
+	return timed
+}
+
+// responseDeadline calculates the latest L1 timestamp at which a move against claim is accepted by the contract.
+// The clock of a new claim is the duration of its grandparent's clock plus the time elapsed since its parent
+// was created and may not exceed half of the game duration.
+func (a *Agent) responseDeadline(claim types.Claim, durations map[int]uint64) uint64 {
+	var grandparentDuration uint64
+	if !claim.IsRoot() {
+		grandparentDuration = durations[claim.ParentContractIndex]
+	}
+	maxDuration := a.gameDuration / 2
+	if grandparentDuration > maxDuration {
+		return claim.Clock.Timestamp
+	}
+	return claim.Clock.Timestamp + maxDuration - grandparentDuration
+}
+
+// spendLimitReached returns true if the total spent on the game has reached the configured limit.
+func (a *Agent) spendLimitReached() bool {
+	if a.maxSpend == nil || a.maxSpend.Sign() == 0 {
+		return false
+	}
+	spent := a.responder.TotalSpent()
+	if spent.Cmp(a.maxSpend) < 0 {
+		return false
+	}
+	a.log.Warn("Maximum spend for game reached, not performing further moves", "spent", spent, "max", a.maxSpend)
+	return true
+}
+
+// recordSpendLimit records that no response is sent to any of the claims the agent disagrees with
+// because the spend limit has been reached.
+func (a *Agent) recordSpendLimit(game types.Game, claims []timedClaim, actions map[int]types.Action) {
+	for _, claim := range claims {
+		if !game.AgreeWithClaimLevel(claim.Claim) {
+			actions[claim.ContractIndex] = types.ActionSpendLimit
+		}
+	}
+}
+
 // tryResolve resolves the game if it is in a terminal state
 // and returns true if the game resolves successfully.
 func (a *Agent) tryResolve(ctx context.Context) bool {
@@ -95,56 +320,72 @@ func (a *Agent) newGameFromContracts(ctx context.Context) (types.Game, error) {
 	return game, nil
 }
 
-// move determines & executes the next move given a claim
-func (a *Agent) move(ctx context.Context, claim types.Claim, game types.Game) error {
-	nextMove, err := a.solver.NextMove(ctx, claim, game.AgreeWithClaimLevel(claim))
+// move executes the planned next move given a claim.
+// No move is made if the clock for responding to the claim had already expired when planning.
+func (a *Agent) move(ctx context.Context, claim types.Claim, game types.Game, plan *plannedResponse, deadline uint64) (types.Action, error) {
+	if plan.expired {
+		a.log.Debug("Skipping move as the clock has expired", "deadline", deadline,
+			"parent_value", claim.Value, "parent_trace_index", claim.TraceIndex(a.maxDepth))
+		return types.ActionExpired, nil
+	}
+	nextMove, err := plan.move, plan.moveErr
 	if err != nil {
-		return fmt.Errorf("execute next move: %w", err)
+		return types.ActionNone, fmt.Errorf("execute next move: %w", err)
 	}
 	if nextMove == nil {
 		a.log.Debug("No next move")
-		return nil
+		return types.ActionNone, nil
 	}
 	move := *nextMove
+	action := types.ActionAttack
+	if move.DefendsParent() {
+		action = types.ActionDefend
+	}
 	log := a.log.New("is_defend", move.DefendsParent(), "depth", move.Depth(), "index_at_depth", move.IndexAtDepth(),
 		"value", move.Value, "trace_index", move.TraceIndex(a.maxDepth),
 		"parent_value", claim.Value, "parent_trace_index", claim.TraceIndex(a.maxDepth))
 	if game.IsDuplicate(move) {
 		log.Debug("Skipping duplicate move")
-		return nil
+		return types.ActionNone, nil
 	}
-	log.Info("Performing move")
-	return a.responder.Respond(ctx, move)
+	log.Info("Performing move", "deadline", deadline)
+	if err := a.responder.Respond(ctx, move); err != nil {
+		return action, err
+	}
+	a.metrics.RecordMove()
+	return action, nil
 }
 
-// step determines & executes the next step against a leaf claim through the responder
-func (a *Agent) step(ctx context.Context, claim types.Claim, game types.Game) error {
+// step executes the planned step against a leaf claim through the responder.
+// Steps are not subject to the chess clock and can be performed until the game is resolved.
+func (a *Agent) step(ctx context.Context, claim types.Claim, game types.Game, plan *plannedResponse) (types.Action, error) {
 	if claim.Depth() != a.maxDepth {
-		return nil
+		return types.ActionNone, nil
 	}
 
 	agreeWithClaimLevel := game.AgreeWithClaimLevel(claim)
 	if agreeWithClaimLevel {
 		a.log.Debug("Agree with leaf claim, skipping step", "claim_depth", claim.Depth(), "maxDepth", a.maxDepth)
-		return nil
+		return types.ActionNone, nil
 	}
 
 	if claim.Countered {
 		a.log.Debug("Step already executed against claim", "depth", claim.Depth(), "index_at_depth", claim.IndexAtDepth(), "value", claim.Value)
-		return nil
+		return types.ActionNone, nil
 	}
 
 	a.log.Info("Attempting step", "claim_depth", claim.Depth(), "maxDepth", a.maxDepth)
-	step, err := a.solver.AttemptStep(ctx, claim, agreeWithClaimLevel)
+	step, err := plan.step, plan.stepErr
 	if err != nil {
-		return fmt.Errorf("attempt step: %w", err)
+		return types.ActionStep, fmt.Errorf("attempt step: %w", err)
 	}
 
 	if step.OracleData != nil {
 		a.log.Info("Updating oracle data", "oracleKey", step.OracleData.OracleKey, "oracleData", step.OracleData.OracleData)
 		if err := a.updater.UpdateOracle(ctx, step.OracleData); err != nil {
-			return fmt.Errorf("failed to load oracle data: %w", err)
+			return types.ActionStep, fmt.Errorf("failed to load oracle data: %w", err)
 		}
+		a.metrics.RecordOracleUpdate()
 	}
 
 	a.log.Info("Performing step", "is_attack", step.IsAttack,
@@ -155,5 +396,9 @@ func (a *Agent) step(ctx context.Context, claim types.Claim, game types.Game) er
 		StateData:  step.PreState,
 		Proof:      step.ProofData,
 	}
-	return a.responder.Step(ctx, callData)
+	if err := a.responder.Step(ctx, callData); err != nil {
+		return types.ActionStep, err
+	}
+	a.metrics.RecordStep()
+	return types.ActionStep, nil
 }
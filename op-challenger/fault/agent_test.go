@@ -0,0 +1,273 @@
+package fault
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/solver"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/test"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	agentTestMaxDepth     = 4
+	agentTestGameDuration = 1000
+)
+
+func TestAgent_RespondsInDeadlineOrder(t *testing.T) {
+	agent, claims, responder, l1 := setupAgentTest(t, nil)
+	l1.time = 600
+
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 2)
+	// The claim created at timestamp 200 expires first so must be responded to first
+	require.Equal(t, claims[3].ClaimData, responder.moves[0].Parent)
+	require.Equal(t, claims[2].ClaimData, responder.moves[1].Parent)
+}
+
+func TestAgent_SkipsMovesWithExpiredClock(t *testing.T) {
+	agent, claims, responder, l1 := setupAgentTest(t, nil)
+	// Deadline for responding to the claim created at timestamp 200 is 650
+	l1.time = 700
+
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 1)
+	require.Equal(t, claims[2].ClaimData, responder.moves[0].Parent)
+}
+
+func TestAgent_MovesAtDeadline(t *testing.T) {
+	agent, _, responder, l1 := setupAgentTest(t, nil)
+	l1.time = 650
+
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 2)
+}
+
+func TestAgent_StopsWhenSpendLimitReached(t *testing.T) {
+	agent, _, responder, l1 := setupAgentTest(t, big.NewInt(100))
+	l1.time = 600
+
+	responder.costPerTx = big.NewInt(60)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 2, "should not stop until the limit is reached")
+
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 2, "should not perform any further moves")
+}
+
+func TestAgent_DoesNotSolveWhenSpendLimitReached(t *testing.T) {
+	agent, claims, responder, l1 := setupAgentTest(t, big.NewInt(100))
+	l1.time = 600
+	trace := &countingTraceProvider{TraceProvider: test.NewAlphabetClaimBuilder(t, agentTestMaxDepth).CorrectTraceProvider()}
+	agent.solver = solver.NewSolver(agentTestMaxDepth, trace)
+	responder.spent.SetUint64(100)
+
+	require.NoError(t, agent.Act(context.Background()))
+	require.Empty(t, responder.moves)
+	require.Zero(t, trace.calls.Load(), "should not generate trace data")
+	summary := agent.Summary()
+	require.Len(t, summary, len(claims))
+	require.Equal(t, types.ActionSpendLimit, summary[2].NextAction)
+	require.Equal(t, types.ActionSpendLimit, summary[3].NextAction)
+}
+
+func TestAgent_DoesNotSolveExpiredClaims(t *testing.T) {
+	agent, _, responder, l1 := setupAgentTest(t, nil)
+	l1.time = 1000
+	trace := &countingTraceProvider{TraceProvider: test.NewAlphabetClaimBuilder(t, agentTestMaxDepth).CorrectTraceProvider()}
+	agent.solver = solver.NewSolver(agentTestMaxDepth, trace)
+
+	require.NoError(t, agent.Act(context.Background()))
+	require.Empty(t, responder.moves)
+	require.Zero(t, trace.calls.Load(), "should not generate trace data")
+	for _, claim := range agent.Summary() {
+		if !claim.Agree {
+			require.Equal(t, types.ActionExpired, claim.NextAction)
+		}
+	}
+}
+
+func TestAgent_Summary(t *testing.T) {
+	agent, claims, _, l1 := setupAgentTest(t, nil)
+	require.Empty(t, agent.Summary(), "should be empty before acting")
+	// Only the claim created at timestamp 300 can still be countered
+	l1.time = 700
+
+	require.NoError(t, agent.Act(context.Background()))
+	summary := agent.Summary()
+	require.Len(t, summary, len(claims))
+	for i, claim := range summary {
+		require.Equal(t, claims[i].ContractIndex, claim.ContractIndex, "should order by contract index")
+		require.Equal(t, claims[i].Value, claim.Value)
+		require.Equal(t, claims[i].Depth(), claim.Depth)
+	}
+	require.False(t, summary[0].Agree)
+	require.Equal(t, types.ActionExpired, summary[0].NextAction, "already countered and clock expired")
+	require.True(t, summary[1].Agree)
+	require.Equal(t, types.ActionNone, summary[1].NextAction)
+	require.False(t, summary[2].Agree)
+	require.Equal(t, uint64(750), summary[2].Deadline)
+	require.Contains(t, []types.Action{types.ActionAttack, types.ActionDefend}, summary[2].NextAction)
+	require.False(t, summary[3].Agree)
+	require.Equal(t, uint64(650), summary[3].Deadline)
+	require.Equal(t, types.ActionExpired, summary[3].NextAction)
+}
+
+func TestAgent_SummaryRecordsSpendLimit(t *testing.T) {
+	agent, _, responder, l1 := setupAgentTest(t, big.NewInt(50))
+	l1.time = 600
+	responder.costPerTx = big.NewInt(60)
+
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 1)
+	summary := agent.Summary()
+	require.Equal(t, types.ActionSpendLimit, summary[2].NextAction)
+	require.Contains(t, []types.Action{types.ActionAttack, types.ActionDefend}, summary[3].NextAction)
+}
+
+func TestAgent_ResponseDeadline(t *testing.T) {
+	agent, _, _, _ := setupAgentTest(t, nil)
+	durations := map[int]uint64{0: 0, 1: 100, 2: 600}
+	root := types.Claim{
+		ClaimData:           types.ClaimData{Position: types.NewPosition(0, 0)},
+		Clock:               types.Clock{Timestamp: 10},
+		ParentContractIndex: 1,
+	}
+	require.Equal(t, uint64(510), agent.responseDeadline(root, durations), "root claim has no grandparent")
+
+	child := types.Claim{
+		ClaimData:           types.ClaimData{Position: types.NewPosition(1, 0)},
+		Clock:               types.Clock{Duration: 20, Timestamp: 50},
+		ContractIndex:       3,
+		ParentContractIndex: 1,
+	}
+	require.Equal(t, uint64(450), agent.responseDeadline(child, durations))
+
+	child.ParentContractIndex = 2
+	require.Equal(t, uint64(50), agent.responseDeadline(child, durations), "should not underflow")
+}
+
+// setupAgentTest creates an agent for a game where the honest actor disagrees with the root claim.
+// The game has two incorrect claims at depth 2 that need to be countered, created at timestamps 300 and 200.
+// With a game duration of 1000, they must be countered by 750 and 650 respectively.
+func setupAgentTest(t *testing.T, maxSpend *big.Int) (*Agent, []types.Claim, *stubResponder, *stubL1HeaderSource) {
+	logger := testlog.Logger(t, log.LvlTrace)
+	builder := test.NewAlphabetClaimBuilder(t, agentTestMaxDepth)
+
+	root := builder.CreateRootClaim(false)
+	root.ContractIndex = 0
+	root.ParentContractIndex = math.MaxUint32
+	root.Clock = types.Clock{Timestamp: 100}
+
+	honest := builder.AttackClaim(root, true)
+	honest.ContractIndex = 1
+	honest.ParentContractIndex = 0
+	honest.Clock = types.Clock{Duration: 50, Timestamp: 150}
+
+	dishonestA := builder.AttackClaim(honest, false)
+	dishonestA.ContractIndex = 2
+	dishonestA.ParentContractIndex = 1
+	dishonestA.Clock = types.Clock{Duration: 150, Timestamp: 300}
+
+	dishonestB := builder.DefendClaim(honest, false)
+	dishonestB.ContractIndex = 3
+	dishonestB.ParentContractIndex = 1
+	dishonestB.Clock = types.Clock{Duration: 50, Timestamp: 200}
+
+	claims := []types.Claim{root, honest, dishonestA, dishonestB}
+	loader := &stubClaimLoader{claims: claims}
+	responder := &stubResponder{}
+	l1 := &stubL1HeaderSource{}
+	agent := NewAgent(loader, agentTestMaxDepth, agentTestGameDuration, builder.CorrectTraceProvider(), responder, &stubUpdater{}, l1, maxSpend, true, metrics.NoopMetrics, logger)
+	return agent, claims, responder, l1
+}
+
+type stubClaimLoader struct {
+	claims []types.Claim
+}
+
+func (s *stubClaimLoader) FetchClaims(_ context.Context) ([]types.Claim, error) {
+	return s.claims, nil
+}
+
+func (s *stubClaimLoader) FetchGameDepth(_ context.Context) (uint64, error) {
+	return agentTestMaxDepth, nil
+}
+
+func (s *stubClaimLoader) FetchGameDuration(_ context.Context) (uint64, error) {
+	return agentTestGameDuration, nil
+}
+
+func (s *stubClaimLoader) FetchAbsolutePrestateHash(_ context.Context) ([]byte, error) {
+	panic("not implemented")
+}
+
+type stubResponder struct {
+	moves     []types.Claim
+	steps     []types.StepCallData
+	costPerTx *big.Int
+	spent     big.Int
+}
+
+func (s *stubResponder) CanResolve(_ context.Context) bool {
+	return false
+}
+
+func (s *stubResponder) Resolve(_ context.Context) error {
+	panic("not implemented")
+}
+
+func (s *stubResponder) Respond(_ context.Context, response types.Claim) error {
+	s.moves = append(s.moves, response)
+	s.recordSpend()
+	return nil
+}
+
+func (s *stubResponder) Step(_ context.Context, stepData types.StepCallData) error {
+	s.steps = append(s.steps, stepData)
+	s.recordSpend()
+	return nil
+}
+
+func (s *stubResponder) recordSpend() {
+	if s.costPerTx != nil {
+		s.spent.Add(&s.spent, s.costPerTx)
+	}
+}
+
+func (s *stubResponder) TotalSpent() *big.Int {
+	return new(big.Int).Set(&s.spent)
+}
+
+type countingTraceProvider struct {
+	types.TraceProvider
+	calls atomic.Int64
+}
+
+func (c *countingTraceProvider) Get(ctx context.Context, i uint64) (common.Hash, error) {
+	c.calls.Add(1)
+	return c.TraceProvider.Get(ctx, i)
+}
+
+type stubUpdater struct{}
+
+func (s *stubUpdater) UpdateOracle(_ context.Context, _ *types.PreimageOracleData) error {
+	return nil
+}
+
+type stubL1HeaderSource struct {
+	time uint64
+}
+
+func (s *stubL1HeaderSource) HeaderByNumber(_ context.Context, _ *big.Int) (*ethtypes.Header, error) {
+	return &ethtypes.Header{Time: s.time}, nil
+}
@@ -1,23 +1,22 @@
 package cannon
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
 )
 
+// parseState reads the VM state at path in either the binary or JSON state format.
 func parseState(path string) (*mipsevm.State, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open state file (%v): %w", path, err)
 	}
 	defer file.Close()
-	var state mipsevm.State
-	err = json.NewDecoder(file).Decode(&state)
+	state, err := mipsevm.ReadState(file)
 	if err != nil {
 		return nil, fmt.Errorf("invalid mipsevm state (%v): %w", path, err)
 	}
-	return &state, nil
+	return state, nil
 }
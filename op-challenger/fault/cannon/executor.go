@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
@@ -20,19 +21,28 @@ import (
 const (
 	snapsDir     = "snapshots"
 	preimagesDir = "preimages"
+	runsDir      = "runs"
 	finalState   = "final.json"
 )
 
-var snapshotNameRegexp = regexp.MustCompile(`^[0-9]+\.json$`)
+// snapshotNameRegexp matches snapshot file names. Snapshots are written in the binary state format but snapshots in
+// JSON format, written by earlier versions, are still used.
+var snapshotNameRegexp = regexp.MustCompile(`^[0-9]+\.(json|bin)$`)
 
 type snapshotSelect func(logger log.Logger, dir string, absolutePreState string, i uint64) (string, error)
 type cmdExecutor func(ctx context.Context, l log.Logger, binary string, args ...string) error
 
+// Metricer records metrics about cannon execution.
+type Metricer interface {
+	RecordCannonExecutionTime(t float64)
+}
+
 type Executor struct {
 	logger           log.Logger
+	metrics          Metricer
 	l1               string
 	l2               string
-	inputs           localGameInputs
+	inputs           LocalGameInputs
 	cannon           string
 	server           string
 	network          string
@@ -41,13 +51,14 @@ type Executor struct {
 	absolutePreState string
 	dataDir          string
 	snapshotFreq     uint
-	selectSnapshot   snapshotSelect
 	cmdExecutor      cmdExecutor
+	*snapshotManager
 }
 
-func NewExecutor(logger log.Logger, cfg *config.Config, inputs localGameInputs) *Executor {
+func NewExecutor(logger log.Logger, m Metricer, cfg *config.Config, inputs LocalGameInputs) *Executor {
 	return &Executor{
 		logger:           logger,
+		metrics:          m,
 		l1:               cfg.L1EthRpc,
 		l2:               cfg.CannonL2,
 		inputs:           inputs,
@@ -59,29 +70,37 @@ func NewExecutor(logger log.Logger, cfg *config.Config, inputs localGameInputs)
 		absolutePreState: cfg.CannonAbsolutePreState,
 		dataDir:          cfg.CannonDatadir,
 		snapshotFreq:     cfg.CannonSnapshotFreq,
-		selectSnapshot:   findStartingSnapshot,
 		cmdExecutor:      runCmd,
+		snapshotManager:  newSnapshotManager(logger, cfg.CannonAbsolutePreState, cfg.CannonMaxSnapshots),
 	}
 }
 
+// GenerateProof executes cannon to generate the proof at trace index i in dir.
+// Cannon writes its output to a run directory specific to i so proofs for different indices can be generated
+// concurrently. Generated proofs and snapshots are moved into dir once execution completes and the final state is
+// only kept if the program exited.
 func (e *Executor) GenerateProof(ctx context.Context, dir string, i uint64) error {
 	snapshotDir := filepath.Join(dir, snapsDir)
-	start, err := e.selectSnapshot(e.logger, snapshotDir, e.absolutePreState, i)
+	start, err := e.acquireSnapshot(snapshotDir, i)
 	if err != nil {
 		return fmt.Errorf("find starting snapshot: %w", err)
 	}
+	defer e.releaseSnapshot(start)
 	proofDir := filepath.Join(dir, proofsDir)
 	dataDir := filepath.Join(e.dataDir, preimagesDir)
-	lastGeneratedState := filepath.Join(dir, finalState)
+	runDir := filepath.Join(dir, runsDir, strconv.FormatUint(i, 10))
+	runProofDir := filepath.Join(runDir, proofsDir)
+	runSnapshotDir := filepath.Join(runDir, snapsDir)
+	lastGeneratedState := filepath.Join(runDir, finalState)
 	args := []string{
 		"run",
 		"--input", start,
 		"--output", lastGeneratedState,
 		"--meta", "",
 		"--proof-at", "=" + strconv.FormatUint(i, 10),
-		"--proof-fmt", filepath.Join(proofDir, "%d.json"),
+		"--proof-fmt", filepath.Join(runProofDir, "%d.json"),
 		"--snapshot-at", "%" + strconv.FormatUint(uint64(e.snapshotFreq), 10),
-		"--snapshot-fmt", filepath.Join(snapshotDir, "%d.json"),
+		"--snapshot-fmt", filepath.Join(runSnapshotDir, "%d.bin"),
 	}
 	if i < math.MaxUint64 {
 		args = append(args, "--stop-at", "="+strconv.FormatUint(i+1, 10))
@@ -92,11 +111,11 @@ func (e *Executor) GenerateProof(ctx context.Context, dir string, i uint64) erro
 		"--l1", e.l1,
 		"--l2", e.l2,
 		"--datadir", dataDir,
-		"--l1.head", e.inputs.l1Head.Hex(),
-		"--l2.head", e.inputs.l2Head.Hex(),
-		"--l2.outputroot", e.inputs.l2OutputRoot.Hex(),
-		"--l2.claim", e.inputs.l2Claim.Hex(),
-		"--l2.blocknumber", e.inputs.l2BlockNumber.Text(10),
+		"--l1.head", e.inputs.L1Head.Hex(),
+		"--l2.head", e.inputs.L2Head.Hex(),
+		"--l2.outputroot", e.inputs.L2OutputRoot.Hex(),
+		"--l2.claim", e.inputs.L2Claim.Hex(),
+		"--l2.blocknumber", e.inputs.L2BlockNumber.Text(10),
 	)
 	if e.network != "" {
 		args = append(args, "--network", e.network)
@@ -108,17 +127,68 @@ func (e *Executor) GenerateProof(ctx context.Context, dir string, i uint64) erro
 		args = append(args, "--l2.genesis", e.l2Genesis)
 	}
 
-	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
-		return fmt.Errorf("could not create snapshot directory %v: %w", snapshotDir, err)
+	for _, d := range []string{snapshotDir, proofDir, runSnapshotDir, runProofDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return fmt.Errorf("could not create directory %v: %w", d, err)
+		}
 	}
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return fmt.Errorf("could not create preimage cache directory %v: %w", dataDir, err)
 	}
-	if err := os.MkdirAll(proofDir, 0755); err != nil {
-		return fmt.Errorf("could not create proofs directory %v: %w", proofDir, err)
-	}
+	defer func() {
+		if err := os.RemoveAll(runDir); err != nil {
+			e.logger.Warn("Failed to remove cannon run directory", "dir", runDir, "err", err)
+		}
+	}()
 	e.logger.Info("Generating trace", "proof", i, "cmd", e.cannon, "args", strings.Join(args, ", "))
-	return e.cmdExecutor(ctx, e.logger.New("proof", i), e.cannon, args...)
+	execStart := time.Now()
+	err = e.cmdExecutor(ctx, e.logger.New("proof", i), e.cannon, args...)
+	e.metrics.RecordCannonExecutionTime(time.Since(execStart).Seconds())
+	if err != nil {
+		return err
+	}
+	if err := moveFiles(runProofDir, proofDir); err != nil {
+		return fmt.Errorf("failed to store generated proofs: %w", err)
+	}
+	if err := moveFiles(runSnapshotDir, snapshotDir); err != nil {
+		return fmt.Errorf("failed to store generated snapshots: %w", err)
+	}
+	if err := keepExitedState(lastGeneratedState, filepath.Join(dir, finalState)); err != nil {
+		return fmt.Errorf("failed to store final state: %w", err)
+	}
+	e.pruneSnapshots(snapshotDir)
+	return nil
+}
+
+// moveFiles moves every file in src to dst. Each file is renamed so it appears in dst atomically.
+func moveFiles(src string, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := os.Rename(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keepExitedState moves the state at src to dst if the program exited. Otherwise src is left to be deleted.
+func keepExitedState(src string, dst string) error {
+	state, err := parseState(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !state.Exited {
+		return nil
+	}
+	return os.Rename(src, dst)
 }
 
 func runCmd(ctx context.Context, l log.Logger, binary string, args ...string) error {
@@ -145,6 +215,7 @@ func findStartingSnapshot(logger log.Logger, snapDir string, absolutePreState st
 		return "", fmt.Errorf("list snapshots in %v: %w", snapDir, err)
 	}
 	bestSnap := uint64(0)
+	bestName := ""
 	for _, entry := range entries {
 		if entry.IsDir() {
 			logger.Warn("Unexpected directory in snapshots dir", "parent", snapDir, "child", entry.Name())
@@ -155,19 +226,20 @@ func findStartingSnapshot(logger log.Logger, snapDir string, absolutePreState st
 			logger.Warn("Unexpected file in snapshots dir", "parent", snapDir, "child", entry.Name())
 			continue
 		}
-		index, err := strconv.ParseUint(name[0:len(name)-len(".json")], 10, 64)
+		index, err := strconv.ParseUint(strings.TrimSuffix(name, filepath.Ext(name)), 10, 64)
 		if err != nil {
 			logger.Error("Unable to parse trace index of snapshot file", "parent", snapDir, "child", entry.Name())
 			continue
 		}
 		if index > bestSnap && index < traceIndex {
 			bestSnap = index
+			bestName = name
 		}
 	}
 	if bestSnap == 0 {
 		return absolutePreState, nil
 	}
-	startFrom := fmt.Sprintf("%v/%v.json", snapDir, bestSnap)
+	startFrom := fmt.Sprintf("%v/%v", snapDir, bestName)
 
 	return startFrom, nil
 }
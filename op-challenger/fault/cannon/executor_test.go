@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -21,7 +22,7 @@ const execTestCannonPrestate = "/foo/pre.json"
 
 func TestGenerateProof(t *testing.T) {
 	input := "starting.json"
-	cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeCannon, true)
+	cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeCannon)
 	cfg.CannonDatadir = t.TempDir()
 	cfg.CannonAbsolutePreState = "pre.json"
 	cfg.CannonBin = "./bin/cannon"
@@ -29,15 +30,15 @@ func TestGenerateProof(t *testing.T) {
 	cfg.CannonL2 = "http://localhost:9999"
 	cfg.CannonSnapshotFreq = 500
 
-	inputs := localGameInputs{
-		l1Head:        common.Hash{0x11},
-		l2Head:        common.Hash{0x22},
-		l2OutputRoot:  common.Hash{0x33},
-		l2Claim:       common.Hash{0x44},
-		l2BlockNumber: big.NewInt(3333),
+	inputs := LocalGameInputs{
+		L1Head:        common.Hash{0x11},
+		L2Head:        common.Hash{0x22},
+		L2OutputRoot:  common.Hash{0x33},
+		L2Claim:       common.Hash{0x44},
+		L2BlockNumber: big.NewInt(3333),
 	}
 	captureExec := func(t *testing.T, cfg config.Config, proofAt uint64) (string, string, map[string]string) {
-		executor := NewExecutor(testlog.Logger(t, log.LvlInfo), &cfg, inputs)
+		executor := NewExecutor(testlog.Logger(t, log.LvlInfo), metrics.NoopMetrics, &cfg, inputs)
 		executor.selectSnapshot = func(logger log.Logger, dir string, absolutePreState string, i uint64) (string, error) {
 			return input, nil
 		}
@@ -76,7 +77,8 @@ func TestGenerateProof(t *testing.T) {
 		require.Equal(t, input, args["--input"])
 		require.Contains(t, args, "--meta")
 		require.Equal(t, "", args["--meta"])
-		require.Equal(t, filepath.Join(cfg.CannonDatadir, finalState), args["--output"])
+		runDir := filepath.Join(cfg.CannonDatadir, runsDir, "150000000")
+		require.Equal(t, filepath.Join(runDir, finalState), args["--output"])
 		require.Equal(t, "=150000000", args["--proof-at"])
 		require.Equal(t, "=150000001", args["--stop-at"])
 		require.Equal(t, "%500", args["--snapshot-at"])
@@ -87,17 +89,18 @@ func TestGenerateProof(t *testing.T) {
 		require.Equal(t, cfg.L1EthRpc, args["--l1"])
 		require.Equal(t, cfg.CannonL2, args["--l2"])
 		require.Equal(t, filepath.Join(cfg.CannonDatadir, preimagesDir), args["--datadir"])
-		require.Equal(t, filepath.Join(cfg.CannonDatadir, proofsDir, "%d.json"), args["--proof-fmt"])
-		require.Equal(t, filepath.Join(cfg.CannonDatadir, snapsDir, "%d.json"), args["--snapshot-fmt"])
+		require.Equal(t, filepath.Join(runDir, proofsDir, "%d.json"), args["--proof-fmt"])
+		require.Equal(t, filepath.Join(runDir, snapsDir, "%d.bin"), args["--snapshot-fmt"])
+		require.NoDirExists(t, runDir, "should remove run directory")
 		require.Equal(t, cfg.CannonNetwork, args["--network"])
 		require.NotContains(t, args, "--rollup.config")
 		require.NotContains(t, args, "--l2.genesis")
 
 		// Local game inputs
-		require.Equal(t, inputs.l1Head.Hex(), args["--l1.head"])
-		require.Equal(t, inputs.l2Head.Hex(), args["--l2.head"])
-		require.Equal(t, inputs.l2OutputRoot.Hex(), args["--l2.outputroot"])
-		require.Equal(t, inputs.l2Claim.Hex(), args["--l2.claim"])
+		require.Equal(t, inputs.L1Head.Hex(), args["--l1.head"])
+		require.Equal(t, inputs.L2Head.Hex(), args["--l2.head"])
+		require.Equal(t, inputs.L2OutputRoot.Hex(), args["--l2.outputroot"])
+		require.Equal(t, inputs.L2Claim.Hex(), args["--l2.claim"])
 		require.Equal(t, "3333", args["--l2.blocknumber"])
 	})
 
@@ -122,6 +125,112 @@ func TestGenerateProof(t *testing.T) {
 	})
 }
 
+func TestGenerateProofStoresOutputs(t *testing.T) {
+	setup := func(t *testing.T, exited bool) (string, *Executor) {
+		dir := t.TempDir()
+		cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeCannon)
+		cfg.CannonDatadir = t.TempDir()
+		executor := NewExecutor(testlog.Logger(t, log.LvlInfo), metrics.NoopMetrics, &cfg, LocalGameInputs{L2BlockNumber: big.NewInt(1)})
+		executor.selectSnapshot = func(logger log.Logger, dir string, absolutePreState string, i uint64) (string, error) {
+			return absolutePreState, nil
+		}
+		executor.cmdExecutor = func(ctx context.Context, l log.Logger, b string, a ...string) error {
+			args := make(map[string]string)
+			for i := 1; i+1 < len(a) && a[i] != "--"; i += 2 {
+				args[a[i]] = a[i+1]
+			}
+			if err := os.WriteFile(fmt.Sprintf(args["--proof-fmt"], 150), []byte("proof"), 0644); err != nil {
+				return err
+			}
+			if err := os.WriteFile(fmt.Sprintf(args["--snapshot-fmt"], 100), []byte("snapshot"), 0644); err != nil {
+				return err
+			}
+			return os.WriteFile(args["--output"], []byte(fmt.Sprintf(`{"exited": %v}`, exited)), 0644)
+		}
+		return dir, executor
+	}
+
+	t.Run("MovesProofsAndSnapshots", func(t *testing.T) {
+		dir, executor := setup(t, false)
+		require.NoError(t, executor.GenerateProof(context.Background(), dir, 150))
+		require.FileExists(t, filepath.Join(dir, proofsDir, "150.json"))
+		require.FileExists(t, filepath.Join(dir, snapsDir, "100.bin"))
+		require.NoDirExists(t, filepath.Join(dir, runsDir, "150"))
+	})
+
+	t.Run("DiscardFinalStateWhenNotExited", func(t *testing.T) {
+		dir, executor := setup(t, false)
+		require.NoError(t, executor.GenerateProof(context.Background(), dir, 150))
+		require.NoFileExists(t, filepath.Join(dir, finalState))
+	})
+
+	t.Run("KeepFinalStateWhenExited", func(t *testing.T) {
+		dir, executor := setup(t, true)
+		require.NoError(t, executor.GenerateProof(context.Background(), dir, 150))
+		require.FileExists(t, filepath.Join(dir, finalState))
+	})
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	setup := func(t *testing.T, maxSnapshots uint, count int) (string, *Executor) {
+		dir := t.TempDir()
+		cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeCannon)
+		cfg.CannonMaxSnapshots = maxSnapshots
+		executor := NewExecutor(testlog.Logger(t, log.LvlInfo), metrics.NoopMetrics, &cfg, LocalGameInputs{})
+		base := time.Now().Add(-time.Hour)
+		for i := 0; i < count; i++ {
+			path := filepath.Join(dir, fmt.Sprintf("%d.json", i*100))
+			require.NoError(t, os.WriteFile(path, []byte{}, 0644))
+			modTime := base.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, os.Chtimes(path, modTime, modTime))
+		}
+		return dir, executor
+	}
+
+	t.Run("DeleteLeastRecentlyUsed", func(t *testing.T) {
+		dir, executor := setup(t, 2, 4)
+		executor.pruneSnapshots(dir)
+		require.NoFileExists(t, filepath.Join(dir, "0.json"))
+		require.NoFileExists(t, filepath.Join(dir, "100.json"))
+		require.FileExists(t, filepath.Join(dir, "200.json"))
+		require.FileExists(t, filepath.Join(dir, "300.json"))
+	})
+
+	t.Run("KeepSnapshotsInUse", func(t *testing.T) {
+		dir, executor := setup(t, 2, 4)
+		inUse := filepath.Join(dir, "0.json")
+		executor.snapshotsInUse[inUse] = 1
+		executor.pruneSnapshots(dir)
+		require.FileExists(t, inUse)
+		require.NoFileExists(t, filepath.Join(dir, "100.json"))
+		require.NoFileExists(t, filepath.Join(dir, "200.json"))
+		require.FileExists(t, filepath.Join(dir, "300.json"))
+	})
+
+	t.Run("AcquiredSnapshotIsMostRecentlyUsed", func(t *testing.T) {
+		dir, executor := setup(t, 2, 4)
+		executor.selectSnapshot = func(logger log.Logger, dir string, absolutePreState string, i uint64) (string, error) {
+			return filepath.Join(dir, "0.json"), nil
+		}
+		snapshot, err := executor.acquireSnapshot(dir, 50)
+		require.NoError(t, err)
+		executor.releaseSnapshot(snapshot)
+		executor.pruneSnapshots(dir)
+		require.FileExists(t, filepath.Join(dir, "0.json"))
+		require.NoFileExists(t, filepath.Join(dir, "100.json"))
+		require.NoFileExists(t, filepath.Join(dir, "200.json"))
+		require.FileExists(t, filepath.Join(dir, "300.json"))
+	})
+
+	t.Run("DisabledWhenZero", func(t *testing.T) {
+		dir, executor := setup(t, 0, 4)
+		executor.pruneSnapshots(dir)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+	})
+}
+
 func TestRunCmdLogsOutput(t *testing.T) {
 	bin := "/bin/echo"
 	if _, err := os.Stat(bin); err != nil {
@@ -192,6 +301,17 @@ func TestFindStartingSnapshot(t *testing.T) {
 		require.Equal(t, filepath.Join(dir, "250.json"), snapshot)
 	})
 
+	t.Run("UseBinarySnapshots", func(t *testing.T) {
+		dir := withSnapshots(t, "100.json", "200.bin")
+		snapshot, err := findStartingSnapshot(logger, dir, execTestCannonPrestate, 250)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "200.bin"), snapshot)
+
+		snapshot, err = findStartingSnapshot(logger, dir, execTestCannonPrestate, 150)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "100.json"), snapshot)
+	})
+
 	t.Run("IgnoreDirectories", func(t *testing.T) {
 		dir := withSnapshots(t, "100.json")
 		require.NoError(t, os.Mkdir(filepath.Join(dir, "120.json"), 0o777))
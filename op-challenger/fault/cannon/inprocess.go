@@ -0,0 +1,337 @@
+package cannon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/chainconfig"
+	"github.com/ethereum-optimism/optimism/op-program/host"
+	hostconfig "github.com/ethereum-optimism/optimism/op-program/host/config"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// PreimageSourceCreator creates a source of pre-images used by the VM.
+// The returned close function is called once the source is no longer required.
+type PreimageSourceCreator func(ctx context.Context, logger log.Logger) (preimage.PreimageGetter, preimage.HintHandler, func(), error)
+
+// preimageSource is a pre-image source created by a [PreimageSourceCreator].
+type preimageSource struct {
+	getPreimage preimage.PreimageGetter
+	hint        preimage.HintHandler
+	close       func()
+}
+
+// InProcessExecutor generates proofs by running op-program in the cannon MIPS VM within the challenger process.
+// Pre-images are served directly from op-program's host key-value store and prefetcher so no separate cannon or
+// op-program executables are required and proofs are returned without being written to disk.
+//
+// Pre-image sources hold connections to the L1 and L2 nodes so are reused across executions rather than created
+// for each proof. A source is not safe for concurrent use, so each concurrent execution uses its own source and
+// at most one source per concurrent execution is created. Sources are released by [InProcessExecutor.Close].
+type InProcessExecutor struct {
+	logger       log.Logger
+	metrics      Metricer
+	snapshotFreq uint
+	createSource PreimageSourceCreator
+	*snapshotManager
+
+	// sourceCtx is used to create pre-image sources, which outlive the execution they were created for.
+	sourceCtx     context.Context
+	cancelSources context.CancelFunc
+	// sourceLock guards idleSources and closed.
+	sourceLock  sync.Mutex
+	idleSources []*preimageSource
+	closed      bool
+}
+
+// NewInProcessExecutor creates an [InProcessExecutor] for the game with the specified local inputs.
+func NewInProcessExecutor(logger log.Logger, m Metricer, cfg *config.Config, inputs LocalGameInputs) *InProcessExecutor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &InProcessExecutor{
+		logger:          logger,
+		metrics:         m,
+		snapshotFreq:    cfg.CannonSnapshotFreq,
+		createSource:    hostPreimageSource(cfg, inputs),
+		snapshotManager: newSnapshotManager(logger, cfg.CannonAbsolutePreState, cfg.CannonMaxSnapshots),
+		sourceCtx:       ctx,
+		cancelSources:   cancel,
+	}
+}
+
+// Close closes the idle pre-image sources and any source still in use once its execution completes.
+func (e *InProcessExecutor) Close() error {
+	e.sourceLock.Lock()
+	defer e.sourceLock.Unlock()
+	e.closed = true
+	for _, source := range e.idleSources {
+		source.close()
+	}
+	e.idleSources = nil
+	e.cancelSources()
+	return nil
+}
+
+// acquireSource returns an idle pre-image source, creating a new one if none are available.
+func (e *InProcessExecutor) acquireSource() (*preimageSource, error) {
+	e.sourceLock.Lock()
+	if e.closed {
+		e.sourceLock.Unlock()
+		return nil, errors.New("executor closed")
+	}
+	if n := len(e.idleSources); n > 0 {
+		source := e.idleSources[n-1]
+		e.idleSources = e.idleSources[:n-1]
+		e.sourceLock.Unlock()
+		return source, nil
+	}
+	e.sourceLock.Unlock()
+	getPreimage, hint, closeSource, err := e.createSource(e.sourceCtx, e.logger)
+	if err != nil {
+		return nil, err
+	}
+	return &preimageSource{getPreimage: getPreimage, hint: hint, close: closeSource}, nil
+}
+
+// releaseSource makes source available to later executions. Sources that failed are closed instead as they
+// may be in an inconsistent state.
+func (e *InProcessExecutor) releaseSource(source *preimageSource, failed bool) {
+	e.sourceLock.Lock()
+	defer e.sourceLock.Unlock()
+	if e.closed || failed {
+		source.close()
+		return
+	}
+	e.idleSources = append(e.idleSources, source)
+}
+
+// hostPreimageSource creates a [PreimageSourceCreator] backed by op-program's host key-value store and prefetcher.
+func hostPreimageSource(cfg *config.Config, inputs LocalGameInputs) PreimageSourceCreator {
+	return func(ctx context.Context, logger log.Logger) (preimage.PreimageGetter, preimage.HintHandler, func(), error) {
+		hostCfg, err := newHostConfig(cfg, inputs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return host.NewPreimageSource(ctx, logger, hostCfg)
+	}
+}
+
+// newHostConfig creates the op-program host config equivalent to the arguments passed to the cannon server.
+func newHostConfig(cfg *config.Config, inputs LocalGameInputs) (*hostconfig.Config, error) {
+	var rollupCfg *rollup.Config
+	var l2ChainCfg *params.ChainConfig
+	if cfg.CannonNetwork != "" {
+		networkCfg, err := chaincfg.GetRollupConfig(cfg.CannonNetwork)
+		if err != nil {
+			return nil, err
+		}
+		rollupCfg = &networkCfg
+		l2ChainCfg = chainconfig.L2ChainConfigsByName[cfg.CannonNetwork]
+		if l2ChainCfg == nil {
+			return nil, fmt.Errorf("no l2 chain config for network %v", cfg.CannonNetwork)
+		}
+	} else {
+		rollupCfg = new(rollup.Config)
+		if err := loadJSONFile(cfg.CannonRollupConfigPath, rollupCfg); err != nil {
+			return nil, fmt.Errorf("load rollup config: %w", err)
+		}
+		var genesis core.Genesis
+		if err := loadJSONFile(cfg.CannonL2GenesisPath, &genesis); err != nil {
+			return nil, fmt.Errorf("load l2 genesis: %w", err)
+		}
+		l2ChainCfg = genesis.Config
+	}
+	hostCfg := hostconfig.NewConfig(rollupCfg, l2ChainCfg, inputs.L1Head, inputs.L2Head, inputs.L2OutputRoot, inputs.L2Claim, inputs.L2BlockNumber.Uint64())
+	hostCfg.L1URL = cfg.L1EthRpc
+	hostCfg.L2URL = cfg.CannonL2
+	hostCfg.DataDir = filepath.Join(cfg.CannonDatadir, preimagesDir)
+	return hostCfg, nil
+}
+
+func loadJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// GenerateProof executes the VM to generate the proof at trace index i and writes it to dir in the same layout
+// as [Executor] so the two are interchangeable.
+func (e *InProcessExecutor) GenerateProof(ctx context.Context, dir string, i uint64) error {
+	proof, final, err := e.StreamProof(ctx, dir, i)
+	if err != nil {
+		return err
+	}
+	if proof == nil {
+		return writeJSONFile(filepath.Join(dir, finalState), final)
+	}
+	proofDir := filepath.Join(dir, proofsDir)
+	if err := os.MkdirAll(proofDir, 0755); err != nil {
+		return fmt.Errorf("could not create proofs directory %v: %w", proofDir, err)
+	}
+	return writeJSONFile(filepath.Join(proofDir, fmt.Sprintf("%d.json", i)), proof)
+}
+
+// StreamProof executes the VM up to trace index i and returns the proof for that step.
+// If the program exits before reaching i, no proof is returned and the exited final state is returned instead.
+// Snapshots are written to dir as execution progresses so later executions can start closer to their target.
+func (e *InProcessExecutor) StreamProof(ctx context.Context, dir string, i uint64) (*proofData, *mipsevm.State, error) {
+	snapshotDir := filepath.Join(dir, snapsDir)
+	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("could not create snapshot directory %v: %w", snapshotDir, err)
+	}
+	start, err := e.acquireSnapshot(snapshotDir, i)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find starting snapshot: %w", err)
+	}
+	defer e.releaseSnapshot(start)
+	state, err := parseState(start)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger := e.logger.New("proof", i)
+	source, err := e.acquireSource()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create pre-image source: %w", err)
+	}
+	oracle := &inProcessOracle{getPreimage: source.getPreimage, hint: source.hint}
+	defer func() {
+		e.releaseSource(source, oracle.err != nil)
+	}()
+	stdOut := &mipsevm.LoggingWriter{Name: "program std-out", Log: logger}
+	stdErr := &mipsevm.LoggingWriter{Name: "program std-err", Log: logger}
+	vm := mipsevm.NewInstrumentedState(state, oracle, stdOut, stdErr)
+
+	logger.Info("Generating trace in-process", "start", start, "startStep", state.Step)
+	execStart := time.Now()
+	defer func() {
+		e.metrics.RecordCannonExecutionTime(time.Since(execStart).Seconds())
+		e.pruneSnapshots(snapshotDir)
+	}()
+	startStep := state.Step
+	for !state.Exited {
+		if state.Step%100 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		step := state.Step
+		if e.snapshotFreq > 0 && step != startStep && step%uint64(e.snapshotFreq) == 0 {
+			if err := writeSnapshot(snapshotDir, state); err != nil {
+				return nil, nil, err
+			}
+		}
+		if step == i {
+			witness, err := vm.Step(true)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed at proof-gen step %d (PC: %08x): %w", step, state.PC, err)
+			}
+			if oracle.err != nil {
+				return nil, nil, oracle.err
+			}
+			proof := &proofData{
+				ClaimValue: crypto.Keccak256(state.EncodeWitness()),
+				StateData:  witness.State,
+				ProofData:  witness.MemProof,
+			}
+			if witness.HasPreimage() {
+				proof.OracleKey = witness.PreimageKey[:]
+				proof.OracleValue = witness.PreimageValue
+				proof.OracleOffset = witness.PreimageOffset
+			}
+			return proof, nil, nil
+		}
+		if _, err := vm.Step(false); err != nil {
+			return nil, nil, fmt.Errorf("failed at step %d (PC: %08x): %w", step, state.PC, err)
+		}
+		if oracle.err != nil {
+			return nil, nil, oracle.err
+		}
+	}
+	return nil, state, nil
+}
+
+// writeSnapshot writes state to snapshotDir, naming it by its step.
+// The snapshot is written to a temporary file first so partially written snapshots are never selected.
+func writeSnapshot(snapshotDir string, state *mipsevm.State) error {
+	path := filepath.Join(snapshotDir, fmt.Sprintf("%d.bin", state.Step))
+	if err := writeFileAtomic(path, state.Serialize); err != nil {
+		return fmt.Errorf("failed to write state snapshot: %w", err)
+	}
+	return nil
+}
+
+// writeJSONFile atomically writes the JSON encoding of v to path.
+func writeJSONFile(path string, v interface{}) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(v)
+	})
+}
+
+// writeFileAtomic writes to a temporary file using write and then renames it to path.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Clean up the temporary file if it wasn't renamed
+		_ = os.Remove(tmp.Name())
+	}()
+	if err := write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// inProcessOracle adapts a pre-image getter and hint handler to the [mipsevm.PreimageOracle] interface.
+// The VM oracle interface can't return errors so the first error encountered is recorded and execution is aborted
+// by the caller after the step completes.
+type inProcessOracle struct {
+	getPreimage preimage.PreimageGetter
+	hint        preimage.HintHandler
+	err         error
+}
+
+var _ mipsevm.PreimageOracle = (*inProcessOracle)(nil)
+
+func (o *inProcessOracle) Hint(v []byte) {
+	if o.err != nil {
+		return
+	}
+	if err := o.hint(string(v)); err != nil {
+		o.err = fmt.Errorf("failed to process hint %q: %w", v, err)
+	}
+}
+
+func (o *inProcessOracle) GetPreimage(k [32]byte) []byte {
+	if o.err != nil {
+		return nil
+	}
+	value, err := o.getPreimage(k)
+	if err != nil {
+		o.err = fmt.Errorf("failed to get pre-image %x: %w", k, err)
+		return nil
+	}
+	return value
+}
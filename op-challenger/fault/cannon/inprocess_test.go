@@ -0,0 +1,288 @@
+package cannon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInProcessStreamProof(t *testing.T) {
+	t.Run("ProofAtStep", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 0)
+		proof, final, err := executor.StreamProof(context.Background(), dir, 1)
+		require.NoError(t, err)
+		require.Nil(t, final)
+
+		expected := exitProgram()
+		vm := mipsevm.NewInstrumentedState(expected, nil, nil, nil)
+		_, err = vm.Step(false)
+		require.NoError(t, err)
+		witness, err := vm.Step(true)
+		require.NoError(t, err)
+		require.EqualValues(t, witness.State, proof.StateData)
+		require.EqualValues(t, witness.MemProof, proof.ProofData)
+		require.EqualValues(t, crypto.Keccak256(expected.EncodeWitness()), proof.ClaimValue)
+		require.Nil(t, proof.OracleKey)
+	})
+
+	t.Run("ProofAfterExit", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 0)
+		proof, final, err := executor.StreamProof(context.Background(), dir, 100)
+		require.NoError(t, err)
+		require.Nil(t, proof)
+		require.True(t, final.Exited)
+		require.Equal(t, uint64(3), final.Step)
+	})
+
+	t.Run("WriteSnapshots", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 1)
+		_, _, err := executor.StreamProof(context.Background(), dir, 100)
+		require.NoError(t, err)
+		require.FileExists(t, filepath.Join(dir, snapsDir, "1.bin"))
+		require.FileExists(t, filepath.Join(dir, snapsDir, "2.bin"))
+		require.NoFileExists(t, filepath.Join(dir, snapsDir, "0.bin"), "should not rewrite starting state")
+		entries, err := os.ReadDir(filepath.Join(dir, snapsDir))
+		require.NoError(t, err)
+		require.Len(t, entries, 2, "should not leave temporary files")
+	})
+
+	t.Run("StartFromSnapshot", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 1)
+		_, _, err := executor.StreamProof(context.Background(), dir, 100)
+		require.NoError(t, err)
+		var started []string
+		executor.selectSnapshot = func(logger log.Logger, dir string, absolutePreState string, i uint64) (string, error) {
+			snapshot, err := findStartingSnapshot(logger, dir, absolutePreState, i)
+			started = append(started, snapshot)
+			return snapshot, err
+		}
+		_, _, err = executor.StreamProof(context.Background(), dir, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(dir, snapsDir, "1.bin")}, started)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err := executor.StreamProof(ctx, dir, 1)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("SourceReused", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 0)
+		created := 0
+		closed := 0
+		executor.createSource = func(ctx context.Context, logger log.Logger) (preimage.PreimageGetter, preimage.HintHandler, func(), error) {
+			created++
+			return nil, nil, func() { closed++ }, nil
+		}
+		_, _, err := executor.StreamProof(context.Background(), dir, 1)
+		require.NoError(t, err)
+		_, _, err = executor.StreamProof(context.Background(), dir, 2)
+		require.NoError(t, err)
+		require.Equal(t, 1, created, "should reuse source")
+		require.Zero(t, closed)
+
+		require.NoError(t, executor.Close())
+		require.Equal(t, 1, closed, "should close source when executor is closed")
+		_, _, err = executor.StreamProof(context.Background(), dir, 1)
+		require.Error(t, err, "should not create sources after closing")
+	})
+
+	t.Run("FailedSourceClosed", func(t *testing.T) {
+		_, executor := setupInProcessExecutor(t, 0)
+		created := 0
+		closed := 0
+		executor.createSource = func(ctx context.Context, logger log.Logger) (preimage.PreimageGetter, preimage.HintHandler, func(), error) {
+			created++
+			return nil, nil, func() { closed++ }, nil
+		}
+		source, err := executor.acquireSource()
+		require.NoError(t, err)
+		executor.releaseSource(source, true)
+		require.Equal(t, 1, closed, "should not reuse failed source")
+
+		_, err = executor.acquireSource()
+		require.NoError(t, err)
+		require.Equal(t, 2, created)
+	})
+}
+
+func TestInProcessGenerateProof(t *testing.T) {
+	t.Run("WriteProof", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 0)
+		require.NoError(t, executor.GenerateProof(context.Background(), dir, 1))
+		require.FileExists(t, filepath.Join(dir, proofsDir, "1.json"))
+		require.NoFileExists(t, filepath.Join(dir, finalState))
+	})
+
+	t.Run("WriteFinalStateWhenExited", func(t *testing.T) {
+		dir, executor := setupInProcessExecutor(t, 0)
+		require.NoError(t, executor.GenerateProof(context.Background(), dir, 100))
+		state, err := parseState(filepath.Join(dir, finalState))
+		require.NoError(t, err)
+		require.True(t, state.Exited)
+	})
+}
+
+func TestInProcessTraceProvider(t *testing.T) {
+	dir, executor := setupInProcessExecutor(t, 0)
+	provider := setupWithGenerator(t, dir, "prestate.json", executor, 1)
+
+	value, err := provider.Get(context.Background(), 1)
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(dir, proofsDir, "1.json"), "should not write proof to disk")
+
+	expected := exitProgram()
+	vm := mipsevm.NewInstrumentedState(expected, nil, nil, nil)
+	for i := 0; i < 2; i++ {
+		_, err := vm.Step(false)
+		require.NoError(t, err)
+	}
+	require.Equal(t, crypto.Keccak256Hash(expected.EncodeWitness()), value)
+
+	// Extends the trace beyond the program exit
+	value, err = provider.Get(context.Background(), 1000)
+	require.NoError(t, err)
+	_, err = vm.Step(false)
+	require.NoError(t, err)
+	require.True(t, expected.Exited)
+	require.Equal(t, crypto.Keccak256Hash(expected.EncodeWitness()), value)
+}
+
+func TestInProcessOracle(t *testing.T) {
+	t.Run("GetPreimage", func(t *testing.T) {
+		oracle := &inProcessOracle{getPreimage: func(key [32]byte) ([]byte, error) {
+			return []byte{key[0]}, nil
+		}}
+		require.Equal(t, []byte{0xaa}, oracle.GetPreimage([32]byte{0xaa}))
+		require.NoError(t, oracle.err)
+	})
+
+	t.Run("RecordGetPreimageError", func(t *testing.T) {
+		expectedErr := errors.New("boom")
+		oracle := &inProcessOracle{getPreimage: func(key [32]byte) ([]byte, error) {
+			return nil, expectedErr
+		}}
+		require.Nil(t, oracle.GetPreimage([32]byte{0xaa}))
+		require.ErrorIs(t, oracle.err, expectedErr)
+	})
+
+	t.Run("Hint", func(t *testing.T) {
+		var hints []string
+		oracle := &inProcessOracle{hint: func(hint string) error {
+			hints = append(hints, hint)
+			return nil
+		}}
+		oracle.Hint([]byte("l1-block-header 0x1234"))
+		require.Equal(t, []string{"l1-block-header 0x1234"}, hints)
+	})
+
+	t.Run("RecordHintError", func(t *testing.T) {
+		expectedErr := errors.New("boom")
+		calls := 0
+		oracle := &inProcessOracle{hint: func(hint string) error {
+			calls++
+			return expectedErr
+		}}
+		oracle.Hint([]byte("hint"))
+		oracle.Hint([]byte("hint"))
+		require.ErrorIs(t, oracle.err, expectedErr)
+		require.Equal(t, 1, calls, "should not process hints after an error")
+	})
+}
+
+func TestNewHostConfig(t *testing.T) {
+	inputs := LocalGameInputs{
+		L1Head:        common.Hash{0x11},
+		L2Head:        common.Hash{0x22},
+		L2OutputRoot:  common.Hash{0x33},
+		L2Claim:       common.Hash{0x44},
+		L2BlockNumber: big.NewInt(3333),
+	}
+	cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeCannon)
+	cfg.CannonL2 = "http://localhost:9999"
+	cfg.CannonDatadir = t.TempDir()
+
+	t.Run("Network", func(t *testing.T) {
+		cfg := cfg
+		cfg.CannonNetwork = "goerli"
+		hostCfg, err := newHostConfig(&cfg, inputs)
+		require.NoError(t, err)
+		require.Equal(t, cfg.L1EthRpc, hostCfg.L1URL)
+		require.Equal(t, cfg.CannonL2, hostCfg.L2URL)
+		require.Equal(t, filepath.Join(cfg.CannonDatadir, preimagesDir), hostCfg.DataDir)
+		require.Equal(t, inputs.L1Head, hostCfg.L1Head)
+		require.Equal(t, inputs.L2Head, hostCfg.L2Head)
+		require.Equal(t, inputs.L2OutputRoot, hostCfg.L2OutputRoot)
+		require.Equal(t, inputs.L2Claim, hostCfg.L2Claim)
+		require.Equal(t, uint64(3333), hostCfg.L2ClaimBlockNumber)
+		require.NoError(t, hostCfg.Check())
+	})
+
+	t.Run("UnknownNetwork", func(t *testing.T) {
+		cfg := cfg
+		cfg.CannonNetwork = "unknown"
+		_, err := newHostConfig(&cfg, inputs)
+		require.Error(t, err)
+	})
+
+	t.Run("MissingRollupConfig", func(t *testing.T) {
+		cfg := cfg
+		cfg.CannonRollupConfigPath = filepath.Join(t.TempDir(), "rollup.json")
+		cfg.CannonL2GenesisPath = filepath.Join(t.TempDir(), "genesis.json")
+		_, err := newHostConfig(&cfg, inputs)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+// exitProgram creates a state for a MIPS program that immediately exits with code 0.
+func exitProgram() *mipsevm.State {
+	state := &mipsevm.State{
+		Memory: mipsevm.NewMemory(),
+		PC:     0,
+		NextPC: 4,
+	}
+	state.Memory.SetMemory(0, 0x24021096) // addiu $v0, $zero, 4246 (exit_group)
+	state.Memory.SetMemory(4, 0x24040000) // addiu $a0, $zero, 0
+	state.Memory.SetMemory(8, 0x0000000c) // syscall
+	return state
+}
+
+func setupInProcessExecutor(t *testing.T, snapshotFreq uint) (string, *InProcessExecutor) {
+	dir := t.TempDir()
+	prestate := filepath.Join(dir, "prestate.json")
+	data, err := json.Marshal(exitProgram())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(prestate, data, 0644))
+
+	cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeCannon)
+	cfg.CannonAbsolutePreState = prestate
+	cfg.CannonSnapshotFreq = snapshotFreq
+	executor := NewInProcessExecutor(testlog.Logger(t, log.LvlInfo), metrics.NoopMetrics, &cfg, LocalGameInputs{})
+	executor.createSource = func(ctx context.Context, logger log.Logger) (preimage.PreimageGetter, preimage.HintHandler, func(), error) {
+		getPreimage := func(key [32]byte) ([]byte, error) {
+			return nil, errors.New("unexpected pre-image request")
+		}
+		hinter := func(hint string) error {
+			return errors.New("unexpected hint")
+		}
+		return getPreimage, hinter, func() {}, nil
+	}
+	return dir, executor
+}
@@ -6,17 +6,19 @@ import (
 	"math/big"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 )
 
-type localGameInputs struct {
-	l1Head        common.Hash
-	l2Head        common.Hash
-	l2OutputRoot  common.Hash
-	l2Claim       common.Hash
-	l2BlockNumber *big.Int
+// LocalGameInputs are the local inputs provided to op-program when executing the trace.
+type LocalGameInputs struct {
+	L1Head        common.Hash
+	L2Head        common.Hash
+	L2OutputRoot  common.Hash
+	L2Claim       common.Hash
+	L2BlockNumber *big.Int
 }
 
 type L2DataSource interface {
@@ -24,6 +26,11 @@ type L2DataSource interface {
 	HeaderByNumber(context.Context, *big.Int) (*ethtypes.Header, error)
 }
 
+// L2OutputSource is a minimal interface around [sources.RollupClient] to load output roots.
+type L2OutputSource interface {
+	OutputAtBlock(ctx context.Context, blockNum uint64) (*eth.OutputResponse, error)
+}
+
 type GameInputsSource interface {
 	L1Head(opts *bind.CallOpts) ([32]byte, error)
 	Proposals(opts *bind.CallOpts) (struct {
@@ -32,30 +39,50 @@ type GameInputsSource interface {
 	}, error)
 }
 
-func fetchLocalInputs(ctx context.Context, gameAddr common.Address, caller GameInputsSource, l2Client L2DataSource) (localGameInputs, error) {
+func fetchLocalInputs(ctx context.Context, gameAddr common.Address, caller GameInputsSource, l2Client L2DataSource) (LocalGameInputs, error) {
 	opts := &bind.CallOpts{Context: ctx}
 	l1Head, err := caller.L1Head(opts)
 	if err != nil {
-		return localGameInputs{}, fmt.Errorf("fetch L1 head for game %v: %w", gameAddr, err)
+		return LocalGameInputs{}, fmt.Errorf("fetch L1 head for game %v: %w", gameAddr, err)
 	}
 
 	proposals, err := caller.Proposals(opts)
 	if err != nil {
-		return localGameInputs{}, fmt.Errorf("fetch proposals: %w", err)
+		return LocalGameInputs{}, fmt.Errorf("fetch proposals: %w", err)
 	}
 	claimedOutput := proposals.Disputed
 	agreedOutput := proposals.Starting
 	agreedHeader, err := l2Client.HeaderByNumber(ctx, agreedOutput.L2BlockNumber)
 	if err != nil {
-		return localGameInputs{}, fmt.Errorf("fetch L2 block header %v: %w", agreedOutput.L2BlockNumber, err)
+		return LocalGameInputs{}, fmt.Errorf("fetch L2 block header %v: %w", agreedOutput.L2BlockNumber, err)
 	}
 	l2Head := agreedHeader.Hash()
 
-	return localGameInputs{
-		l1Head:        l1Head,
-		l2Head:        l2Head,
-		l2OutputRoot:  agreedOutput.OutputRoot,
-		l2Claim:       claimedOutput.OutputRoot,
-		l2BlockNumber: claimedOutput.L2BlockNumber,
+	return LocalGameInputs{
+		L1Head:        l1Head,
+		L2Head:        l2Head,
+		L2OutputRoot:  agreedOutput.OutputRoot,
+		L2Claim:       claimedOutput.OutputRoot,
+		L2BlockNumber: claimedOutput.L2BlockNumber,
+	}, nil
+}
+
+// FetchLocalInputsFromOutputs creates the local inputs to execute the transition from the output at prestateBlock
+// to the output at poststateBlock, using the outputs reported by the rollup node.
+func FetchLocalInputsFromOutputs(ctx context.Context, l1Head common.Hash, outputs L2OutputSource, prestateBlock uint64, poststateBlock uint64) (LocalGameInputs, error) {
+	agreedOutput, err := outputs.OutputAtBlock(ctx, prestateBlock)
+	if err != nil {
+		return LocalGameInputs{}, fmt.Errorf("fetch output at block %v: %w", prestateBlock, err)
+	}
+	claimedOutput, err := outputs.OutputAtBlock(ctx, poststateBlock)
+	if err != nil {
+		return LocalGameInputs{}, fmt.Errorf("fetch output at block %v: %w", poststateBlock, err)
+	}
+	return LocalGameInputs{
+		L1Head:        l1Head,
+		L2Head:        agreedOutput.BlockRef.Hash,
+		L2OutputRoot:  common.Hash(agreedOutput.OutputRoot),
+		L2Claim:       common.Hash(claimedOutput.OutputRoot),
+		L2BlockNumber: new(big.Int).SetUint64(poststateBlock),
 	}, nil
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -39,11 +40,46 @@ func TestFetchLocalInputs(t *testing.T) {
 	inputs, err := fetchLocalInputs(ctx, gameAddr, l1Client, l2Client)
 	require.NoError(t, err)
 
-	require.Equal(t, l1Client.l1Head, inputs.l1Head)
-	require.Equal(t, l2Client.header.Hash(), inputs.l2Head)
-	require.EqualValues(t, l1Client.starting.OutputRoot, inputs.l2OutputRoot)
-	require.EqualValues(t, l1Client.disputed.OutputRoot, inputs.l2Claim)
-	require.Equal(t, l1Client.disputed.L2BlockNumber, inputs.l2BlockNumber)
+	require.Equal(t, l1Client.l1Head, inputs.L1Head)
+	require.Equal(t, l2Client.header.Hash(), inputs.L2Head)
+	require.EqualValues(t, l1Client.starting.OutputRoot, inputs.L2OutputRoot)
+	require.EqualValues(t, l1Client.disputed.OutputRoot, inputs.L2Claim)
+	require.Equal(t, l1Client.disputed.L2BlockNumber, inputs.L2BlockNumber)
+}
+
+func TestFetchLocalInputsFromOutputs(t *testing.T) {
+	ctx := context.Background()
+	l1Head := common.Hash{0xcc}
+	outputs := &mockL2OutputSource{
+		outputs: map[uint64]*eth.OutputResponse{
+			2222: {OutputRoot: eth.Bytes32{0xdd}, BlockRef: eth.L2BlockRef{Hash: common.Hash{0xaa}, Number: 2222}},
+			2223: {OutputRoot: eth.Bytes32{0xee}, BlockRef: eth.L2BlockRef{Hash: common.Hash{0xbb}, Number: 2223}},
+		},
+	}
+
+	inputs, err := FetchLocalInputsFromOutputs(ctx, l1Head, outputs, 2222, 2223)
+	require.NoError(t, err)
+
+	require.Equal(t, l1Head, inputs.L1Head)
+	require.Equal(t, common.Hash{0xaa}, inputs.L2Head)
+	require.Equal(t, common.Hash{0xdd}, inputs.L2OutputRoot)
+	require.Equal(t, common.Hash{0xee}, inputs.L2Claim)
+	require.Equal(t, big.NewInt(2223), inputs.L2BlockNumber)
+
+	_, err = FetchLocalInputsFromOutputs(ctx, l1Head, outputs, 2223, 2224)
+	require.ErrorIs(t, err, ethereum.NotFound)
+}
+
+type mockL2OutputSource struct {
+	outputs map[uint64]*eth.OutputResponse
+}
+
+func (s *mockL2OutputSource) OutputAtBlock(_ context.Context, blockNum uint64) (*eth.OutputResponse, error) {
+	output, ok := s.outputs[blockNum]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return output, nil
 }
 
 type mockGameInputsSource struct {
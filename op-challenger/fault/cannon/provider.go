@@ -5,9 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
@@ -17,12 +24,20 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	proofsDir = "proofs"
+
+	// traceEndKey is the key used to store the end of the trace once it is known.
+	traceEndKey = "cannon-trace-end"
 )
 
+// proofNameRegexp matches the names of the proof files in the proofs directory.
+var proofNameRegexp = regexp.MustCompile(`^[0-9]+\.json$`)
+
 type proofData struct {
 	ClaimValue   hexutil.Bytes `json:"post"`
 	StateData    hexutil.Bytes `json:"state-data"`
@@ -32,17 +47,51 @@ type proofData struct {
 	OracleOffset uint32        `json:"oracle-offset,omitempty"`
 }
 
+// traceEnd records the last step in the actual trace and the proof data used for all steps after it.
+type traceEnd struct {
+	LastStep uint64     `json:"last-step"`
+	Proof    *proofData `json:"proof"`
+}
+
+// TraceStore persists information about the trace that would otherwise require executing cannon again after a restart.
+// Generated proofs are kept in the proofs directory and do not need to be stored.
+type TraceStore interface {
+	Get(key string, v interface{}) (bool, error)
+	Put(key string, v interface{}) error
+}
+
 type ProofGenerator interface {
 	// GenerateProof executes cannon to generate a proof at the specified trace index in dataDir.
 	GenerateProof(ctx context.Context, dataDir string, proofAt uint64) error
 }
 
+// proofStreamer is implemented by a [ProofGenerator] that can return proofs directly rather than writing them to disk.
+type proofStreamer interface {
+	// StreamProof executes cannon to generate a proof at the specified trace index.
+	// If the program exits before reaching proofAt, the proof is nil and the exited final state is returned.
+	StreamProof(ctx context.Context, dataDir string, proofAt uint64) (*proofData, *mipsevm.State, error)
+}
+
+// CannonTraceProvider is a [types.TraceProvider] that generates proofs by executing cannon.
+// It is safe for concurrent use. Proofs for different trace indices are generated in parallel, up to the
+// configured number of workers, while concurrent requests for the same index share a single cannon execution.
 type CannonTraceProvider struct {
 	logger    log.Logger
 	dir       string
 	prestate  string
 	generator ProofGenerator
+	store     TraceStore
+
+	// workers limits the number of concurrent cannon executions.
+	workers chan struct{}
+	// inflight de-duplicates concurrent requests for the same proof.
+	inflight singleflight.Group
+	// proofs caches recently loaded proofs to avoid reading them from disk again.
+	// Proof files are deleted from disk once evicted so they are bounded by the cache size.
+	proofs *lru.Cache[uint64, *proofData]
 
+	// endLock guards lastStep and lastProof.
+	endLock sync.Mutex
 	// lastStep stores the last step in the actual trace if known. 0 indicates unknown.
 	// Cached as an optimisation to avoid repeatedly attempting to execute beyond the end of the trace.
 	lastStep uint64
@@ -50,26 +99,79 @@ type CannonTraceProvider struct {
 	lastProof *proofData
 }
 
-func NewTraceProvider(ctx context.Context, logger log.Logger, cfg *config.Config, l1Client bind.ContractCaller) (*CannonTraceProvider, error) {
+func NewTraceProvider(ctx context.Context, logger log.Logger, m Metricer, cfg *config.Config, l1Client bind.ContractCaller, dir string, gameAddr common.Address, store TraceStore) (*CannonTraceProvider, error) {
 	l2Client, err := ethclient.DialContext(ctx, cfg.CannonL2)
 	if err != nil {
 		return nil, fmt.Errorf("dial l2 client %v: %w", cfg.CannonL2, err)
 	}
 	defer l2Client.Close() // Not needed after fetching the inputs
-	gameCaller, err := bindings.NewFaultDisputeGameCaller(cfg.GameAddress, l1Client)
+	gameCaller, err := bindings.NewFaultDisputeGameCaller(gameAddr, l1Client)
 	if err != nil {
-		return nil, fmt.Errorf("create caller for game %v: %w", cfg.GameAddress, err)
+		return nil, fmt.Errorf("create caller for game %v: %w", gameAddr, err)
 	}
-	l1Head, err := fetchLocalInputs(ctx, cfg.GameAddress, gameCaller, l2Client)
+	localInputs, err := fetchLocalInputs(ctx, gameAddr, gameCaller, l2Client)
 	if err != nil {
 		return nil, fmt.Errorf("fetch local game inputs: %w", err)
 	}
-	return &CannonTraceProvider{
+	return NewTraceProviderFromInputs(logger, m, cfg, localInputs, dir, store)
+}
+
+// NewTraceProviderFromInputs creates a [CannonTraceProvider] that executes op-program with the specified local inputs.
+func NewTraceProviderFromInputs(logger log.Logger, m Metricer, cfg *config.Config, localInputs LocalGameInputs, dir string, store TraceStore) (*CannonTraceProvider, error) {
+	var generator ProofGenerator
+	if cfg.CannonInProcess {
+		generator = NewInProcessExecutor(logger, m, cfg, localInputs)
+	} else {
+		generator = NewExecutor(logger, m, cfg, localInputs)
+	}
+	provider, err := newCannonTraceProvider(logger, dir, cfg.CannonAbsolutePreState, generator, store,
+		cfg.CannonWorkers, cfg.CannonProofCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.loadTraceEnd(); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func newCannonTraceProvider(logger log.Logger, dir string, prestate string, generator ProofGenerator, store TraceStore, workers uint, cacheSize uint) (*CannonTraceProvider, error) {
+	if workers == 0 {
+		workers = 1
+	}
+	if cacheSize == 0 {
+		cacheSize = 1
+	}
+	provider := &CannonTraceProvider{
 		logger:    logger,
-		dir:       cfg.CannonDatadir,
-		prestate:  cfg.CannonAbsolutePreState,
-		generator: NewExecutor(logger, cfg, l1Head),
-	}, nil
+		dir:       dir,
+		prestate:  prestate,
+		generator: generator,
+		store:     store,
+		workers:   make(chan struct{}, workers),
+	}
+	proofs, err := lru.NewWithEvict[uint64, *proofData](int(cacheSize), func(i uint64, _ *proofData) {
+		provider.removeProof(i)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proof cache: %w", err)
+	}
+	provider.proofs = proofs
+	// Proofs left on disk by a previous run are not in the cache, so prune them to the cache size.
+	provider.pruneProofs(cacheSize)
+	return provider, nil
+}
+
+// loadTraceEnd restores the end of the trace if it was found before a restart.
+func (p *CannonTraceProvider) loadTraceEnd() error {
+	var end traceEnd
+	if ok, err := p.store.Get(traceEndKey, &end); err != nil {
+		return fmt.Errorf("failed to load trace end: %w", err)
+	} else if ok {
+		p.logger.Info("Restored end of trace", "last", end.LastStep)
+		p.setTraceEnd(end.LastStep, end.Proof)
+	}
+	return nil
 }
 
 func (p *CannonTraceProvider) Get(ctx context.Context, i uint64) (common.Hash, error) {
@@ -106,6 +208,28 @@ func (p *CannonTraceProvider) GetStepData(ctx context.Context, i uint64) ([]byte
 }
 
 func (p *CannonTraceProvider) AbsolutePreState(ctx context.Context) ([]byte, error) {
+	return NewPrestateProvider(p.prestate).AbsolutePreState(ctx)
+}
+
+// Close releases the resources held by the proof generator, such as connections to the L1 and L2 nodes.
+func (p *CannonTraceProvider) Close() error {
+	if closer, ok := p.generator.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// CannonPrestateProvider provides the absolute pre-state of cannon traces without being able to execute cannon.
+type CannonPrestateProvider struct {
+	prestate string
+}
+
+// NewPrestateProvider creates a [CannonPrestateProvider] that loads the absolute pre-state from the prestate file.
+func NewPrestateProvider(prestate string) *CannonPrestateProvider {
+	return &CannonPrestateProvider{prestate: prestate}
+}
+
+func (p *CannonPrestateProvider) AbsolutePreState(_ context.Context) ([]byte, error) {
 	state, err := parseState(p.prestate)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load absolute pre-state: %w", err)
@@ -116,14 +240,36 @@ func (p *CannonTraceProvider) AbsolutePreState(ctx context.Context) ([]byte, err
 // loadProof will attempt to load or generate the proof data at the specified index
 // If the requested index is beyond the end of the actual trace it is extended with no-op instructions.
 func (p *CannonTraceProvider) loadProof(ctx context.Context, i uint64) (*proofData, error) {
-	if p.lastProof != nil && i > p.lastStep {
+	if proof, ok := p.traceEndProof(i); ok {
 		// If the requested index is after the last step in the actual trace, extend the final no-op step
-		return p.lastProof, nil
+		return proof, nil
+	}
+	if proof, ok := p.proofs.Get(i); ok {
+		return proof, nil
+	}
+	result, err, _ := p.inflight.Do(strconv.FormatUint(i, 10), func() (interface{}, error) {
+		proof, err := p.loadOrGenerateProof(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		p.proofs.Add(i, proof)
+		return proof, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	path := filepath.Join(p.dir, proofsDir, fmt.Sprintf("%d.json", i))
+	return result.(*proofData), nil
+}
+
+// loadOrGenerateProof reads the proof at index i from disk, executing cannon to generate it if required.
+func (p *CannonTraceProvider) loadOrGenerateProof(ctx context.Context, i uint64) (*proofData, error) {
+	path := p.proofPath(i)
 	file, err := os.Open(path)
 	if errors.Is(err, os.ErrNotExist) {
-		if err := p.generator.GenerateProof(ctx, p.dir, i); err != nil {
+		if streamer, ok := p.generator.(proofStreamer); ok {
+			return p.streamProof(ctx, streamer, i)
+		}
+		if err := p.generateProof(ctx, i); err != nil {
 			return nil, fmt.Errorf("generate cannon trace with proof at %v: %w", i, err)
 		}
 		// Try opening the file again now and it should exist.
@@ -134,27 +280,7 @@ func (p *CannonTraceProvider) loadProof(ctx context.Context, i uint64) (*proofDa
 			if err != nil {
 				return nil, fmt.Errorf("cannot read final state: %w", err)
 			}
-			if state.Exited && state.Step <= i {
-				p.logger.Warn("Requested proof was after the program exited", "proof", i, "last", state.Step)
-				// The final instruction has already been applied to this state, so the last step we can execute
-				// is one before its Step value.
-				p.lastStep = state.Step - 1
-				// Extend the trace out to the full length using a no-op instruction that doesn't change any state
-				// No execution is done, so no proof-data or oracle values are required.
-				witness := state.EncodeWitness()
-				proof := &proofData{
-					ClaimValue:   crypto.Keccak256(witness),
-					StateData:    witness,
-					ProofData:    []byte{},
-					OracleKey:    nil,
-					OracleValue:  nil,
-					OracleOffset: 0,
-				}
-				p.lastProof = proof
-				return proof, nil
-			} else {
-				return nil, fmt.Errorf("expected proof not generated but final state was not exited, requested step %v, final state at step %v", i, state.Step)
-			}
+			return p.extendTrace(state, i)
 		}
 	}
 	if err != nil {
@@ -168,3 +294,136 @@ func (p *CannonTraceProvider) loadProof(ctx context.Context, i uint64) (*proofDa
 	}
 	return &proof, nil
 }
+
+// proofPath returns the path of the proof file at index i.
+func (p *CannonTraceProvider) proofPath(i uint64) string {
+	return filepath.Join(p.dir, proofsDir, fmt.Sprintf("%d.json", i))
+}
+
+// removeProof deletes the proof file at index i, if any, once it is evicted from the cache.
+func (p *CannonTraceProvider) removeProof(i uint64) {
+	if err := os.Remove(p.proofPath(i)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		p.logger.Warn("Failed to remove evicted proof", "proof", i, "err", err)
+	}
+}
+
+// pruneProofs deletes the least recently modified proof files until at most maxProofs remain.
+func (p *CannonTraceProvider) pruneProofs(maxProofs uint) {
+	entries, err := os.ReadDir(filepath.Join(p.dir, proofsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	} else if err != nil {
+		p.logger.Warn("Failed to list proofs for pruning", "err", err)
+		return
+	}
+	type proofFile struct {
+		path    string
+		modTime time.Time
+	}
+	var proofs []proofFile
+	for _, entry := range entries {
+		if entry.IsDir() || !proofNameRegexp.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		proofs = append(proofs, proofFile{path: filepath.Join(p.dir, proofsDir, entry.Name()), modTime: info.ModTime()})
+	}
+	if uint(len(proofs)) <= maxProofs {
+		return
+	}
+	sort.Slice(proofs, func(i, j int) bool {
+		return proofs[i].modTime.Before(proofs[j].modTime)
+	})
+	for _, proof := range proofs[:len(proofs)-int(maxProofs)] {
+		if err := os.Remove(proof.path); err != nil {
+			p.logger.Warn("Failed to prune proof", "proof", proof.path, "err", err)
+		}
+	}
+}
+
+// streamProof generates the proof at index i in-process once a worker is available.
+func (p *CannonTraceProvider) streamProof(ctx context.Context, streamer proofStreamer, i uint64) (*proofData, error) {
+	if err := p.acquireWorker(ctx); err != nil {
+		return nil, err
+	}
+	defer p.releaseWorker()
+	proof, state, err := streamer.StreamProof(ctx, p.dir, i)
+	if err != nil {
+		return nil, fmt.Errorf("generate cannon trace with proof at %v: %w", i, err)
+	}
+	if proof != nil {
+		return proof, nil
+	}
+	return p.extendTrace(state, i)
+}
+
+// extendTrace returns the proof for index i when execution stopped before reaching it.
+// If the program exited, the trace is extended out to its full length by repeating the final state.
+func (p *CannonTraceProvider) extendTrace(state *mipsevm.State, i uint64) (*proofData, error) {
+	if !state.Exited || state.Step > i {
+		return nil, fmt.Errorf("expected proof not generated but final state was not exited, requested step %v, final state at step %v", i, state.Step)
+	}
+	p.logger.Warn("Requested proof was after the program exited", "proof", i, "last", state.Step)
+	// The final instruction has already been applied to this state, so the last step we can execute
+	// is one before its Step value.
+	lastStep := state.Step - 1
+	// Extend the trace out to the full length using a no-op instruction that doesn't change any state
+	// No execution is done, so no proof-data or oracle values are required.
+	witness := state.EncodeWitness()
+	proof := &proofData{
+		ClaimValue:   crypto.Keccak256(witness),
+		StateData:    witness,
+		ProofData:    []byte{},
+		OracleKey:    nil,
+		OracleValue:  nil,
+		OracleOffset: 0,
+	}
+	p.setTraceEnd(lastStep, proof)
+	if err := p.store.Put(traceEndKey, traceEnd{LastStep: lastStep, Proof: proof}); err != nil {
+		p.logger.Warn("Failed to store end of trace", "err", err)
+	}
+	return proof, nil
+}
+
+// generateProof executes cannon to generate the proof at index i once a worker is available.
+func (p *CannonTraceProvider) generateProof(ctx context.Context, i uint64) error {
+	if err := p.acquireWorker(ctx); err != nil {
+		return err
+	}
+	defer p.releaseWorker()
+	return p.generator.GenerateProof(ctx, p.dir, i)
+}
+
+// acquireWorker blocks until a worker is available to execute cannon or ctx is done.
+func (p *CannonTraceProvider) acquireWorker(ctx context.Context) error {
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *CannonTraceProvider) releaseWorker() {
+	<-p.workers
+}
+
+// traceEndProof returns the proof to use for index i if it is after the end of the actual trace.
+func (p *CannonTraceProvider) traceEndProof(i uint64) (*proofData, bool) {
+	p.endLock.Lock()
+	defer p.endLock.Unlock()
+	if p.lastProof != nil && i > p.lastStep {
+		return p.lastProof, true
+	}
+	return nil, false
+}
+
+func (p *CannonTraceProvider) setTraceEnd(lastStep uint64, proof *proofData) {
+	p.endLock.Lock()
+	defer p.endLock.Unlock()
+	p.lastStep = lastStep
+	p.lastProof = proof
+}
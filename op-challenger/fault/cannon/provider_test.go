@@ -8,10 +8,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -45,6 +48,26 @@ func TestGet(t *testing.T) {
 		require.Equal(t, crypto.Keccak256Hash(generator.finalState.EncodeWitness()), value)
 	})
 
+	t.Run("RestoreEndOfTrace", func(t *testing.T) {
+		provider, generator := setupWithTestData(t, dataDir, prestate)
+		generator.finalState = &mipsevm.State{
+			Memory: &mipsevm.Memory{},
+			Step:   10,
+			Exited: true,
+		}
+		expected, err := provider.Get(context.Background(), 7000)
+		require.NoError(t, err)
+
+		// Simulate a restart with the same store
+		restarted, generator := setupWithTestData(t, dataDir, prestate)
+		restarted.store = provider.store
+		require.NoError(t, restarted.loadTraceEnd())
+		value, err := restarted.Get(context.Background(), 8000)
+		require.NoError(t, err)
+		require.Equal(t, expected, value)
+		require.Empty(t, generator.generated, "should not execute cannon again")
+	})
+
 	t.Run("MissingPostHash", func(t *testing.T) {
 		provider, generator := setupWithTestData(t, dataDir, prestate)
 		_, err := provider.Get(context.Background(), 1)
@@ -147,6 +170,132 @@ func TestGetStepData(t *testing.T) {
 	})
 }
 
+func TestConcurrentProofGeneration(t *testing.T) {
+	proof := &proofData{
+		ClaimValue: common.Hash{0xaa}.Bytes(),
+		StateData:  []byte{0xbb},
+		ProofData:  []byte{0xcc},
+	}
+
+	t.Run("SameIndexGeneratedOnce", func(t *testing.T) {
+		dataDir, prestate := setupTestData(t)
+		generator := newBlockingGenerator(proof)
+		provider := setupWithGenerator(t, dataDir, prestate, generator, 4)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := provider.Get(context.Background(), 42)
+				require.NoError(t, err)
+				require.Equal(t, common.Hash{0xaa}, value)
+			}()
+		}
+		<-generator.started
+		close(generator.release)
+		wg.Wait()
+		require.Equal(t, []uint64{42}, generator.Generated())
+	})
+
+	t.Run("LimitConcurrentExecutions", func(t *testing.T) {
+		dataDir, prestate := setupTestData(t)
+		generator := newBlockingGenerator(proof)
+		provider := setupWithGenerator(t, dataDir, prestate, generator, 2)
+
+		var wg sync.WaitGroup
+		for i := uint64(100); i < 104; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := provider.Get(context.Background(), i)
+				require.NoError(t, err)
+			}()
+		}
+		<-generator.started
+		<-generator.started
+		require.Never(t, func() bool {
+			return len(generator.Generated()) > 2
+		}, 100*time.Millisecond, 10*time.Millisecond, "should not exceed worker limit")
+		close(generator.release)
+		wg.Wait()
+		require.ElementsMatch(t, []uint64{100, 101, 102, 103}, generator.Generated())
+	})
+
+	t.Run("CancelWhileWaitingForWorker", func(t *testing.T) {
+		dataDir, prestate := setupTestData(t)
+		generator := newBlockingGenerator(proof)
+		provider := setupWithGenerator(t, dataDir, prestate, generator, 1)
+		defer close(generator.release)
+
+		go func() {
+			_, _ = provider.Get(context.Background(), 100)
+		}()
+		<-generator.started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := provider.Get(ctx, 101)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestProofCache(t *testing.T) {
+	dataDir, prestate := setupTestData(t)
+	provider, generator := setupWithTestData(t, dataDir, prestate)
+	expected, err := provider.Get(context.Background(), 0)
+	require.NoError(t, err)
+
+	// Remove the proof from disk to ensure the cached value is used
+	require.NoError(t, os.Remove(filepath.Join(dataDir, proofsDir, "0.json")))
+	value, err := provider.Get(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, expected, value)
+	require.Empty(t, generator.generated)
+}
+
+func TestProofFilesPruned(t *testing.T) {
+	proof := &proofData{
+		ClaimValue: common.Hash{0xaa}.Bytes(),
+		StateData:  []byte{0xbb},
+		ProofData:  []byte{0xcc},
+	}
+
+	t.Run("RemovedWhenEvicted", func(t *testing.T) {
+		dataDir, prestate := setupTestData(t)
+		generator := &stubGenerator{proof: proof}
+		provider, err := newCannonTraceProvider(testlog.Logger(t, log.LvlInfo), dataDir, filepath.Join(dataDir, prestate), generator,
+			store.NewMemoryStore().GameStore(common.Address{0xaa}), 1, 5)
+		require.NoError(t, err)
+
+		for i := uint64(100); i < 106; i++ {
+			_, err := provider.Get(context.Background(), i)
+			require.NoError(t, err)
+		}
+		// Only the proofs still in the cache are kept
+		require.NoFileExists(t, filepath.Join(dataDir, proofsDir, "100.json"))
+		for i := 101; i < 106; i++ {
+			require.FileExists(t, filepath.Join(dataDir, proofsDir, fmt.Sprintf("%d.json", i)))
+		}
+	})
+
+	t.Run("PrunedOnStartup", func(t *testing.T) {
+		dataDir, prestate := setupTestData(t)
+		// Make the proof at index 0 the oldest one
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(dataDir, proofsDir, "0.json"), old, old))
+
+		_, err := newCannonTraceProvider(testlog.Logger(t, log.LvlInfo), dataDir, filepath.Join(dataDir, prestate), &stubGenerator{},
+			store.NewMemoryStore().GameStore(common.Address{0xaa}), 1, 4)
+		require.NoError(t, err)
+		require.NoFileExists(t, filepath.Join(dataDir, proofsDir, "0.json"))
+		entries, err := os.ReadDir(filepath.Join(dataDir, proofsDir))
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+	})
+}
+
 func TestAbsolutePreState(t *testing.T) {
 	dataDir := t.TempDir()
 	_ = os.Mkdir(dataDir, 0o777)
@@ -216,21 +365,26 @@ func setupTestData(t *testing.T) (string, string) {
 
 func setupWithTestData(t *testing.T, dataDir string, prestate string) (*CannonTraceProvider, *stubGenerator) {
 	generator := &stubGenerator{}
-	return &CannonTraceProvider{
-		logger:    testlog.Logger(t, log.LvlInfo),
-		dir:       dataDir,
-		generator: generator,
-		prestate:  filepath.Join(dataDir, prestate),
-	}, generator
+	return setupWithGenerator(t, dataDir, prestate, generator, 1), generator
+}
+
+func setupWithGenerator(t *testing.T, dataDir string, prestate string, generator ProofGenerator, workers uint) *CannonTraceProvider {
+	provider, err := newCannonTraceProvider(testlog.Logger(t, log.LvlInfo), dataDir, filepath.Join(dataDir, prestate), generator,
+		store.NewMemoryStore().GameStore(common.Address{0xaa}), workers, 10)
+	require.NoError(t, err)
+	return provider
 }
 
 type stubGenerator struct {
+	lock       sync.Mutex
 	generated  []int // Using int makes assertions easier
 	finalState *mipsevm.State
 	proof      *proofData
 }
 
 func (e *stubGenerator) GenerateProof(ctx context.Context, dir string, i uint64) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
 	e.generated = append(e.generated, int(i))
 	if e.finalState != nil && e.finalState.Step <= i {
 		// Requesting a trace index past the end of the trace
@@ -250,3 +404,39 @@ func (e *stubGenerator) GenerateProof(ctx context.Context, dir string, i uint64)
 	}
 	return nil
 }
+
+// blockingGenerator writes the configured proof for any requested index but blocks until released.
+type blockingGenerator struct {
+	proof     *proofData
+	started   chan struct{}
+	release   chan struct{}
+	lock      sync.Mutex
+	generated []uint64
+}
+
+func newBlockingGenerator(proof *proofData) *blockingGenerator {
+	return &blockingGenerator{
+		proof:   proof,
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (g *blockingGenerator) GenerateProof(ctx context.Context, dir string, i uint64) error {
+	g.lock.Lock()
+	g.generated = append(g.generated, i)
+	g.lock.Unlock()
+	g.started <- struct{}{}
+	<-g.release
+	data, err := json.Marshal(g.proof)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, proofsDir, fmt.Sprintf("%d.json", i)), data, 0644)
+}
+
+func (g *blockingGenerator) Generated() []uint64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return append([]uint64(nil), g.generated...)
+}
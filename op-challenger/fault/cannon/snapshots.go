@@ -0,0 +1,113 @@
+package cannon
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// snapshotManager selects the snapshots to start cannon executions from and prunes the least recently used
+// snapshots so at most maxSnapshots are retained.
+type snapshotManager struct {
+	logger           log.Logger
+	absolutePreState string
+	maxSnapshots     uint
+	selectSnapshot   snapshotSelect
+
+	// snapshotLock guards snapshot selection and pruning so snapshots in use are not deleted.
+	snapshotLock   sync.Mutex
+	snapshotsInUse map[string]int
+}
+
+func newSnapshotManager(logger log.Logger, absolutePreState string, maxSnapshots uint) *snapshotManager {
+	return &snapshotManager{
+		logger:           logger,
+		absolutePreState: absolutePreState,
+		maxSnapshots:     maxSnapshots,
+		selectSnapshot:   findStartingSnapshot,
+		snapshotsInUse:   make(map[string]int),
+	}
+}
+
+// acquireSnapshot selects the snapshot to start execution from and marks it as in use so it isn't pruned.
+// The snapshot's modification time is updated so that recently used snapshots are the last to be pruned.
+func (e *snapshotManager) acquireSnapshot(snapshotDir string, i uint64) (string, error) {
+	e.snapshotLock.Lock()
+	defer e.snapshotLock.Unlock()
+	start, err := e.selectSnapshot(e.logger, snapshotDir, e.absolutePreState, i)
+	if err != nil {
+		return "", err
+	}
+	if start != e.absolutePreState {
+		now := time.Now()
+		if err := os.Chtimes(start, now, now); err != nil {
+			e.logger.Warn("Failed to update snapshot modification time", "snapshot", start, "err", err)
+		}
+	}
+	e.snapshotsInUse[start]++
+	return start, nil
+}
+
+func (e *snapshotManager) releaseSnapshot(path string) {
+	e.snapshotLock.Lock()
+	defer e.snapshotLock.Unlock()
+	e.snapshotsInUse[path]--
+	if e.snapshotsInUse[path] <= 0 {
+		delete(e.snapshotsInUse, path)
+	}
+}
+
+// pruneSnapshots deletes the least recently used snapshots in snapshotDir until at most maxSnapshots remain.
+// Snapshots currently being used as the starting point of an execution are never deleted.
+func (e *snapshotManager) pruneSnapshots(snapshotDir string) {
+	if e.maxSnapshots == 0 {
+		return
+	}
+	e.snapshotLock.Lock()
+	defer e.snapshotLock.Unlock()
+	entries, err := os.ReadDir(snapshotDir)
+	if err != nil {
+		e.logger.Warn("Failed to list snapshots for pruning", "dir", snapshotDir, "err", err)
+		return
+	}
+	type snapshot struct {
+		path    string
+		modTime time.Time
+	}
+	var snapshots []snapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !snapshotNameRegexp.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{path: filepath.Join(snapshotDir, entry.Name()), modTime: info.ModTime()})
+	}
+	if uint(len(snapshots)) <= e.maxSnapshots {
+		return
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].modTime.Before(snapshots[j].modTime)
+	})
+	excess := len(snapshots) - int(e.maxSnapshots)
+	for _, snap := range snapshots {
+		if excess == 0 {
+			break
+		}
+		if e.snapshotsInUse[snap.path] > 0 {
+			continue
+		}
+		if err := os.Remove(snap.path); err != nil {
+			e.logger.Warn("Failed to prune snapshot", "snapshot", snap.path, "err", err)
+			continue
+		}
+		e.logger.Debug("Pruned snapshot", "snapshot", snap.path)
+		excess--
+	}
+}
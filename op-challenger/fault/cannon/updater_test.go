@@ -50,6 +50,10 @@ func (m *mockTxManager) Call(_ context.Context, _ ethereum.CallMsg, _ *big.Int)
 	panic("not implemented")
 }
 
+func (m *mockTxManager) Cancel(ctx context.Context, nonce uint64) (*ethtypes.Receipt, error) {
+	panic("not implemented")
+}
+
 func (m *mockTxManager) BlockNumber(ctx context.Context) (uint64, error) {
 	panic("not implemented")
 }
@@ -0,0 +1,68 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MinimalDisputeGameFactoryCaller is a minimal interface around [bindings.DisputeGameFactoryCaller].
+// This needs to be updated if the [bindings.DisputeGameFactoryCaller] interface changes.
+type MinimalDisputeGameFactoryCaller interface {
+	GameCount(opts *bind.CallOpts) (*big.Int, error)
+	GameAtIndex(opts *bind.CallOpts, _index *big.Int) (struct {
+		Proxy     common.Address
+		Timestamp *big.Int
+	}, error)
+}
+
+// FaultDisputeGame is a dispute game created by the dispute game factory.
+type FaultDisputeGame struct {
+	Index     uint64
+	Proxy     common.Address
+	Timestamp uint64
+}
+
+// gameFactoryLoader pulls in the dispute games created by the dispute game factory.
+type gameFactoryLoader struct {
+	caller MinimalDisputeGameFactoryCaller
+}
+
+// NewGameFactoryLoader creates a new [gameFactoryLoader].
+func NewGameFactoryLoader(caller MinimalDisputeGameFactoryCaller) *gameFactoryLoader {
+	return &gameFactoryLoader{
+		caller: caller,
+	}
+}
+
+// FetchGamesFrom fetches all games created by the factory with an index greater than or equal to startIndex.
+func (l *gameFactoryLoader) FetchGamesFrom(ctx context.Context, startIndex uint64) ([]FaultDisputeGame, error) {
+	callOpts := &bind.CallOpts{
+		Context: ctx,
+	}
+	gameCount, err := l.caller.GameCount(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch game count: %w", err)
+	}
+
+	count := gameCount.Uint64()
+	if startIndex >= count {
+		return nil, nil
+	}
+	games := make([]FaultDisputeGame, 0, count-startIndex)
+	for i := startIndex; i < count; i++ {
+		game, err := l.caller.GameAtIndex(callOpts, new(big.Int).SetUint64(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch game at index %d: %w", i, err)
+		}
+		games = append(games, FaultDisputeGame{
+			Index:     i,
+			Proxy:     game.Proxy,
+			Timestamp: game.Timestamp.Uint64(),
+		})
+	}
+	return games, nil
+}
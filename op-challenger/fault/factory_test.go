@@ -0,0 +1,91 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	gameCountErr   = errors.New("game count error")
+	gameAtIndexErr = errors.New("game at index error")
+)
+
+func TestFetchGamesFrom(t *testing.T) {
+	games := []FaultDisputeGame{
+		{Index: 0, Proxy: common.Address{0xaa}, Timestamp: 100},
+		{Index: 1, Proxy: common.Address{0xbb}, Timestamp: 200},
+		{Index: 2, Proxy: common.Address{0xcc}, Timestamp: 300},
+	}
+
+	t.Run("AllGames", func(t *testing.T) {
+		loader := NewGameFactoryLoader(&mockFactoryCaller{games: games})
+		actual, err := loader.FetchGamesFrom(context.Background(), 0)
+		require.NoError(t, err)
+		require.Equal(t, games, actual)
+	})
+
+	t.Run("NewGamesOnly", func(t *testing.T) {
+		loader := NewGameFactoryLoader(&mockFactoryCaller{games: games})
+		actual, err := loader.FetchGamesFrom(context.Background(), 2)
+		require.NoError(t, err)
+		require.Equal(t, games[2:], actual)
+	})
+
+	t.Run("NoNewGames", func(t *testing.T) {
+		loader := NewGameFactoryLoader(&mockFactoryCaller{games: games})
+		actual, err := loader.FetchGamesFrom(context.Background(), 3)
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+
+	t.Run("GameCountError", func(t *testing.T) {
+		loader := NewGameFactoryLoader(&mockFactoryCaller{gameCountErr: true})
+		_, err := loader.FetchGamesFrom(context.Background(), 0)
+		require.ErrorIs(t, err, gameCountErr)
+	})
+
+	t.Run("GameAtIndexError", func(t *testing.T) {
+		loader := NewGameFactoryLoader(&mockFactoryCaller{games: games, gameAtIndexErr: true})
+		_, err := loader.FetchGamesFrom(context.Background(), 0)
+		require.ErrorIs(t, err, gameAtIndexErr)
+	})
+}
+
+type mockFactoryCaller struct {
+	gameCountErr   bool
+	gameAtIndexErr bool
+	games          []FaultDisputeGame
+}
+
+func (m *mockFactoryCaller) GameCount(_ *bind.CallOpts) (*big.Int, error) {
+	if m.gameCountErr {
+		return nil, gameCountErr
+	}
+	return big.NewInt(int64(len(m.games))), nil
+}
+
+func (m *mockFactoryCaller) GameAtIndex(_ *bind.CallOpts, _index *big.Int) (struct {
+	Proxy     common.Address
+	Timestamp *big.Int
+}, error) {
+	if m.gameAtIndexErr {
+		return struct {
+			Proxy     common.Address
+			Timestamp *big.Int
+		}{}, gameAtIndexErr
+	}
+	game := m.games[_index.Uint64()]
+	return struct {
+		Proxy     common.Address
+		Timestamp *big.Int
+	}{
+		Proxy:     game.Proxy,
+		Timestamp: new(big.Int).SetUint64(game.Timestamp),
+	}, nil
+}
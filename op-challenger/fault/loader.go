@@ -20,6 +20,7 @@ type MinimalFaultDisputeGameCaller interface {
 	}, error)
 	ClaimDataLen(opts *bind.CallOpts) (*big.Int, error)
 	MAXGAMEDEPTH(opts *bind.CallOpts) (*big.Int, error)
+	GAMEDURATION(opts *bind.CallOpts) (uint64, error)
 	ABSOLUTEPRESTATE(opts *bind.CallOpts) ([32]byte, error)
 }
 
@@ -27,6 +28,7 @@ type MinimalFaultDisputeGameCaller interface {
 type Loader interface {
 	FetchClaims(ctx context.Context) ([]types.Claim, error)
 	FetchGameDepth(ctx context.Context) (uint64, error)
+	FetchGameDuration(ctx context.Context) (uint64, error)
 	FetchAbsolutePrestateHash(ctx context.Context) ([]byte, error)
 }
 
@@ -56,6 +58,15 @@ func (l *loader) FetchGameDepth(ctx context.Context) (uint64, error) {
 	return gameDepth.Uint64(), nil
 }
 
+// FetchGameDuration fetches the total duration of the game in seconds from the fault dispute game.
+func (l *loader) FetchGameDuration(ctx context.Context) (uint64, error) {
+	callOpts := bind.CallOpts{
+		Context: ctx,
+	}
+
+	return l.caller.GAMEDURATION(&callOpts)
+}
+
 // fetchClaim fetches a single [Claim] with a hydrated parent.
 func (l *loader) fetchClaim(ctx context.Context, arrIndex uint64) (types.Claim, error) {
 	callOpts := bind.CallOpts{
@@ -73,7 +84,7 @@ func (l *loader) fetchClaim(ctx context.Context, arrIndex uint64) (types.Claim,
 			Position: types.NewPositionFromGIndex(fetchedClaim.Position.Uint64()),
 		},
 		Countered:           fetchedClaim.Countered,
-		Clock:               fetchedClaim.Clock.Uint64(),
+		Clock:               types.NewClockFromPacked(fetchedClaim.Clock),
 		ContractIndex:       int(arrIndex),
 		ParentContractIndex: int(fetchedClaim.ParentIndex),
 	}
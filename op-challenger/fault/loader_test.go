@@ -18,6 +18,7 @@ var (
 	mockClaimLenError     = fmt.Errorf("claim len errored")
 	mockMaxGameDepthError = fmt.Errorf("max game depth errored")
 	mockPrestateError     = fmt.Errorf("prestate errored")
+	mockGameDurationError = fmt.Errorf("game duration errored")
 )
 
 type mockCaller struct {
@@ -25,7 +26,9 @@ type mockCaller struct {
 	claimLenError     bool
 	maxGameDepthError bool
 	prestateError     bool
+	gameDurationError bool
 	maxGameDepth      uint64
+	gameDuration      uint64
 	currentIndex      uint64
 	returnClaims      []struct {
 		ParentIndex uint32
@@ -55,7 +58,8 @@ func newMockCaller() *mockCaller {
 				Claim:     [32]byte{0x01},
 				Position:  big.NewInt(0),
 				Countered: false,
-				Clock:     big.NewInt(0),
+				// Duration of 5 seconds in the high-order bits, timestamp of 100 in the low-order bits
+				Clock: new(big.Int).Or(new(big.Int).Lsh(big.NewInt(5), 64), big.NewInt(100)),
 			},
 			{
 				Claim:     [32]byte{0x02},
@@ -102,6 +106,13 @@ func (m *mockCaller) MAXGAMEDEPTH(opts *bind.CallOpts) (*big.Int, error) {
 	return big.NewInt(int64(m.maxGameDepth)), nil
 }
 
+func (m *mockCaller) GAMEDURATION(opts *bind.CallOpts) (uint64, error) {
+	if m.gameDurationError {
+		return 0, mockGameDurationError
+	}
+	return m.gameDuration, nil
+}
+
 func (m *mockCaller) ABSOLUTEPRESTATE(opts *bind.CallOpts) ([32]byte, error) {
 	if m.prestateError {
 		return [32]byte{}, mockPrestateError
@@ -130,6 +141,26 @@ func TestLoader_FetchGameDepth(t *testing.T) {
 	})
 }
 
+// TestLoader_FetchGameDuration tests [loader.FetchGameDuration].
+func TestLoader_FetchGameDuration(t *testing.T) {
+	t.Run("Succeeds", func(t *testing.T) {
+		mockCaller := newMockCaller()
+		mockCaller.gameDuration = 600
+		loader := NewLoader(mockCaller)
+		duration, err := loader.FetchGameDuration(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(600), duration)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		mockCaller := newMockCaller()
+		mockCaller.gameDurationError = true
+		loader := NewLoader(mockCaller)
+		_, err := loader.FetchGameDuration(context.Background())
+		require.ErrorIs(t, err, mockGameDurationError)
+	})
+}
+
 // TestLoader_FetchAbsolutePrestateHash tests the [loader.FetchAbsolutePrestateHash] function.
 func TestLoader_FetchAbsolutePrestateHash(t *testing.T) {
 	t.Run("Succeeds", func(t *testing.T) {
@@ -168,7 +199,7 @@ func TestLoader_FetchClaims_Succeeds(t *testing.T) {
 				Position: types.NewPositionFromGIndex(expectedClaims[0].Position.Uint64()),
 			},
 			Countered:     false,
-			Clock:         uint64(0),
+			Clock:         types.Clock{},
 			ContractIndex: 0,
 		},
 		{
@@ -181,7 +212,7 @@ func TestLoader_FetchClaims_Succeeds(t *testing.T) {
 				Position: types.NewPositionFromGIndex(expectedClaims[1].Position.Uint64()),
 			},
 			Countered:     false,
-			Clock:         uint64(0),
+			Clock:         types.Clock{Duration: 5, Timestamp: 100},
 			ContractIndex: 1,
 		},
 		{
@@ -194,7 +225,7 @@ func TestLoader_FetchClaims_Succeeds(t *testing.T) {
 				Position: types.NewPositionFromGIndex(expectedClaims[2].Position.Uint64()),
 			},
 			Countered:     false,
-			Clock:         uint64(0),
+			Clock:         types.Clock{},
 			ContractIndex: 2,
 		},
 	}, claims)
@@ -1,32 +1,127 @@
 package fault
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
 )
 
-type GameInfo interface {
-	GetGameStatus(context.Context) (types.GameStatus, error)
-	LogGameInfo(ctx context.Context)
+type gameSource interface {
+	FetchGamesFrom(ctx context.Context, startIndex uint64) ([]FaultDisputeGame, error)
 }
 
-type Actor interface {
-	Act(ctx context.Context) error
+// gameStore persists the games being monitored so they can be resumed after a restart.
+type gameStore interface {
+	NextGameIndex() (uint64, error)
+	SetNextGameIndex(index uint64) error
+	TrackedGames() ([]store.TrackedGame, error)
+	TrackGame(game store.TrackedGame) error
+	UntrackGame(addr common.Address) error
 }
 
-func MonitorGame(ctx context.Context, logger log.Logger, agreeWithProposedOutput bool, actor Actor, caller GameInfo) error {
-	logger.Info("Monitoring fault dispute game", "agreeWithOutput", agreeWithProposedOutput)
+type gamePlayer interface {
+	ProgressGame(ctx context.Context) bool
+	Summary() types.GameSummary
+	// Close releases the resources held by the player once the game is no longer played.
+	Close() error
+}
+
+// monitorMetricer records metrics about the games being progressed.
+type monitorMetricer interface {
+	RecordTrackedGames(count int)
+	RecordClaims(agree int, disagree int)
+}
+
+// gameMetadata is the information about a game required to decide whether it should be played.
+type gameMetadata struct {
+	GameType uint8
+	Status   types.GameStatus
+}
+
+type metadataLoader func(ctx context.Context, addr common.Address) (gameMetadata, error)
+type playerCreator func(ctx context.Context, addr common.Address) (gamePlayer, error)
+type gameDataRemover func(addr common.Address) error
+
+// gameMonitor discovers the games created by the dispute game factory and progresses
+// each active game, dropping games once they are resolved.
+type gameMonitor struct {
+	logger         log.Logger
+	source         gameSource
+	store          gameStore
+	metrics        monitorMetricer
+	loadMetadata   metadataLoader
+	createPlayer   playerCreator
+	removeGameData gameDataRemover
+	allowedGames   []common.Address
+	allowedTypes   []uint8
+	maxConcurrency int
+	pollInterval   time.Duration
+
+	// nextIndex is the index of the next game in the factory that has not yet been considered.
+	nextIndex uint64
+	// retry holds the games that could not be loaded and should be considered again on the next update.
+	retry []FaultDisputeGame
+
+	// playersLock guards players, which is read when reporting the status of games.
+	playersLock sync.Mutex
+	players     map[common.Address]gamePlayer
+}
 
+func newGameMonitor(
+	logger log.Logger,
+	source gameSource,
+	store gameStore,
+	m monitorMetricer,
+	loadMetadata metadataLoader,
+	createPlayer playerCreator,
+	removeGameData gameDataRemover,
+	allowedGames []common.Address,
+	allowedTypes []uint8,
+	maxConcurrency uint,
+	pollInterval time.Duration,
+) *gameMonitor {
+	return &gameMonitor{
+		logger:         logger,
+		source:         source,
+		store:          store,
+		metrics:        m,
+		loadMetadata:   loadMetadata,
+		createPlayer:   createPlayer,
+		removeGameData: removeGameData,
+		allowedGames:   allowedGames,
+		allowedTypes:   allowedTypes,
+		maxConcurrency: int(maxConcurrency),
+		pollInterval:   pollInterval,
+		players:        make(map[common.Address]gamePlayer),
+	}
+}
+
+// MonitorGames polls the factory for new games and progresses all active games until ctx is done.
+// Games tracked before a restart are resumed first.
+func (m *gameMonitor) MonitorGames(ctx context.Context) error {
+	if err := m.restore(); err != nil {
+		return fmt.Errorf("failed to restore tracked games: %w", err)
+	}
+	m.logger.Info("Monitoring dispute games", "allowedGames", m.allowedGames, "allowedTypes", m.allowedTypes,
+		"maxConcurrency", m.maxConcurrency, "nextIndex", m.nextIndex)
+
+	defer m.closePlayers()
 	for {
-		done := progressGame(ctx, logger, agreeWithProposedOutput, actor, caller)
-		if done {
-			return nil
+		if err := m.updateGames(ctx); err != nil {
+			m.logger.Error("Failed to load games", "err", err)
 		}
+		m.progressGames(ctx)
 		select {
-		case <-time.After(300 * time.Millisecond):
+		case <-time.After(m.pollInterval):
 		// Continue
 		case <-ctx.Done():
 			return ctx.Err()
@@ -34,30 +129,223 @@ func MonitorGame(ctx context.Context, logger log.Logger, agreeWithProposedOutput
 	}
 }
 
-// progressGame checks the current state of the game, and attempts to progress it by performing moves, steps or resolving
-// Returns true if the game is complete or false if it needs to be monitored further
-func progressGame(ctx context.Context, logger log.Logger, agreeWithProposedOutput bool, actor Actor, caller GameInfo) bool {
-	logger.Trace("Checking if actions are required")
-	if err := actor.Act(ctx); err != nil {
-		logger.Error("Error when acting on game", "err", err)
+// closePlayers closes the players of all games being progressed.
+func (m *gameMonitor) closePlayers() {
+	m.playersLock.Lock()
+	defer m.playersLock.Unlock()
+	for addr, player := range m.players {
+		if err := player.Close(); err != nil {
+			m.logger.Error("Failed to close game player", "game", addr, "err", err)
+		}
+	}
+}
+
+// restore loads the games that were being tracked before a restart so they are considered again on the next update.
+func (m *gameMonitor) restore() error {
+	nextIndex, err := m.store.NextGameIndex()
+	if err != nil {
+		return err
+	}
+	tracked, err := m.store.TrackedGames()
+	if err != nil {
+		return err
+	}
+	m.nextIndex = nextIndex
+	for _, game := range tracked {
+		m.retry = append(m.retry, FaultDisputeGame{Index: game.Index, Proxy: game.Proxy, Timestamp: game.Timestamp})
+	}
+	if len(tracked) > 0 {
+		m.logger.Info("Resuming previously tracked games", "count", len(tracked))
+	}
+	return nil
+}
+
+// updateGames fetches any games created since the last update and creates a player for each
+// game that should be played. Games that could not be loaded are retried on the next update.
+// New games are recorded in the store before they are considered so they are not missed after a restart.
+func (m *gameMonitor) updateGames(ctx context.Context) error {
+	games, err := m.source.FetchGamesFrom(ctx, m.nextIndex)
+	if err != nil {
+		return err
+	}
+	for _, game := range games {
+		if err := m.store.TrackGame(store.TrackedGame{Index: game.Index, Proxy: game.Proxy, Timestamp: game.Timestamp}); err != nil {
+			return fmt.Errorf("failed to record game %v: %w", game.Proxy, err)
+		}
+	}
+	if len(games) > 0 {
+		nextIndex := games[len(games)-1].Index + 1
+		if err := m.store.SetNextGameIndex(nextIndex); err != nil {
+			return fmt.Errorf("failed to record next game index: %w", err)
+		}
+		m.nextIndex = nextIndex
+	}
+	candidates := append(m.retry, games...)
+	m.retry = nil
+	var lastErr error
+	for _, game := range candidates {
+		if err := m.considerGame(ctx, game); err != nil {
+			m.logger.Warn("Failed to load game, will retry", "game", game.Proxy, "err", err)
+			m.retry = append(m.retry, game)
+			lastErr = fmt.Errorf("failed to load game %v: %w", game.Proxy, err)
+		}
+	}
+	return lastErr
+}
+
+// considerGame starts playing the game if it is allowed and still in progress.
+// Games that will not be played are no longer tracked.
+func (m *gameMonitor) considerGame(ctx context.Context, game FaultDisputeGame) error {
+	logger := m.logger.New("game", game.Proxy)
+	if !m.allowedGame(game.Proxy) {
+		logger.Debug("Skipping game not on allow list")
+		return m.untrack(game.Proxy)
+	}
+	metadata, err := m.loadMetadata(ctx, game.Proxy)
+	if err != nil {
+		return err
+	}
+	if !m.allowedGameType(metadata.GameType) {
+		logger.Debug("Skipping game with disallowed game type", "type", metadata.GameType)
+		return m.untrack(game.Proxy)
+	}
+	if metadata.Status != types.GameStatusInProgress {
+		logger.Debug("Skipping resolved game", "status", GameStatusString(metadata.Status))
+		return m.untrack(game.Proxy)
+	}
+	player, err := m.createPlayer(ctx, game.Proxy)
+	if err != nil {
+		return fmt.Errorf("failed to create game player: %w", err)
+	}
+	logger.Info("Tracking new game", "type", metadata.GameType)
+	m.playersLock.Lock()
+	defer m.playersLock.Unlock()
+	m.players[game.Proxy] = player
+	return nil
+}
+
+// progressGames progresses every tracked game concurrently, limited to maxConcurrency games at a time.
+// Games that have been resolved are no longer tracked.
+func (m *gameMonitor) progressGames(ctx context.Context) {
+	type result struct {
+		addr common.Address
+		done bool
+	}
+	players := m.activePlayers()
+	results := make(chan result, len(players))
+	var group errgroup.Group
+	group.SetLimit(m.maxConcurrency)
+	for addr, player := range players {
+		addr, player := addr, player
+		group.Go(func() error {
+			results <- result{addr: addr, done: player.ProgressGame(ctx)}
+			return nil
+		})
+	}
+	_ = group.Wait()
+	close(results)
+	for res := range results {
+		if res.done {
+			m.logger.Info("Game complete, no longer tracking", "game", res.addr)
+			m.playersLock.Lock()
+			player := m.players[res.addr]
+			delete(m.players, res.addr)
+			m.playersLock.Unlock()
+			if err := player.Close(); err != nil {
+				m.logger.Error("Failed to close player of completed game", "game", res.addr, "err", err)
+			}
+			if err := m.untrack(res.addr); err != nil {
+				m.logger.Error("Failed to remove completed game from store", "game", res.addr, "err", err)
+			}
+		}
 	}
-	if status, err := caller.GetGameStatus(ctx); err != nil {
-		logger.Warn("Unable to retrieve game status", "err", err)
-	} else if status != 0 {
-		var expectedStatus types.GameStatus
-		if agreeWithProposedOutput {
-			expectedStatus = types.GameStatusChallengerWon
-		} else {
-			expectedStatus = types.GameStatusDefenderWon
+	m.recordMetrics()
+}
+
+// recordMetrics records the number of games being progressed and the claims in them.
+func (m *gameMonitor) recordMetrics() {
+	var agree, disagree int
+	games := m.Games()
+	for _, game := range games {
+		for _, claim := range game.Claims {
+			if claim.Agree {
+				agree++
+			} else {
+				disagree++
+			}
 		}
-		if expectedStatus == status {
-			logger.Info("Game won", "status", GameStatusString(status))
-		} else {
-			logger.Error("Game lost", "status", GameStatusString(status))
+	}
+	m.metrics.RecordTrackedGames(len(games))
+	m.metrics.RecordClaims(agree, disagree)
+}
+
+// activePlayers returns a copy of the current players so they can be progressed without holding the lock.
+func (m *gameMonitor) activePlayers() map[common.Address]gamePlayer {
+	m.playersLock.Lock()
+	defer m.playersLock.Unlock()
+	players := make(map[common.Address]gamePlayer, len(m.players))
+	for addr, player := range m.players {
+		players[addr] = player
+	}
+	return players
+}
+
+// Games returns a summary of every game currently being progressed, ordered by address.
+func (m *gameMonitor) Games() []types.GameSummary {
+	players := m.activePlayers()
+	games := make([]types.GameSummary, 0, len(players))
+	for _, player := range players {
+		games = append(games, player.Summary())
+	}
+	sort.Slice(games, func(i, j int) bool {
+		return bytes.Compare(games[i].Address[:], games[j].Address[:]) < 0
+	})
+	return games
+}
+
+// Game returns a summary of the game at addr. Returns false if the game is not being progressed.
+func (m *gameMonitor) Game(addr common.Address) (types.GameSummary, bool) {
+	m.playersLock.Lock()
+	player, ok := m.players[addr]
+	m.playersLock.Unlock()
+	if !ok {
+		return types.GameSummary{}, false
+	}
+	return player.Summary(), true
+}
+
+// untrack removes the game from the store so it is not resumed after a restart,
+// and deletes any data stored on disk for the game.
+func (m *gameMonitor) untrack(addr common.Address) error {
+	if err := m.store.UntrackGame(addr); err != nil {
+		return fmt.Errorf("failed to untrack game: %w", err)
+	}
+	if err := m.removeGameData(addr); err != nil {
+		return fmt.Errorf("failed to remove game data: %w", err)
+	}
+	return nil
+}
+
+func (m *gameMonitor) allowedGame(addr common.Address) bool {
+	if len(m.allowedGames) == 0 {
+		return true
+	}
+	for _, allowed := range m.allowedGames {
+		if allowed == addr {
+			return true
 		}
+	}
+	return false
+}
+
+func (m *gameMonitor) allowedGameType(gameType uint8) bool {
+	if len(m.allowedTypes) == 0 {
 		return true
-	} else {
-		caller.LogGameInfo(ctx)
+	}
+	for _, allowed := range m.allowedTypes {
+		if allowed == gameType {
+			return true
+		}
 	}
 	return false
 }
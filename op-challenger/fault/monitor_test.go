@@ -3,133 +3,390 @@ package fault
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/require"
 )
 
+var (
+	addr1 = common.Address{0xaa}
+	addr2 = common.Address{0xbb}
+	addr3 = common.Address{0xcc}
+)
+
 func TestMonitorExitsWhenContextDone(t *testing.T) {
-	logger := testlog.Logger(t, log.LvlDebug)
-	actor := &stubActor{}
-	gameInfo := &stubGameInfo{}
+	monitor, source, players := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}}
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	err := MonitorGame(ctx, logger, true, actor, gameInfo)
+	err := monitor.MonitorGames(ctx)
 	require.ErrorIs(t, err, context.Canceled)
+	require.True(t, players.players[addr1].closed, "should close players on exit")
+}
+
+func TestMonitorCreatesPlayersForNewGames(t *testing.T) {
+	monitor, source, players := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Len(t, monitor.players, 2)
+	require.Contains(t, monitor.players, addr1)
+	require.Contains(t, monitor.players, addr2)
+	require.Equal(t, 2, players.created)
+
+	// Existing games should not be loaded again
+	source.games = append(source.games, FaultDisputeGame{Index: 2, Proxy: addr3})
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Len(t, monitor.players, 3)
+	require.Equal(t, 3, players.created)
+	require.Equal(t, uint64(2), source.lastStartIndex)
+}
+
+func TestMonitorOnlyPlaysAllowedGames(t *testing.T) {
+	monitor, source, _ := setupMonitorTest(t, []common.Address{addr2}, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Len(t, monitor.players, 1)
+	require.Contains(t, monitor.players, addr2)
+}
+
+func TestMonitorOnlyPlaysAllowedGameTypes(t *testing.T) {
+	monitor, source, _ := setupMonitorTest(t, nil, []uint8{1})
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+	source.metadata[addr1] = gameMetadata{GameType: 0}
+	source.metadata[addr2] = gameMetadata{GameType: 1}
+
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Len(t, monitor.players, 1)
+	require.Contains(t, monitor.players, addr2)
+}
+
+func TestMonitorSkipsResolvedGames(t *testing.T) {
+	monitor, source, _ := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+	source.metadata[addr1] = gameMetadata{Status: types.GameStatusDefenderWon}
+
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Len(t, monitor.players, 1)
+	require.Contains(t, monitor.players, addr2)
+}
+
+func TestMonitorRetriesGamesWhenPlayerCreationFails(t *testing.T) {
+	monitor, source, players := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+	players.err = errors.New("boom")
+
+	require.ErrorIs(t, monitor.updateGames(context.Background()), players.err)
+	require.Empty(t, monitor.players)
+	require.Equal(t, uint64(2), monitor.nextIndex)
+
+	players.err = nil
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Len(t, monitor.players, 2)
+	require.Equal(t, uint64(2), source.lastStartIndex)
+}
+
+func TestMonitorRetriesGamesWhenMetadataUnavailable(t *testing.T) {
+	monitor, source, _ := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}, {Index: 2, Proxy: addr3}}
+	source.metadataErr[addr2] = errors.New("boom")
+
+	require.ErrorIs(t, monitor.updateGames(context.Background()), source.metadataErr[addr2])
+	require.Len(t, monitor.players, 2)
+	require.Contains(t, monitor.players, addr1)
+	require.Contains(t, monitor.players, addr3)
+	require.Equal(t, uint64(3), monitor.nextIndex)
+
+	delete(source.metadataErr, addr2)
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Len(t, monitor.players, 3)
+	require.Contains(t, monitor.players, addr2)
+	require.Empty(t, monitor.retry)
+}
+
+func TestMonitorDropsCompletedGames(t *testing.T) {
+	monitor, source, players := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+	require.NoError(t, monitor.updateGames(context.Background()))
+
+	players.players[addr1].done = true
+	monitor.progressGames(context.Background())
+	require.Len(t, monitor.players, 1)
+	require.Contains(t, monitor.players, addr2)
+	require.Equal(t, 1, players.players[addr1].progressCount)
+	require.Equal(t, 1, players.players[addr2].progressCount)
+
+	monitor.progressGames(context.Background())
+	require.Equal(t, 1, players.players[addr1].progressCount)
+	require.Equal(t, 2, players.players[addr2].progressCount)
+}
+
+func TestMonitorReportsGameSummaries(t *testing.T) {
+	monitor, source, players := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr2}, {Index: 1, Proxy: addr1}}
+	require.NoError(t, monitor.updateGames(context.Background()))
+	players.players[addr1].claims = []types.ClaimSummary{{ContractIndex: 0}}
+
+	games := monitor.Games()
+	require.Len(t, games, 2)
+	require.Equal(t, addr1, games[0].Address, "should order games by address")
+	require.Equal(t, 1, games[0].ClaimCount)
+	require.Equal(t, addr2, games[1].Address)
+
+	game, ok := monitor.Game(addr1)
+	require.True(t, ok)
+	require.Equal(t, games[0], game)
+
+	_, ok = monitor.Game(addr3)
+	require.False(t, ok)
+}
+
+func TestMonitorRecordsMetrics(t *testing.T) {
+	monitor, source, players, m := setupMonitorTestWithMetrics(t, store.NewMemoryStore(), nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+	require.NoError(t, monitor.updateGames(context.Background()))
+	players.players[addr1].claims = []types.ClaimSummary{{Agree: true}, {Agree: false}}
+	players.players[addr2].claims = []types.ClaimSummary{{Agree: false}, {Agree: false}}
+
+	monitor.progressGames(context.Background())
+	require.Equal(t, 2, m.trackedGames)
+	require.Equal(t, 1, m.agree)
+	require.Equal(t, 3, m.disagree)
+
+	players.players[addr2].done = true
+	monitor.progressGames(context.Background())
+	require.Equal(t, 1, m.trackedGames)
+	require.Equal(t, 1, m.agree)
+	require.Equal(t, 1, m.disagree)
+}
+
+func TestMonitorRecordsTrackedGames(t *testing.T) {
+	gameStore := store.NewMemoryStore()
+	monitor, source, _ := setupMonitorTestWithStore(t, gameStore, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1, Timestamp: 10}, {Index: 1, Proxy: addr2, Timestamp: 20}}
+	source.metadata[addr1] = gameMetadata{Status: types.GameStatusDefenderWon}
+
+	require.NoError(t, monitor.updateGames(context.Background()))
+	tracked, err := gameStore.TrackedGames()
+	require.NoError(t, err)
+	require.Equal(t, []store.TrackedGame{{Index: 1, Proxy: addr2, Timestamp: 20}}, tracked, "should not track resolved game")
+	nextIndex, err := gameStore.NextGameIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), nextIndex)
+}
+
+func TestMonitorKeepsTrackingGamesThatFailToLoad(t *testing.T) {
+	gameStore := store.NewMemoryStore()
+	monitor, source, _ := setupMonitorTestWithStore(t, gameStore, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}}
+	source.metadataErr[addr1] = errors.New("boom")
+
+	require.Error(t, monitor.updateGames(context.Background()))
+	tracked, err := gameStore.TrackedGames()
+	require.NoError(t, err)
+	require.Equal(t, []store.TrackedGame{{Index: 0, Proxy: addr1}}, tracked)
 }
 
-func TestProgressGameAndLogState(t *testing.T) {
-	logger, _, actor, gameInfo := setupProgressGameTest(t)
-	done := progressGame(context.Background(), logger, true, actor, gameInfo)
-	require.False(t, done, "should not be done")
-	require.Equal(t, 1, actor.callCount, "should perform next actions")
-	require.Equal(t, 1, gameInfo.logCount, "should log latest game state")
-}
-
-func TestProgressGame_LogErrorFromAct(t *testing.T) {
-	logger, handler, actor, gameInfo := setupProgressGameTest(t)
-	actor.err = errors.New("Boom")
-	done := progressGame(context.Background(), logger, true, actor, gameInfo)
-	require.False(t, done, "should not be done")
-	require.Equal(t, 1, actor.callCount, "should perform next actions")
-	require.Equal(t, 1, gameInfo.logCount, "should log latest game state")
-	errLog := handler.FindLog(log.LvlError, "Error when acting on game")
-	require.NotNil(t, errLog, "should log error")
-	require.Equal(t, actor.err, errLog.GetContextValue("err"))
-}
-
-func TestProgressGame_LogErrorWhenGameLost(t *testing.T) {
-	tests := []struct {
-		name            string
-		status          types.GameStatus
-		agreeWithOutput bool
-		logLevel        log.Lvl
-		logMsg          string
-		statusText      string
-	}{
-		{
-			name:            "GameLostAsDefender",
-			status:          types.GameStatusChallengerWon,
-			agreeWithOutput: false,
-			logLevel:        log.LvlError,
-			logMsg:          "Game lost",
-			statusText:      "Challenger Won",
-		},
-		{
-			name:            "GameLostAsChallenger",
-			status:          types.GameStatusDefenderWon,
-			agreeWithOutput: true,
-			logLevel:        log.LvlError,
-			logMsg:          "Game lost",
-			statusText:      "Defender Won",
-		},
-		{
-			name:            "GameWonAsDefender",
-			status:          types.GameStatusDefenderWon,
-			agreeWithOutput: false,
-			logLevel:        log.LvlInfo,
-			logMsg:          "Game won",
-			statusText:      "Defender Won",
-		},
-		{
-			name:            "GameWonAsChallenger",
-			status:          types.GameStatusChallengerWon,
-			agreeWithOutput: true,
-			logLevel:        log.LvlInfo,
-			logMsg:          "Game won",
-			statusText:      "Challenger Won",
-		},
+func TestMonitorResumesTrackedGames(t *testing.T) {
+	gameStore := store.NewMemoryStore()
+	require.NoError(t, gameStore.SetNextGameIndex(2))
+	require.NoError(t, gameStore.TrackGame(store.TrackedGame{Index: 0, Proxy: addr1}))
+	monitor, source, players := setupMonitorTestWithStore(t, gameStore, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}, {Index: 2, Proxy: addr3}}
+
+	require.NoError(t, monitor.restore())
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Equal(t, uint64(2), source.lastStartIndex)
+	require.Len(t, monitor.players, 2)
+	require.Contains(t, monitor.players, addr1)
+	require.Contains(t, monitor.players, addr3)
+	require.Equal(t, 2, players.created)
+}
+
+func TestMonitorUntracksCompletedGames(t *testing.T) {
+	gameStore := store.NewMemoryStore()
+	monitor, source, players := setupMonitorTestWithStore(t, gameStore, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.NoError(t, gameStore.GameStore(addr1).Put("key", "value"))
+
+	players.players[addr1].done = true
+	monitor.progressGames(context.Background())
+	tracked, err := gameStore.TrackedGames()
+	require.NoError(t, err)
+	require.Equal(t, []store.TrackedGame{{Index: 1, Proxy: addr2}}, tracked)
+	var value string
+	ok, err := gameStore.GameStore(addr1).Get("key", &value)
+	require.NoError(t, err)
+	require.False(t, ok, "should delete game data")
+	require.Equal(t, []common.Address{addr1}, players.removed, "should delete game data on disk")
+	require.True(t, players.players[addr1].closed, "should close player of completed game")
+	require.False(t, players.players[addr2].closed)
+}
+
+func TestMonitorRemovesDataOfSkippedGames(t *testing.T) {
+	monitor, source, players := setupMonitorTest(t, nil, nil)
+	source.games = []FaultDisputeGame{{Index: 0, Proxy: addr1}, {Index: 1, Proxy: addr2}}
+	source.metadata[addr1] = gameMetadata{Status: types.GameStatusDefenderWon}
+
+	require.NoError(t, monitor.updateGames(context.Background()))
+	require.Equal(t, []common.Address{addr1}, players.removed)
+}
+
+func TestMonitorLimitsConcurrency(t *testing.T) {
+	monitor, source, players := setupMonitorTest(t, nil, nil)
+	monitor.maxConcurrency = 2
+	for i := 0; i < 10; i++ {
+		source.games = append(source.games, FaultDisputeGame{Index: uint64(i), Proxy: common.Address{byte(i)}})
 	}
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			logger, handler, actor, gameInfo := setupProgressGameTest(t)
-			gameInfo.status = test.status
-
-			done := progressGame(context.Background(), logger, test.agreeWithOutput, actor, gameInfo)
-			require.True(t, done, "should be done")
-			require.Equal(t, 0, gameInfo.logCount, "should not log latest game state")
-			errLog := handler.FindLog(test.logLevel, test.logMsg)
-			require.NotNil(t, errLog, "should log game result")
-			require.Equal(t, test.statusText, errLog.GetContextValue("status"))
-		})
+	require.NoError(t, monitor.updateGames(context.Background()))
+
+	tracker := &concurrencyTracker{}
+	for _, player := range players.players {
+		player.tracker = tracker
 	}
+	monitor.progressGames(context.Background())
+	require.Equal(t, 2, tracker.max)
 }
 
-func setupProgressGameTest(t *testing.T) (log.Logger, *testlog.CapturingHandler, *stubActor, *stubGameInfo) {
+func setupMonitorTest(t *testing.T, allowedGames []common.Address, allowedTypes []uint8) (*gameMonitor, *stubGameSource, *stubPlayerCreator) {
+	return setupMonitorTestWithStore(t, store.NewMemoryStore(), allowedGames, allowedTypes)
+}
+
+func setupMonitorTestWithStore(t *testing.T, gameStore gameStore, allowedGames []common.Address, allowedTypes []uint8) (*gameMonitor, *stubGameSource, *stubPlayerCreator) {
+	monitor, source, players, _ := setupMonitorTestWithMetrics(t, gameStore, allowedGames, allowedTypes)
+	return monitor, source, players
+}
+
+func setupMonitorTestWithMetrics(t *testing.T, gameStore gameStore, allowedGames []common.Address, allowedTypes []uint8) (*gameMonitor, *stubGameSource, *stubPlayerCreator, *stubMonitorMetrics) {
 	logger := testlog.Logger(t, log.LvlDebug)
-	handler := &testlog.CapturingHandler{
-		Delegate: logger.GetHandler(),
+	source := &stubGameSource{
+		metadata:    make(map[common.Address]gameMetadata),
+		metadataErr: make(map[common.Address]error),
+	}
+	players := &stubPlayerCreator{
+		players: make(map[common.Address]*stubPlayer),
+	}
+	m := &stubMonitorMetrics{}
+	monitor := newGameMonitor(logger, source, gameStore, m, source.LoadMetadata, players.CreatePlayer, players.RemoveGameData, allowedGames, allowedTypes, 4, time.Minute)
+	return monitor, source, players, m
+}
+
+type stubMonitorMetrics struct {
+	trackedGames int
+	agree        int
+	disagree     int
+}
+
+func (s *stubMonitorMetrics) RecordTrackedGames(count int) {
+	s.trackedGames = count
+}
+
+func (s *stubMonitorMetrics) RecordClaims(agree int, disagree int) {
+	s.agree = agree
+	s.disagree = disagree
+}
+
+type stubGameSource struct {
+	games          []FaultDisputeGame
+	lastStartIndex uint64
+	metadata       map[common.Address]gameMetadata
+	metadataErr    map[common.Address]error
+}
+
+func (s *stubGameSource) FetchGamesFrom(_ context.Context, startIndex uint64) ([]FaultDisputeGame, error) {
+	s.lastStartIndex = startIndex
+	var games []FaultDisputeGame
+	for _, game := range s.games {
+		if game.Index >= startIndex {
+			games = append(games, game)
+		}
 	}
-	logger.SetHandler(handler)
-	actor := &stubActor{}
-	gameInfo := &stubGameInfo{}
-	return logger, handler, actor, gameInfo
+	return games, nil
 }
 
-type stubActor struct {
-	callCount int
-	err       error
+func (s *stubGameSource) LoadMetadata(_ context.Context, addr common.Address) (gameMetadata, error) {
+	if err, ok := s.metadataErr[addr]; ok {
+		return gameMetadata{}, err
+	}
+	return s.metadata[addr], nil
 }
 
-func (a *stubActor) Act(ctx context.Context) error {
-	a.callCount++
-	return a.err
+type stubPlayerCreator struct {
+	created int
+	err     error
+	players map[common.Address]*stubPlayer
+	removed []common.Address
+}
+
+func (s *stubPlayerCreator) RemoveGameData(addr common.Address) error {
+	s.removed = append(s.removed, addr)
+	return nil
+}
+
+func (s *stubPlayerCreator) CreatePlayer(_ context.Context, addr common.Address) (gamePlayer, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.created++
+	player := &stubPlayer{addr: addr}
+	s.players[addr] = player
+	return player, nil
 }
 
-type stubGameInfo struct {
-	status   types.GameStatus
-	err      error
-	logCount int
+type stubPlayer struct {
+	addr          common.Address
+	claims        []types.ClaimSummary
+	done          bool
+	closed        bool
+	progressCount int
+	tracker       *concurrencyTracker
 }
 
-func (s *stubGameInfo) GetGameStatus(ctx context.Context) (types.GameStatus, error) {
-	return s.status, s.err
+func (s *stubPlayer) ProgressGame(_ context.Context) bool {
+	if s.tracker != nil {
+		s.tracker.enter()
+		time.Sleep(10 * time.Millisecond)
+		s.tracker.exit()
+	}
+	s.progressCount++
+	return s.done
+}
+
+func (s *stubPlayer) Summary() types.GameSummary {
+	return types.GameSummary{Address: s.addr, ClaimCount: len(s.claims), Claims: s.claims}
+}
+
+func (s *stubPlayer) Close() error {
+	s.closed = true
+	return nil
+}
+
+type concurrencyTracker struct {
+	lock    sync.Mutex
+	current int
+	max     int
+}
+
+func (c *concurrencyTracker) enter() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.current++
+	if c.current > c.max {
+		c.max = c.current
+	}
 }
 
-func (s *stubGameInfo) LogGameInfo(ctx context.Context) {
-	s.logCount++
+func (c *concurrencyTracker) exit() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.current--
 }
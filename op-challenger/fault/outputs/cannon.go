@@ -0,0 +1,62 @@
+package outputs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// NewOutputCannonTraceProvider creates an [OutputTraceProvider] that uses cannon to execute the single disputed block.
+// Each block's cannon trace is stored in its own subdirectory of dir.
+func NewOutputCannonTraceProvider(
+	ctx context.Context,
+	logger log.Logger,
+	m cannon.Metricer,
+	cfg *config.Config,
+	caller cannon.GameInputsSource,
+	rollupClient OutputRollupClient,
+	dir string,
+	gameStore *store.GameStore,
+	maxDepth uint64,
+) (*OutputTraceProvider, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	l1Head, err := caller.L1Head(opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetch L1 head: %w", err)
+	}
+	proposals, err := caller.Proposals(opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetch proposals: %w", err)
+	}
+	if cfg.OutputSplitDepth >= maxDepth {
+		return nil, fmt.Errorf("output split depth %v must be less than game depth %v", cfg.OutputSplitDepth, maxDepth)
+	}
+	createTrace := func(ctx context.Context, prestateBlock uint64, poststateBlock uint64) (types.TraceProvider, error) {
+		inputs, err := cannon.FetchLocalInputsFromOutputs(ctx, common.Hash(l1Head), rollupClient, prestateBlock, poststateBlock)
+		if err != nil {
+			return nil, fmt.Errorf("fetch local game inputs: %w", err)
+		}
+		block := strconv.FormatUint(poststateBlock, 10)
+		traceStore := gameStore.WithPrefix("block-" + block + "/")
+		return cannon.NewTraceProviderFromInputs(logger.New("block", poststateBlock), m, cfg, inputs, filepath.Join(dir, block), traceStore)
+	}
+	return NewTraceProvider(
+		logger,
+		rollupClient,
+		cannon.NewPrestateProvider(cfg.CannonAbsolutePreState),
+		createTrace,
+		proposals.Starting.L2BlockNumber.Uint64(),
+		proposals.Disputed.L2BlockNumber.Uint64(),
+		cfg.OutputSplitDepth,
+		maxDepth,
+	), nil
+}
@@ -0,0 +1,240 @@
+package outputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	ErrIndexTooLarge = errors.New("index is larger than the maximum index")
+	ErrNoStepData    = errors.New("no step data beyond the disputed block")
+)
+
+// OutputRollupClient is a minimal interface around [sources.RollupClient] to load output roots.
+type OutputRollupClient interface {
+	OutputAtBlock(ctx context.Context, blockNum uint64) (*eth.OutputResponse, error)
+}
+
+// PrestateProvider provides the absolute pre-state of the execution traces.
+type PrestateProvider interface {
+	AbsolutePreState(ctx context.Context) ([]byte, error)
+}
+
+// ExecutionTraceCreator creates the execution trace for the transition from the output root at prestateBlock
+// to the output root at poststateBlock.
+type ExecutionTraceCreator func(ctx context.Context, prestateBlock uint64, poststateBlock uint64) (types.TraceProvider, error)
+
+// OutputTraceProvider is a [types.PositionTraceProvider] that bisects over L2 output roots until a single block
+// is disputed, then hands off to an execution trace for that block.
+//
+// The trace is divided into one segment per L2 block after prestateBlock, each covering 2^(maxDepth-splitDepth)
+// indices. Positions at or above the split depth commit to the output root of the block their trace index falls
+// in, while positions below it commit to the execution trace of the block. The last leaf of a segment shares its
+// trace index with the positions above it, so [OutputTraceProvider.Get] always returns the execution trace and
+// only [OutputTraceProvider.GetAtPosition] returns output roots. Segments after poststateBlock repeat the
+// disputed output root.
+//
+// It is safe for concurrent use. Concurrent requests for the same block share a single output root
+// request and execution trace.
+type OutputTraceProvider struct {
+	logger           log.Logger
+	rollupClient     OutputRollupClient
+	prestateProvider PrestateProvider
+	createTrace      ExecutionTraceCreator
+	prestateBlock    uint64
+	poststateBlock   uint64
+	splitDepth       uint64
+	maxDepth         uint64
+	bottomDepth      uint64
+	maxIndex         uint64
+
+	// inflight de-duplicates concurrent requests for the output root or execution trace of the same block.
+	inflight singleflight.Group
+	// cacheLock guards outputs and traces.
+	cacheLock sync.Mutex
+	outputs   map[uint64]common.Hash
+	traces    map[uint64]types.TraceProvider
+}
+
+var _ types.PositionTraceProvider = (*OutputTraceProvider)(nil)
+
+// NewTraceProvider creates a new [OutputTraceProvider] for a game disputing the output root at poststateBlock,
+// starting from the agreed output root at prestateBlock.
+func NewTraceProvider(
+	logger log.Logger,
+	rollupClient OutputRollupClient,
+	prestateProvider PrestateProvider,
+	createTrace ExecutionTraceCreator,
+	prestateBlock uint64,
+	poststateBlock uint64,
+	splitDepth uint64,
+	maxDepth uint64,
+) *OutputTraceProvider {
+	return &OutputTraceProvider{
+		logger:           logger,
+		rollupClient:     rollupClient,
+		prestateProvider: prestateProvider,
+		createTrace:      createTrace,
+		prestateBlock:    prestateBlock,
+		poststateBlock:   poststateBlock,
+		splitDepth:       splitDepth,
+		maxDepth:         maxDepth,
+		bottomDepth:      maxDepth - splitDepth,
+		maxIndex:         (1 << maxDepth) - 1,
+		outputs:          make(map[uint64]common.Hash),
+		traces:           make(map[uint64]types.TraceProvider),
+	}
+}
+
+// GetAtPosition returns the claim value at the requested position. Positions at or above the split depth
+// commit to output roots, positions below it to the execution trace of the disputed block.
+func (p *OutputTraceProvider) GetAtPosition(ctx context.Context, pos types.Position) (common.Hash, error) {
+	if uint64(pos.Depth()) > p.splitDepth {
+		return p.Get(ctx, pos.TraceIndex(int(p.maxDepth)))
+	}
+	i := pos.TraceIndex(int(p.maxDepth))
+	if i > p.maxIndex {
+		return common.Hash{}, ErrIndexTooLarge
+	}
+	block, _ := p.split(i)
+	if block > p.poststateBlock {
+		block = p.poststateBlock
+	}
+	return p.outputAtBlock(ctx, block)
+}
+
+// Get returns the claim value of the leaf position at the requested index, which comes from the execution
+// trace of the block containing index i.
+func (p *OutputTraceProvider) Get(ctx context.Context, i uint64) (common.Hash, error) {
+	if i > p.maxIndex {
+		return common.Hash{}, ErrIndexTooLarge
+	}
+	block, offset := p.split(i)
+	if block > p.poststateBlock {
+		return p.outputAtBlock(ctx, p.poststateBlock)
+	}
+	trace, err := p.executionTrace(ctx, block)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return trace.Get(ctx, offset)
+}
+
+// GetStepData returns the step data from the execution trace of the block containing index i.
+func (p *OutputTraceProvider) GetStepData(ctx context.Context, i uint64) ([]byte, []byte, *types.PreimageOracleData, error) {
+	if i > p.maxIndex {
+		return nil, nil, nil, ErrIndexTooLarge
+	}
+	block, offset := p.split(i)
+	if block > p.poststateBlock {
+		return nil, nil, nil, fmt.Errorf("%w: index %v is in block %v", ErrNoStepData, i, block)
+	}
+	trace, err := p.executionTrace(ctx, block)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return trace.GetStepData(ctx, offset)
+}
+
+// AbsolutePreState returns the absolute pre-state of the execution traces.
+func (p *OutputTraceProvider) AbsolutePreState(ctx context.Context) ([]byte, error) {
+	return p.prestateProvider.AbsolutePreState(ctx)
+}
+
+// Close closes the execution traces created for the disputed blocks.
+func (p *OutputTraceProvider) Close() error {
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+	var lastErr error
+	for block, trace := range p.traces {
+		if closer, ok := trace.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				lastErr = fmt.Errorf("failed to close execution trace for block %v: %w", block, err)
+			}
+		}
+	}
+	return lastErr
+}
+
+// split returns the L2 block number index i is part of and the offset of i within that block's segment.
+func (p *OutputTraceProvider) split(i uint64) (uint64, uint64) {
+	return p.prestateBlock + (i >> p.bottomDepth) + 1, i & p.segmentMask()
+}
+
+func (p *OutputTraceProvider) segmentMask() uint64 {
+	return (1 << p.bottomDepth) - 1
+}
+
+func (p *OutputTraceProvider) outputAtBlock(ctx context.Context, block uint64) (common.Hash, error) {
+	if output, ok := p.cachedOutput(block); ok {
+		return output, nil
+	}
+	result, err, _ := p.inflight.Do("output-"+strconv.FormatUint(block, 10), func() (interface{}, error) {
+		// Check the cache again in case a previous request completed since
+		if output, ok := p.cachedOutput(block); ok {
+			return output, nil
+		}
+		output, err := p.rollupClient.OutputAtBlock(ctx, block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch output at block %v: %w", block, err)
+		}
+		root := common.Hash(output.OutputRoot)
+		p.cacheLock.Lock()
+		p.outputs[block] = root
+		p.cacheLock.Unlock()
+		return root, nil
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return result.(common.Hash), nil
+}
+
+func (p *OutputTraceProvider) executionTrace(ctx context.Context, block uint64) (types.TraceProvider, error) {
+	if trace, ok := p.cachedTrace(block); ok {
+		return trace, nil
+	}
+	result, err, _ := p.inflight.Do("trace-"+strconv.FormatUint(block, 10), func() (interface{}, error) {
+		// Check the cache again in case a previous request completed since
+		if trace, ok := p.cachedTrace(block); ok {
+			return trace, nil
+		}
+		p.logger.Info("Creating execution trace", "prestateBlock", block-1, "poststateBlock", block)
+		trace, err := p.createTrace(ctx, block-1, block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create execution trace for block %v: %w", block, err)
+		}
+		p.cacheLock.Lock()
+		p.traces[block] = trace
+		p.cacheLock.Unlock()
+		return trace, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(types.TraceProvider), nil
+}
+
+func (p *OutputTraceProvider) cachedOutput(block uint64) (common.Hash, bool) {
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+	output, ok := p.outputs[block]
+	return output, ok
+}
+
+func (p *OutputTraceProvider) cachedTrace(block uint64) (types.TraceProvider, bool) {
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+	trace, ok := p.traces[block]
+	return trace, ok
+}
@@ -0,0 +1,291 @@
+package outputs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	prestateBlock  = uint64(100)
+	poststateBlock = uint64(103)
+	splitDepth     = uint64(2)
+	maxDepth       = uint64(5)
+)
+
+var prestate = []byte{0x01, 0x02}
+
+func TestGetAtPosition(t *testing.T) {
+	t.Run("OutputRootAtSplitDepth", func(t *testing.T) {
+		provider, _, creator := setupWithTestData(t)
+		// Each block covers 2^3 indices so position (2, 0) has trace index 7, the end of the first block after the prestate.
+		value, err := provider.GetAtPosition(context.Background(), types.NewPosition(int(splitDepth), 0))
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(101), value)
+
+		value, err = provider.GetAtPosition(context.Background(), types.NewPosition(int(splitDepth), 2))
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(103), value)
+		require.Empty(t, creator.prestateBlocks, "should not create execution traces for output roots")
+	})
+
+	t.Run("OutputRootAboveSplitDepth", func(t *testing.T) {
+		provider, _, _ := setupWithTestData(t)
+		value, err := provider.GetAtPosition(context.Background(), types.NewPosition(1, 0))
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(102), value)
+
+		value, err = provider.GetAtPosition(context.Background(), types.NewPosition(0, 0))
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(103), value, "should use disputed output after the poststate block")
+	})
+
+	t.Run("ExecutionTraceBelowSplitDepth", func(t *testing.T) {
+		provider, _, _ := setupWithTestData(t)
+		value, err := provider.GetAtPosition(context.Background(), types.NewPosition(int(maxDepth), 10))
+		require.NoError(t, err)
+		require.Equal(t, executionClaim(102, 2), value)
+
+		value, err = provider.GetAtPosition(context.Background(), types.NewPosition(int(splitDepth)+1, 1))
+		require.NoError(t, err)
+		require.Equal(t, executionClaim(101, 7), value)
+	})
+
+	t.Run("ExecutionTraceAtLastIndexOfSegment", func(t *testing.T) {
+		provider, _, creator := setupWithTestData(t)
+		value, err := provider.GetAtPosition(context.Background(), types.NewPosition(int(maxDepth), 7))
+		require.NoError(t, err)
+		require.Equal(t, executionClaim(101, 7), value)
+		require.Equal(t, []uint64{100}, creator.prestateBlocks)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("ExecutionTraceAtLastIndexOfSegment", func(t *testing.T) {
+		provider, _, creator := setupWithTestData(t)
+		value, err := provider.Get(context.Background(), 7)
+		require.NoError(t, err)
+		require.Equal(t, executionClaim(101, 7), value)
+
+		value, err = provider.Get(context.Background(), 23)
+		require.NoError(t, err)
+		require.Equal(t, executionClaim(103, 7), value)
+		require.Equal(t, []uint64{100, 102}, creator.prestateBlocks)
+	})
+
+	t.Run("ExecutionTraceWithinSegment", func(t *testing.T) {
+		provider, _, creator := setupWithTestData(t)
+		value, err := provider.Get(context.Background(), 10)
+		require.NoError(t, err)
+		require.Equal(t, executionClaim(102, 2), value)
+		require.Equal(t, []uint64{101}, creator.prestateBlocks)
+	})
+
+	t.Run("DisputedOutputAfterPoststateBlock", func(t *testing.T) {
+		provider, _, creator := setupWithTestData(t)
+		value, err := provider.Get(context.Background(), 26)
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(103), value)
+
+		value, err = provider.Get(context.Background(), 31)
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(103), value)
+		require.Empty(t, creator.prestateBlocks, "should not create execution traces after the disputed block")
+	})
+
+	t.Run("IndexTooLarge", func(t *testing.T) {
+		provider, _, _ := setupWithTestData(t)
+		_, err := provider.Get(context.Background(), 32)
+		require.ErrorIs(t, err, ErrIndexTooLarge)
+	})
+
+	t.Run("CachesOutputsAndTraces", func(t *testing.T) {
+		provider, rollup, creator := setupWithTestData(t)
+		for i := 0; i < 2; i++ {
+			_, err := provider.GetAtPosition(context.Background(), types.NewPosition(int(splitDepth), 0))
+			require.NoError(t, err)
+			_, err = provider.Get(context.Background(), 3)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 1, rollup.requests[101])
+		require.Equal(t, []uint64{100}, creator.prestateBlocks)
+	})
+
+	t.Run("ConcurrentRequestsShareOutputsAndTraces", func(t *testing.T) {
+		provider, rollup, creator := setupWithTestData(t)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := provider.GetAtPosition(context.Background(), types.NewPosition(int(splitDepth), 0))
+				require.NoError(t, err)
+				_, err = provider.Get(context.Background(), 3)
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, 1, rollup.Requests(101))
+		require.Equal(t, []uint64{100}, creator.PrestateBlocks())
+	})
+
+	t.Run("OutputError", func(t *testing.T) {
+		provider, rollup, _ := setupWithTestData(t)
+		rollup.err = errors.New("boom")
+		_, err := provider.GetAtPosition(context.Background(), types.NewPosition(int(splitDepth), 0))
+		require.ErrorIs(t, err, rollup.err)
+	})
+
+	t.Run("CreateTraceError", func(t *testing.T) {
+		provider, _, creator := setupWithTestData(t)
+		creator.err = errors.New("boom")
+		_, err := provider.Get(context.Background(), 3)
+		require.ErrorIs(t, err, creator.err)
+	})
+}
+
+func TestGetStepData(t *testing.T) {
+	t.Run("DelegatesToExecutionTrace", func(t *testing.T) {
+		provider, _, creator := setupWithTestData(t)
+		preimage, proof, data, err := provider.GetStepData(context.Background(), 19)
+		require.NoError(t, err)
+		require.Equal(t, []byte{103, 3}, preimage)
+		require.Equal(t, []byte{0xaa}, proof)
+		require.Nil(t, data)
+		require.Equal(t, []uint64{102}, creator.prestateBlocks)
+	})
+
+	t.Run("NoStepDataAfterPoststateBlock", func(t *testing.T) {
+		provider, _, _ := setupWithTestData(t)
+		_, _, _, err := provider.GetStepData(context.Background(), 25)
+		require.ErrorIs(t, err, ErrNoStepData)
+	})
+
+	t.Run("IndexTooLarge", func(t *testing.T) {
+		provider, _, _ := setupWithTestData(t)
+		_, _, _, err := provider.GetStepData(context.Background(), 32)
+		require.ErrorIs(t, err, ErrIndexTooLarge)
+	})
+}
+
+func TestClose(t *testing.T) {
+	provider, _, creator := setupWithTestData(t)
+	_, err := provider.Get(context.Background(), 3)
+	require.NoError(t, err)
+	_, err = provider.Get(context.Background(), 10)
+	require.NoError(t, err)
+	require.NoError(t, provider.Close())
+	require.Len(t, creator.traces, 2)
+	for _, trace := range creator.traces {
+		require.True(t, trace.closed)
+	}
+}
+
+func TestAbsolutePreState(t *testing.T) {
+	provider, _, _ := setupWithTestData(t)
+	value, err := provider.AbsolutePreState(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, prestate, value)
+}
+
+func setupWithTestData(t *testing.T) (*OutputTraceProvider, *stubRollupClient, *stubTraceCreator) {
+	logger := testlog.Logger(t, log.LvlInfo)
+	rollup := &stubRollupClient{requests: make(map[uint64]int)}
+	creator := &stubTraceCreator{}
+	provider := NewTraceProvider(logger, rollup, &stubPrestateProvider{}, creator.Create, prestateBlock, poststateBlock, splitDepth, maxDepth)
+	return provider, rollup, creator
+}
+
+func outputRoot(block uint64) common.Hash {
+	return common.Hash{0xff, byte(block)}
+}
+
+func executionClaim(block uint64, i uint64) common.Hash {
+	return common.Hash{byte(block), byte(i)}
+}
+
+type stubRollupClient struct {
+	mu       sync.Mutex
+	requests map[uint64]int
+	err      error
+}
+
+func (s *stubRollupClient) OutputAtBlock(_ context.Context, blockNum uint64) (*eth.OutputResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[blockNum]++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &eth.OutputResponse{OutputRoot: eth.Bytes32(outputRoot(blockNum))}, nil
+}
+
+type stubPrestateProvider struct{}
+
+func (s *stubPrestateProvider) AbsolutePreState(_ context.Context) ([]byte, error) {
+	return prestate, nil
+}
+
+func (s *stubRollupClient) Requests(blockNum uint64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[blockNum]
+}
+
+type stubTraceCreator struct {
+	mu             sync.Mutex
+	prestateBlocks []uint64
+	traces         []*stubExecutionTrace
+	err            error
+}
+
+func (s *stubTraceCreator) Create(_ context.Context, prestateBlock uint64, poststateBlock uint64) (types.TraceProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	if poststateBlock != prestateBlock+1 {
+		return nil, errors.New("execution traces must cover a single block")
+	}
+	s.prestateBlocks = append(s.prestateBlocks, prestateBlock)
+	trace := &stubExecutionTrace{block: poststateBlock}
+	s.traces = append(s.traces, trace)
+	return trace, nil
+}
+
+func (s *stubTraceCreator) PrestateBlocks() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint64(nil), s.prestateBlocks...)
+}
+
+type stubExecutionTrace struct {
+	block  uint64
+	closed bool
+}
+
+func (s *stubExecutionTrace) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *stubExecutionTrace) Get(_ context.Context, i uint64) (common.Hash, error) {
+	return executionClaim(s.block, i), nil
+}
+
+func (s *stubExecutionTrace) GetStepData(_ context.Context, i uint64) ([]byte, []byte, *types.PreimageOracleData, error) {
+	return []byte{byte(s.block), byte(i)}, []byte{0xaa}, nil, nil
+}
+
+func (s *stubExecutionTrace) AbsolutePreState(_ context.Context) ([]byte, error) {
+	return prestate, nil
+}
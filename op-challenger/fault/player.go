@@ -0,0 +1,209 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/alphabet"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrOutputCannonUnsupported is returned when playing output_cannon games. The cannon steps in those games execute
+// a single block, but the FaultDisputeGame contract can only load the game level local data into the pre-image oracle.
+var ErrOutputCannonUnsupported = errors.New("output_cannon games require per-block local oracle data which is not supported yet")
+
+type GameInfo interface {
+	GetGameStatus(context.Context) (types.GameStatus, error)
+	LogGameInfo(ctx context.Context)
+}
+
+type Actor interface {
+	Act(ctx context.Context) error
+	Summary() []types.ClaimSummary
+}
+
+// GamePlayer progresses a single fault dispute game.
+type GamePlayer struct {
+	addr                    common.Address
+	agent                   Actor
+	agreeWithProposedOutput bool
+	caller                  GameInfo
+	logger                  log.Logger
+	// trace is the trace provider of the game, closed once the game is no longer played.
+	trace types.TraceProvider
+
+	statusLock sync.Mutex
+	status     types.GameStatus
+}
+
+// NewGamePlayer creates a new [GamePlayer] for the fault dispute game at addr.
+func NewGamePlayer(
+	ctx context.Context,
+	logger log.Logger,
+	cfg *config.Config,
+	addr common.Address,
+	txMgr txmgr.TxManager,
+	client *ethclient.Client,
+	rollupClient OutputRollupClient,
+	gameStore *store.GameStore,
+	m metrics.Metricer,
+) (*GamePlayer, error) {
+	if cfg.TraceType == config.TraceTypeOutputCannon {
+		return nil, ErrOutputCannonUnsupported
+	}
+	logger = logger.New("game", addr)
+	contract, err := bindings.NewFaultDisputeGameCaller(addr, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind the fault dispute game contract: %w", err)
+	}
+
+	loader := NewLoader(contract)
+
+	gameDepth, err := loader.FetchGameDepth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the game depth: %w", err)
+	}
+
+	gameDuration, err := loader.FetchGameDuration(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the game duration: %w", err)
+	}
+
+	var provider types.TraceProvider
+	var updater types.OracleUpdater
+	var validator ProposalValidator
+	switch cfg.TraceType {
+	case config.TraceTypeCannon:
+		dir := gameDataDir(cfg, addr)
+		provider, err = cannon.NewTraceProvider(ctx, logger, m, cfg, client, dir, addr, gameStore)
+		if err != nil {
+			return nil, fmt.Errorf("create cannon trace provider: %w", err)
+		}
+		updater, err = cannon.NewOracleUpdater(ctx, logger, txMgr, addr, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the cannon updater: %w", err)
+		}
+		validator = NewOutputValidator(logger, contract, rollupClient)
+	case config.TraceTypeAlphabet:
+		provider = alphabet.NewTraceProvider(cfg.AlphabetTrace, gameDepth)
+		updater = alphabet.NewOracleUpdater(logger)
+		validator = NewTraceValidator(logger, contract, provider, gameDepth)
+	default:
+		return nil, fmt.Errorf("unsupported trace type: %v", cfg.TraceType)
+	}
+
+	if err := ValidateAbsolutePrestate(ctx, provider, loader); err != nil {
+		return nil, fmt.Errorf("failed to validate absolute prestate: %w", err)
+	}
+
+	agreeWithProposedOutput, err := validator.AgreeWithProposedOutput(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine if the proposed output is valid: %w", err)
+	}
+	m.RecordProposedOutputOpinion(agreeWithProposedOutput)
+
+	responder, err := NewFaultResponder(logger, txMgr, addr, gameStore, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the responder: %w", err)
+	}
+
+	caller, err := NewFaultCallerFromBindings(addr, client, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind the fault contract: %w", err)
+	}
+
+	return &GamePlayer{
+		addr:                    addr,
+		agent:                   NewAgent(loader, int(gameDepth), gameDuration, provider, responder, updater, client, cfg.MaxSpendPerGame, agreeWithProposedOutput, m, logger),
+		agreeWithProposedOutput: agreeWithProposedOutput,
+		caller:                  caller,
+		logger:                  logger,
+		trace:                   provider,
+	}, nil
+}
+
+// Close releases the resources held by the game's trace provider.
+func (g *GamePlayer) Close() error {
+	if closer, ok := g.trace.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// RemoveGameData deletes the data stored on disk for the game at addr, such as cannon proofs and snapshots.
+func RemoveGameData(cfg *config.Config, addr common.Address) error {
+	if cfg.CannonDatadir == "" {
+		return nil
+	}
+	return os.RemoveAll(gameDataDir(cfg, addr))
+}
+
+// gameDataDir is the directory in which data for the game at addr is stored.
+func gameDataDir(cfg *config.Config, addr common.Address) string {
+	return filepath.Join(cfg.CannonDatadir, addr.Hex())
+}
+
+// ProgressGame checks the current state of the game, and attempts to progress it by performing moves, steps or resolving
+// Returns true if the game is complete or false if it needs to be monitored further
+func (g *GamePlayer) ProgressGame(ctx context.Context) bool {
+	g.logger.Trace("Checking if actions are required")
+	if err := g.agent.Act(ctx); err != nil {
+		g.logger.Error("Error when acting on game", "err", err)
+	}
+	status, err := g.caller.GetGameStatus(ctx)
+	if err != nil {
+		g.logger.Warn("Unable to retrieve game status", "err", err)
+		return false
+	}
+	g.setStatus(status)
+	if status == types.GameStatusInProgress {
+		g.caller.LogGameInfo(ctx)
+		return false
+	}
+	var expectedStatus types.GameStatus
+	if g.agreeWithProposedOutput {
+		expectedStatus = types.GameStatusChallengerWon
+	} else {
+		expectedStatus = types.GameStatusDefenderWon
+	}
+	if expectedStatus == status {
+		g.logger.Info("Game won", "status", GameStatusString(status))
+	} else {
+		g.logger.Error("Game lost", "status", GameStatusString(status))
+	}
+	return true
+}
+
+// Summary returns the current state of the game and the claims in it as of the last time it was progressed.
+func (g *GamePlayer) Summary() types.GameSummary {
+	claims := g.agent.Summary()
+	g.statusLock.Lock()
+	defer g.statusLock.Unlock()
+	return types.GameSummary{
+		Address:                 g.addr,
+		Status:                  g.status,
+		AgreeWithProposedOutput: g.agreeWithProposedOutput,
+		ClaimCount:              len(claims),
+		Claims:                  claims,
+	}
+}
+
+func (g *GamePlayer) setStatus(status types.GameStatus) {
+	g.statusLock.Lock()
+	defer g.statusLock.Unlock()
+	g.status = status
+}
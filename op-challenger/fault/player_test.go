@@ -0,0 +1,181 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressGameAndLogState(t *testing.T) {
+	_, game, actor, gameInfo := setupProgressGameTest(t, true)
+	done := game.ProgressGame(context.Background())
+	require.False(t, done, "should not be done")
+	require.Equal(t, 1, actor.callCount, "should perform next actions")
+	require.Equal(t, 1, gameInfo.logCount, "should log latest game state")
+}
+
+func TestProgressGame_LogErrorFromAct(t *testing.T) {
+	handler, game, actor, gameInfo := setupProgressGameTest(t, true)
+	actor.err = errors.New("Boom")
+	done := game.ProgressGame(context.Background())
+	require.False(t, done, "should not be done")
+	require.Equal(t, 1, actor.callCount, "should perform next actions")
+	require.Equal(t, 1, gameInfo.logCount, "should log latest game state")
+	errLog := handler.FindLog(log.LvlError, "Error when acting on game")
+	require.NotNil(t, errLog, "should log error")
+	require.Equal(t, actor.err, errLog.GetContextValue("err"))
+}
+
+func TestProgressGame_LogErrorWhenGameLost(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          types.GameStatus
+		agreeWithOutput bool
+		logLevel        log.Lvl
+		logMsg          string
+		statusText      string
+	}{
+		{
+			name:            "GameLostAsDefender",
+			status:          types.GameStatusChallengerWon,
+			agreeWithOutput: false,
+			logLevel:        log.LvlError,
+			logMsg:          "Game lost",
+			statusText:      "Challenger Won",
+		},
+		{
+			name:            "GameLostAsChallenger",
+			status:          types.GameStatusDefenderWon,
+			agreeWithOutput: true,
+			logLevel:        log.LvlError,
+			logMsg:          "Game lost",
+			statusText:      "Defender Won",
+		},
+		{
+			name:            "GameWonAsDefender",
+			status:          types.GameStatusDefenderWon,
+			agreeWithOutput: false,
+			logLevel:        log.LvlInfo,
+			logMsg:          "Game won",
+			statusText:      "Defender Won",
+		},
+		{
+			name:            "GameWonAsChallenger",
+			status:          types.GameStatusChallengerWon,
+			agreeWithOutput: true,
+			logLevel:        log.LvlInfo,
+			logMsg:          "Game won",
+			statusText:      "Challenger Won",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			handler, game, _, gameInfo := setupProgressGameTest(t, test.agreeWithOutput)
+			gameInfo.status = test.status
+
+			done := game.ProgressGame(context.Background())
+			require.True(t, done, "should be done")
+			require.Equal(t, 0, gameInfo.logCount, "should not log latest game state")
+			errLog := handler.FindLog(test.logLevel, test.logMsg)
+			require.NotNil(t, errLog, "should log game result")
+			require.Equal(t, test.statusText, errLog.GetContextValue("status"))
+		})
+	}
+}
+
+func TestSummaryReportsLatestStatus(t *testing.T) {
+	_, game, actor, gameInfo := setupProgressGameTest(t, true)
+	actor.claims = []types.ClaimSummary{{ContractIndex: 0}, {ContractIndex: 1}}
+	game.addr = common.Address{0xaa}
+
+	game.ProgressGame(context.Background())
+	summary := game.Summary()
+	require.Equal(t, common.Address{0xaa}, summary.Address)
+	require.Equal(t, types.GameStatusInProgress, summary.Status)
+	require.True(t, summary.AgreeWithProposedOutput)
+	require.Equal(t, 2, summary.ClaimCount)
+	require.Equal(t, actor.claims, summary.Claims)
+
+	gameInfo.status = types.GameStatusChallengerWon
+	game.ProgressGame(context.Background())
+	require.Equal(t, types.GameStatusChallengerWon, game.Summary().Status)
+}
+
+func setupProgressGameTest(t *testing.T, agreeWithProposedRoot bool) (*testlog.CapturingHandler, *GamePlayer, *stubActor, *stubGameInfo) {
+	logger := testlog.Logger(t, log.LvlDebug)
+	handler := &testlog.CapturingHandler{
+		Delegate: logger.GetHandler(),
+	}
+	logger.SetHandler(handler)
+	actor := &stubActor{}
+	gameInfo := &stubGameInfo{}
+	game := &GamePlayer{
+		agent:                   actor,
+		agreeWithProposedOutput: agreeWithProposedRoot,
+		caller:                  gameInfo,
+		logger:                  logger,
+	}
+	return handler, game, actor, gameInfo
+}
+
+type stubActor struct {
+	callCount int
+	err       error
+	claims    []types.ClaimSummary
+}
+
+func (a *stubActor) Act(ctx context.Context) error {
+	a.callCount++
+	return a.err
+}
+
+func (a *stubActor) Summary() []types.ClaimSummary {
+	return a.claims
+}
+
+type stubGameInfo struct {
+	status   types.GameStatus
+	err      error
+	logCount int
+}
+
+func (s *stubGameInfo) GetGameStatus(ctx context.Context) (types.GameStatus, error) {
+	return s.status, s.err
+}
+
+func (s *stubGameInfo) LogGameInfo(ctx context.Context) {
+	s.logCount++
+}
+
+func TestRemoveGameData(t *testing.T) {
+	cfg := &config.Config{CannonDatadir: t.TempDir()}
+	addr := common.Address{0xaa}
+	other := common.Address{0xbb}
+	require.NoError(t, os.MkdirAll(filepath.Join(gameDataDir(cfg, addr), "proofs"), 0755))
+	require.NoError(t, os.MkdirAll(gameDataDir(cfg, other), 0755))
+
+	require.NoError(t, RemoveGameData(cfg, addr))
+	require.NoDirExists(t, gameDataDir(cfg, addr))
+	require.DirExists(t, gameDataDir(cfg, other))
+
+	// Removing data that no longer exists is not an error
+	require.NoError(t, RemoveGameData(cfg, addr))
+	require.NoError(t, RemoveGameData(&config.Config{}, addr))
+}
+
+func TestNewGamePlayer_RejectsOutputCannon(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlError)
+	cfg := &config.Config{TraceType: config.TraceTypeOutputCannon}
+	_, err := NewGamePlayer(context.Background(), logger, cfg, common.Address{0xaa}, nil, nil, nil, nil, nil)
+	require.ErrorIs(t, err, ErrOutputCannonUnsupported)
+}
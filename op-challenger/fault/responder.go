@@ -2,40 +2,76 @@ package fault
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// ResponderStore records the transactions sent by the responder, and the total spent on them,
+// so transactions are not sent again and the spend limit is enforced across restarts.
+type ResponderStore interface {
+	GetTx(id common.Hash) (store.TxRecord, bool, error)
+	PutTx(id common.Hash, record store.TxRecord) error
+	DeleteTx(id common.Hash) error
+	GetSpent() (*big.Int, error)
+	PutSpent(spent *big.Int) error
+}
+
+// TxSource is a minimal interface to check whether previously published transactions were included.
+type TxSource interface {
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *ethtypes.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethtypes.Receipt, error)
+}
+
 // faultResponder implements the [Responder] interface to send onchain transactions.
 type faultResponder struct {
-	log log.Logger
+	log   log.Logger
+	clock clock.Clock
 
-	txMgr txmgr.TxManager
+	txMgr    txmgr.TxManager
+	txStore  ResponderStore
+	txSource TxSource
 
 	fdgAddr common.Address
 	fdgAbi  *abi.ABI
+
+	// spent is the total amount of wei spent on transactions sent by the responder.
+	spent *big.Int
 }
 
 // NewFaultResponder returns a new [faultResponder].
-func NewFaultResponder(logger log.Logger, txManagr txmgr.TxManager, fdgAddr common.Address) (*faultResponder, error) {
+func NewFaultResponder(logger log.Logger, txManagr txmgr.TxManager, fdgAddr common.Address, txStore ResponderStore, txSource TxSource) (*faultResponder, error) {
 	fdgAbi, err := bindings.FaultDisputeGameMetaData.GetAbi()
 	if err != nil {
 		return nil, err
 	}
+	spent, err := txStore.GetSpent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load total spent: %w", err)
+	}
 	return &faultResponder{
-		log:     logger,
-		txMgr:   txManagr,
-		fdgAddr: fdgAddr,
-		fdgAbi:  fdgAbi,
+		log:      logger,
+		clock:    clock.SystemClock,
+		txMgr:    txManagr,
+		txStore:  txStore,
+		txSource: txSource,
+		fdgAddr:  fdgAddr,
+		fdgAbi:   fdgAbi,
+		spent:    spent,
 	}, nil
 }
 
@@ -114,15 +150,49 @@ func (r *faultResponder) Respond(ctx context.Context, response types.Claim) erro
 
 // sendTxAndWait sends a transaction through the [txmgr] and waits for a receipt.
 // This sets the tx GasLimit to 0, performing gas estimation online through the [txmgr].
+// Transactions are recorded in the [ResponderStore] as soon as they are published so that a transaction
+// that was successfully included, or may still be pending from before a restart, is not sent again.
 func (r *faultResponder) sendTxAndWait(ctx context.Context, txData []byte) error {
+	id := crypto.Keccak256Hash(txData)
+	if record, ok, err := r.txStore.GetTx(id); err != nil {
+		return fmt.Errorf("failed to load tx record: %w", err)
+	} else if ok && record.Confirmed() && record.Status == ethtypes.ReceiptStatusSuccessful {
+		r.log.Info("Transaction already included, not sending again", "tx_hash", record.TxHash, "nonce", record.Nonce)
+		return nil
+	} else if ok && !record.Confirmed() && len(record.Published) > 0 {
+		if done, err := r.checkPublishedTx(ctx, id, record); err != nil {
+			return fmt.Errorf("failed to check previously published tx: %w", err)
+		} else if done {
+			return nil
+		}
+	}
+	record := store.TxRecord{Created: r.clock.Now()}
+	if err := r.txStore.PutTx(id, record); err != nil {
+		return fmt.Errorf("failed to record tx: %w", err)
+	}
 	receipt, err := r.txMgr.Send(ctx, txmgr.TxCandidate{
 		To:       &r.fdgAddr,
 		TxData:   txData,
 		GasLimit: 0,
+		OnPublish: func(tx *ethtypes.Transaction) {
+			record.Published = append(record.Published, tx.Hash())
+			record.Nonce = tx.Nonce()
+			if err := r.txStore.PutTx(id, record); err != nil {
+				r.log.Error("Failed to record published tx", "tx_hash", tx.Hash(), "err", err)
+			}
+		},
 	})
-	if err != nil {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		// The transaction may still be included so the record is kept to check it before sending again
+		return err
+	} else if err != nil {
+		if err := r.txStore.DeleteTx(id); err != nil {
+			r.log.Error("Failed to delete record of unsent tx", "err", err)
+		}
 		return err
 	}
+	r.recordSpend(receipt)
+	r.recordReceipt(id, record, receipt)
 	if receipt.Status == ethtypes.ReceiptStatusFailed {
 		r.log.Error("Responder tx successfully published but reverted", "tx_hash", receipt.TxHash)
 	} else {
@@ -131,6 +201,77 @@ func (r *faultResponder) sendTxAndWait(ctx context.Context, txData []byte) error
 	return nil
 }
 
+// checkPublishedTx checks the chain state of a transaction that was published but whose receipt was not
+// recorded, such as when the challenger restarted while waiting for it to be included.
+// Returns true if one of the published transactions was successfully included or is still pending,
+// in which case the transaction must not be sent again.
+func (r *faultResponder) checkPublishedTx(ctx context.Context, id common.Hash, record store.TxRecord) (bool, error) {
+	nonce, err := r.txSource.NonceAt(ctx, r.txMgr.From(), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to load nonce: %w", err)
+	}
+	if nonce <= record.Nonce {
+		// Nothing was included at the nonce yet, wait if a published transaction is still in the tx pool
+		for _, hash := range record.Published {
+			if _, _, err := r.txSource.TransactionByHash(ctx, hash); errors.Is(err, ethereum.NotFound) {
+				continue
+			} else if err != nil {
+				return false, fmt.Errorf("failed to load tx %v: %w", hash, err)
+			}
+			r.log.Info("Previously published transaction is still pending, waiting for it to be included", "tx_hash", hash, "nonce", record.Nonce)
+			return true, nil
+		}
+		r.log.Warn("Previously published transaction was dropped, sending again", "nonce", record.Nonce)
+		return false, nil
+	}
+	for _, hash := range record.Published {
+		receipt, err := r.txSource.TransactionReceipt(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("failed to load receipt of tx %v: %w", hash, err)
+		}
+		r.recordSpend(receipt)
+		r.recordReceipt(id, record, receipt)
+		if receipt.Status == ethtypes.ReceiptStatusFailed {
+			r.log.Warn("Previously published transaction reverted, sending again", "tx_hash", hash, "nonce", record.Nonce)
+			return false, nil
+		}
+		r.log.Info("Previously published transaction was included, not sending again", "tx_hash", hash, "nonce", record.Nonce)
+		return true, nil
+	}
+	r.log.Warn("Previously published transaction was replaced, sending again", "nonce", record.Nonce)
+	return false, nil
+}
+
+// recordReceipt updates the record of the transaction identified by id with the details of the included transaction.
+func (r *faultResponder) recordReceipt(id common.Hash, record store.TxRecord, receipt *ethtypes.Receipt) {
+	txHash := receipt.TxHash
+	record.TxHash = &txHash
+	record.Status = receipt.Status
+	if err := r.txStore.PutTx(id, record); err != nil {
+		r.log.Error("Failed to record included tx", "tx_hash", txHash, "err", err)
+	}
+}
+
+// recordSpend adds the fees paid for the transaction in receipt to the total spent and persists the new total.
+// Fees are paid even if the transaction reverted.
+func (r *faultResponder) recordSpend(receipt *ethtypes.Receipt) {
+	if receipt.EffectiveGasPrice == nil {
+		return
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	r.spent.Add(r.spent, fee)
+	if err := r.txStore.PutSpent(r.spent); err != nil {
+		r.log.Error("Failed to record total spent", "spent", r.spent, "err", err)
+	}
+}
+
+// TotalSpent returns the total amount of wei spent on transactions sent by the responder.
+func (r *faultResponder) TotalSpent() *big.Int {
+	return new(big.Int).Set(r.spent)
+}
+
 // buildStepTxData creates the transaction data for the step function.
 func (r *faultResponder) buildStepTxData(stepData types.StepCallData) ([]byte, error) {
 	return r.fdgAbi.Pack(
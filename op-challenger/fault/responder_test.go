@@ -5,15 +5,18 @@ import (
 	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/stretchr/testify/require"
@@ -26,9 +29,14 @@ var (
 
 type mockTxManager struct {
 	from      common.Address
+	nonce     uint64
 	sends     int
 	calls     int
 	sendFails bool
+	sendErr   error
+	reverts   bool
+	gasUsed   uint64
+	gasPrice  *big.Int
 }
 
 func (m *mockTxManager) Send(ctx context.Context, candidate txmgr.TxCandidate) (*ethtypes.Receipt, error) {
@@ -36,11 +44,21 @@ func (m *mockTxManager) Send(ctx context.Context, candidate txmgr.TxCandidate) (
 		return nil, mockSendError
 	}
 	m.sends++
-	return ethtypes.NewReceipt(
+	if candidate.OnPublish != nil {
+		candidate.OnPublish(ethtypes.NewTx(&ethtypes.DynamicFeeTx{Nonce: m.nonce, To: candidate.To, Data: candidate.TxData}))
+	}
+	if m.sendErr != nil {
+		return nil, m.sendErr
+	}
+	receipt := ethtypes.NewReceipt(
 		[]byte{},
-		false,
+		m.reverts,
 		0,
-	), nil
+	)
+	receipt.TxHash = common.Hash{byte(m.sends)}
+	receipt.GasUsed = m.gasUsed
+	receipt.EffectiveGasPrice = m.gasPrice
+	return receipt, nil
 }
 
 func (m *mockTxManager) Call(_ context.Context, _ ethereum.CallMsg, _ *big.Int) ([]byte, error) {
@@ -51,6 +69,10 @@ func (m *mockTxManager) Call(_ context.Context, _ ethereum.CallMsg, _ *big.Int)
 	return []byte{}, nil
 }
 
+func (m *mockTxManager) Cancel(ctx context.Context, nonce uint64) (*ethtypes.Receipt, error) {
+	panic("not implemented")
+}
+
 func (m *mockTxManager) BlockNumber(ctx context.Context) (uint64, error) {
 	panic("not implemented")
 }
@@ -59,13 +81,50 @@ func (m *mockTxManager) From() common.Address {
 	return m.from
 }
 
+type stubTxSource struct {
+	nonce    uint64
+	pending  map[common.Hash]bool
+	receipts map[common.Hash]*ethtypes.Receipt
+}
+
+func (s *stubTxSource) NonceAt(_ context.Context, _ common.Address, _ *big.Int) (uint64, error) {
+	return s.nonce, nil
+}
+
+func (s *stubTxSource) TransactionByHash(_ context.Context, hash common.Hash) (*ethtypes.Transaction, bool, error) {
+	if !s.pending[hash] {
+		return nil, false, ethereum.NotFound
+	}
+	return ethtypes.NewTx(&ethtypes.DynamicFeeTx{}), true, nil
+}
+
+func (s *stubTxSource) TransactionReceipt(_ context.Context, hash common.Hash) (*ethtypes.Receipt, error) {
+	receipt, ok := s.receipts[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
 func newTestFaultResponder(t *testing.T, sendFails bool) (*faultResponder, *mockTxManager) {
+	responder, mockTxMgr, _ := newTestFaultResponderWithStore(t, sendFails)
+	return responder, mockTxMgr
+}
+
+func newTestFaultResponderWithStore(t *testing.T, sendFails bool) (*faultResponder, *mockTxManager, *store.GameStore) {
+	responder, mockTxMgr, txStore, _ := newTestFaultResponderWithTxSource(t, sendFails)
+	return responder, mockTxMgr, txStore
+}
+
+func newTestFaultResponderWithTxSource(t *testing.T, sendFails bool) (*faultResponder, *mockTxManager, *store.GameStore, *stubTxSource) {
 	log := testlog.Logger(t, log.LvlError)
-	mockTxMgr := &mockTxManager{}
+	mockTxMgr := &mockTxManager{nonce: 7}
 	mockTxMgr.sendFails = sendFails
-	responder, err := NewFaultResponder(log, mockTxMgr, mockFdgAddress)
+	txStore := store.NewMemoryStore().GameStore(mockFdgAddress)
+	txSource := &stubTxSource{nonce: 7, pending: make(map[common.Hash]bool), receipts: make(map[common.Hash]*ethtypes.Receipt)}
+	responder, err := NewFaultResponder(log, mockTxMgr, mockFdgAddress, txStore, txSource)
 	require.NoError(t, err)
-	return responder, mockTxMgr
+	return responder, mockTxMgr, txStore, txSource
 }
 
 // TestResponder_CanResolve_CallFails tests the [Responder.CanResolve] method
@@ -144,6 +203,166 @@ func TestResponder_Respond_Success(t *testing.T) {
 	require.Equal(t, 1, mockTxMgr.sends)
 }
 
+// TestResponder_TotalSpent tests the [Responder.TotalSpent] method
+// accumulates the fees paid for each transaction sent.
+func TestResponder_TotalSpent(t *testing.T) {
+	responder, mockTxMgr := newTestFaultResponder(t, false)
+	require.Equal(t, big.NewInt(0), responder.TotalSpent())
+
+	mockTxMgr.gasUsed = 100
+	mockTxMgr.gasPrice = big.NewInt(3)
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.Equal(t, big.NewInt(300), responder.TotalSpent())
+
+	mockTxMgr.gasUsed = 50
+	require.NoError(t, responder.Step(context.Background(), types.StepCallData{}))
+	require.Equal(t, big.NewInt(450), responder.TotalSpent())
+
+	// Failed sends do not cost anything
+	mockTxMgr.sendFails = true
+	require.ErrorIs(t, responder.Step(context.Background(), types.StepCallData{ClaimIndex: 1}), mockSendError)
+	require.Equal(t, big.NewInt(450), responder.TotalSpent())
+}
+
+// TestResponder_TotalSpentPersisted tests the total spent is loaded from the store
+// when the [Responder] is recreated, such as after a restart.
+func TestResponder_TotalSpentPersisted(t *testing.T) {
+	responder, mockTxMgr, txStore := newTestFaultResponderWithStore(t, false)
+	mockTxMgr.gasUsed = 100
+	mockTxMgr.gasPrice = big.NewInt(3)
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.Equal(t, big.NewInt(300), responder.TotalSpent())
+
+	restarted, err := NewFaultResponder(testlog.Logger(t, log.LvlError), mockTxMgr, mockFdgAddress, txStore, &stubTxSource{})
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(300), restarted.TotalSpent())
+}
+
+// TestResponder_DoesNotResendIncludedTx tests the [Responder] does not send a transaction
+// that was already successfully included, recording its hash and nonce.
+func TestResponder_DoesNotResendIncludedTx(t *testing.T) {
+	responder, mockTxMgr, txStore := newTestFaultResponderWithStore(t, false)
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.Equal(t, 1, mockTxMgr.sends)
+
+	txData, err := responder.buildResolveData()
+	require.NoError(t, err)
+	record, ok, err := txStore.GetTx(crypto.Keccak256Hash(txData))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, common.Hash{0x01}, *record.TxHash)
+	require.Equal(t, uint64(7), record.Nonce)
+	require.Equal(t, ethtypes.ReceiptStatusSuccessful, record.Status)
+}
+
+// TestResponder_ResendsRevertedTx tests the [Responder] sends a transaction again if it previously reverted.
+func TestResponder_ResendsRevertedTx(t *testing.T) {
+	responder, mockTxMgr := newTestFaultResponder(t, false)
+	mockTxMgr.reverts = true
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.Equal(t, 2, mockTxMgr.sends)
+}
+
+// TestResponder_RetriesFailedSend tests the [Responder] sends a transaction again if sending failed.
+func TestResponder_RetriesFailedSend(t *testing.T) {
+	responder, mockTxMgr := newTestFaultResponder(t, true)
+	require.ErrorIs(t, responder.Resolve(context.Background()), mockSendError)
+	mockTxMgr.sendFails = false
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.Equal(t, 1, mockTxMgr.sends)
+}
+
+// TestResponder_KeepsRecordOfTimedOutTx tests the [Responder] keeps the record of a published
+// transaction when sending times out, as it may still be included.
+func TestResponder_KeepsRecordOfTimedOutTx(t *testing.T) {
+	responder, mockTxMgr, txStore := newTestFaultResponderWithStore(t, false)
+	mockTxMgr.sendErr = context.DeadlineExceeded
+	require.ErrorIs(t, responder.Resolve(context.Background()), context.DeadlineExceeded)
+
+	txData, err := responder.buildResolveData()
+	require.NoError(t, err)
+	record, ok, err := txStore.GetTx(crypto.Keccak256Hash(txData))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, record.Confirmed())
+	require.Len(t, record.Published, 1)
+	require.Equal(t, uint64(7), record.Nonce)
+}
+
+// TestResponder_ChecksPublishedTx tests the [Responder] checks the chain state of a transaction
+// published before a restart to decide whether to send it again.
+func TestResponder_ChecksPublishedTx(t *testing.T) {
+	published := common.Hash{0xaa}
+	setup := func(t *testing.T) (*faultResponder, *mockTxManager, *store.GameStore, *stubTxSource, common.Hash) {
+		responder, mockTxMgr, txStore, txSource := newTestFaultResponderWithTxSource(t, false)
+		txData, err := responder.buildResolveData()
+		require.NoError(t, err)
+		id := crypto.Keccak256Hash(txData)
+		require.NoError(t, txStore.PutTx(id, store.TxRecord{Created: time.Unix(1000, 0), Published: []common.Hash{{0x01}, published}, Nonce: 7}))
+		return responder, mockTxMgr, txStore, txSource, id
+	}
+
+	t.Run("NotPublished", func(t *testing.T) {
+		responder, mockTxMgr, txStore, _, id := setup(t)
+		require.NoError(t, txStore.PutTx(id, store.TxRecord{Created: time.Unix(1000, 0)}))
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 1, mockTxMgr.sends)
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		responder, mockTxMgr, _, txSource, _ := setup(t)
+		txSource.pending[published] = true
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 0, mockTxMgr.sends)
+	})
+
+	t.Run("Dropped", func(t *testing.T) {
+		responder, mockTxMgr, _, _, _ := setup(t)
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 1, mockTxMgr.sends)
+	})
+
+	t.Run("Included", func(t *testing.T) {
+		responder, mockTxMgr, txStore, txSource, id := setup(t)
+		txSource.nonce = 8
+		txSource.receipts[published] = &ethtypes.Receipt{
+			Status:            ethtypes.ReceiptStatusSuccessful,
+			TxHash:            published,
+			GasUsed:           100,
+			EffectiveGasPrice: big.NewInt(3),
+		}
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 0, mockTxMgr.sends)
+		require.Equal(t, big.NewInt(300), responder.TotalSpent())
+
+		record, ok, err := txStore.GetTx(id)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, published, *record.TxHash)
+		require.Equal(t, ethtypes.ReceiptStatusSuccessful, record.Status)
+
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 0, mockTxMgr.sends, "should not check the chain again")
+	})
+
+	t.Run("Reverted", func(t *testing.T) {
+		responder, mockTxMgr, _, txSource, _ := setup(t)
+		txSource.nonce = 8
+		txSource.receipts[published] = &ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed, TxHash: published}
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 1, mockTxMgr.sends)
+	})
+
+	t.Run("Replaced", func(t *testing.T) {
+		responder, mockTxMgr, _, txSource, _ := setup(t)
+		txSource.nonce = 8
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 1, mockTxMgr.sends)
+	})
+}
+
 // TestResponder_BuildTx_Attack tests the [Responder.BuildTx] method
 // returns a tx candidate with the correct data for an attack tx.
 func TestResponder_BuildTx_Attack(t *testing.T) {
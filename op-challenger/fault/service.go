@@ -7,100 +7,129 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
-	"github.com/ethereum-optimism/optimism/op-challenger/fault/alphabet"
-	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-challenger/rpc"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
+	"github.com/ethereum-optimism/optimism/op-challenger/version"
 	"github.com/ethereum-optimism/optimism/op-service/client"
+	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
-	"github.com/ethereum-optimism/optimism/op-service/txmgr/metrics"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
 )
 
 // Service provides a clean interface for the challenger to interact
 // with the fault package.
 type Service interface {
-	// MonitorGame monitors the fault dispute game and attempts to progress it.
-	MonitorGame(context.Context) error
+	// MonitorGames monitors the fault dispute games created by the factory and attempts to progress them.
+	MonitorGames(context.Context) error
+
+	// Close releases the resources held by the service.
+	Close() error
 }
 
 type service struct {
-	agent                   *Agent
-	agreeWithProposedOutput bool
-	caller                  *FaultCaller
-	logger                  log.Logger
+	monitor *gameMonitor
+	store   *store.Store
+	server  *oprpc.Server
 }
 
 // NewService creates a new Service.
 func NewService(ctx context.Context, logger log.Logger, cfg *config.Config) (*service, error) {
-	txMgr, err := txmgr.NewSimpleTxManager("challenger", logger, &metrics.NoopTxMetrics{}, cfg.TxMgrConfig)
+	m := metrics.NewMetrics()
+	txMgr, err := txmgr.NewSimpleTxManager("challenger", logger, m, cfg.TxMgrConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the transaction manager: %w", err)
 	}
 
-	client, err := client.DialEthClientWithTimeout(client.DefaultDialTimeout, logger, cfg.L1EthRpc)
+	l1Client, err := client.DialEthClientWithTimeout(client.DefaultDialTimeout, logger, cfg.L1EthRpc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial L1: %w", err)
 	}
 
-	contract, err := bindings.NewFaultDisputeGameCaller(cfg.GameAddress, client)
+	var rollupClient OutputRollupClient
+	if cfg.RollupRpc != "" {
+		rollupClient, err = client.DialRollupClientWithTimeout(client.DefaultDialTimeout, logger, cfg.RollupRpc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial rollup client: %w", err)
+		}
+	}
+
+	st, err := store.Open(cfg.Datadir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to bind the fault dispute game contract: %w", err)
+		return nil, fmt.Errorf("failed to open the challenger store: %w", err)
 	}
 
-	loader := NewLoader(contract)
+	m.RecordInfo(version.Version)
+	m.RecordUp()
 
-	gameDepth, err := loader.FetchGameDepth(ctx)
+	factory, err := bindings.NewDisputeGameFactoryCaller(cfg.GameFactoryAddress, l1Client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch the game depth: %w", err)
+		_ = st.Close()
+		return nil, fmt.Errorf("failed to bind the dispute game factory contract: %w", err)
 	}
-	gameDepth = uint64(gameDepth)
+	source := NewGameFactoryLoader(factory)
 
-	var trace types.TraceProvider
-	var updater types.OracleUpdater
-	switch cfg.TraceType {
-	case config.TraceTypeCannon:
-		trace, err = cannon.NewTraceProvider(ctx, logger, cfg, client)
+	loadMetadata := func(ctx context.Context, addr common.Address) (gameMetadata, error) {
+		caller, err := bindings.NewFaultDisputeGameCaller(addr, l1Client)
 		if err != nil {
-			return nil, fmt.Errorf("create cannon trace provider: %w", err)
+			return gameMetadata{}, fmt.Errorf("failed to bind the fault dispute game contract: %w", err)
 		}
-		updater, err = cannon.NewOracleUpdater(ctx, logger, txMgr, cfg.GameAddress, client)
+		opts := &bind.CallOpts{Context: ctx}
+		gameType, err := caller.GameType(opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create the cannon updater: %w", err)
+			return gameMetadata{}, fmt.Errorf("failed to fetch game type: %w", err)
 		}
-	case config.TraceTypeAlphabet:
-		trace = alphabet.NewTraceProvider(cfg.AlphabetTrace, gameDepth)
-		updater = alphabet.NewOracleUpdater(logger)
-	default:
-		return nil, fmt.Errorf("unsupported trace type: %v", cfg.TraceType)
+		status, err := caller.Status(opts)
+		if err != nil {
+			return gameMetadata{}, fmt.Errorf("failed to fetch game status: %w", err)
+		}
+		return gameMetadata{GameType: gameType, Status: types.GameStatus(status)}, nil
+	}
+	createPlayer := func(ctx context.Context, addr common.Address) (gamePlayer, error) {
+		return NewGamePlayer(ctx, logger, cfg, addr, txMgr, l1Client, rollupClient, st.GameStore(addr), m)
 	}
 
-	return newTypedService(ctx, logger, cfg, loader, gameDepth, client, trace, updater, txMgr)
-}
-
-// newTypedService creates a new Service from a provided trace provider.
-func newTypedService(ctx context.Context, logger log.Logger, cfg *config.Config, loader Loader, gameDepth uint64, client *ethclient.Client, provider types.TraceProvider, updater types.OracleUpdater, txMgr txmgr.TxManager) (*service, error) {
-	if err := ValidateAbsolutePrestate(ctx, provider, loader); err != nil {
-		return nil, fmt.Errorf("failed to validate absolute prestate: %w", err)
+	removeGameData := func(addr common.Address) error {
+		return RemoveGameData(cfg, addr)
 	}
 
-	gameLogger := logger.New("game", cfg.GameAddress)
-	responder, err := NewFaultResponder(gameLogger, txMgr, cfg.GameAddress)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create the responder: %w", err)
+	monitor := newGameMonitor(logger, source, st, m, loadMetadata, createPlayer, removeGameData,
+		cfg.GameAllowlist, cfg.GameTypes, cfg.MaxConcurrency, cfg.PollInterval)
+
+	var server *oprpc.Server
+	if cfg.RPCEnabled {
+		server = oprpc.NewServer(cfg.RPCConfig.ListenAddr, cfg.RPCConfig.ListenPort, version.Version, oprpc.WithLogger(logger))
+		server.AddAPI(gethrpc.API{
+			Namespace: rpc.Namespace,
+			Service:   rpc.NewChallengerAPI(monitor),
+		})
+		if err := server.Start(); err != nil {
+			_ = st.Close()
+			return nil, fmt.Errorf("failed to start the RPC server: %w", err)
+		}
+		logger.Info("Started RPC server", "endpoint", server.Endpoint())
 	}
 
-	caller, err := NewFaultCallerFromBindings(cfg.GameAddress, client, gameLogger)
-	if err != nil {
-		return nil, fmt.Errorf("failed to bind the fault contract: %w", err)
+	// Started last as the metrics server is only stopped with the context
+	if cfg.MetricsConfig.Enabled {
+		logger.Info("starting metrics server", "addr", cfg.MetricsConfig.ListenAddr, "port", cfg.MetricsConfig.ListenPort)
+		go func() {
+			if err := m.Serve(ctx, cfg.MetricsConfig.ListenAddr, cfg.MetricsConfig.ListenPort); err != nil {
+				logger.Error("error starting metrics server", "err", err)
+			}
+		}()
+		m.StartBalanceMetrics(ctx, logger, l1Client, txMgr.From())
 	}
 
 	return &service{
-		agent:                   NewAgent(loader, int(gameDepth), provider, responder, updater, cfg.AgreeWithProposedOutput, gameLogger),
-		agreeWithProposedOutput: cfg.AgreeWithProposedOutput,
-		caller:                  caller,
-		logger:                  gameLogger,
+		monitor: monitor,
+		store:   st,
+		server:  server,
 	}, nil
 }
 
@@ -121,7 +150,18 @@ func ValidateAbsolutePrestate(ctx context.Context, trace types.TraceProvider, lo
 	return nil
 }
 
-// MonitorGame monitors the fault dispute game and attempts to progress it.
-func (s *service) MonitorGame(ctx context.Context) error {
-	return MonitorGame(ctx, s.logger, s.agreeWithProposedOutput, s.agent, s.caller)
+// MonitorGames monitors the fault dispute games created by the factory and attempts to progress them.
+func (s *service) MonitorGames(ctx context.Context) error {
+	return s.monitor.MonitorGames(ctx)
+}
+
+// Close stops the RPC server, if enabled, and closes the challenger store.
+func (s *service) Close() error {
+	if s.server != nil {
+		if err := s.server.Stop(); err != nil {
+			_ = s.store.Close()
+			return fmt.Errorf("failed to stop the RPC server: %w", err)
+		}
+	}
+	return s.store.Close()
 }
@@ -94,6 +94,9 @@ func (m *mockLoader) FetchClaims(ctx context.Context) ([]types.Claim, error) {
 func (m *mockLoader) FetchGameDepth(ctx context.Context) (uint64, error) {
 	panic("not implemented")
 }
+func (m *mockLoader) FetchGameDuration(ctx context.Context) (uint64, error) {
+	panic("not implemented")
+}
 func (m *mockLoader) FetchAbsolutePrestateHash(ctx context.Context) ([]byte, error) {
 	if m.prestateError {
 		return nil, mockLoaderError
@@ -136,7 +136,11 @@ func (s *Solver) agreeWithClaim(ctx context.Context, claim types.ClaimData) (boo
 }
 
 // traceAtPosition returns the [common.Hash] from internal [TraceProvider] at the given [Position].
+// The trace index is used unless the provider is a [types.PositionTraceProvider].
 func (s *Solver) traceAtPosition(ctx context.Context, p types.Position) (common.Hash, error) {
+	if trace, ok := s.trace.(types.PositionTraceProvider); ok {
+		return trace.GetAtPosition(ctx, p)
+	}
 	index := p.TraceIndex(s.gameDepth)
 	hash, err := s.trace.Get(ctx, index)
 	return hash, err
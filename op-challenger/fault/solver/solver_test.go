@@ -8,6 +8,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/solver"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/test"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -101,6 +102,34 @@ func TestNextMove(t *testing.T) {
 	}
 }
 
+func TestNextMoveUsesPositionTraceProvider(t *testing.T) {
+	maxDepth := 4
+	builder := test.NewAlphabetClaimBuilder(t, maxDepth)
+	root := builder.CreateRootClaim(true)
+	trace := &positionTraceProvider{TraceProvider: builder.CorrectTraceProvider(), maxDepth: maxDepth, rootClaim: common.Hash{0xaa}}
+	solver := solver.NewSolver(maxDepth, trace)
+
+	// The root claim matches the trace index but not the claim at the root position, so must be attacked
+	move, err := solver.NextMove(context.Background(), root, false)
+	require.NoError(t, err)
+	require.NotNil(t, move)
+	require.False(t, move.DefendsParent())
+	require.Equal(t, types.NewPosition(1, 0), move.Position)
+}
+
+type positionTraceProvider struct {
+	types.TraceProvider
+	maxDepth  int
+	rootClaim common.Hash
+}
+
+func (p *positionTraceProvider) GetAtPosition(ctx context.Context, pos types.Position) (common.Hash, error) {
+	if pos.IsRootPosition() {
+		return p.rootClaim, nil
+	}
+	return p.TraceProvider.Get(ctx, pos.TraceIndex(p.maxDepth))
+}
+
 func TestAttemptStep(t *testing.T) {
 	maxDepth := 3
 	builder := test.NewAlphabetClaimBuilder(t, maxDepth)
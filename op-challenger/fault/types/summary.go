@@ -0,0 +1,49 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Action is the action the agent decided to take in response to a claim.
+type Action string
+
+const (
+	// ActionNone means no response is required, e.g. because the claim is on our side of the game.
+	ActionNone Action = "none"
+	// ActionAttack means the claim is attacked with a new claim.
+	ActionAttack Action = "attack"
+	// ActionDefend means the claim is defended with a new claim.
+	ActionDefend Action = "defend"
+	// ActionStep means a step is executed against the leaf claim.
+	ActionStep Action = "step"
+	// ActionExpired means a response is required but the clock for responding to the claim has expired.
+	ActionExpired Action = "expired"
+	// ActionSpendLimit means a response may be required but the spend limit for the game has been reached.
+	ActionSpendLimit Action = "spend-limit"
+)
+
+// ClaimSummary is the challenger's view of a single claim in a game.
+type ClaimSummary struct {
+	ContractIndex       int         `json:"contractIndex"`
+	ParentContractIndex int         `json:"parentContractIndex"`
+	Depth               int         `json:"depth"`
+	IndexAtDepth        int         `json:"indexAtDepth"`
+	TraceIndex          uint64      `json:"traceIndex"`
+	Value               common.Hash `json:"value"`
+	Countered           bool        `json:"countered"`
+	// Agree is true if the claim is on the same side of the game as the challenger.
+	Agree bool `json:"agree"`
+	// Deadline is the latest L1 timestamp at which a move against the claim is accepted.
+	Deadline uint64 `json:"deadline"`
+	// NextAction is the action decided on the last time the game was progressed.
+	NextAction Action `json:"nextAction"`
+}
+
+// GameSummary is the challenger's view of a game as of the last time it was progressed.
+type GameSummary struct {
+	Address                 common.Address `json:"address"`
+	Status                  GameStatus     `json:"status"`
+	AgreeWithProposedOutput bool           `json:"agreeWithProposedOutput"`
+	ClaimCount              int            `json:"claimCount"`
+	Claims                  []ClaimSummary `json:"claims,omitempty"`
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"errors"
+	"math"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -84,6 +85,16 @@ type TraceProvider interface {
 	AbsolutePreState(ctx context.Context) (preimage []byte, err error)
 }
 
+// PositionTraceProvider is a [TraceProvider] where the claim value depends on the depth of the position and
+// not only on its trace index, such as when positions above a split depth commit to different data than the
+// leaf positions sharing the same trace index. [TraceProvider.Get] then only returns the claims of leaf positions.
+type PositionTraceProvider interface {
+	TraceProvider
+
+	// GetAtPosition returns the claim value at the requested position.
+	GetAtPosition(ctx context.Context, pos Position) (common.Hash, error)
+}
+
 // ClaimData is the core of a claim. It must be unique inside a specific game.
 type ClaimData struct {
 	Value common.Hash
@@ -97,6 +108,25 @@ func (c *ClaimData) ValueBytes() [32]byte {
 	return responseArr
 }
 
+// Clock is the chess clock of a claim as stored in the FaultDisputeGame contract.
+// The contract packs the duration into the high-order 64 bits and the timestamp into the low-order 64 bits.
+type Clock struct {
+	// Duration is the time in seconds accumulated on the clock at the time the claim was made.
+	Duration uint64
+	// Timestamp is the block timestamp at which the claim was made.
+	Timestamp uint64
+}
+
+// NewClockFromPacked unpacks a [Clock] from the packed representation used by the FaultDisputeGame contract.
+func NewClockFromPacked(packed *big.Int) Clock {
+	duration := new(big.Int).Rsh(packed, 64)
+	timestamp := new(big.Int).And(packed, new(big.Int).SetUint64(math.MaxUint64))
+	return Clock{
+		Duration:  duration.Uint64(),
+		Timestamp: timestamp.Uint64(),
+	}
+}
+
 // Claim extends ClaimData with information about the relationship between two claims.
 // It uses ClaimData to break cyclicity without using pointers.
 // If the position of the game is Depth 0, IndexAtDepth 0 it is the root claim
@@ -108,7 +138,7 @@ type Claim struct {
 	//       When caching is implemented for the Challenger, this will need
 	//       to be changed/removed to avoid invalid/stale contract state.
 	Countered bool
-	Clock     uint64
+	Clock     Clock
 	Parent    ClaimData
 	// Location of the claim & it's parent inside the contract. Does not exist
 	// for claims that have not made it to the contract.
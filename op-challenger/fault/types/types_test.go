@@ -1,6 +1,8 @@
 package types
 
 import (
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -23,3 +25,21 @@ func TestNewPreimageOracleData(t *testing.T) {
 		require.Equal(t, uint32(7), data.OracleOffset)
 	})
 }
+
+func TestNewClockFromPacked(t *testing.T) {
+	t.Run("Zero", func(t *testing.T) {
+		require.Equal(t, Clock{}, NewClockFromPacked(big.NewInt(0)))
+	})
+
+	t.Run("DurationAndTimestamp", func(t *testing.T) {
+		packed := new(big.Int).Lsh(big.NewInt(30), 64)
+		packed.Or(packed, big.NewInt(1000))
+		require.Equal(t, Clock{Duration: 30, Timestamp: 1000}, NewClockFromPacked(packed))
+	})
+
+	t.Run("MaxValues", func(t *testing.T) {
+		packed := new(big.Int).Lsh(new(big.Int).SetUint64(math.MaxUint64), 64)
+		packed.Or(packed, new(big.Int).SetUint64(math.MaxUint64))
+		require.Equal(t, Clock{Duration: math.MaxUint64, Timestamp: math.MaxUint64}, NewClockFromPacked(packed))
+	})
+}
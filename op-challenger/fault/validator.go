@@ -0,0 +1,115 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ProposalValidator determines whether the challenger agrees with the output proposed in a fault dispute game.
+// Agreeing with the proposed output means disagreeing with the root claim.
+type ProposalValidator interface {
+	AgreeWithProposedOutput(ctx context.Context) (bool, error)
+}
+
+// ProposalSource is a minimal interface around [bindings.FaultDisputeGameCaller] to load the game's output proposals.
+type ProposalSource interface {
+	Proposals(opts *bind.CallOpts) (struct {
+		Starting bindings.IFaultDisputeGameOutputProposal
+		Disputed bindings.IFaultDisputeGameOutputProposal
+	}, error)
+}
+
+// OutputRollupClient is a minimal interface around [sources.RollupClient] to load output roots.
+type OutputRollupClient interface {
+	OutputAtBlock(ctx context.Context, blockNum uint64) (*eth.OutputResponse, error)
+}
+
+// outputValidator compares the output proposed in the game to the output root reported by the rollup node.
+type outputValidator struct {
+	logger log.Logger
+	caller ProposalSource
+	rollup OutputRollupClient
+}
+
+// NewOutputValidator creates a new [outputValidator].
+func NewOutputValidator(logger log.Logger, caller ProposalSource, rollup OutputRollupClient) *outputValidator {
+	return &outputValidator{
+		logger: logger,
+		caller: caller,
+		rollup: rollup,
+	}
+}
+
+// AgreeWithProposedOutput returns true if the output root disputed in the game matches the output root
+// the rollup node computes for the same L2 block.
+func (v *outputValidator) AgreeWithProposedOutput(ctx context.Context) (bool, error) {
+	proposals, err := v.caller.Proposals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch proposals: %w", err)
+	}
+	disputed := proposals.Disputed
+	output, err := v.rollup.OutputAtBlock(ctx, disputed.L2BlockNumber.Uint64())
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch output at block %v: %w", disputed.L2BlockNumber, err)
+	}
+	expected := common.Hash(output.OutputRoot)
+	proposed := common.Hash(disputed.OutputRoot)
+	if expected != proposed {
+		v.logger.Warn("Disagree with proposed output", "l2BlockNumber", disputed.L2BlockNumber,
+			"proposed", proposed, "expected", expected)
+		return false, nil
+	}
+	v.logger.Info("Agree with proposed output", "l2BlockNumber", disputed.L2BlockNumber, "output", proposed)
+	return true, nil
+}
+
+// RootClaimSource is a minimal interface around [bindings.FaultDisputeGameCaller] to load the game's root claim.
+type RootClaimSource interface {
+	RootClaim(opts *bind.CallOpts) ([32]byte, error)
+}
+
+// traceValidator compares the root claim of the game to the final claim in the trace.
+// It is used for trace types that are not backed by real L2 outputs.
+type traceValidator struct {
+	logger   log.Logger
+	caller   RootClaimSource
+	trace    types.TraceProvider
+	maxDepth uint64
+}
+
+// NewTraceValidator creates a new [traceValidator].
+func NewTraceValidator(logger log.Logger, caller RootClaimSource, trace types.TraceProvider, maxDepth uint64) *traceValidator {
+	return &traceValidator{
+		logger:   logger,
+		caller:   caller,
+		trace:    trace,
+		maxDepth: maxDepth,
+	}
+}
+
+// AgreeWithProposedOutput returns true if the root claim does not match the final claim in the trace.
+func (v *traceValidator) AgreeWithProposedOutput(ctx context.Context) (bool, error) {
+	rootClaim, err := v.caller.RootClaim(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch root claim: %w", err)
+	}
+	rootPosition := types.NewPosition(0, 0)
+	expected, err := v.trace.Get(ctx, rootPosition.TraceIndex(int(v.maxDepth)))
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch final trace claim: %w", err)
+	}
+	agreeWithRootClaim := common.Hash(rootClaim) == expected
+	if agreeWithRootClaim {
+		v.logger.Warn("Agree with root claim, so disagree with proposed output", "rootClaim", common.Hash(rootClaim))
+	} else {
+		v.logger.Info("Disagree with root claim, so agree with proposed output", "rootClaim", common.Hash(rootClaim), "expected", expected)
+	}
+	return !agreeWithRootClaim, nil
+}
@@ -0,0 +1,132 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/alphabet"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockProposalsError = errors.New("proposals errored")
+	mockOutputError    = errors.New("output errored")
+	mockRootClaimError = errors.New("root claim errored")
+)
+
+func TestOutputValidator(t *testing.T) {
+	proposedOutput := common.Hash{0xaa}
+	l2BlockNumber := uint64(42)
+
+	t.Run("AgreeWhenOutputsMatch", func(t *testing.T) {
+		caller := &stubProposalSource{outputRoot: proposedOutput, l2BlockNumber: l2BlockNumber}
+		rollup := &stubRollupClient{outputs: map[uint64]common.Hash{l2BlockNumber: proposedOutput}}
+		validator := NewOutputValidator(testlog.Logger(t, log.LvlInfo), caller, rollup)
+		agree, err := validator.AgreeWithProposedOutput(context.Background())
+		require.NoError(t, err)
+		require.True(t, agree)
+	})
+
+	t.Run("DisagreeWhenOutputsDiffer", func(t *testing.T) {
+		caller := &stubProposalSource{outputRoot: proposedOutput, l2BlockNumber: l2BlockNumber}
+		rollup := &stubRollupClient{outputs: map[uint64]common.Hash{l2BlockNumber: {0xbb}}}
+		validator := NewOutputValidator(testlog.Logger(t, log.LvlInfo), caller, rollup)
+		agree, err := validator.AgreeWithProposedOutput(context.Background())
+		require.NoError(t, err)
+		require.False(t, agree)
+	})
+
+	t.Run("ProposalsError", func(t *testing.T) {
+		caller := &stubProposalSource{err: mockProposalsError}
+		validator := NewOutputValidator(testlog.Logger(t, log.LvlInfo), caller, &stubRollupClient{})
+		_, err := validator.AgreeWithProposedOutput(context.Background())
+		require.ErrorIs(t, err, mockProposalsError)
+	})
+
+	t.Run("OutputError", func(t *testing.T) {
+		caller := &stubProposalSource{outputRoot: proposedOutput, l2BlockNumber: l2BlockNumber}
+		validator := NewOutputValidator(testlog.Logger(t, log.LvlInfo), caller, &stubRollupClient{})
+		_, err := validator.AgreeWithProposedOutput(context.Background())
+		require.ErrorIs(t, err, mockOutputError)
+	})
+}
+
+func TestTraceValidator(t *testing.T) {
+	maxDepth := uint64(3)
+	trace := alphabet.NewTraceProvider("abcdefgh", maxDepth)
+	correctRoot, err := trace.Get(context.Background(), 7)
+	require.NoError(t, err)
+
+	t.Run("DisagreeWithOutputWhenRootClaimMatches", func(t *testing.T) {
+		validator := NewTraceValidator(testlog.Logger(t, log.LvlInfo), &stubRootClaimSource{rootClaim: correctRoot}, trace, maxDepth)
+		agree, err := validator.AgreeWithProposedOutput(context.Background())
+		require.NoError(t, err)
+		require.False(t, agree)
+	})
+
+	t.Run("AgreeWithOutputWhenRootClaimDiffers", func(t *testing.T) {
+		validator := NewTraceValidator(testlog.Logger(t, log.LvlInfo), &stubRootClaimSource{rootClaim: common.Hash{0xaa}}, trace, maxDepth)
+		agree, err := validator.AgreeWithProposedOutput(context.Background())
+		require.NoError(t, err)
+		require.True(t, agree)
+	})
+
+	t.Run("RootClaimError", func(t *testing.T) {
+		validator := NewTraceValidator(testlog.Logger(t, log.LvlInfo), &stubRootClaimSource{err: mockRootClaimError}, trace, maxDepth)
+		_, err := validator.AgreeWithProposedOutput(context.Background())
+		require.ErrorIs(t, err, mockRootClaimError)
+	})
+}
+
+type stubProposalSource struct {
+	outputRoot    common.Hash
+	l2BlockNumber uint64
+	err           error
+}
+
+func (s *stubProposalSource) Proposals(_ *bind.CallOpts) (struct {
+	Starting bindings.IFaultDisputeGameOutputProposal
+	Disputed bindings.IFaultDisputeGameOutputProposal
+}, error) {
+	result := struct {
+		Starting bindings.IFaultDisputeGameOutputProposal
+		Disputed bindings.IFaultDisputeGameOutputProposal
+	}{}
+	if s.err != nil {
+		return result, s.err
+	}
+	result.Disputed = bindings.IFaultDisputeGameOutputProposal{
+		OutputRoot:    s.outputRoot,
+		L2BlockNumber: new(big.Int).SetUint64(s.l2BlockNumber),
+	}
+	return result, nil
+}
+
+type stubRollupClient struct {
+	outputs map[uint64]common.Hash
+}
+
+func (s *stubRollupClient) OutputAtBlock(_ context.Context, blockNum uint64) (*eth.OutputResponse, error) {
+	output, ok := s.outputs[blockNum]
+	if !ok {
+		return nil, mockOutputError
+	}
+	return &eth.OutputResponse{OutputRoot: eth.Bytes32(output)}, nil
+}
+
+type stubRootClaimSource struct {
+	rootClaim common.Hash
+	err       error
+}
+
+func (s *stubRootClaimSource) RootClaim(_ *bind.CallOpts) ([32]byte, error) {
+	return s.rootClaim, s.err
+}
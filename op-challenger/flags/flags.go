@@ -2,6 +2,8 @@ package flags
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
@@ -9,8 +11,12 @@ import (
 	opservice "github.com/ethereum-optimism/optimism/op-service"
 	openum "github.com/ethereum-optimism/optimism/op-service/enum"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
+	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
+	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/urfave/cli/v2"
 )
 
@@ -29,10 +35,10 @@ var (
 		Usage:   "HTTP provider URL for L1.",
 		EnvVars: prefixEnvVars("L1_ETH_RPC"),
 	}
-	DGFAddressFlag = &cli.StringFlag{
-		Name:    "game-address",
-		Usage:   "Address of the Fault Game contract.",
-		EnvVars: prefixEnvVars("GAME_ADDRESS"),
+	FactoryAddressFlag = &cli.StringFlag{
+		Name:    "game-factory-address",
+		Usage:   "Address of the Dispute Game Factory contract.",
+		EnvVars: prefixEnvVars("GAME_FACTORY_ADDRESS"),
 	}
 	TraceTypeFlag = &cli.GenericFlag{
 		Name:    "trace-type",
@@ -43,12 +49,45 @@ var (
 			return &out
 		}(),
 	}
-	AgreeWithProposedOutputFlag = &cli.BoolFlag{
-		Name:    "agree-with-proposed-output",
-		Usage:   "Temporary hardcoded flag if we agree or disagree with the proposed output.",
-		EnvVars: prefixEnvVars("AGREE_WITH_PROPOSED_OUTPUT"),
-	}
 	// Optional Flags
+	RollupRpcFlag = &cli.StringFlag{
+		Name:    "rollup-rpc",
+		Usage:   "HTTP provider URL for the rollup node, used to determine the correct output root (cannon trace type only)",
+		EnvVars: prefixEnvVars("ROLLUP_RPC"),
+	}
+	GameAllowlistFlag = &cli.StringSliceFlag{
+		Name: "game-allowlist",
+		Usage: "List of Fault Game contract addresses the challenger is allowed to play. " +
+			"If empty, the challenger will play all games created by the factory.",
+		EnvVars: prefixEnvVars("GAME_ALLOWLIST"),
+	}
+	GameTypesFlag = &cli.Uint64SliceFlag{
+		Name:    "game-types",
+		Usage:   "List of game types the challenger is allowed to play. If empty, the challenger will play all game types.",
+		EnvVars: prefixEnvVars("GAME_TYPES"),
+	}
+	MaxConcurrencyFlag = &cli.UintFlag{
+		Name:    "max-concurrency",
+		Usage:   "Maximum number of games to progress concurrently",
+		EnvVars: prefixEnvVars("MAX_CONCURRENCY"),
+		Value:   config.DefaultMaxConcurrency,
+	}
+	PollIntervalFlag = &cli.DurationFlag{
+		Name:    "poll-interval",
+		Usage:   "How frequently to poll the factory for new games and progress in-progress games",
+		EnvVars: prefixEnvVars("POLL_INTERVAL"),
+		Value:   config.DefaultPollInterval,
+	}
+	MaxSpendPerGameFlag = &cli.Uint64Flag{
+		Name:    "max-spend-per-game",
+		Usage:   "Maximum amount (in gwei) to spend on transaction fees for moves and steps in a single game. 0 for no limit",
+		EnvVars: prefixEnvVars("MAX_SPEND_PER_GAME"),
+	}
+	DatadirFlag = &cli.StringFlag{
+		Name:    "datadir",
+		Usage:   "Directory to store challenger state in so it can resume games after a restart. If empty, state is only held in memory",
+		EnvVars: prefixEnvVars("DATADIR"),
+	}
 	AlphabetFlag = &cli.StringFlag{
 		Name:    "alphabet",
 		Usage:   "Correct Alphabet Trace (alphabet trace type only)",
@@ -69,6 +108,11 @@ var (
 		Usage:   "Path to the op-geth genesis file (cannon trace type only)",
 		EnvVars: prefixEnvVars("CANNON_L2_GENESIS"),
 	}
+	CannonInProcessFlag = &cli.BoolFlag{
+		Name:    "cannon-in-process",
+		Usage:   "Execute cannon and the pre-image oracle server within the challenger process instead of running the cannon-bin and cannon-server executables (cannon trace type only)",
+		EnvVars: prefixEnvVars("CANNON_IN_PROCESS"),
+	}
 	CannonBinFlag = &cli.StringFlag{
 		Name:    "cannon-bin",
 		Usage:   "Path to cannon executable to use when generating trace data (cannon trace type only)",
@@ -100,33 +144,75 @@ var (
 		EnvVars: prefixEnvVars("CANNON_SNAPSHOT_FREQ"),
 		Value:   config.DefaultCannonSnapshotFreq,
 	}
+	CannonMaxSnapshotsFlag = &cli.UintFlag{
+		Name:    "cannon-max-snapshots",
+		Usage:   "Maximum number of cannon snapshots to retain per game, 0 to retain all snapshots (cannon trace type only)",
+		EnvVars: prefixEnvVars("CANNON_MAX_SNAPSHOTS"),
+		Value:   config.DefaultCannonMaxSnapshots,
+	}
+	CannonWorkersFlag = &cli.UintFlag{
+		Name:    "cannon-workers",
+		Usage:   "Maximum number of concurrent cannon executions per game (cannon trace type only)",
+		EnvVars: prefixEnvVars("CANNON_WORKERS"),
+		Value:   config.DefaultCannonWorkers,
+	}
+	CannonProofCacheSizeFlag = &cli.UintFlag{
+		Name:    "cannon-proof-cache-size",
+		Usage:   "Number of cannon proofs to keep in memory and on disk per game (cannon trace type only)",
+		EnvVars: prefixEnvVars("CANNON_PROOF_CACHE_SIZE"),
+		Value:   config.DefaultCannonProofCacheSize,
+	}
+	OutputSplitDepthFlag = &cli.Uint64Flag{
+		Name:    "output-split-depth",
+		Usage:   "Depth of the game at which claims switch from output roots to cannon traces (output_cannon trace type only)",
+		EnvVars: prefixEnvVars("OUTPUT_SPLIT_DEPTH"),
+	}
+	RPCEnabledFlag = &cli.BoolFlag{
+		Name:    "rpc.enabled",
+		Usage:   "Enable the RPC server that reports the state of the games being played",
+		EnvVars: prefixEnvVars("RPC_ENABLED"),
+	}
 )
 
 // requiredFlags are checked by [CheckRequired]
 var requiredFlags = []cli.Flag{
 	L1EthRpcFlag,
-	DGFAddressFlag,
+	FactoryAddressFlag,
 	TraceTypeFlag,
-	AgreeWithProposedOutputFlag,
 }
 
 // optionalFlags is a list of unchecked cli flags
 var optionalFlags = []cli.Flag{
+	RollupRpcFlag,
+	GameAllowlistFlag,
+	GameTypesFlag,
+	MaxConcurrencyFlag,
+	PollIntervalFlag,
+	MaxSpendPerGameFlag,
+	DatadirFlag,
 	AlphabetFlag,
 	CannonNetworkFlag,
 	CannonRollupConfigFlag,
 	CannonL2GenesisFlag,
+	CannonInProcessFlag,
 	CannonBinFlag,
 	CannonServerFlag,
 	CannonPreStateFlag,
 	CannonDatadirFlag,
 	CannonL2Flag,
 	CannonSnapshotFreqFlag,
+	CannonMaxSnapshotsFlag,
+	CannonWorkersFlag,
+	CannonProofCacheSizeFlag,
+	OutputSplitDepthFlag,
+	RPCEnabledFlag,
 }
 
 func init() {
 	optionalFlags = append(optionalFlags, oplog.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, txmgr.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, opmetrics.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, oprpc.CLIFlags(envVarPrefix)...)
 
 	Flags = append(requiredFlags, optionalFlags...)
 }
@@ -142,7 +228,10 @@ func CheckRequired(ctx *cli.Context) error {
 	}
 	gameType := config.TraceType(strings.ToLower(ctx.String(TraceTypeFlag.Name)))
 	switch gameType {
-	case config.TraceTypeCannon:
+	case config.TraceTypeCannon, config.TraceTypeOutputCannon:
+		if !ctx.IsSet(RollupRpcFlag.Name) {
+			return fmt.Errorf("flag %s is required", RollupRpcFlag.Name)
+		}
 		if !ctx.IsSet(CannonNetworkFlag.Name) && !(ctx.IsSet(CannonRollupConfigFlag.Name) && ctx.IsSet(CannonL2GenesisFlag.Name)) {
 			return fmt.Errorf("flag %v or %v and %v is required",
 				CannonNetworkFlag.Name, CannonRollupConfigFlag.Name, CannonL2GenesisFlag.Name)
@@ -151,11 +240,13 @@ func CheckRequired(ctx *cli.Context) error {
 			return fmt.Errorf("flag %v can not be used with %v and %v",
 				CannonNetworkFlag.Name, CannonRollupConfigFlag.Name, CannonL2GenesisFlag.Name)
 		}
-		if !ctx.IsSet(CannonBinFlag.Name) {
-			return fmt.Errorf("flag %s is required", CannonBinFlag.Name)
-		}
-		if !ctx.IsSet(CannonServerFlag.Name) {
-			return fmt.Errorf("flag %s is required", CannonServerFlag.Name)
+		if !ctx.Bool(CannonInProcessFlag.Name) {
+			if !ctx.IsSet(CannonBinFlag.Name) {
+				return fmt.Errorf("flag %s is required", CannonBinFlag.Name)
+			}
+			if !ctx.IsSet(CannonServerFlag.Name) {
+				return fmt.Errorf("flag %s is required", CannonServerFlag.Name)
+			}
 		}
 		if !ctx.IsSet(CannonPreStateFlag.Name) {
 			return fmt.Errorf("flag %s is required", CannonPreStateFlag.Name)
@@ -166,6 +257,9 @@ func CheckRequired(ctx *cli.Context) error {
 		if !ctx.IsSet(CannonL2Flag.Name) {
 			return fmt.Errorf("flag %s is required", CannonL2Flag.Name)
 		}
+		if gameType == config.TraceTypeOutputCannon && !ctx.IsSet(OutputSplitDepthFlag.Name) {
+			return fmt.Errorf("flag %s is required", OutputSplitDepthFlag.Name)
+		}
 	case config.TraceTypeAlphabet:
 		if !ctx.IsSet(AlphabetFlag.Name) {
 			return fmt.Errorf("flag %s is required", "alphabet")
@@ -181,10 +275,27 @@ func NewConfigFromCLI(ctx *cli.Context) (*config.Config, error) {
 	if err := CheckRequired(ctx); err != nil {
 		return nil, err
 	}
-	dgfAddress, err := opservice.ParseAddress(ctx.String(DGFAddressFlag.Name))
+	gameFactoryAddress, err := opservice.ParseAddress(ctx.String(FactoryAddressFlag.Name))
 	if err != nil {
 		return nil, err
 	}
+	var allowedGames []common.Address
+	for _, addr := range ctx.StringSlice(GameAllowlistFlag.Name) {
+		gameAddress, err := opservice.ParseAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+		allowedGames = append(allowedGames, gameAddress)
+	}
+	var gameTypes []uint8
+	for _, gameType := range ctx.Uint64Slice(GameTypesFlag.Name) {
+		if gameType > math.MaxUint8 {
+			return nil, fmt.Errorf("invalid game type: %v", gameType)
+		}
+		gameTypes = append(gameTypes, uint8(gameType))
+	}
+
+	maxSpendPerGame := new(big.Int).Mul(new(big.Int).SetUint64(ctx.Uint64(MaxSpendPerGameFlag.Name)), big.NewInt(params.GWei))
 
 	txMgrConfig := txmgr.ReadCLIConfig(ctx)
 
@@ -192,20 +303,34 @@ func NewConfigFromCLI(ctx *cli.Context) (*config.Config, error) {
 
 	return &config.Config{
 		// Required Flags
-		L1EthRpc:                ctx.String(L1EthRpcFlag.Name),
-		TraceType:               traceTypeFlag,
-		GameAddress:             dgfAddress,
-		AlphabetTrace:           ctx.String(AlphabetFlag.Name),
-		CannonNetwork:           ctx.String(CannonNetworkFlag.Name),
-		CannonRollupConfigPath:  ctx.String(CannonRollupConfigFlag.Name),
-		CannonL2GenesisPath:     ctx.String(CannonL2GenesisFlag.Name),
-		CannonBin:               ctx.String(CannonBinFlag.Name),
-		CannonServer:            ctx.String(CannonServerFlag.Name),
-		CannonAbsolutePreState:  ctx.String(CannonPreStateFlag.Name),
-		CannonDatadir:           ctx.String(CannonDatadirFlag.Name),
-		CannonL2:                ctx.String(CannonL2Flag.Name),
-		CannonSnapshotFreq:      ctx.Uint(CannonSnapshotFreqFlag.Name),
-		AgreeWithProposedOutput: ctx.Bool(AgreeWithProposedOutputFlag.Name),
-		TxMgrConfig:             txMgrConfig,
+		L1EthRpc:               ctx.String(L1EthRpcFlag.Name),
+		RollupRpc:              ctx.String(RollupRpcFlag.Name),
+		TraceType:              traceTypeFlag,
+		GameFactoryAddress:     gameFactoryAddress,
+		GameAllowlist:          allowedGames,
+		GameTypes:              gameTypes,
+		MaxConcurrency:         ctx.Uint(MaxConcurrencyFlag.Name),
+		PollInterval:           ctx.Duration(PollIntervalFlag.Name),
+		MaxSpendPerGame:        maxSpendPerGame,
+		Datadir:                ctx.String(DatadirFlag.Name),
+		AlphabetTrace:          ctx.String(AlphabetFlag.Name),
+		CannonNetwork:          ctx.String(CannonNetworkFlag.Name),
+		CannonRollupConfigPath: ctx.String(CannonRollupConfigFlag.Name),
+		CannonL2GenesisPath:    ctx.String(CannonL2GenesisFlag.Name),
+		CannonInProcess:        ctx.Bool(CannonInProcessFlag.Name),
+		CannonBin:              ctx.String(CannonBinFlag.Name),
+		CannonServer:           ctx.String(CannonServerFlag.Name),
+		CannonAbsolutePreState: ctx.String(CannonPreStateFlag.Name),
+		CannonDatadir:          ctx.String(CannonDatadirFlag.Name),
+		CannonL2:               ctx.String(CannonL2Flag.Name),
+		CannonSnapshotFreq:     ctx.Uint(CannonSnapshotFreqFlag.Name),
+		CannonMaxSnapshots:     ctx.Uint(CannonMaxSnapshotsFlag.Name),
+		CannonWorkers:          ctx.Uint(CannonWorkersFlag.Name),
+		CannonProofCacheSize:   ctx.Uint(CannonProofCacheSizeFlag.Name),
+		OutputSplitDepth:       ctx.Uint64(OutputSplitDepthFlag.Name),
+		TxMgrConfig:            txMgrConfig,
+		MetricsConfig:          opmetrics.ReadCLIConfig(ctx),
+		RPCEnabled:             ctx.Bool(RPCEnabledFlag.Name),
+		RPCConfig:              oprpc.ReadCLIConfig(ctx),
 	}, nil
 }
@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
+	txmetrics "github.com/ethereum-optimism/optimism/op-service/txmgr/metrics"
+)
+
+const Namespace = "op_challenger"
+
+type Metricer interface {
+	RecordInfo(version string)
+	RecordUp()
+
+	// Record Tx metrics
+	txmetrics.TxMetricer
+
+	RecordProposedOutputOpinion(agree bool)
+
+	RecordTrackedGames(count int)
+	RecordClaims(agree int, disagree int)
+	RecordMove()
+	RecordStep()
+	RecordOracleUpdate()
+	RecordCannonExecutionTime(t float64)
+}
+
+type Metrics struct {
+	ns       string
+	registry *prometheus.Registry
+	factory  opmetrics.Factory
+
+	txmetrics.TxMetrics
+
+	info prometheus.GaugeVec
+	up   prometheus.Gauge
+
+	proposedOutputOpinions prometheus.CounterVec
+
+	trackedGames        prometheus.Gauge
+	claims              prometheus.GaugeVec
+	moves               prometheus.Counter
+	steps               prometheus.Counter
+	oracleUpdates       prometheus.Counter
+	cannonExecutionTime prometheus.Histogram
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+func NewMetrics() *Metrics {
+	registry := opmetrics.NewRegistry()
+	factory := opmetrics.With(registry)
+
+	return &Metrics{
+		ns:       Namespace,
+		registry: registry,
+		factory:  factory,
+
+		TxMetrics: txmetrics.MakeTxMetrics(Namespace, factory),
+
+		info: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "info",
+			Help:      "Pseudo-metric tracking version and config info",
+		}, []string{
+			"version",
+		}),
+		up: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "up",
+			Help:      "1 if the op-challenger has finished starting up",
+		}),
+		proposedOutputOpinions: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "proposed_output_opinions",
+			Help:      "Number of games where the challenger agreed or disagreed with the proposed output",
+		}, []string{
+			"opinion",
+		}),
+		trackedGames: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "tracked_games",
+			Help:      "Number of games currently being progressed",
+		}),
+		claims: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "claims",
+			Help:      "Number of claims in tracked games, by whether the challenger agrees with them",
+		}, []string{
+			"agreement",
+		}),
+		moves: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "moves",
+			Help:      "Number of moves made by the challenger",
+		}),
+		steps: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "steps",
+			Help:      "Number of steps made by the challenger",
+		}),
+		oracleUpdates: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "oracle_updates",
+			Help:      "Number of preimage oracle updates made by the challenger",
+		}),
+		cannonExecutionTime: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "cannon_execution_time",
+			Help:      "Time (in seconds) to execute cannon",
+			Buckets: append(
+				[]float64{1.0, 10.0},
+				prometheus.ExponentialBuckets(30.0, 2.0, 14)...),
+		}),
+	}
+}
+
+func (m *Metrics) Serve(ctx context.Context, host string, port int) error {
+	return opmetrics.ListenAndServe(ctx, m.registry, host, port)
+}
+
+func (m *Metrics) StartBalanceMetrics(ctx context.Context, l log.Logger, client *ethclient.Client, account common.Address) {
+	opmetrics.LaunchBalanceMetrics(ctx, l, m.registry, m.ns, client, account)
+}
+
+// RecordInfo sets a pseudo-metric that contains versioning and
+// config info for the op-challenger.
+func (m *Metrics) RecordInfo(version string) {
+	m.info.WithLabelValues(version).Set(1)
+}
+
+// RecordUp sets the up metric to 1.
+func (m *Metrics) RecordUp() {
+	prometheus.MustRegister()
+	m.up.Set(1)
+}
+
+// RecordProposedOutputOpinion records whether the challenger agreed with the output proposed in a game.
+func (m *Metrics) RecordProposedOutputOpinion(agree bool) {
+	if agree {
+		m.proposedOutputOpinions.WithLabelValues("agree").Inc()
+	} else {
+		m.proposedOutputOpinions.WithLabelValues("disagree").Inc()
+	}
+}
+
+// RecordTrackedGames sets the number of games currently being progressed.
+func (m *Metrics) RecordTrackedGames(count int) {
+	m.trackedGames.Set(float64(count))
+}
+
+// RecordClaims sets the number of claims in tracked games the challenger agrees and disagrees with.
+func (m *Metrics) RecordClaims(agree int, disagree int) {
+	m.claims.WithLabelValues("agree").Set(float64(agree))
+	m.claims.WithLabelValues("disagree").Set(float64(disagree))
+}
+
+func (m *Metrics) RecordMove() {
+	m.moves.Inc()
+}
+
+func (m *Metrics) RecordStep() {
+	m.steps.Inc()
+}
+
+func (m *Metrics) RecordOracleUpdate() {
+	m.oracleUpdates.Inc()
+}
+
+// RecordCannonExecutionTime records the time in seconds taken to run cannon.
+func (m *Metrics) RecordCannonExecutionTime(t float64) {
+	m.cannonExecutionTime.Observe(t)
+}
+
+func (m *Metrics) Document() []opmetrics.DocumentedMetric {
+	return m.factory.Document()
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	txmetrics "github.com/ethereum-optimism/optimism/op-service/txmgr/metrics"
+)
+
+type noopMetrics struct {
+	txmetrics.NoopTxMetrics
+}
+
+var NoopMetrics Metricer = new(noopMetrics)
+
+func (*noopMetrics) RecordInfo(version string) {}
+func (*noopMetrics) RecordUp()                 {}
+
+func (*noopMetrics) RecordProposedOutputOpinion(agree bool) {}
+
+func (*noopMetrics) RecordTrackedGames(count int)         {}
+func (*noopMetrics) RecordClaims(agree int, disagree int) {}
+func (*noopMetrics) RecordMove()                          {}
+func (*noopMetrics) RecordStep()                          {}
+func (*noopMetrics) RecordOracleUpdate()                  {}
+func (*noopMetrics) RecordCannonExecutionTime(t float64)  {}
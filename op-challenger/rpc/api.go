@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Namespace is the RPC namespace the challenger API is served under.
+const Namespace = "challenger"
+
+var ErrUnknownGame = errors.New("unknown game")
+
+type gameSource interface {
+	Games() []types.GameSummary
+	Game(addr common.Address) (types.GameSummary, bool)
+}
+
+// challengerAPI reports the state of the games the challenger is progressing.
+type challengerAPI struct {
+	games gameSource
+}
+
+func NewChallengerAPI(games gameSource) *challengerAPI {
+	return &challengerAPI{
+		games: games,
+	}
+}
+
+// ListGames returns a summary of each game being progressed, without the claims in the game.
+func (a *challengerAPI) ListGames(_ context.Context) ([]types.GameSummary, error) {
+	games := a.games.Games()
+	for i := range games {
+		games[i].Claims = nil
+	}
+	return games, nil
+}
+
+// GetGame returns a summary of the game at addr including the claim tree, whether the challenger agrees
+// with each claim and the action it decided to take in response.
+func (a *challengerAPI) GetGame(_ context.Context, addr common.Address) (types.GameSummary, error) {
+	game, ok := a.games.Game(addr)
+	if !ok {
+		return types.GameSummary{}, fmt.Errorf("%w: %v", ErrUnknownGame, addr)
+	}
+	return game, nil
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	gameA = types.GameSummary{
+		Address:    common.Address{0xaa},
+		Status:     types.GameStatusInProgress,
+		ClaimCount: 1,
+		Claims: []types.ClaimSummary{
+			{ContractIndex: 0, Value: common.Hash{0x01}, NextAction: types.ActionAttack},
+		},
+	}
+	gameB = types.GameSummary{
+		Address:                 common.Address{0xbb},
+		Status:                  types.GameStatusDefenderWon,
+		AgreeWithProposedOutput: true,
+	}
+)
+
+func TestListGamesOmitsClaims(t *testing.T) {
+	api := NewChallengerAPI(&stubGameSource{games: []types.GameSummary{gameA, gameB}})
+	games, err := api.ListGames(context.Background())
+	require.NoError(t, err)
+	require.Len(t, games, 2)
+	require.Equal(t, gameA.Address, games[0].Address)
+	require.Equal(t, 1, games[0].ClaimCount)
+	require.Nil(t, games[0].Claims)
+	require.Equal(t, gameB, games[1])
+}
+
+func TestGetGame(t *testing.T) {
+	api := NewChallengerAPI(&stubGameSource{games: []types.GameSummary{gameA, gameB}})
+	game, err := api.GetGame(context.Background(), gameA.Address)
+	require.NoError(t, err)
+	require.Equal(t, gameA, game)
+
+	_, err = api.GetGame(context.Background(), common.Address{0xcc})
+	require.ErrorIs(t, err, ErrUnknownGame)
+}
+
+type stubGameSource struct {
+	games []types.GameSummary
+}
+
+func (s *stubGameSource) Games() []types.GameSummary {
+	return append([]types.GameSummary(nil), s.games...)
+}
+
+func (s *stubGameSource) Game(addr common.Address) (types.GameSummary, bool) {
+	for _, game := range s.games {
+		if game.Address == addr {
+			return game, true
+		}
+	}
+	return types.GameSummary{}, false
+}
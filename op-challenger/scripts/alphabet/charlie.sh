@@ -17,11 +17,14 @@ CHARLIE_KEY="74feb147d72bfae943e6b4e483410933d9e447d5dc47d52432dcc2c1454dabb7"
 FAULT_GAME_ADDRESS=$(cat $FAULT_GAME_ADDR_FILE)
 echo "Fault dispute game address: $FAULT_GAME_ADDRESS"
 
+MONOREPO_DIR=$(echo ${CHALLENGER_DIR%/*})
+DISPUTE_GAME_FACTORY=$(jq -r .DisputeGameFactoryProxy $MONOREPO_DIR/.devnet/addresses.json)
+
 $CHALLENGER_DIR/bin/op-challenger \
   --l1-eth-rpc http://localhost:8545 \
   --trace-type="alphabet" \
   --alphabet "abcdefgh" \
-  --game-address $FAULT_GAME_ADDRESS \
+  --game-factory-address $DISPUTE_GAME_FACTORY \
+  --game-allowlist $FAULT_GAME_ADDRESS \
   --private-key $CHARLIE_KEY \
-  --num-confirmations 1 \
-  --agree-with-proposed-output=true
+  --num-confirmations 1
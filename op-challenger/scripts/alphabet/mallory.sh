@@ -17,11 +17,14 @@ MALLORY_KEY="28d7045146193f5f4eeb151c4843544b1b0d30a7ac1680c845a416fac65a7715"
 FAULT_GAME_ADDRESS=$(cat $FAULT_GAME_ADDR_FILE)
 echo "Fault dispute game address: $FAULT_GAME_ADDRESS"
 
+MONOREPO_DIR=$(echo ${CHALLENGER_DIR%/*})
+DISPUTE_GAME_FACTORY=$(jq -r .DisputeGameFactoryProxy $MONOREPO_DIR/.devnet/addresses.json)
+
 $CHALLENGER_DIR/bin/op-challenger \
   --l1-eth-rpc http://localhost:8545 \
   --trace-type="alphabet" \
   --alphabet "abcdexyz" \
-  --game-address $FAULT_GAME_ADDRESS \
+  --game-factory-address $DISPUTE_GAME_FACTORY \
+  --game-allowlist $FAULT_GAME_ADDRESS \
   --private-key $MALLORY_KEY \
-  --num-confirmations 1 \
-  --agree-with-proposed-output=false
+  --num-confirmations 1
@@ -0,0 +1,264 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+const (
+	// cacheSize is the amount of memory, in megabytes, leveldb is allowed to use for caching.
+	cacheSize = 16
+	// handles is the number of file handles leveldb is allowed to use.
+	handles = 16
+)
+
+var (
+	nextGameIndexKey  = []byte("next-game-index")
+	trackedGamePrefix = []byte("tracked-game/")
+	gameDataPrefix    = []byte("game/")
+	txPrefix          = "tx/"
+	spentKey          = "spent"
+)
+
+// TrackedGame is a game the challenger is monitoring and will resume after a restart.
+type TrackedGame struct {
+	Index     uint64         `json:"index"`
+	Proxy     common.Address `json:"proxy"`
+	Timestamp uint64         `json:"timestamp"`
+}
+
+// TxRecord records a transaction sent by the challenger.
+// A record is written before the transaction is sent, updated with the hash and nonce of each
+// transaction published for it and again once a receipt is available. A record without published
+// transactions indicates the transaction was never broadcast.
+type TxRecord struct {
+	// Created is the time the transaction was first submitted to the transaction manager.
+	Created time.Time `json:"created"`
+	// Published is the hashes of the transactions published, including those with bumped fees.
+	Published []common.Hash `json:"published,omitempty"`
+	// Nonce is the nonce of the published transactions. Only valid when Published or TxHash is set.
+	Nonce uint64 `json:"nonce,omitempty"`
+	// TxHash is the hash of the transaction that was included, or nil if no receipt has been received.
+	TxHash *common.Hash `json:"txHash,omitempty"`
+	// Status is the receipt status of the included transaction. Only valid when TxHash is set.
+	Status uint64 `json:"status,omitempty"`
+}
+
+// Confirmed returns true if the transaction was included in a block.
+func (r TxRecord) Confirmed() bool {
+	return r.TxHash != nil
+}
+
+// Store persists the challenger state so it can resume after a restart.
+type Store struct {
+	db ethdb.KeyValueStore
+}
+
+// Open opens the store in dir, creating it if it doesn't exist.
+// If dir is empty, the state is only held in memory and is lost when the challenger stops.
+func Open(dir string) (*Store, error) {
+	if dir == "" {
+		return NewMemoryStore(), nil
+	}
+	db, err := leveldb.New(dir, cacheSize, handles, "", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open challenger db at %v: %w", dir, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// NewMemoryStore creates a new [Store] that only holds state in memory.
+func NewMemoryStore() *Store {
+	return &Store{db: memorydb.New()}
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// NextGameIndex returns the index of the next game in the factory that has not yet been considered.
+func (s *Store) NextGameIndex() (uint64, error) {
+	data, ok, err := get(s.db, nextGameIndexKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read next game index: %w", err)
+	} else if !ok {
+		return 0, nil
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("invalid next game index: %x", data)
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// SetNextGameIndex records the index of the next game in the factory that has not yet been considered.
+func (s *Store) SetNextGameIndex(index uint64) error {
+	return s.db.Put(nextGameIndexKey, binary.BigEndian.AppendUint64(nil, index))
+}
+
+// TrackedGames returns all games currently being tracked, in no particular order.
+func (s *Store) TrackedGames() ([]TrackedGame, error) {
+	it := s.db.NewIterator(trackedGamePrefix, nil)
+	defer it.Release()
+	var games []TrackedGame
+	for it.Next() {
+		var game TrackedGame
+		if err := json.Unmarshal(it.Value(), &game); err != nil {
+			return nil, fmt.Errorf("failed to decode tracked game %x: %w", it.Key(), err)
+		}
+		games = append(games, game)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read tracked games: %w", err)
+	}
+	return games, nil
+}
+
+// TrackGame records that game is being tracked.
+func (s *Store) TrackGame(game TrackedGame) error {
+	data, err := json.Marshal(game)
+	if err != nil {
+		return fmt.Errorf("failed to encode tracked game: %w", err)
+	}
+	return s.db.Put(trackedGameKey(game.Proxy), data)
+}
+
+// UntrackGame removes the game from the list of tracked games and deletes all data stored for it.
+func (s *Store) UntrackGame(addr common.Address) error {
+	batch := s.db.NewBatch()
+	if err := batch.Delete(trackedGameKey(addr)); err != nil {
+		return err
+	}
+	prefix := gameDataKey(addr, "")
+	it := s.db.NewIterator(prefix, nil)
+	defer it.Release()
+	for it.Next() {
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return fmt.Errorf("failed to read data for game %v: %w", addr, err)
+	}
+	return batch.Write()
+}
+
+// GameStore returns a [GameStore] for data specific to the game at addr.
+func (s *Store) GameStore(addr common.Address) *GameStore {
+	return &GameStore{
+		db:   s.db,
+		addr: addr,
+	}
+}
+
+// GameStore persists the data for a single game.
+// All data is deleted when the game is untracked.
+type GameStore struct {
+	db     ethdb.KeyValueStore
+	addr   common.Address
+	prefix string
+}
+
+// WithPrefix returns a [GameStore] for the same game that prepends prefix to all keys.
+// It allows multiple components to store data for the same game without their keys conflicting.
+func (g *GameStore) WithPrefix(prefix string) *GameStore {
+	return &GameStore{
+		db:     g.db,
+		addr:   g.addr,
+		prefix: g.prefix + prefix,
+	}
+}
+
+// Get loads the JSON encoded value for key into v.
+// Returns false if there is no value stored for key.
+func (g *GameStore) Get(key string, v interface{}) (bool, error) {
+	data, ok, err := get(g.db, gameDataKey(g.addr, g.prefix+key))
+	if err != nil {
+		return false, fmt.Errorf("failed to read %v for game %v: %w", key, g.addr, err)
+	} else if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode %v for game %v: %w", key, g.addr, err)
+	}
+	return true, nil
+}
+
+// Put stores v, JSON encoded, for key.
+func (g *GameStore) Put(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %v for game %v: %w", key, g.addr, err)
+	}
+	return g.db.Put(gameDataKey(g.addr, g.prefix+key), data)
+}
+
+// Delete removes the value stored for key, if any.
+func (g *GameStore) Delete(key string) error {
+	return g.db.Delete(gameDataKey(g.addr, g.prefix+key))
+}
+
+// GetTx returns the record of the transaction identified by id.
+func (g *GameStore) GetTx(id common.Hash) (TxRecord, bool, error) {
+	var record TxRecord
+	ok, err := g.Get(txPrefix+id.Hex(), &record)
+	return record, ok, err
+}
+
+// PutTx stores the record of the transaction identified by id.
+func (g *GameStore) PutTx(id common.Hash, record TxRecord) error {
+	return g.Put(txPrefix+id.Hex(), record)
+}
+
+// DeleteTx removes the record of the transaction identified by id.
+func (g *GameStore) DeleteTx(id common.Hash) error {
+	return g.Delete(txPrefix + id.Hex())
+}
+
+// GetSpent returns the total amount of wei spent on transactions for the game.
+func (g *GameStore) GetSpent() (*big.Int, error) {
+	spent := new(big.Int)
+	if _, err := g.Get(spentKey, spent); err != nil {
+		return nil, err
+	}
+	return spent, nil
+}
+
+// PutSpent stores the total amount of wei spent on transactions for the game.
+func (g *GameStore) PutSpent(spent *big.Int) error {
+	return g.Put(spentKey, spent)
+}
+
+// get reads the value for key, returning false if the key does not exist.
+// The not found error differs between database implementations so existence is checked first.
+func get(db ethdb.KeyValueReader, key []byte) ([]byte, bool, error) {
+	if ok, err := db.Has(key); err != nil {
+		return nil, false, err
+	} else if !ok {
+		return nil, false, nil
+	}
+	data, err := db.Get(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func trackedGameKey(addr common.Address) []byte {
+	return append(append([]byte{}, trackedGamePrefix...), addr.Bytes()...)
+}
+
+func gameDataKey(addr common.Address, key string) []byte {
+	out := append([]byte{}, gameDataPrefix...)
+	out = append(out, addr.Bytes()...)
+	out = append(out, '/')
+	return append(out, key...)
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	game1 = TrackedGame{Index: 1, Proxy: common.Address{0xaa}, Timestamp: 100}
+	game2 = TrackedGame{Index: 2, Proxy: common.Address{0xbb}, Timestamp: 200}
+)
+
+func TestStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) *Store{
+		"Memory": func(t *testing.T) *Store {
+			return NewMemoryStore()
+		},
+		"LevelDB": func(t *testing.T) *Store {
+			s, err := Open(t.TempDir())
+			require.NoError(t, err)
+			return s
+		},
+	}
+	for name, create := range stores {
+		create := create
+		t.Run(name, func(t *testing.T) {
+			t.Run("NextGameIndex", func(t *testing.T) {
+				s := create(t)
+				defer s.Close()
+				index, err := s.NextGameIndex()
+				require.NoError(t, err)
+				require.Zero(t, index)
+
+				require.NoError(t, s.SetNextGameIndex(42))
+				index, err = s.NextGameIndex()
+				require.NoError(t, err)
+				require.Equal(t, uint64(42), index)
+			})
+
+			t.Run("TrackedGames", func(t *testing.T) {
+				s := create(t)
+				defer s.Close()
+				games, err := s.TrackedGames()
+				require.NoError(t, err)
+				require.Empty(t, games)
+
+				require.NoError(t, s.TrackGame(game1))
+				require.NoError(t, s.TrackGame(game2))
+				games, err = s.TrackedGames()
+				require.NoError(t, err)
+				require.ElementsMatch(t, []TrackedGame{game1, game2}, games)
+
+				require.NoError(t, s.UntrackGame(game1.Proxy))
+				games, err = s.TrackedGames()
+				require.NoError(t, err)
+				require.Equal(t, []TrackedGame{game2}, games)
+			})
+
+			t.Run("GameData", func(t *testing.T) {
+				s := create(t)
+				defer s.Close()
+				gameStore := s.GameStore(game1.Proxy)
+				var value string
+				ok, err := gameStore.Get("key", &value)
+				require.NoError(t, err)
+				require.False(t, ok)
+
+				require.NoError(t, gameStore.Put("key", "value"))
+				ok, err = gameStore.Get("key", &value)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, "value", value)
+
+				ok, err = s.GameStore(game2.Proxy).Get("key", &value)
+				require.NoError(t, err)
+				require.False(t, ok, "should not share data between games")
+
+				require.NoError(t, gameStore.Delete("key"))
+				ok, err = gameStore.Get("key", &value)
+				require.NoError(t, err)
+				require.False(t, ok)
+			})
+
+			t.Run("PrefixedGameData", func(t *testing.T) {
+				s := create(t)
+				defer s.Close()
+				gameStore := s.GameStore(game1.Proxy)
+				prefixed := gameStore.WithPrefix("a/")
+				require.NoError(t, gameStore.Put("key", "unprefixed"))
+				require.NoError(t, prefixed.Put("key", "prefixed"))
+
+				var value string
+				ok, err := gameStore.Get("key", &value)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, "unprefixed", value)
+				ok, err = prefixed.Get("key", &value)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, "prefixed", value)
+				ok, err = gameStore.Get("a/key", &value)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, "prefixed", value)
+
+				require.NoError(t, s.UntrackGame(game1.Proxy))
+				ok, err = prefixed.Get("key", &value)
+				require.NoError(t, err)
+				require.False(t, ok, "should delete prefixed data when game is untracked")
+			})
+
+			t.Run("TxRecords", func(t *testing.T) {
+				s := create(t)
+				defer s.Close()
+				gameStore := s.GameStore(game1.Proxy)
+				id := common.Hash{0x01}
+				_, ok, err := gameStore.GetTx(id)
+				require.NoError(t, err)
+				require.False(t, ok)
+
+				created := time.Unix(1000, 0).UTC()
+				require.NoError(t, gameStore.PutTx(id, TxRecord{Created: created}))
+				record, ok, err := gameStore.GetTx(id)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.False(t, record.Confirmed())
+				require.True(t, created.Equal(record.Created))
+
+				published := []common.Hash{{0xaa}, {0xbb}}
+				require.NoError(t, gameStore.PutTx(id, TxRecord{Created: created, Published: published, Nonce: 5}))
+				record, ok, err = gameStore.GetTx(id)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.False(t, record.Confirmed())
+				require.Equal(t, published, record.Published)
+				require.Equal(t, uint64(5), record.Nonce)
+
+				txHash := common.Hash{0xff}
+				require.NoError(t, gameStore.PutTx(id, TxRecord{Created: created, TxHash: &txHash, Nonce: 5, Status: 1}))
+				record, ok, err = gameStore.GetTx(id)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.True(t, record.Confirmed())
+				require.Equal(t, txHash, *record.TxHash)
+				require.Equal(t, uint64(5), record.Nonce)
+				require.Equal(t, uint64(1), record.Status)
+
+				require.NoError(t, gameStore.DeleteTx(id))
+				_, ok, err = gameStore.GetTx(id)
+				require.NoError(t, err)
+				require.False(t, ok)
+			})
+
+			t.Run("Spent", func(t *testing.T) {
+				s := create(t)
+				defer s.Close()
+				gameStore := s.GameStore(game1.Proxy)
+				spent, err := gameStore.GetSpent()
+				require.NoError(t, err)
+				require.Zero(t, spent.Sign())
+
+				require.NoError(t, gameStore.PutSpent(big.NewInt(123456)))
+				spent, err = gameStore.GetSpent()
+				require.NoError(t, err)
+				require.Equal(t, big.NewInt(123456), spent)
+
+				otherSpent, err := s.GameStore(game2.Proxy).GetSpent()
+				require.NoError(t, err)
+				require.Zero(t, otherSpent.Sign())
+			})
+
+			t.Run("UntrackDeletesGameData", func(t *testing.T) {
+				s := create(t)
+				defer s.Close()
+				require.NoError(t, s.TrackGame(game1))
+				require.NoError(t, s.TrackGame(game2))
+				require.NoError(t, s.GameStore(game1.Proxy).Put("key", "value1"))
+				require.NoError(t, s.GameStore(game2.Proxy).Put("key", "value2"))
+
+				require.NoError(t, s.UntrackGame(game1.Proxy))
+				var value string
+				ok, err := s.GameStore(game1.Proxy).Get("key", &value)
+				require.NoError(t, err)
+				require.False(t, ok)
+
+				ok, err = s.GameStore(game2.Proxy).Get("key", &value)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, "value2", value)
+			})
+		})
+	}
+}
+
+func TestStatePersistedAcrossRestarts(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "challenger")
+	s, err := Open(dir)
+	require.NoError(t, err)
+	require.NoError(t, s.SetNextGameIndex(3))
+	require.NoError(t, s.TrackGame(game1))
+	require.NoError(t, s.GameStore(game1.Proxy).Put("key", "value"))
+	require.NoError(t, s.Close())
+
+	s, err = Open(dir)
+	require.NoError(t, err)
+	defer s.Close()
+	index, err := s.NextGameIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), index)
+	games, err := s.TrackedGames()
+	require.NoError(t, err)
+	require.Equal(t, []TrackedGame{game1}, games)
+	var value string
+	ok, err := s.GameStore(game1.Proxy).Get("key", &value)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "value", value)
+}
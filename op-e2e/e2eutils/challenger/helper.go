@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -46,10 +47,12 @@ func NewChallengerConfig(t *testing.T, l1Endpoint string, options ...Option) *co
 	txmgrCfg.NumConfirmations = 1
 	txmgrCfg.ReceiptQueryInterval = 1 * time.Second
 	cfg := &config.Config{
-		L1EthRpc:                l1Endpoint,
-		AlphabetTrace:           "",
-		AgreeWithProposedOutput: true,
-		TxMgrConfig:             txmgrCfg,
+		L1EthRpc:       l1Endpoint,
+		AlphabetTrace:  "",
+		TxMgrConfig:    txmgrCfg,
+		MaxConcurrency: 4,
+		PollInterval:   time.Second,
+		Datadir:        filepath.Join(t.TempDir(), "challenger"),
 	}
 	for _, option := range options {
 		option(cfg)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
 	"github.com/ethereum-optimism/optimism/op-e2e/e2eutils/challenger"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type AlphabetGameHelper struct {
@@ -15,12 +16,12 @@ type AlphabetGameHelper struct {
 func (g *AlphabetGameHelper) StartChallenger(ctx context.Context, l1Endpoint string, name string, options ...challenger.Option) *challenger.Helper {
 	opts := []challenger.Option{
 		func(c *config.Config) {
-			c.GameAddress = g.addr
+			c.GameFactoryAddress = g.factoryAddr
+			c.GameAllowlist = []common.Address{g.addr}
 			c.TraceType = config.TraceTypeAlphabet
 			// By default the challenger agrees with the root claim (thus disagrees with the proposed output)
 			// This can be overridden by passing in options
 			c.AlphabetTrace = g.claimedAlphabet
-			c.AgreeWithProposedOutput = false
 		},
 	}
 	opts = append(opts, options...)
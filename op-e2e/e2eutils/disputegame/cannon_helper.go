@@ -8,10 +8,13 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-challenger/store"
 	"github.com/ethereum-optimism/optimism/op-e2e/e2eutils/challenger"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -34,7 +37,7 @@ func (g *CannonGameHelper) CreateHonestActor(ctx context.Context, rollupCfg *rol
 	opts := []challenger.Option{g.createConfigOption(rollupCfg, l2Genesis, l2Endpoint)}
 	opts = append(opts, options...)
 	cfg := challenger.NewChallengerConfig(g.t, l1Endpoint, opts...)
-	provider, err := cannon.NewTraceProvider(ctx, testlog.Logger(g.t, log.LvlTrace).New("role", "CorrectTrace"), cfg, l1Client)
+	provider, err := cannon.NewTraceProvider(ctx, testlog.Logger(g.t, log.LvlTrace).New("role", "CorrectTrace"), metrics.NoopMetrics, cfg, l1Client, filepath.Join(cfg.CannonDatadir, "honest"), g.addr, store.NewMemoryStore().GameStore(g.addr))
 	g.require.NoError(err, "create cannon trace provider")
 
 	return &HonestHelper{
@@ -47,15 +50,18 @@ func (g *CannonGameHelper) CreateHonestActor(ctx context.Context, rollupCfg *rol
 
 func (g *CannonGameHelper) createConfigOption(rollupCfg *rollup.Config, l2Genesis *core.Genesis, l2Endpoint string) challenger.Option {
 	return func(c *config.Config) {
-		c.GameAddress = g.addr
+		c.GameFactoryAddress = g.factoryAddr
+		c.GameAllowlist = []common.Address{g.addr}
 		c.TraceType = config.TraceTypeCannon
-		c.AgreeWithProposedOutput = false
 		c.CannonL2 = l2Endpoint
 		c.CannonBin = "../cannon/bin/cannon"
 		c.CannonDatadir = g.t.TempDir()
 		c.CannonServer = "../op-program/bin/op-program"
 		c.CannonAbsolutePreState = "../op-program/bin/prestate.json"
 		c.CannonSnapshotFreq = 10_000_000
+		c.CannonMaxSnapshots = config.DefaultCannonMaxSnapshots
+		c.CannonWorkers = config.DefaultCannonWorkers
+		c.CannonProofCacheSize = config.DefaultCannonProofCacheSize
 
 		genesisBytes, err := json.Marshal(l2Genesis)
 		g.require.NoError(err, "marshall l2 genesis config")
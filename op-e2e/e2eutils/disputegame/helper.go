@@ -52,6 +52,7 @@ type FactoryHelper struct {
 	require     *require.Assertions
 	client      *ethclient.Client
 	opts        *bind.TransactOpts
+	factoryAddr common.Address
 	factory     *bindings.DisputeGameFactory
 	blockOracle *bindings.BlockOracle
 	l2oo        *bindings.L2OutputOracleCaller
@@ -77,6 +78,7 @@ func NewFactoryHelper(t *testing.T, ctx context.Context, deployments *genesis.L1
 		require:     require,
 		client:      client,
 		opts:        opts,
+		factoryAddr: deployments.DisputeGameFactoryProxy,
 		factory:     factory,
 		blockOracle: blockOracle,
 		l2oo:        l2oo,
@@ -108,12 +110,13 @@ func (h *FactoryHelper) StartAlphabetGame(ctx context.Context, claimedAlphabet s
 
 	return &AlphabetGameHelper{
 		FaultGameHelper: FaultGameHelper{
-			t:       h.t,
-			require: h.require,
-			client:  h.client,
-			opts:    h.opts,
-			game:    game,
-			addr:    createdEvent.DisputeProxy,
+			t:           h.t,
+			require:     h.require,
+			client:      h.client,
+			opts:        h.opts,
+			factoryAddr: h.factoryAddr,
+			game:        game,
+			addr:        createdEvent.DisputeProxy,
 		},
 		claimedAlphabet: claimedAlphabet,
 	}
@@ -141,12 +144,13 @@ func (h *FactoryHelper) StartCannonGame(ctx context.Context, rootClaim common.Ha
 
 	return &CannonGameHelper{
 		FaultGameHelper: FaultGameHelper{
-			t:       h.t,
-			require: h.require,
-			client:  h.client,
-			opts:    h.opts,
-			game:    game,
-			addr:    createdEvent.DisputeProxy,
+			t:           h.t,
+			require:     h.require,
+			client:      h.client,
+			opts:        h.opts,
+			factoryAddr: h.factoryAddr,
+			game:        game,
+			addr:        createdEvent.DisputeProxy,
 		},
 	}
 }
@@ -29,8 +29,7 @@ func TestResolveDisputeGame(t *testing.T) {
 	game.WaitForGameStatus(ctx, disputegame.StatusInProgress)
 
 	game.StartChallenger(ctx, sys.NodeEndpoint("l1"), "HonestAlice", func(c *config.Config) {
-		c.AgreeWithProposedOutput = true // Agree with the proposed output, so disagree with the root claim
-		c.AlphabetTrace = "abcdefg"
+		c.AlphabetTrace = "abcdefg" // Disagree with the root claim
 		c.TxMgrConfig.PrivateKey = e2eutils.EncodePrivKeyToString(sys.cfg.Secrets.Alice)
 	})
 
@@ -129,8 +128,7 @@ func TestChallengerCompleteDisputeGame(t *testing.T) {
 			})
 
 			game.StartChallenger(ctx, sys.NodeEndpoint("l1"), "Challenger", func(c *config.Config) {
-				c.AgreeWithProposedOutput = true // Agree with the proposed output, so disagree with the root claim
-				c.AlphabetTrace = test.otherAlphabet
+				c.AlphabetTrace = test.otherAlphabet // Disagree with the root claim
 				c.TxMgrConfig.PrivateKey = e2eutils.EncodePrivKeyToString(sys.cfg.Secrets.Alice)
 			})
 
@@ -171,7 +169,7 @@ func TestCannonDisputeGame(t *testing.T) {
 			game.LogGameData(ctx)
 
 			game.StartChallenger(ctx, sys.RollupConfig, sys.L2GenesisCfg, sys.NodeEndpoint("l1"), sys.NodeEndpoint("sequencer"), "Challenger", func(c *config.Config) {
-				c.AgreeWithProposedOutput = true // Agree with the proposed output, so disagree with the root claim
+				c.RollupRpc = sys.RollupNodes["sequencer"].HTTPEndpoint()
 				c.TxMgrConfig.PrivateKey = e2eutils.EncodePrivKeyToString(sys.cfg.Secrets.Alice)
 			})
 
@@ -221,11 +219,12 @@ func TestCannonDefendStep(t *testing.T) {
 	l1Endpoint := sys.NodeEndpoint("l1")
 	l2Endpoint := sys.NodeEndpoint("sequencer")
 	game.StartChallenger(ctx, sys.RollupConfig, sys.L2GenesisCfg, l1Endpoint, l2Endpoint, "Challenger", func(c *config.Config) {
-		c.AgreeWithProposedOutput = true // Agree with the proposed output, so disagree with the root claim
+		c.RollupRpc = sys.RollupNodes["sequencer"].HTTPEndpoint()
 		c.TxMgrConfig.PrivateKey = e2eutils.EncodePrivKeyToString(sys.cfg.Secrets.Alice)
 	})
 
 	correctTrace := game.CreateHonestActor(ctx, sys.RollupConfig, sys.L2GenesisCfg, l1Client, l1Endpoint, l2Endpoint, func(c *config.Config) {
+		c.RollupRpc = sys.RollupNodes["sequencer"].HTTPEndpoint()
 		c.TxMgrConfig.PrivateKey = e2eutils.EncodePrivKeyToString(sys.cfg.Secrets.Mallory)
 	})
 
@@ -127,6 +127,7 @@ func FaultProofProgram(ctx context.Context, logger log.Logger, cfg *config.Confi
 func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config, preimageChannel oppio.FileChannel, hintChannel oppio.FileChannel) error {
 	var serverDone chan error
 	var hinterDone chan error
+	closeSource := func() {}
 	defer func() {
 		preimageChannel.Close()
 		hintChannel.Close()
@@ -138,8 +139,31 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 			// Wait for hinter to complete
 			<-hinterDone
 		}
+		closeSource()
 	}()
 	logger.Info("Starting preimage server")
+	preimageGetter, hinter, closeFn, err := NewPreimageSource(ctx, logger, cfg)
+	if err != nil {
+		return err
+	}
+	closeSource = closeFn
+
+	serverDone = launchOracleServer(logger, preimageChannel, preimageGetter)
+	hinterDone = routeHints(logger, hintChannel, hinter)
+	select {
+	case err := <-serverDone:
+		return err
+	case err := <-hinterDone:
+		return err
+	}
+}
+
+// NewPreimageSource creates the pre-image getter and hint handler that serve requests from the fault proof program.
+// Pre-images are read from the configured key-value store and, when fetching is enabled, retrieved from the L1 and
+// L2 nodes as required. Local pre-images are provided from cfg.
+// The returned close function must be called to release any connections once the source is no longer required.
+// The returned source is not safe for concurrent use.
+func NewPreimageSource(ctx context.Context, logger log.Logger, cfg *config.Config) (preimage.PreimageGetter, preimage.HintHandler, func(), error) {
 	var kv kvstore.KV
 	if cfg.DataDir == "" {
 		logger.Info("Using in-memory storage")
@@ -147,7 +171,7 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 	} else {
 		logger.Info("Creating disk storage", "datadir", cfg.DataDir)
 		if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
-			return fmt.Errorf("creating datadir: %w", err)
+			return nil, nil, nil, fmt.Errorf("creating datadir: %w", err)
 		}
 		kv = kvstore.NewDiskKV(cfg.DataDir)
 	}
@@ -155,14 +179,16 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 	var (
 		getPreimage kvstore.PreimageSource
 		hinter      preimage.HintHandler
+		closeFn     = func() {}
 	)
 	if cfg.FetchingEnabled() {
-		prefetch, err := makePrefetcher(ctx, logger, kv, cfg)
+		prefetch, closePrefetcher, err := makePrefetcher(ctx, logger, kv, cfg)
 		if err != nil {
-			return fmt.Errorf("failed to create prefetcher: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to create prefetcher: %w", err)
 		}
 		getPreimage = func(key common.Hash) ([]byte, error) { return prefetch.GetPreimage(ctx, key) }
 		hinter = prefetch.Hint
+		closeFn = closePrefetcher
 	} else {
 		logger.Info("Using offline mode. All required pre-images must be pre-populated.")
 		getPreimage = kv.Get
@@ -174,43 +200,41 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 
 	localPreimageSource := kvstore.NewLocalPreimageSource(cfg)
 	splitter := kvstore.NewPreimageSourceSplitter(localPreimageSource.Get, getPreimage)
-	preimageGetter := splitter.Get
-
-	serverDone = launchOracleServer(logger, preimageChannel, preimageGetter)
-	hinterDone = routeHints(logger, hintChannel, hinter)
-	select {
-	case err := <-serverDone:
-		return err
-	case err := <-hinterDone:
-		return err
-	}
+	return splitter.Get, hinter, closeFn, nil
 }
 
-func makePrefetcher(ctx context.Context, logger log.Logger, kv kvstore.KV, cfg *config.Config) (*prefetcher.Prefetcher, error) {
+func makePrefetcher(ctx context.Context, logger log.Logger, kv kvstore.KV, cfg *config.Config) (*prefetcher.Prefetcher, func(), error) {
 	logger.Info("Connecting to L1 node", "l1", cfg.L1URL)
 	l1RPC, err := client.NewRPC(ctx, logger, cfg.L1URL, client.WithDialBackoff(10))
 	if err != nil {
-		return nil, fmt.Errorf("failed to setup L1 RPC: %w", err)
+		return nil, nil, fmt.Errorf("failed to setup L1 RPC: %w", err)
 	}
 
 	logger.Info("Connecting to L2 node", "l2", cfg.L2URL)
 	l2RPC, err := client.NewRPC(ctx, logger, cfg.L2URL, client.WithDialBackoff(10))
 	if err != nil {
-		return nil, fmt.Errorf("failed to setup L2 RPC: %w", err)
+		l1RPC.Close()
+		return nil, nil, fmt.Errorf("failed to setup L2 RPC: %w", err)
+	}
+	closeRPCs := func() {
+		l1RPC.Close()
+		l2RPC.Close()
 	}
 
 	l1ClCfg := sources.L1ClientDefaultConfig(cfg.Rollup, cfg.L1TrustRPC, cfg.L1RPCKind)
 	l2ClCfg := sources.L2ClientDefaultConfig(cfg.Rollup, true)
 	l1Cl, err := sources.NewL1Client(l1RPC, logger, nil, l1ClCfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create L1 client: %w", err)
+		closeRPCs()
+		return nil, nil, fmt.Errorf("failed to create L1 client: %w", err)
 	}
 	l2Cl, err := NewL2Client(l2RPC, logger, nil, &L2ClientConfig{L2ClientConfig: l2ClCfg, L2Head: cfg.L2Head})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create L2 client: %w", err)
+		closeRPCs()
+		return nil, nil, fmt.Errorf("failed to create L2 client: %w", err)
 	}
 	l2DebugCl := &L2Source{L2Client: l2Cl, DebugClient: sources.NewDebugClient(l2RPC.CallContext)}
-	return prefetcher.NewPrefetcher(logger, l1Cl, l2DebugCl, kv), nil
+	return prefetcher.NewPrefetcher(logger, l1Cl, l2DebugCl, kv), closeRPCs, nil
 }
 
 func routeHints(logger log.Logger, hHostRW io.ReadWriter, hinter preimage.HintHandler) chan error {
@@ -13,8 +13,19 @@ const (
 	EnabledFlagName    = "metrics.enabled"
 	ListenAddrFlagName = "metrics.addr"
 	PortFlagName       = "metrics.port"
+
+	defaultListenPort = 7300
 )
 
+// DefaultCLIConfig returns the config used when no metrics flags are set, with metrics disabled.
+func DefaultCLIConfig() CLIConfig {
+	return CLIConfig{
+		Enabled:    false,
+		ListenAddr: opservice.DefaultListenAddr,
+		ListenPort: defaultListenPort,
+	}
+}
+
 func CLIFlags(envPrefix string) []cli.Flag {
 	return []cli.Flag{
 		&cli.BoolFlag{
@@ -25,13 +36,13 @@ func CLIFlags(envPrefix string) []cli.Flag {
 		&cli.StringFlag{
 			Name:    ListenAddrFlagName,
 			Usage:   "Metrics listening address",
-			Value:   "0.0.0.0", // TODO(CLI-4159): Switch to 127.0.0.1
+			Value:   opservice.DefaultListenAddr,
 			EnvVars: opservice.PrefixEnvVar(envPrefix, "METRICS_ADDR"),
 		},
 		&cli.IntFlag{
 			Name:    PortFlagName,
 			Usage:   "Metrics listening port",
-			Value:   7300,
+			Value:   defaultListenPort,
 			EnvVars: opservice.PrefixEnvVar(envPrefix, "METRICS_PORT"),
 		},
 	}
@@ -11,20 +11,30 @@ import (
 const (
 	ListenAddrFlagName = "rpc.addr"
 	PortFlagName       = "rpc.port"
+
+	defaultListenPort = 8545
 )
 
+// DefaultCLIConfig returns the config used when no rpc flags are set.
+func DefaultCLIConfig() CLIConfig {
+	return CLIConfig{
+		ListenAddr: opservice.DefaultListenAddr,
+		ListenPort: defaultListenPort,
+	}
+}
+
 func CLIFlags(envPrefix string) []cli.Flag {
 	return []cli.Flag{
 		&cli.StringFlag{
 			Name:    ListenAddrFlagName,
 			Usage:   "rpc listening address",
-			Value:   "0.0.0.0", // TODO(CLI-4159): Switch to 127.0.0.1
+			Value:   opservice.DefaultListenAddr,
 			EnvVars: opservice.PrefixEnvVar(envPrefix, "RPC_ADDR"),
 		},
 		&cli.IntFlag{
 			Name:    PortFlagName,
 			Usage:   "rpc listening port",
-			Value:   8545,
+			Value:   defaultListenPort,
 			EnvVars: opservice.PrefixEnvVar(envPrefix, "RPC_PORT"),
 		},
 	}
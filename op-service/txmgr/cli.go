@@ -31,6 +31,10 @@ const (
 	TxSendTimeoutFlagName             = "txmgr.send-timeout"
 	TxNotInMempoolTimeoutFlagName     = "txmgr.not-in-mempool-timeout"
 	ReceiptQueryIntervalFlagName      = "txmgr.receipt-query-interval"
+	JournalPathFlagName               = "txmgr.journal-path"
+	JournalRecoveryFlagName           = "txmgr.journal-recovery"
+	JournalRecoveryTimeoutFlagName    = "txmgr.journal-recovery-timeout"
+	CancelTimeoutFlagName             = "txmgr.cancel-timeout"
 )
 
 var (
@@ -56,6 +60,9 @@ var (
 	defaultTxSendTimeout             = 0 * time.Second
 	defaultTxNotInMempoolTimeout     = 2 * time.Minute
 	defaultReceiptQueryInterval      = 12 * time.Second
+	defaultJournalRecovery           = JournalRecoveryResume
+	defaultJournalRecoveryTimeout    = 10 * time.Minute
+	defaultCancelTimeout             = 0 * time.Second
 )
 
 func CLIFlags(envPrefix string) []cli.Flag {
@@ -120,6 +127,29 @@ func CLIFlags(envPrefix string) []cli.Flag {
 			Value:   defaultReceiptQueryInterval,
 			EnvVars: prefixEnvVars("TXMGR_RECEIPT_QUERY_INTERVAL"),
 		},
+		&cli.StringFlag{
+			Name:    JournalPathFlagName,
+			Usage:   "Path of the file journaling in-flight transactions, recovered on restart. If empty it is disabled.",
+			EnvVars: prefixEnvVars("TXMGR_JOURNAL_PATH"),
+		},
+		&cli.StringFlag{
+			Name:    JournalRecoveryFlagName,
+			Usage:   "How to recover journaled transactions on restart: 'resume' to keep bumping them, 'cancel' to replace them with self-transfers",
+			Value:   defaultJournalRecovery,
+			EnvVars: prefixEnvVars("TXMGR_JOURNAL_RECOVERY"),
+		},
+		&cli.DurationFlag{
+			Name:    JournalRecoveryTimeoutFlagName,
+			Usage:   "Timeout for recovering journaled transactions on startup, after which new transactions are sent regardless",
+			Value:   defaultJournalRecoveryTimeout,
+			EnvVars: prefixEnvVars("TXMGR_JOURNAL_RECOVERY_TIMEOUT"),
+		},
+		&cli.DurationFlag{
+			Name:    CancelTimeoutFlagName,
+			Usage:   "Timeout after which a pending tx is replaced by a zero-value self-transfer. If 0 it is disabled.",
+			Value:   defaultCancelTimeout,
+			EnvVars: prefixEnvVars("TXMGR_CANCEL_TIMEOUT"),
+		},
 	}, client.CLIFlags(envPrefix)...)
 }
 
@@ -138,6 +168,10 @@ type CLIConfig struct {
 	NetworkTimeout            time.Duration
 	TxSendTimeout             time.Duration
 	TxNotInMempoolTimeout     time.Duration
+	JournalPath               string
+	JournalRecovery           string
+	JournalRecoveryTimeout    time.Duration
+	CancelTimeout             time.Duration
 }
 
 func NewCLIConfig(l1RPCURL string) CLIConfig {
@@ -150,6 +184,9 @@ func NewCLIConfig(l1RPCURL string) CLIConfig {
 		TxSendTimeout:             defaultTxSendTimeout,
 		TxNotInMempoolTimeout:     defaultTxNotInMempoolTimeout,
 		ReceiptQueryInterval:      defaultReceiptQueryInterval,
+		JournalRecovery:           defaultJournalRecovery,
+		JournalRecoveryTimeout:    defaultJournalRecoveryTimeout,
+		CancelTimeout:             defaultCancelTimeout,
 		SignerCLIConfig:           client.NewCLIConfig(),
 	}
 }
@@ -176,6 +213,12 @@ func (m CLIConfig) Check() error {
 	if m.SafeAbortNonceTooLowCount == 0 {
 		return errors.New("SafeAbortNonceTooLowCount must not be 0")
 	}
+	if m.JournalRecovery != "" && m.JournalRecovery != JournalRecoveryResume && m.JournalRecovery != JournalRecoveryCancel {
+		return fmt.Errorf("invalid JournalRecovery %q, must be %q or %q", m.JournalRecovery, JournalRecoveryResume, JournalRecoveryCancel)
+	}
+	if m.TxSendTimeout != 0 && m.CancelTimeout >= m.TxSendTimeout {
+		return errors.New("CancelTimeout must be less than TxSendTimeout")
+	}
 	if err := m.SignerCLIConfig.Check(); err != nil {
 		return err
 	}
@@ -198,6 +241,10 @@ func ReadCLIConfig(ctx *cli.Context) CLIConfig {
 		NetworkTimeout:            ctx.Duration(NetworkTimeoutFlagName),
 		TxSendTimeout:             ctx.Duration(TxSendTimeoutFlagName),
 		TxNotInMempoolTimeout:     ctx.Duration(TxNotInMempoolTimeoutFlagName),
+		JournalPath:               ctx.String(JournalPathFlagName),
+		JournalRecovery:           ctx.String(JournalRecoveryFlagName),
+		JournalRecoveryTimeout:    ctx.Duration(JournalRecoveryTimeoutFlagName),
+		CancelTimeout:             ctx.Duration(CancelTimeoutFlagName),
 	}
 }
 
@@ -245,6 +292,10 @@ func NewConfig(cfg CLIConfig, l log.Logger) (Config, error) {
 		SafeAbortNonceTooLowCount: cfg.SafeAbortNonceTooLowCount,
 		Signer:                    signerFactory(chainID),
 		From:                      from,
+		JournalPath:               cfg.JournalPath,
+		JournalRecovery:           cfg.JournalRecovery,
+		JournalRecoveryTimeout:    cfg.JournalRecoveryTimeout,
+		CancelTimeout:             cfg.CancelTimeout,
 	}, nil
 }
 
@@ -289,4 +340,23 @@ type Config struct {
 	// Signer is used to sign transactions when the gas price is increased.
 	Signer opcrypto.SignerFn
 	From   common.Address
+
+	// JournalPath is the file in which in-flight transactions are journaled, such that the
+	// transactions left pending when the process stops are recovered on restart.
+	// By default it is empty and journaling is disabled.
+	JournalPath string
+
+	// JournalRecovery is either JournalRecoveryResume, to keep bumping the recovered
+	// transactions, or JournalRecoveryCancel, to replace them with self-transfers.
+	// Defaults to JournalRecoveryResume if empty.
+	JournalRecovery string
+
+	// JournalRecoveryTimeout is how long the recovery of the journaled transactions may take.
+	// Sends wait for the recovery until then, so that new transactions don't take the nonces of
+	// the recovered ones. Defaults to 10 minutes if 0.
+	JournalRecoveryTimeout time.Duration
+
+	// CancelTimeout is how long a transaction may be pending before it is replaced by a
+	// zero-value self-transfer, releasing its nonce. By default it is 0 and disabled.
+	CancelTimeout time.Duration
 }
@@ -39,3 +39,19 @@ func configForArgs(args ...string) CLIConfig {
 	_ = app.Run(args)
 	return config
 }
+
+func TestInvalidJournalRecovery(t *testing.T) {
+	cfg := configForArgs("txmgr", "--"+JournalRecoveryFlagName+"=drop")
+	require.ErrorContains(t, cfg.Check(), "invalid JournalRecovery")
+
+	cfg = configForArgs("txmgr", "--"+JournalRecoveryFlagName+"="+JournalRecoveryCancel)
+	require.NoError(t, cfg.Check())
+}
+
+func TestCancelTimeoutBelowSendTimeout(t *testing.T) {
+	cfg := configForArgs("txmgr", "--"+TxSendTimeoutFlagName+"=10m", "--"+CancelTimeoutFlagName+"=10m")
+	require.ErrorContains(t, cfg.Check(), "CancelTimeout must be less than TxSendTimeout")
+
+	cfg = configForArgs("txmgr", "--"+TxSendTimeoutFlagName+"=10m", "--"+CancelTimeoutFlagName+"=5m")
+	require.NoError(t, cfg.Check())
+}
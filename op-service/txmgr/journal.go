@@ -0,0 +1,176 @@
+package txmgr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// JournalRecoveryResume resumes the fee bumping of the txs left pending by a previous run
+	JournalRecoveryResume = "resume"
+	// JournalRecoveryCancel replaces the txs left pending by a previous run with zero-value self-transfers
+	JournalRecoveryCancel = "cancel"
+)
+
+// journalEntry is the latest crafted tx at a given nonce, along with the hashes of
+// every tx published at that nonce.
+type journalEntry struct {
+	Nonce     hexutil.Uint64  `json:"nonce"`
+	To        *common.Address `json:"to"`
+	Data      hexutil.Bytes   `json:"data"`
+	Gas       hexutil.Uint64  `json:"gas"`
+	GasTipCap *hexutil.Big    `json:"gasTipCap"`
+	GasFeeCap *hexutil.Big    `json:"gasFeeCap"`
+	TxHashes  []common.Hash   `json:"txHashes"`
+}
+
+// journal persists the in-flight txs of the tx manager to a file, such that the txs left
+// pending when the process stops can be recovered on restart. Every tx is recorded before
+// it is published. Without a path the journal is only kept in memory. A nil journal is
+// valid and records nothing.
+type journal struct {
+	path    string
+	mu      sync.Mutex
+	entries map[uint64]*journalEntry
+}
+
+// openJournal loads the journal at the given path. A missing file is an empty journal.
+func openJournal(path string) (*journal, error) {
+	j := &journal{path: path, entries: make(map[uint64]*journalEntry)}
+	if path == "" {
+		return j, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read journal (%v): %w", path, err)
+	}
+
+	var entries []*journalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid journal (%v): %w", path, err)
+	}
+	for _, entry := range entries {
+		j.entries[uint64(entry.Nonce)] = entry
+	}
+	return j, nil
+}
+
+// record adds the tx to the journal, replacing the fee caps of a previous tx at the same nonce.
+func (j *journal) record(tx *types.Transaction) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[tx.Nonce()]
+	if !ok {
+		entry = &journalEntry{Nonce: hexutil.Uint64(tx.Nonce())}
+		j.entries[tx.Nonce()] = entry
+	}
+	entry.To = tx.To()
+	entry.Data = tx.Data()
+	entry.Gas = hexutil.Uint64(tx.Gas())
+	entry.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+	entry.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+	entry.TxHashes = append(entry.TxHashes, tx.Hash())
+	return j.write()
+}
+
+// remove drops the entry at the given nonce, if any.
+func (j *journal) remove(nonce uint64) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.entries[nonce]; !ok {
+		return nil
+	}
+	delete(j.entries, nonce)
+	return j.write()
+}
+
+// latest returns a copy of the entry at the given nonce, if any.
+func (j *journal) latest(nonce uint64) (journalEntry, bool) {
+	if j == nil {
+		return journalEntry{}, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[nonce]
+	if !ok {
+		return journalEntry{}, false
+	}
+	return *entry, true
+}
+
+// pending returns a copy of the journaled entries, ordered by nonce.
+func (j *journal) pending() []journalEntry {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]journalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, k int) bool { return entries[i].Nonce < entries[k].Nonce })
+	return entries
+}
+
+// write replaces the journal file with the current entries. It must be called with the lock held.
+func (j *journal) write() error {
+	if j.path == "" {
+		return nil
+	}
+	entries := make([]*journalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, k int) bool { return entries[i].Nonce < entries[k].Nonce })
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("marshal journal: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("create journal dir (%v): %w", j.path, err)
+	}
+	// Write the new content to a temp file first, then rename into place
+	// Avoids corrupting the journal if the process stops mid-write
+	tmpFile := j.path + ".tmp"
+	file, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("open file (%v) for writing: %w", tmpFile, err)
+	}
+	defer file.Close() // Ensure file is closed even if write or sync fails
+	if _, err = file.Write(data); err != nil {
+		return fmt.Errorf("write journal to temp file (%v): %w", tmpFile, err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync journal temp file (%v): %w", tmpFile, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close journal temp file (%v): %w", tmpFile, err)
+	}
+	if err := os.Rename(tmpFile, j.path); err != nil {
+		return fmt.Errorf("rename temp journal file to final destination: %w", err)
+	}
+	return nil
+}
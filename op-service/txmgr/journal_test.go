@@ -0,0 +1,72 @@
+package txmgr
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func journalTestTx(nonce uint64, gasFeeCap int64) *types.Transaction {
+	to := common.HexToAddress("0x42000000000000000000000000000000000000ff")
+	return types.NewTx(&types.DynamicFeeTx{
+		Nonce:     nonce,
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(gasFeeCap),
+		Data:      []byte{0x01, 0x02},
+	})
+}
+
+func TestJournalReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "txmgr", "journal.json")
+	j, err := openJournal(path)
+	require.NoError(t, err)
+	require.Empty(t, j.pending())
+
+	tx1, tx2, bumped := journalTestTx(2, 10), journalTestTx(1, 10), journalTestTx(2, 11)
+	require.NoError(t, j.record(tx1))
+	require.NoError(t, j.record(tx2))
+	require.NoError(t, j.record(bumped))
+
+	j, err = openJournal(path)
+	require.NoError(t, err)
+	entries := j.pending()
+	require.Len(t, entries, 2)
+	require.EqualValues(t, 1, entries[0].Nonce)
+	require.Equal(t, []common.Hash{tx2.Hash()}, entries[0].TxHashes)
+	require.EqualValues(t, 2, entries[1].Nonce)
+	require.Equal(t, []common.Hash{tx1.Hash(), bumped.Hash()}, entries[1].TxHashes)
+	require.Equal(t, big.NewInt(11), entries[1].GasFeeCap.ToInt())
+	require.Equal(t, tx1.To(), entries[1].To)
+	require.Equal(t, tx1.Data(), []byte(entries[1].Data))
+	require.EqualValues(t, tx1.Gas(), entries[1].Gas)
+
+	require.NoError(t, j.remove(1))
+	require.NoError(t, j.remove(3))
+	j, err = openJournal(path)
+	require.NoError(t, err)
+	entries = j.pending()
+	require.Len(t, entries, 1)
+	require.EqualValues(t, 2, entries[0].Nonce)
+}
+
+func TestJournalInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0644))
+	_, err := openJournal(path)
+	require.ErrorContains(t, err, "invalid journal")
+}
+
+func TestNilJournal(t *testing.T) {
+	var j *journal
+	require.NoError(t, j.record(journalTestTx(1, 10)))
+	require.NoError(t, j.remove(1))
+	require.Empty(t, j.pending())
+}
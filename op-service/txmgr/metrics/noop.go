@@ -10,4 +10,5 @@ func (*NoopTxMetrics) RecordGasBumpCount(int)            {}
 func (*NoopTxMetrics) RecordTxConfirmationLatency(int64) {}
 func (*NoopTxMetrics) TxConfirmed(*types.Receipt)        {}
 func (*NoopTxMetrics) TxPublished(string)                {}
+func (*NoopTxMetrics) TxCancelled()                      {}
 func (*NoopTxMetrics) RPCError()                         {}
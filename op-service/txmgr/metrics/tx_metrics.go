@@ -15,6 +15,7 @@ type TxMetricer interface {
 	RecordPendingTx(pending int64)
 	TxConfirmed(*types.Receipt)
 	TxPublished(string)
+	TxCancelled()
 	RPCError()
 }
 
@@ -29,6 +30,7 @@ type TxMetrics struct {
 	txPublishError     *prometheus.CounterVec
 	publishEvent       metrics.Event
 	confirmEvent       metrics.EventVec
+	cancelEvent        metrics.Event
 	rpcError           prometheus.Counter
 }
 
@@ -98,6 +100,7 @@ func MakeTxMetrics(ns string, factory metrics.Factory) TxMetrics {
 		}, []string{"error"}),
 		confirmEvent: metrics.NewEventVec(factory, ns, "txmgr", "confirm", "tx confirm", []string{"status"}),
 		publishEvent: metrics.NewEvent(factory, ns, "txmgr", "publish", "tx publish"),
+		cancelEvent:  metrics.NewEvent(factory, ns, "txmgr", "cancel", "tx cancel"),
 		rpcError: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: ns,
 			Name:      "rpc_error_count",
@@ -141,6 +144,11 @@ func (t *TxMetrics) TxPublished(errString string) {
 	}
 }
 
+// TxCancelled records a transaction replaced by a confirmed cancellation
+func (t *TxMetrics) TxCancelled() {
+	t.cancelEvent.Record()
+}
+
 func (t *TxMetrics) RPCError() {
 	t.rpcError.Inc()
 }
@@ -16,6 +16,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ethereum-optimism/optimism/op-service/txmgr/metrics"
 )
@@ -32,6 +34,10 @@ const (
 var priceBumpPercent = big.NewInt(100 + priceBump)
 var oneHundred = big.NewInt(100)
 
+// ErrTxCancelled is returned by Send when the tx was replaced by a cancellation after
+// being pending for longer than [Config.CancelTimeout].
+var ErrTxCancelled = errors.New("transaction cancelled")
+
 // TxManager is an interface that allows callers to reliably publish txs,
 // bumping the gas price if needed, and obtain the receipt of the resulting tx.
 //
@@ -45,6 +51,11 @@ type TxManager interface {
 	// NOTE: Send can be called concurrently, the nonce will be managed internally.
 	Send(ctx context.Context, candidate TxCandidate) (*types.Receipt, error)
 
+	// Cancel replaces the transaction at the given nonce with a zero-value self-transfer,
+	// with fees bumped enough to replace the last transaction sent at that nonce, and waits
+	// for it to confirm. The receipt of the self-transfer is returned.
+	Cancel(ctx context.Context, nonce uint64) (*types.Receipt, error)
+
 	// Call is used to call a contract.
 	// Internally, it uses the [ethclient.Client.CallContract] method.
 	Call(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
@@ -103,6 +114,12 @@ type SimpleTxManager struct {
 	nonceLock sync.RWMutex
 
 	pending atomic.Int64
+
+	// journal tracks the in-flight txs, persisted if a path is configured. The txs left
+	// pending by a previous run are recovered in the background from creation, and sends
+	// wait for the recovery to complete. recoveryDone is closed once it has.
+	journal      *journal
+	recoveryDone chan struct{}
 }
 
 // NewSimpleTxManager initializes a new SimpleTxManager with the passed Config.
@@ -112,14 +129,22 @@ func NewSimpleTxManager(name string, l log.Logger, m metrics.TxMetricer, cfg CLI
 		return nil, err
 	}
 
-	return &SimpleTxManager{
+	j, err := openJournal(conf.JournalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mgr := &SimpleTxManager{
 		chainID: conf.ChainID,
 		name:    name,
 		cfg:     conf,
 		backend: conf.Backend,
 		l:       l.New("service", name),
 		metr:    m,
-	}, nil
+		journal: j,
+	}
+	mgr.startRecovery()
+	return mgr, nil
 }
 
 func (m *SimpleTxManager) From() common.Address {
@@ -139,6 +164,10 @@ type TxCandidate struct {
 	To *common.Address
 	// GasLimit is the gas limit to be used in the constructed tx.
 	GasLimit uint64
+	// OnPublish, if set, is called with every tx crafted for the candidate, including fee bumps,
+	// before it is published. It allows callers to track the tx while it is in flight.
+	// It is not called for the cancellations sent in place of the tx.
+	OnPublish func(tx *types.Transaction)
 }
 
 // Send is used to publish a transaction with incrementally higher gas prices
@@ -155,6 +184,9 @@ func (m *SimpleTxManager) Send(ctx context.Context, candidate TxCandidate) (*typ
 	defer func() {
 		m.metr.RecordPendingTx(m.pending.Add(-1))
 	}()
+	if err := m.awaitRecovery(ctx); err != nil {
+		return nil, fmt.Errorf("failed waiting for the recovery of journaled txs: %w", err)
+	}
 	receipt, err := m.send(ctx, candidate)
 	if err != nil {
 		m.resetNonce()
@@ -162,6 +194,42 @@ func (m *SimpleTxManager) Send(ctx context.Context, candidate TxCandidate) (*typ
 	return receipt, err
 }
 
+// Cancel replaces the transaction at the given nonce with a zero-value self-transfer and waits
+// for it to confirm. The fees are bumped above the last transaction sent at that nonce, or
+// above the current fee suggestions if the nonce is unknown. It waits for the recovery of the
+// journaled txs first, such that the fees of a recovered tx at that nonce are known.
+func (m *SimpleTxManager) Cancel(ctx context.Context, nonce uint64) (*types.Receipt, error) {
+	m.metr.RecordPendingTx(m.pending.Add(1))
+	defer func() {
+		m.metr.RecordPendingTx(m.pending.Add(-1))
+	}()
+	if m.cfg.TxSendTimeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.cfg.TxSendTimeout)
+		defer cancel()
+	}
+	if err := m.awaitRecovery(ctx); err != nil {
+		return nil, fmt.Errorf("failed waiting for the recovery of journaled txs: %w", err)
+	}
+
+	var gasTipCap, gasFeeCap *big.Int
+	if entry, ok := m.journal.latest(nonce); ok {
+		gasTipCap, gasFeeCap = entry.GasTipCap.ToInt(), entry.GasFeeCap.ToInt()
+	}
+	tx, err := m.cancellationTx(ctx, nonce, gasTipCap, gasFeeCap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the cancellation tx: %w", err)
+	}
+
+	m.l.Info("Cancelling transaction", "nonce", nonce)
+	receipt, err := m.sendJournaledTx(ctx, tx, nil)
+	if err != nil {
+		return nil, err
+	}
+	m.metr.TxCancelled()
+	return receipt, nil
+}
+
 // Call is used to call a contract.
 // Internally, it uses the [ethclient.Client.CallContract] method.
 func (m *SimpleTxManager) Call(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
@@ -179,7 +247,159 @@ func (m *SimpleTxManager) send(ctx context.Context, candidate TxCandidate) (*typ
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the tx: %w", err)
 	}
-	return m.sendTx(ctx, tx)
+	return m.sendJournaledTx(ctx, tx, candidate.OnPublish)
+}
+
+// sendJournaledTx records the tx in the journal before sending it with [sendTx]. The journal entry
+// is removed once the tx is confirmed or aborted, but kept if the context is cancelled as the tx
+// may still be pending.
+func (m *SimpleTxManager) sendJournaledTx(ctx context.Context, tx *types.Transaction, onPublish func(tx *types.Transaction)) (*types.Receipt, error) {
+	if err := m.journal.record(tx); err != nil {
+		return nil, fmt.Errorf("failed to journal the tx: %w", err)
+	}
+	receipt, err := m.sendTx(ctx, tx, onPublish)
+	if ctx.Err() == nil {
+		if err := m.journal.remove(tx.Nonce()); err != nil {
+			m.l.Error("Failed to remove tx from the journal", "nonce", tx.Nonce(), "err", err)
+		}
+	}
+	return receipt, err
+}
+
+// startRecovery recovers the journaled txs in the background, within [Config.JournalRecoveryTimeout],
+// such that the txs of a restarted service are recovered even if it doesn't send new ones. Txs that
+// fail to be recovered are left in the journal and recovered on the next restart.
+func (m *SimpleTxManager) startRecovery() {
+	done := make(chan struct{})
+	m.recoveryDone = done
+	timeout := m.cfg.JournalRecoveryTimeout
+	if timeout == 0 {
+		timeout = defaultJournalRecoveryTimeout
+	}
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := m.recoverJournal(ctx); err != nil {
+			m.l.Error("Failed to recover journaled txs", "err", err)
+		}
+	}()
+}
+
+// awaitRecovery waits for the recovery of the journaled txs to complete, or for the context
+// to be done. The recovery itself is bounded by [Config.JournalRecoveryTimeout].
+func (m *SimpleTxManager) awaitRecovery(ctx context.Context) error {
+	if m.recoveryDone == nil {
+		return nil
+	}
+	select {
+	case <-m.recoveryDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// recoverJournal resends the txs left pending in the journal by a previous run, or replaces them
+// with self-transfers depending on [Config.JournalRecovery], and waits for them to confirm. Txs at
+// nonces already used on chain are dropped.
+func (m *SimpleTxManager) recoverJournal(ctx context.Context) error {
+	entries := m.journal.pending()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	nonce, err := m.backend.NonceAt(cCtx, m.cfg.From, nil)
+	if err != nil {
+		m.metr.RPCError()
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	m.l.Info("Recovering journaled txs", "count", len(entries), "nonce", nonce, "mode", m.cfg.JournalRecovery)
+	group, gCtx := errgroup.WithContext(ctx)
+	for _, entry := range entries {
+		entry := entry
+		log := m.l.New("nonce", uint64(entry.Nonce), "hashes", entry.TxHashes)
+		if uint64(entry.Nonce) < nonce {
+			log.Info("Dropping journaled tx, nonce already used")
+			if err := m.journal.remove(uint64(entry.Nonce)); err != nil {
+				return fmt.Errorf("failed to remove tx from the journal: %w", err)
+			}
+			continue
+		}
+
+		group.Go(func() error {
+			var tx *types.Transaction
+			var err error
+			if m.cfg.JournalRecovery == JournalRecoveryCancel {
+				log.Info("Cancelling journaled tx")
+				tx, err = m.cancellationTx(gCtx, uint64(entry.Nonce), entry.GasTipCap.ToInt(), entry.GasFeeCap.ToInt())
+			} else {
+				log.Info("Resuming journaled tx")
+				tx, err = m.journaledTx(gCtx, entry)
+			}
+			if err != nil {
+				return err
+			}
+
+			receipt, err := m.sendJournaledTx(gCtx, tx, nil)
+			if gCtx.Err() != nil {
+				return gCtx.Err()
+			} else if err != nil {
+				// an aborted tx no longer holds its nonce
+				log.Warn("Journaled tx aborted", "err", err)
+				return nil
+			}
+			log.Info("Journaled tx confirmed", "hash", receipt.TxHash)
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+// journaledTx re-creates the signed, latest, tx of a journal entry.
+func (m *SimpleTxManager) journaledTx(ctx context.Context, entry journalEntry) (*types.Transaction, error) {
+	rawTx := &types.DynamicFeeTx{
+		ChainID:   m.chainID,
+		Nonce:     uint64(entry.Nonce),
+		To:        entry.To,
+		Gas:       uint64(entry.Gas),
+		GasTipCap: entry.GasTipCap.ToInt(),
+		GasFeeCap: entry.GasFeeCap.ToInt(),
+		Data:      entry.Data,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	return m.cfg.Signer(ctx, m.cfg.From, types.NewTx(rawTx))
+}
+
+// cancellationTx creates a zero-value self-transfer at the given nonce, with fees bumped enough
+// to replace a tx with the given tip & fee cap. If they are nil, the unknown tx at the nonce is
+// assumed to pay the current fee suggestions.
+func (m *SimpleTxManager) cancellationTx(ctx context.Context, nonce uint64, gasTipCap, gasFeeCap *big.Int) (*types.Transaction, error) {
+	tip, basefee, err := m.suggestGasPriceCaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if gasTipCap == nil || gasFeeCap == nil {
+		gasTipCap, gasFeeCap = tip, calcGasFeeCap(basefee, tip)
+	}
+	bumpedTip, bumpedFee := updateFees(gasTipCap, gasFeeCap, tip, basefee, m.l)
+	rawTx := &types.DynamicFeeTx{
+		ChainID:   m.chainID,
+		Nonce:     nonce,
+		To:        &m.cfg.From,
+		Gas:       params.TxGas,
+		GasTipCap: bumpedTip,
+		GasFeeCap: bumpedFee,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	return m.cfg.Signer(ctx, m.cfg.From, types.NewTx(rawTx))
 }
 
 // craftTx creates the signed transaction
@@ -269,8 +489,11 @@ func (m *SimpleTxManager) resetNonce() {
 }
 
 // send submits the same transaction several times with increasing gas prices as necessary.
-// It waits for the transaction to be confirmed on chain.
-func (m *SimpleTxManager) sendTx(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+// It waits for the transaction to be confirmed on chain. If the transaction is pending for
+// longer than [Config.CancelTimeout], it is replaced by a cancellation and [ErrTxCancelled]
+// is returned once the cancellation confirms. onPublish, if not nil, is called before each tx
+// other than a cancellation is published.
+func (m *SimpleTxManager) sendTx(ctx context.Context, tx *types.Transaction, onPublish func(tx *types.Transaction)) (*types.Receipt, error) {
 	var wg sync.WaitGroup
 	defer wg.Wait()
 	ctx, cancel := context.WithCancel(ctx)
@@ -283,13 +506,23 @@ func (m *SimpleTxManager) sendTx(ctx context.Context, tx *types.Transaction) (*t
 		m.publishAndWaitForTx(ctx, tx, sendState, receiptChan)
 	}
 
+	// Explicit cancellations are never cancelled themselves. The hashes of the
+	// cancellations sent in place of the transaction are tracked to report them.
+	cancelling := isCancellation(tx, m.cfg.From)
+
 	// Immediately publish a transaction before starting the resumbission loop
+	if onPublish != nil && !cancelling {
+		onPublish(tx)
+	}
 	wg.Add(1)
 	go sendTxAsync(tx)
 
 	ticker := time.NewTicker(m.cfg.ResubmissionTimeout)
 	defer ticker.Stop()
 
+	start := time.Now()
+	cancellations := make(map[common.Hash]struct{})
+
 	bumpCounter := 0
 	for {
 		select {
@@ -303,8 +536,17 @@ func (m *SimpleTxManager) sendTx(ctx context.Context, tx *types.Transaction) (*t
 				m.l.Warn("Aborting transaction submission")
 				return nil, errors.New("aborted transaction sending")
 			}
-			// Increase the gas price & submit the new transaction
-			newTx, err := m.increaseGasPrice(ctx, tx)
+			var newTx *types.Transaction
+			var err error
+			pastDeadline := !cancelling && m.cfg.CancelTimeout != 0 && time.Since(start) >= m.cfg.CancelTimeout
+			if pastDeadline {
+				// Replace the transaction with a self-transfer to release its nonce
+				m.l.Warn("Cancelling transaction pending past the cancel timeout", "hash", tx.Hash(), "nonce", tx.Nonce())
+				newTx, err = m.cancellationTx(ctx, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap())
+			} else {
+				// Increase the gas price & submit the new transaction
+				newTx, err = m.increaseGasPrice(ctx, tx)
+			}
 			if err != nil || sendState.IsWaitingForConfirmation() {
 				// there is a chance the previous tx goes into "waiting for confirmation" state
 				// during the increaseGasPrice call. In some (but not all) cases increaseGasPrice
@@ -313,6 +555,16 @@ func (m *SimpleTxManager) sendTx(ctx context.Context, tx *types.Transaction) (*t
 				continue
 			}
 			tx = newTx
+			cancelling = cancelling || pastDeadline
+			if pastDeadline || len(cancellations) > 0 {
+				cancellations[tx.Hash()] = struct{}{}
+			}
+			if err := m.journal.record(tx); err != nil {
+				m.l.Error("Failed to journal the bumped tx", "hash", tx.Hash(), "err", err)
+			}
+			if onPublish != nil && !cancelling {
+				onPublish(tx)
+			}
 			wg.Add(1)
 			bumpCounter += 1
 			go sendTxAsync(tx)
@@ -323,6 +575,10 @@ func (m *SimpleTxManager) sendTx(ctx context.Context, tx *types.Transaction) (*t
 		case receipt := <-receiptChan:
 			m.metr.RecordGasBumpCount(bumpCounter)
 			m.metr.TxConfirmed(receipt)
+			if _, ok := cancellations[receipt.TxHash]; ok {
+				m.metr.TxCancelled()
+				return nil, ErrTxCancelled
+			}
 			return receipt, nil
 		}
 	}
@@ -518,6 +774,11 @@ func (m *SimpleTxManager) increaseGasPrice(ctx context.Context, tx *types.Transa
 	return newTx, nil
 }
 
+// isCancellation returns true if the tx is a zero-value self-transfer, as sent by [cancellationTx].
+func isCancellation(tx *types.Transaction, from common.Address) bool {
+	return tx.To() != nil && *tx.To() == from && tx.Value().Sign() == 0 && len(tx.Data()) == 0
+}
+
 // suggestGasPriceCaps suggests what the new tip & new basefee should be based on the current L1 conditions
 func (m *SimpleTxManager) suggestGasPriceCaps(ctx context.Context) (*big.Int, *big.Int, error) {
 	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -282,7 +283,7 @@ func TestTxMgrConfirmAtMinGasPrice(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	receipt, err := h.mgr.sendTx(ctx, tx)
+	receipt, err := h.mgr.sendTx(ctx, tx, nil)
 	require.Nil(t, err)
 	require.NotNil(t, receipt)
 	require.Equal(t, gasPricer.expGasFeeCap().Uint64(), receipt.GasUsed)
@@ -310,7 +311,7 @@ func TestTxMgrNeverConfirmCancel(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	receipt, err := h.mgr.sendTx(ctx, tx)
+	receipt, err := h.mgr.sendTx(ctx, tx, nil)
 	require.Equal(t, err, context.DeadlineExceeded)
 	require.Nil(t, receipt)
 }
@@ -339,12 +340,89 @@ func TestTxMgrConfirmsAtHigherGasPrice(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	receipt, err := h.mgr.sendTx(ctx, tx)
+	receipt, err := h.mgr.sendTx(ctx, tx, nil)
 	require.Nil(t, err)
 	require.NotNil(t, receipt)
 	require.Equal(t, h.gasPricer.expGasFeeCap().Uint64(), receipt.GasUsed)
 }
 
+// TestTxMgrCallsOnPublish asserts that the OnPublish hook of the candidate is called
+// before each tx is published, including the fee bumped txs, but not cancellations.
+func TestTxMgrCallsOnPublish(t *testing.T) {
+	t.Parallel()
+
+	cfg := configWithNumConfs(1)
+	cfg.ResubmissionTimeout = 100 * time.Millisecond
+	cfg.CancelTimeout = 300 * time.Millisecond
+	h := newTestHarnessWithConfig(t, cfg)
+
+	var mu sync.Mutex
+	hooked := make(map[common.Hash]bool)
+	var published []*types.Transaction
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, tx)
+		// Only the cancellation is ever mined
+		if isCancellation(tx, h.cfg.From) {
+			txHash := tx.Hash()
+			h.backend.mine(&txHash, tx.GasFeeCap())
+		} else if !hooked[tx.Hash()] {
+			return errors.New("published before calling hook")
+		}
+		return nil
+	})
+
+	candidate := h.createTxCandidate()
+	candidate.OnPublish = func(tx *types.Transaction) {
+		mu.Lock()
+		defer mu.Unlock()
+		hooked[tx.Hash()] = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := h.mgr.Send(ctx, candidate)
+	require.ErrorIs(t, err, ErrTxCancelled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Greater(t, len(hooked), 1, "should call hook for the fee bumped txs")
+	for _, tx := range published {
+		require.Equal(t, !isCancellation(tx, h.cfg.From), hooked[tx.Hash()])
+	}
+}
+
+// TestTxMgrCallsOnPublishForMinedTx asserts that the OnPublish hook is called with the
+// tx that ends up mined, before it is published.
+func TestTxMgrCallsOnPublishForMinedTx(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+
+	var hooked []*types.Transaction
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		require.Len(t, hooked, 1, "should call hook before publishing")
+		require.Equal(t, hooked[0].Hash(), tx.Hash())
+		txHash := tx.Hash()
+		h.backend.mine(&txHash, tx.GasFeeCap())
+		return nil
+	})
+
+	candidate := h.createTxCandidate()
+	candidate.OnPublish = func(tx *types.Transaction) {
+		hooked = append(hooked, tx)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	receipt, err := h.mgr.Send(ctx, candidate)
+	require.NoError(t, err)
+	require.Len(t, hooked, 1)
+	require.Equal(t, hooked[0].Hash(), receipt.TxHash)
+	require.Equal(t, candidate.TxData, hooked[0].Data())
+}
+
 // errRpcFailure is a sentinel error used in testing to fail publications.
 var errRpcFailure = errors.New("rpc failure")
 
@@ -370,7 +448,7 @@ func TestTxMgrBlocksOnFailingRpcCalls(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	receipt, err := h.mgr.sendTx(ctx, tx)
+	receipt, err := h.mgr.sendTx(ctx, tx, nil)
 	require.Equal(t, err, context.DeadlineExceeded)
 	require.Nil(t, receipt)
 }
@@ -448,7 +526,7 @@ func TestTxMgrOnlyOnePublicationSucceeds(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	receipt, err := h.mgr.sendTx(ctx, tx)
+	receipt, err := h.mgr.sendTx(ctx, tx, nil)
 	require.Nil(t, err)
 
 	require.NotNil(t, receipt)
@@ -483,7 +561,7 @@ func TestTxMgrConfirmsMinGasPriceAfterBumping(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	receipt, err := h.mgr.sendTx(ctx, tx)
+	receipt, err := h.mgr.sendTx(ctx, tx, nil)
 	require.Nil(t, err)
 	require.NotNil(t, receipt)
 	require.Equal(t, h.gasPricer.expGasFeeCap().Uint64(), receipt.GasUsed)
@@ -528,7 +606,7 @@ func TestTxMgrDoesntAbortNonceTooLowAfterMiningTx(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	receipt, err := h.mgr.sendTx(ctx, tx)
+	receipt, err := h.mgr.sendTx(ctx, tx, nil)
 	require.Nil(t, err)
 	require.NotNil(t, receipt)
 	require.Equal(t, h.gasPricer.expGasFeeCap().Uint64(), receipt.GasUsed)
@@ -922,3 +1000,242 @@ func TestNonceReset(t *testing.T) {
 	// internal nonce tracking should be reset every 3rd tx
 	require.Equal(t, []uint64{0, 0, 1, 2, 0, 1, 2, 0}, nonces)
 }
+
+// TestJournalRecovery asserts that the txs left pending in the journal are resumed,
+// or cancelled, before the first send and removed from the journal once confirmed.
+func TestJournalRecovery(t *testing.T) {
+	for _, mode := range []string{JournalRecoveryResume, JournalRecoveryCancel} {
+		mode := mode
+		t.Run(mode, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHarness(t)
+			h.mgr.cfg.JournalRecovery = mode
+
+			path := filepath.Join(t.TempDir(), "journal.json")
+			j, err := openJournal(path)
+			require.NoError(t, err)
+			pending := journalTestTx(0, 1000)
+			require.NoError(t, j.record(pending))
+			h.mgr.journal = j
+
+			var mu sync.Mutex
+			var published []*types.Transaction
+			h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+				mu.Lock()
+				published = append(published, tx)
+				mu.Unlock()
+				txHash := tx.Hash()
+				h.backend.mine(&txHash, tx.GasFeeCap())
+				return nil
+			})
+
+			h.mgr.startRecovery()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			candidate := h.createTxCandidate()
+			_, err = h.mgr.Send(ctx, candidate)
+			require.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Len(t, published, 2)
+			recovered := published[0]
+			require.Equal(t, pending.Nonce(), recovered.Nonce())
+			if mode == JournalRecoveryResume {
+				require.Equal(t, pending.Hash(), recovered.Hash())
+			} else {
+				require.Equal(t, h.cfg.From, *recovered.To())
+				require.Empty(t, recovered.Data())
+				require.Zero(t, recovered.Value().Sign())
+				require.True(t, recovered.GasFeeCap().Cmp(calcThresholdValue(pending.GasFeeCap())) >= 0)
+				require.True(t, recovered.GasTipCap().Cmp(calcThresholdValue(pending.GasTipCap())) >= 0)
+			}
+			require.Equal(t, candidate.TxData, published[1].Data())
+
+			// both the recovered and the new tx have been removed
+			j, err = openJournal(path)
+			require.NoError(t, err)
+			require.Empty(t, j.pending())
+		})
+	}
+}
+
+// TestJournalRecoveryWithoutSend asserts that the journaled txs are recovered even if
+// no new tx is sent, and that sends only wait for the recovery as long as their context.
+func TestJournalRecoveryWithoutSend(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	j, err := openJournal("")
+	require.NoError(t, err)
+	require.NoError(t, j.record(journalTestTx(0, 1000)))
+	h.mgr.journal = j
+
+	mined := make(chan struct{})
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		// hold the recovered tx until a send has given up waiting for it
+		<-mined
+		txHash := tx.Hash()
+		h.backend.mine(&txHash, tx.GasFeeCap())
+		return nil
+	})
+	h.mgr.startRecovery()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = h.mgr.Send(ctx, h.createTxCandidate())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(mined)
+	select {
+	case <-h.mgr.recoveryDone:
+	case <-time.After(10 * time.Second):
+		t.Fatal("recovery did not complete")
+	}
+	require.Empty(t, j.pending())
+}
+
+// TestJournalRecoveryTimeout asserts that sends proceed once the recovery times out.
+func TestJournalRecoveryTimeout(t *testing.T) {
+	t.Parallel()
+
+	cfg := configWithNumConfs(1)
+	cfg.JournalRecoveryTimeout = 200 * time.Millisecond
+	h := newTestHarnessWithConfig(t, cfg)
+	j, err := openJournal("")
+	require.NoError(t, err)
+	pending := journalTestTx(0, 1000)
+	require.NoError(t, j.record(pending))
+	h.mgr.journal = j
+
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		// the recovered tx is never mined
+		if tx.Hash() != pending.Hash() {
+			txHash := tx.Hash()
+			h.backend.mine(&txHash, tx.GasFeeCap())
+		}
+		return nil
+	})
+	h.mgr.startRecovery()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = h.mgr.Send(ctx, h.createTxCandidate())
+	require.NoError(t, err)
+}
+
+// TestTxMgrCancelTimeout asserts that a tx pending past the cancel timeout is replaced
+// by a self-transfer and that Send returns ErrTxCancelled once it confirms.
+func TestTxMgrCancelTimeout(t *testing.T) {
+	t.Parallel()
+
+	cfg := configWithNumConfs(1)
+	cfg.ResubmissionTimeout = 100 * time.Millisecond
+	cfg.CancelTimeout = 300 * time.Millisecond
+	h := newTestHarnessWithConfig(t, cfg)
+
+	var mu sync.Mutex
+	var published []*types.Transaction
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		mu.Lock()
+		published = append(published, tx)
+		mu.Unlock()
+		// Only the cancellation is ever mined
+		if isCancellation(tx, h.cfg.From) {
+			txHash := tx.Hash()
+			h.backend.mine(&txHash, tx.GasFeeCap())
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	receipt, err := h.mgr.Send(ctx, h.createTxCandidate())
+	require.ErrorIs(t, err, ErrTxCancelled)
+	require.Nil(t, receipt)
+
+	mu.Lock()
+	defer mu.Unlock()
+	original, cancellation := published[0], published[len(published)-1]
+	require.True(t, isCancellation(cancellation, h.cfg.From))
+	require.Equal(t, original.Nonce(), cancellation.Nonce())
+	require.EqualValues(t, 21000, cancellation.Gas())
+}
+
+// TestTxMgrCancelUnknownNonce asserts that cancelling a nonce without a journaled tx bumps
+// the fees above the current suggestions, to replace a tx sent at those fees.
+func TestTxMgrCancelUnknownNonce(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	var cancellation *types.Transaction
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		if cancellation == nil {
+			cancellation = tx
+		}
+		txHash := tx.Hash()
+		h.backend.mine(&txHash, tx.GasFeeCap())
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := h.mgr.Cancel(ctx, 5)
+	require.NoError(t, err)
+	require.True(t, isCancellation(cancellation, h.cfg.From))
+	suggestedTip, suggestedFeeCap := h.gasPricer.feesForEpoch(1)
+	require.True(t, cancellation.GasTipCap().Cmp(calcThresholdValue(suggestedTip)) >= 0)
+	require.True(t, cancellation.GasFeeCap().Cmp(calcThresholdValue(suggestedFeeCap)) >= 0)
+}
+
+// TestTxMgrCancelAwaitsRecovery asserts that cancellations wait for the recovery of the journaled txs.
+func TestTxMgrCancelAwaitsRecovery(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	h.mgr.recoveryDone = make(chan struct{})
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		t.Fatal("should not send the cancellation before the recovery completed")
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := h.mgr.Cancel(ctx, 5)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestTxMgrCancel asserts that an explicit cancellation bumps the fees of the last tx
+// sent at the nonce and returns the receipt of the self-transfer.
+func TestTxMgrCancel(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	j, err := openJournal("")
+	require.NoError(t, err)
+	stuck := journalTestTx(3, 1000)
+	require.NoError(t, j.record(stuck))
+	h.mgr.journal = j
+
+	var cancellation *types.Transaction
+	h.backend.setTxSender(func(ctx context.Context, tx *types.Transaction) error {
+		cancellation = tx
+		txHash := tx.Hash()
+		h.backend.mine(&txHash, tx.GasFeeCap())
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	receipt, err := h.mgr.Cancel(ctx, stuck.Nonce())
+	require.NoError(t, err)
+	require.Equal(t, cancellation.Hash(), receipt.TxHash)
+	require.True(t, isCancellation(cancellation, h.cfg.From))
+	require.Equal(t, stuck.Nonce(), cancellation.Nonce())
+	require.True(t, cancellation.GasFeeCap().Cmp(calcThresholdValue(stuck.GasFeeCap())) >= 0)
+	require.True(t, cancellation.GasTipCap().Cmp(calcThresholdValue(stuck.GasTipCap())) >= 0)
+
+	_, ok := j.latest(stuck.Nonce())
+	require.False(t, ok)
+}
@@ -16,6 +16,9 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// DefaultListenAddr is the default listening address of the servers configured by the CLI flags
+const DefaultListenAddr = "0.0.0.0" // TODO(CLI-4159): Switch to 127.0.0.1
+
 // PrefixEnvVar adds a prefix to the environment variable,
 // and returns the env-var wrapped in a slice for usage with urfave CLI v2.
 func PrefixEnvVar(prefix, suffix string) []string {
@@ -0,0 +1,86 @@
+package proxyd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// HandleAdminRPC serves the admin RPC, authorized by the admin key as a bearer token:
+//   - proxyd_getUsage(key, [day]) returns the usage of a client and the limits of its tier
+//   - proxyd_resetUsage(key, [day]) resets the usage of a client
+//
+// The key is the alias of the authentication key of the client, and the day is formatted as YYYY-MM-DD in UTC,
+// defaulting to the current day.
+func (s *Server) HandleAdminRPC(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), ContextKeyReqID, randStr(10)) // nolint:staticcheck
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminKey)) != 1 {
+		log.Info("blocked unauthorized admin request", "req_id", GetReqID(ctx))
+		httpResponseCodesTotal.WithLabelValues("401").Inc()
+		w.WriteHeader(401)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.maxBodySize))
+	if err != nil {
+		log.Error("error reading admin request body", "err", err)
+		writeRPCError(ctx, w, nil, ErrInternal)
+		return
+	}
+	req, err := ParseRPCReq(body)
+	if err != nil {
+		writeRPCError(ctx, w, nil, err)
+		return
+	}
+	if err := ValidateRPCReq(req); err != nil {
+		writeRPCError(ctx, w, nil, err)
+		return
+	}
+
+	if req.Method != "proxyd_getUsage" && req.Method != "proxyd_resetUsage" {
+		writeRPCError(ctx, w, req.ID, ErrMethodNotWhitelisted)
+		return
+	}
+
+	var params []string
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) < 1 || len(params) > 2 {
+		writeRPCError(ctx, w, req.ID, ErrInvalidParams("expected a key and an optional day"))
+		return
+	}
+	key := params[0]
+	var day string
+	if len(params) == 2 {
+		day = params[1]
+		if _, err := time.Parse(quotaDayFormat, day); err != nil {
+			writeRPCError(ctx, w, req.ID, ErrInvalidParams("day must be formatted as YYYY-MM-DD"))
+			return
+		}
+	}
+
+	switch req.Method {
+	case "proxyd_getUsage":
+		usage, err := s.quotas.Usage(ctx, key, day)
+		if err != nil {
+			log.Error("error getting usage", "key", key, "req_id", GetReqID(ctx), "err", err)
+			writeRPCError(ctx, w, req.ID, ErrInternal)
+			return
+		}
+		writeRPCRes(ctx, w, NewRPCRes(req.ID, usage))
+	case "proxyd_resetUsage":
+		if err := s.quotas.ResetUsage(ctx, key, day); err != nil {
+			log.Error("error resetting usage", "key", key, "req_id", GetReqID(ctx), "err", err)
+			writeRPCError(ctx, w, req.ID, ErrInternal)
+			return
+		}
+		log.Info("reset usage", "key", key, "day", day, "req_id", GetReqID(ctx))
+		writeRPCRes(ctx, w, NewRPCRes(req.ID, true))
+	}
+}
@@ -511,8 +511,11 @@ func (b *Backend) doForward(ctx context.Context, rpcReqs []*RPCReq, isBatch bool
 	start := time.Now()
 	httpRes, err := b.client.DoLimited(httpReq)
 	if err != nil {
-		b.networkErrorsSlidingWindow.Incr()
-		RecordBackendNetworkErrorRateSlidingWindow(b, b.ErrorRate())
+		// requests cancelled by proxyd, such as the slower request of a hedged pair, aren't backend errors
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			b.networkErrorsSlidingWindow.Incr()
+			RecordBackendNetworkErrorRateSlidingWindow(b, b.ErrorRate())
+		}
 		return nil, wrapErr(err, "error in backend request")
 	}
 
@@ -656,6 +659,15 @@ type BackendGroup struct {
 	Name      string
 	Backends  []*Backend
 	Consensus *ConsensusPoller
+	// Router orders the backends for each request, nil keeps their order
+	Router Router
+	// Sticky routes the reads depending on a submitted transaction to the backend that received it, if set
+	Sticky *StickySessions
+	// BlockCache caches the responses of requests at a block number, for consensus aware groups
+	BlockCache *BlockCache
+
+	GetLogsMaxBlockRange uint64
+	GetLogsMaxChunks     int
 }
 
 func (bg *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch bool) ([]*RPCRes, error) {
@@ -668,48 +680,112 @@ func (bg *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch
 	overriddenResponses := make([]*indexedReqRes, 0)
 	rewrittenReqs := make([]*RPCReq, 0, len(rpcReqs))
 
+	var rctx *RewriteContext
 	if bg.Consensus != nil {
 		// When `consensus_aware` is set to `true`, the backend group acts as a load balancer
 		// serving traffic from any backend that agrees in the consensus group
 		backends = bg.loadBalancedConsensusGroup()
 
 		// We also rewrite block tags to enforce compliance with consensus
-		rctx := RewriteContext{
+		rctx = &RewriteContext{
 			latest:    bg.Consensus.GetLatestBlockNumber(),
 			safe:      bg.Consensus.GetSafeBlockNumber(),
 			finalized: bg.Consensus.GetFinalizedBlockNumber(),
 		}
+	}
 
+	if rctx != nil || bg.GetLogsMaxBlockRange > 0 {
 		for i, req := range rpcReqs {
-			res := RPCRes{JSONRPC: JSONRPCVersion, ID: req.ID}
-			result, err := RewriteTags(rctx, req, &res)
-			switch result {
-			case RewriteOverrideError:
+			if rctx != nil {
+				res := RPCRes{JSONRPC: JSONRPCVersion, ID: req.ID}
+				result, err := RewriteTags(*rctx, req, &res)
+				switch result {
+				case RewriteOverrideError:
+					overriddenResponses = append(overriddenResponses, &indexedReqRes{
+						index: i,
+						req:   req,
+						res:   &res,
+					})
+					if errors.Is(err, ErrRewriteBlockOutOfRange) {
+						res.Error = ErrBlockOutOfRange
+					} else {
+						res.Error = ErrParseErr
+					}
+					continue
+				case RewriteOverrideResponse:
+					overriddenResponses = append(overriddenResponses, &indexedReqRes{
+						index: i,
+						req:   req,
+						res:   &res,
+					})
+					continue
+				}
+			}
+
+			if bg.BlockCache != nil && rctx != nil {
+				if res := bg.BlockCache.Get(*rctx, req); res != nil {
+					overriddenResponses = append(overriddenResponses, &indexedReqRes{
+						index: i,
+						req:   req,
+						res:   res,
+					})
+					continue
+				}
+			}
+
+			// Oversized eth_getLogs requests are split and fanned out across the backends,
+			// after block tags have been rewritten to block numbers
+			filter, chunks, err := splitGetLogsRange(req, bg.GetLogsMaxBlockRange, bg.GetLogsMaxChunks)
+			if err != nil {
 				overriddenResponses = append(overriddenResponses, &indexedReqRes{
 					index: i,
 					req:   req,
-					res:   &res,
+					res:   NewRPCErrorRes(req.ID, err),
 				})
-				if errors.Is(err, ErrRewriteBlockOutOfRange) {
-					res.Error = ErrBlockOutOfRange
-				} else {
-					res.Error = ErrParseErr
+				continue
+			}
+			if chunks != nil {
+				res := bg.forwardGetLogsChunks(ctx, backends, req, filter, chunks)
+				if bg.BlockCache != nil && rctx != nil {
+					bg.BlockCache.Put(*rctx, req, res)
 				}
-			case RewriteOverrideResponse:
 				overriddenResponses = append(overriddenResponses, &indexedReqRes{
 					index: i,
 					req:   req,
-					res:   &res,
+					res:   res,
 				})
-			case RewriteOverrideRequest, RewriteNone:
-				rewrittenReqs = append(rewrittenReqs, req)
+				continue
 			}
+
+			rewrittenReqs = append(rewrittenReqs, req)
 		}
 		rpcReqs = rewrittenReqs
 	}
 
 	rpcRequestsTotal.Inc()
 
+	if len(rpcReqs) > 0 {
+		if bg.Router != nil {
+			backends = bg.Router.Order(backends)
+		}
+		// requests pinned by a sticky session must not be hedged to another backend
+		pinned := false
+		if bg.Sticky != nil {
+			backends, pinned = bg.Sticky.Order(ctx, bg, rpcReqs, backends)
+		}
+		if router, ok := bg.Router.(*hedgedRouter); ok && len(backends) > 0 && !pinned && canHedge(rpcReqs) {
+			res, back, err := bg.forwardHedged(ctx, router, backends, rpcReqs, isBatch)
+			if errors.Is(err, ErrNoBackends) {
+				RecordUnserviceableRequest(ctx, RPCRequestSourceHTTP)
+			}
+			if err != nil {
+				return nil, err
+			}
+			bg.forwarded(ctx, rctx, rpcReqs, res, back)
+			return reapplyOverriddenResponses(res, overriddenResponses), nil
+		}
+	}
+
 	for _, back := range backends {
 		res := make([]*RPCRes, 0)
 		var err error
@@ -749,25 +825,39 @@ func (bg *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch
 				)
 				continue
 			}
+			bg.forwarded(ctx, rctx, rpcReqs, res, back)
 		}
 
-		// re-apply overridden responses
-		for _, ov := range overriddenResponses {
-			if len(res) > 0 {
-				// insert ov.res at position ov.index
-				res = append(res[:ov.index], append([]*RPCRes{ov.res}, res[ov.index:]...)...)
-			} else {
-				res = append(res, ov.res)
-			}
-		}
-
-		return res, nil
+		return reapplyOverriddenResponses(res, overriddenResponses), nil
 	}
 
 	RecordUnserviceableRequest(ctx, RPCRequestSourceHTTP)
 	return nil, ErrNoBackends
 }
 
+// forwarded is called once the requests have been forwarded to a backend
+func (bg *BackendGroup) forwarded(ctx context.Context, rctx *RewriteContext, rpcReqs []*RPCReq, res []*RPCRes, back *Backend) {
+	RecordRoutedRequest(bg, back)
+	if bg.Sticky != nil {
+		bg.Sticky.Record(ctx, bg, rpcReqs, res, back)
+	}
+	if bg.BlockCache != nil && rctx != nil {
+		bg.BlockCache.PutAll(*rctx, rpcReqs, res)
+	}
+}
+
+func reapplyOverriddenResponses(res []*RPCRes, overriddenResponses []*indexedReqRes) []*RPCRes {
+	for _, ov := range overriddenResponses {
+		if len(res) > 0 {
+			// insert ov.res at position ov.index
+			res = append(res[:ov.index], append([]*RPCRes{ov.res}, res[ov.index:]...)...)
+		} else {
+			res = append(res, ov.res)
+		}
+	}
+	return res
+}
+
 func (bg *BackendGroup) ProxyWS(ctx context.Context, clientConn *websocket.Conn, methodWhitelist *StringSet) (*WSProxier, error) {
 	for _, back := range bg.Backends {
 		proxier, err := back.ProxyWS(clientConn, methodWhitelist)
@@ -858,6 +948,7 @@ type WSProxier struct {
 	backendConn     *websocket.Conn
 	backendConnMu   sync.Mutex
 	methodWhitelist *StringSet
+	quotas          *Quotas
 	readTimeout     time.Duration
 	writeTimeout    time.Duration
 }
@@ -911,7 +1002,7 @@ func (w *WSProxier) clientPump(ctx context.Context, errC chan error) {
 
 		// Don't bother sending invalid requests to the backend,
 		// just handle them here.
-		req, err := w.prepareClientMsg(msg)
+		req, err := w.prepareClientMsg(ctx, msg)
 		if err != nil {
 			var id json.RawMessage
 			method := MethodUnknown
@@ -1031,7 +1122,7 @@ func (w *WSProxier) close() {
 	activeBackendWsConnsGauge.WithLabelValues(w.backend.Name).Dec()
 }
 
-func (w *WSProxier) prepareClientMsg(msg []byte) (*RPCReq, error) {
+func (w *WSProxier) prepareClientMsg(ctx context.Context, msg []byte) (*RPCReq, error) {
 	req, err := ParseRPCReq(msg)
 	if err != nil {
 		return nil, err
@@ -1041,6 +1132,12 @@ func (w *WSProxier) prepareClientMsg(msg []byte) (*RPCReq, error) {
 		return req, ErrMethodNotWhitelisted
 	}
 
+	if w.quotas != nil {
+		if err := w.quotas.TakeMessage(ctx, req.Method); err != nil {
+			return req, err
+		}
+	}
+
 	return req, nil
 }
 
@@ -0,0 +1,193 @@
+package proxyd
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	defaultBlockCacheSize = 10000
+	defaultSafeTTL        = time.Minute
+	defaultUnsafeTTL      = 2 * time.Second
+)
+
+// blockParamPositions is the position of the block number param of the methods cached by BlockCache
+var blockParamPositions = map[string]int{
+	"eth_getBlockByNumber":                    0,
+	"eth_getBlockTransactionCountByNumber":    0,
+	"eth_getTransactionByBlockNumberAndIndex": 0,
+	"eth_getBalance":                          1,
+	"eth_getCode":                             1,
+	"eth_call":                                1,
+	"eth_getStorageAt":                        2,
+}
+
+// BlockCache caches the responses of requests at a given block number for a consensus aware backend group.
+// It relies on the block tags having been rewritten to the numbers of the consensus, so requests for "latest"
+// are cached under the latest block number. Responses at or below the finalized block are kept until evicted,
+// the ones at or below the safe block expire after the safe TTL and the other ones after the unsafe TTL.
+// eth_getLogs is only cached for ranges ending at or below the finalized block.
+type BlockCache struct {
+	safeTTL   time.Duration
+	unsafeTTL time.Duration
+	now       func() time.Time
+
+	// mtx serializes invalidations with puts
+	mtx sync.Mutex
+	lru *lru.Cache
+}
+
+type blockCacheEntry struct {
+	block  uint64
+	value  json.RawMessage
+	expiry time.Time
+}
+
+func NewBlockCache(size int, safeTTL time.Duration, unsafeTTL time.Duration) *BlockCache {
+	if size == 0 {
+		size = defaultBlockCacheSize
+	}
+	if safeTTL == 0 {
+		safeTTL = defaultSafeTTL
+	}
+	if unsafeTTL == 0 {
+		unsafeTTL = defaultUnsafeTTL
+	}
+	rep, _ := lru.New(size)
+	return &BlockCache{
+		safeTTL:   safeTTL,
+		unsafeTTL: unsafeTTL,
+		now:       time.Now,
+		lru:       rep,
+	}
+}
+
+func (c *BlockCache) key(req *RPCReq) string {
+	h := sha256.New()
+	h.Write(req.Params)
+	return strings.Join([]string{req.Method, fmt.Sprintf("%x", h.Sum(nil))}, ":")
+}
+
+// blockNumber returns the block number a request is cacheable at
+func (c *BlockCache) blockNumber(rctx RewriteContext, req *RPCReq) (uint64, bool) {
+	if req.Method == "eth_getLogs" {
+		var p []map[string]interface{}
+		if err := json.Unmarshal(req.Params, &p); err != nil || len(p) != 1 {
+			return 0, false
+		}
+		if _, ok := p[0]["blockHash"]; ok {
+			return 0, false
+		}
+		if _, ok := blockNumberParam(p[0], "fromBlock"); !ok {
+			return 0, false
+		}
+		to, ok := blockNumberParam(p[0], "toBlock")
+		if !ok || to > uint64(rctx.finalized) {
+			return 0, false
+		}
+		return to, true
+	}
+
+	pos, ok := blockParamPositions[req.Method]
+	if !ok {
+		return 0, false
+	}
+	var p []interface{}
+	if err := json.Unmarshal(req.Params, &p); err != nil || len(p) <= pos {
+		return 0, false
+	}
+	s, ok := p[pos].(string)
+	if !ok {
+		return 0, false
+	}
+	block, err := hexutil.DecodeUint64(s)
+	if err != nil {
+		return 0, false
+	}
+	return block, true
+}
+
+// Get returns the cached response of the request, or nil
+func (c *BlockCache) Get(rctx RewriteContext, req *RPCReq) *RPCRes {
+	block, ok := c.blockNumber(rctx, req)
+	if !ok {
+		return nil
+	}
+	val, ok := c.lru.Get(c.key(req))
+	if !ok {
+		RecordCacheMiss(req.Method)
+		return nil
+	}
+	entry := val.(*blockCacheEntry)
+	// entries above the latest block may be from a chain that has since been reorged
+	if entry.block != block || block > uint64(rctx.latest) ||
+		(!entry.expiry.IsZero() && !c.now().Before(entry.expiry)) {
+		RecordCacheMiss(req.Method)
+		return nil
+	}
+	RecordCacheHit(req.Method)
+	return &RPCRes{
+		JSONRPC: JSONRPCVersion,
+		Result:  entry.value,
+		ID:      req.ID,
+	}
+}
+
+// Put caches the response of the request, unless it is an error or empty
+func (c *BlockCache) Put(rctx RewriteContext, req *RPCReq, res *RPCRes) {
+	if res == nil || res.IsError() || res.Result == nil {
+		return
+	}
+	block, ok := c.blockNumber(rctx, req)
+	if !ok || block > uint64(rctx.latest) {
+		return
+	}
+	entry := &blockCacheEntry{
+		block: block,
+		value: mustMarshalJSON(res.Result),
+	}
+	if block > uint64(rctx.safe) {
+		entry.expiry = c.now().Add(c.unsafeTTL)
+	} else if block > uint64(rctx.finalized) {
+		entry.expiry = c.now().Add(c.safeTTL)
+	}
+
+	c.mtx.Lock()
+	c.lru.Add(c.key(req), entry)
+	c.mtx.Unlock()
+}
+
+// InvalidateAbove removes the entries above the block, and returns how many were removed
+func (c *BlockCache) InvalidateAbove(block uint64) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	removed := 0
+	for _, key := range c.lru.Keys() {
+		val, ok := c.lru.Peek(key)
+		if !ok {
+			continue
+		}
+		if val.(*blockCacheEntry).block > block {
+			c.lru.Remove(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// PutAll caches the responses of forwarded requests
+func (c *BlockCache) PutAll(rctx RewriteContext, reqs []*RPCReq, res []*RPCRes) {
+	for i, req := range reqs {
+		if i < len(res) && string(res[i].ID) == string(req.ID) {
+			c.Put(rctx, req, res[i])
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package proxyd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockCache(t *testing.T) {
+	rctx := RewriteContext{latest: 0x100, safe: 0xe0, finalized: 0xc0}
+	cache := NewBlockCache(0, time.Minute, time.Second)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	req := func(method string, params string) *RPCReq {
+		return &RPCReq{JSONRPC: JSONRPCVersion, Method: method, Params: json.RawMessage(params), ID: json.RawMessage("1")}
+	}
+	put := func(r *RPCReq) {
+		cache.Put(rctx, r, NewRPCRes(r.ID, "cached"))
+	}
+
+	finalized := req("eth_getBlockByNumber", `["0xc0",false]`)
+	safe := req("eth_call", `[{"to":"0x01"},"0xe0"]`)
+	unsafe := req("eth_getBalance", `["0x01","0x100"]`)
+	for _, r := range []*RPCReq{finalized, safe, unsafe} {
+		require.Nil(t, cache.Get(rctx, r))
+		put(r)
+		res := cache.Get(rctx, r)
+		require.NotNil(t, res)
+		require.Equal(t, r.ID, res.ID)
+		require.Equal(t, `"cached"`, string(mustMarshalJSON(res.Result)))
+	}
+
+	t.Run("uncacheable requests", func(t *testing.T) {
+		uncacheable := []*RPCReq{
+			req("eth_getBlockByNumber", `["pending",false]`),
+			req("eth_call", `[{"to":"0x01"},{"blockHash":"0x01"}]`),
+			req("eth_getBalance", `["0x01","0x101"]`),
+			req("eth_getLogs", `[{"fromBlock":"0x1","toBlock":"0xc1"}]`),
+			req("eth_getLogs", `[{"toBlock":"0xc0"}]`),
+			req("eth_getLogs", `[{"blockHash":"0x01","fromBlock":"0x1","toBlock":"0xc0"}]`),
+			req("eth_getTransactionReceipt", `["0x01"]`),
+		}
+		for _, r := range uncacheable {
+			put(r)
+			require.Nil(t, cache.Get(rctx, r), r.Method+" "+string(r.Params))
+		}
+
+		getLogs := req("eth_getLogs", `[{"fromBlock":"0x1","toBlock":"0xc0"}]`)
+		put(getLogs)
+		require.NotNil(t, cache.Get(rctx, getLogs))
+	})
+
+	t.Run("errors and empty results are not cached", func(t *testing.T) {
+		r := req("eth_getBlockByNumber", `["0xc1",false]`)
+		cache.Put(rctx, r, NewRPCErrorRes(r.ID, ErrInternal))
+		require.Nil(t, cache.Get(rctx, r))
+		cache.Put(rctx, r, NewRPCRes(r.ID, nil))
+		require.Nil(t, cache.Get(rctx, r))
+	})
+
+	t.Run("entries expire above the finalized block", func(t *testing.T) {
+		now = now.Add(time.Second)
+		require.Nil(t, cache.Get(rctx, unsafe))
+		require.NotNil(t, cache.Get(rctx, safe))
+
+		now = now.Add(time.Minute)
+		require.Nil(t, cache.Get(rctx, safe))
+		require.NotNil(t, cache.Get(rctx, finalized))
+	})
+
+	t.Run("entries above the latest block are not served", func(t *testing.T) {
+		put(unsafe)
+		reorged := rctx
+		reorged.latest = 0xff
+		require.Nil(t, cache.Get(reorged, unsafe))
+	})
+
+	t.Run("invalidation", func(t *testing.T) {
+		put(safe)
+		put(unsafe)
+		require.Equal(t, 2, cache.InvalidateAbove(0xc0))
+		require.Nil(t, cache.Get(rctx, safe))
+		require.Nil(t, cache.Get(rctx, unsafe))
+		require.NotNil(t, cache.Get(rctx, finalized))
+	})
+}
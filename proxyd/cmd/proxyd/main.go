@@ -17,6 +17,17 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		// keep stdout for the diff of the responses
+		log.Root().SetHandler(
+			log.LvlFilterHandler(
+				log.LvlWarn,
+				log.StreamHandler(os.Stderr, log.JSONFormat()),
+			),
+		)
+		os.Exit(runReplay(os.Args[2:]))
+	}
+
 	// Set up logger with a default INFO level in case we fail to parse flags.
 	// Otherwise the final critical log won't show what the parsing error was.
 	log.Root().SetHandler(
@@ -32,8 +43,8 @@ func main() {
 		log.Crit("must specify a config file on the command line")
 	}
 
-	config := new(proxyd.Config)
-	if _, err := toml.DecodeFile(os.Args[1], config); err != nil {
+	config, err := readConfig(os.Args[1])
+	if err != nil {
 		log.Crit("error reading config file", "err", err)
 	}
 
@@ -52,14 +63,39 @@ func main() {
 		),
 	)
 
-	_, shutdown, err := proxyd.Start(config)
+	srv, shutdown, err := proxyd.Start(config)
 	if err != nil {
 		log.Crit("error starting proxyd", "err", err)
 	}
 
+	// SIGHUP reloads the config file without dropping connections
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			log.Info("caught SIGHUP, reloading config", "path", os.Args[1])
+			config, err := readConfig(os.Args[1])
+			if err != nil {
+				log.Error("error reading config file, keeping current config", "err", err)
+				continue
+			}
+			if err := srv.Reload(config); err != nil {
+				log.Error("error reloading config, keeping current config", "err", err)
+			}
+		}
+	}()
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 	recvSig := <-sig
 	log.Info("caught signal, shutting down", "signal", recvSig)
 	shutdown()
 }
+
+func readConfig(path string) (*proxyd.Config, error) {
+	config := new(proxyd.Config)
+	if _, err := toml.DecodeFile(path, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/ethereum-optimism/optimism/proxyd/tools/mockserver/handler"
+)
+
+const replayUsage = `usage: proxyd replay [-config <config.toml> | -mock <responses.yml> | -url <url>] [-auth <key>] [-v] <recording>...
+
+Replays recorded requests in order and diffs the responses with the recorded ones.
+Recordings are files or directories of files written by the recorder.
+Against a config with authentication, requests are sent to the path of the key of their recorded alias.
+
+`
+
+// runReplay implements the replay command, and returns the exit code
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), replayUsage)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "start proxyd with this config and replay against it")
+	mockPath := fs.String("mock", "", "replay against a mockserver serving these mocked responses")
+	url := fs.String("url", "", "replay against this URL")
+	auth := fs.String("auth", "", "send all the requests to the authenticated path of this key")
+	verbose := fs.Bool("v", false, "print the matching responses too")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	targets := 0
+	for _, target := range []string{*configPath, *mockPath, *url} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets != 1 || fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	recs, err := readRecordings(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading recordings: %v\n", err)
+		return 1
+	}
+
+	target := *url
+	authKeys := make(map[string]string)
+	switch {
+	case *configPath != "":
+		config, err := readConfig(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading config file: %v\n", err)
+			return 1
+		}
+		if config.Server.RPCPort == 0 {
+			fmt.Fprintln(os.Stderr, "config must define an rpc_port")
+			return 1
+		}
+		// don't record the replayed requests
+		config.Recorder.Enabled = false
+		for key, alias := range config.Authentication {
+			authKeys[alias] = key
+		}
+		_, shutdown, err := proxyd.Start(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error starting proxyd: %v\n", err)
+			return 1
+		}
+		defer shutdown()
+		host := config.Server.RPCHost
+		if host == "" || host == "0.0.0.0" {
+			host = "127.0.0.1"
+		}
+		target = fmt.Sprintf("http://%s:%d", host, config.Server.RPCPort)
+	case *mockPath != "":
+		mock := &handler.MockedHandler{
+			Autoload:     true,
+			AutoloadFile: *mockPath,
+		}
+		server := httptest.NewServer(http.HandlerFunc(mock.Handler))
+		defer server.Close()
+		target = server.URL
+	}
+	if *auth != "" {
+		for _, rec := range recs {
+			authKeys[rec.Auth] = *auth
+		}
+	}
+
+	summary, err := proxyd.Replay(context.Background(), http.DefaultClient, target, authKeys, recs, func(res *proxyd.ReplayResult) {
+		if res.Match && !*verbose {
+			return
+		}
+		status := "MATCH"
+		if !res.Match {
+			status = "MISMATCH"
+		}
+		fmt.Printf("%s req_id=%s auth=%s\n", status, res.Recording.ReqID, res.Recording.Auth)
+		fmt.Printf("  request:  %s\n", res.Recording.Request)
+		fmt.Printf("  recorded: %d %s\n", res.Recording.Status, res.Recording.Response)
+		fmt.Printf("  replayed: %d %s\n", res.Status, res.Response)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error replaying: %v\n", err)
+		return 1
+	}
+	fmt.Printf("replayed=%d matched=%d mismatched=%d skipped=%d\n",
+		summary.Replayed, summary.Matched, summary.Mismatched, summary.Skipped)
+	if summary.Mismatched > 0 {
+		return 1
+	}
+	return 0
+}
+
+func readRecordings(paths []string) ([]*proxyd.Recording, error) {
+	recs := make([]*proxyd.Recording, 0)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		files := []string{path}
+		if info.IsDir() {
+			if files, err = proxyd.RecordingFiles(path); err != nil {
+				return nil, err
+			}
+			if len(files) == 0 {
+				return nil, errors.New("no recordings in " + filepath.Clean(path))
+			}
+		}
+		for _, file := range files {
+			fileRecs, err := proxyd.ReadRecordings(file)
+			if err != nil {
+				return nil, err
+			}
+			recs = append(recs, fileRecs...)
+		}
+	}
+	return recs, nil
+}
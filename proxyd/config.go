@@ -24,10 +24,23 @@ type ServerConfig struct {
 
 	EnableRequestLog     bool `toml:"enable_request_log"`
 	MaxRequestBodyLogLen int  `toml:"max_request_body_log_len"`
+
+	// MaxWSSubscriptions and MaxWSSubscriptionsPerClient limit the managed WS subscriptions
+	// in total and per client connection. Zero means unlimited.
+	MaxWSSubscriptions          int `toml:"max_ws_subscriptions"`
+	MaxWSSubscriptionsPerClient int `toml:"max_ws_subscriptions_per_client"`
 }
 
 type CacheConfig struct {
 	Enabled bool `toml:"enabled"`
+	// BlockAware caches the requests at a block number of consensus aware backend groups, see BlockCache.
+	BlockAware bool `toml:"block_aware"`
+	// BlockCacheSize is the maximum number of responses in the block cache of each backend group.
+	BlockCacheSize int `toml:"block_cache_size"`
+	// SafeTTL is how long responses above the finalized block and at or below the safe block are cached.
+	SafeTTL TOMLDuration `toml:"safe_ttl"`
+	// UnsafeTTL is how long responses above the safe block are cached.
+	UnsafeTTL TOMLDuration `toml:"unsafe_ttl"`
 }
 
 type RedisConfig struct {
@@ -57,6 +70,44 @@ type RateLimitMethodOverride struct {
 	Global   bool         `toml:"global"`
 }
 
+type RecorderConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Path of the directory of the recordings.
+	Path string `toml:"path"`
+	// SampleRate is the fraction of the requests that are recorded. Defaults to 1.
+	SampleRate float64 `toml:"sample_rate"`
+	// MaxFileSizeBytes is the size after which a new file is started. Defaults to 100MB.
+	MaxFileSizeBytes int64 `toml:"max_file_size_bytes"`
+	// MaxFiles is the number of files kept, the oldest ones are removed. Defaults to 10.
+	MaxFiles int `toml:"max_files"`
+	// RedactMethods are the methods whose params and results are not recorded.
+	RedactMethods []string `toml:"redact_methods"`
+}
+
+type QuotasConfig struct {
+	Enabled bool `toml:"enabled"`
+	// UseRedis stores the usage counters in Redis, to share them between the instances of proxyd.
+	UseRedis bool `toml:"use_redis"`
+	// AdminKey authorizes the admin RPC on the /admin path, which is disabled if empty.
+	AdminKey string `toml:"admin_key"`
+	// DefaultTier applies to the clients without a tier in Keys. Clients are unlimited if empty.
+	DefaultTier string `toml:"default_tier"`
+	// Tiers of quotas by name.
+	Tiers map[string]*QuotaTierConfig `toml:"tiers"`
+	// Keys maps the aliases of the authentication keys to their tier.
+	Keys map[string]string `toml:"keys"`
+	// MethodWeights is the number of compute units of each method. Defaults to 1.
+	MethodWeights map[string]int `toml:"method_weights"`
+}
+
+// QuotaTierConfig holds the limits of a tier, a limit of 0 is unlimited.
+// RPS counts an HTTP batch as a single request. Daily budgets reset at midnight UTC.
+type QuotaTierConfig struct {
+	RPS               int   `toml:"rps" json:"rps"`
+	DailyRequests     int64 `toml:"daily_requests" json:"dailyRequests"`
+	DailyComputeUnits int64 `toml:"daily_compute_units" json:"dailyComputeUnits"`
+}
+
 type TOMLDuration time.Duration
 
 func (t *TOMLDuration) UnmarshalText(b []byte) error {
@@ -108,6 +159,28 @@ type BackendGroupConfig struct {
 	ConsensusMaxUpdateThreshold TOMLDuration `toml:"consensus_max_update_threshold"`
 	ConsensusMaxBlockLag        uint64       `toml:"consensus_max_block_lag"`
 	ConsensusMinPeerCount       int          `toml:"consensus_min_peer_count"`
+
+	// GetLogsMaxBlockRange splits eth_getLogs requests spanning more blocks into chunks of at most this many blocks,
+	// forwarded in parallel across the backends of the group. Disabled if 0.
+	GetLogsMaxBlockRange uint64 `toml:"get_logs_max_block_range"`
+	// GetLogsMaxChunks rejects eth_getLogs requests that would be split into more chunks. Defaults to 100 if 0.
+	GetLogsMaxChunks int `toml:"get_logs_max_chunks"`
+
+	// RoutingStrategy selects how requests are spread across the backends, see RoutingStrategy.
+	RoutingStrategy RoutingStrategy `toml:"routing_strategy"`
+	// Weights of the backends for the weighted_round_robin strategy, by backend name. Defaults to 1.
+	Weights map[string]int `toml:"weights"`
+	// HedgePercentile is the latency percentile of recent requests after which the hedged strategy
+	// sends the request to another backend.
+	HedgePercentile float64 `toml:"hedge_percentile"`
+	// HedgeDelay is used instead of the latency percentile until enough requests have been observed.
+	HedgeDelay TOMLDuration `toml:"hedge_delay"`
+
+	// StickyRouting routes the reads depending on a submitted transaction to the backend that received it,
+	// see StickyMode. Disabled if empty.
+	StickyRouting StickyMode `toml:"sticky_routing"`
+	// StickyTTL is how long reads stick to the backend after a transaction is submitted. Defaults to 1m.
+	StickyTTL TOMLDuration `toml:"sticky_ttl"`
 }
 
 type BackendGroupsConfig map[string]*BackendGroupConfig
@@ -144,6 +217,8 @@ type Config struct {
 	WSMethodWhitelist     []string              `toml:"ws_method_whitelist"`
 	WhitelistErrorMessage string                `toml:"whitelist_error_message"`
 	SenderRateLimit       SenderRateLimitConfig `toml:"sender_rate_limit"`
+	Quotas                QuotasConfig          `toml:"quotas"`
+	Recorder              RecorderConfig        `toml:"recorder"`
 }
 
 func ReadFromEnvOrConfig(value string) (string, error) {
@@ -17,7 +17,8 @@ const (
 	PollerInterval = 1 * time.Second
 )
 
-type OnConsensusBroken func()
+// OnConsensusBroken is called with the highest block the backends agree on when the consensus is broken
+type OnConsensusBroken func(commonAncestor hexutil.Uint64)
 
 // ConsensusPoller checks the consensus state for each member of a BackendGroup
 // resolves the highest common block for multiple nodes, and reconciles the consensus
@@ -426,7 +427,7 @@ func (cp *ConsensusPoller) UpdateBackendGroupConsensus(ctx context.Context) {
 	if broken {
 		// propagate event to other interested parts, such as cache invalidator
 		for _, l := range cp.listeners {
-			l()
+			l(proposedBlock)
 		}
 		log.Info("consensus broken",
 			"currentConsensusBlockNumber", currentConsensusBlockNumber,
@@ -0,0 +1,215 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var ErrGetLogsRangeTooLarge = &RPCErr{
+	Code:          JSONRPCErrorInternal - 20,
+	Message:       "block range is too large",
+	HTTPErrorCode: 400,
+}
+
+// getLogsChunk is a sub-range of an eth_getLogs request, with both bounds inclusive
+type getLogsChunk struct {
+	from uint64
+	to   uint64
+}
+
+// defaultGetLogsMaxChunks caps the number of chunks an eth_getLogs request is split into when no limit is configured
+const defaultGetLogsMaxChunks = 100
+
+// splitGetLogsRange returns the filter of an eth_getLogs request and its range split into chunks of at most maxRange
+// blocks. Only requests with explicit numeric bounds are split, so chunks is nil for requests that fit in a single
+// chunk, that use block tags or that filter by block hash. Requests that would be split into more than maxChunks
+// chunks, or defaultGetLogsMaxChunks if 0, are rejected with ErrGetLogsRangeTooLarge before any chunk is allocated.
+func splitGetLogsRange(req *RPCReq, maxRange uint64, maxChunks int) (filter map[string]interface{}, chunks []getLogsChunk, err error) {
+	if req.Method != "eth_getLogs" || maxRange == 0 {
+		return nil, nil, nil
+	}
+	var p []map[string]interface{}
+	if err := json.Unmarshal(req.Params, &p); err != nil || len(p) != 1 {
+		return nil, nil, nil
+	}
+	filter = p[0]
+	if _, ok := filter["blockHash"]; ok {
+		return nil, nil, nil
+	}
+	from, ok := blockNumberParam(filter, "fromBlock")
+	if !ok {
+		return nil, nil, nil
+	}
+	to, ok := blockNumberParam(filter, "toBlock")
+	if !ok || to < from || to-from < maxRange {
+		return nil, nil, nil
+	}
+	if maxChunks <= 0 {
+		maxChunks = defaultGetLogsMaxChunks
+	}
+	// to-from >= maxRange, so there are at least two chunks and the count can't overflow
+	count := (to-from)/maxRange + 1
+	if count > uint64(maxChunks) {
+		return nil, nil, ErrGetLogsRangeTooLarge
+	}
+	chunks = make([]getLogsChunk, count)
+	for i := range chunks {
+		start := from + uint64(i)*maxRange
+		end := to
+		if to-start >= maxRange {
+			end = start + maxRange - 1
+		}
+		chunks[i] = getLogsChunk{from: start, to: end}
+	}
+	return filter, chunks, nil
+}
+
+func blockNumberParam(m map[string]interface{}, key string) (uint64, bool) {
+	s, ok := m[key].(string)
+	if !ok {
+		return 0, false
+	}
+	n, err := hexutil.DecodeUint64(s)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// forwardGetLogsChunks forwards each chunk of an eth_getLogs request as a separate request, spreading the chunks
+// across the backends in parallel, and merges the resulting logs in block order.
+// Any error, either forwarding a chunk or returned by a backend, is returned as the response to the whole request.
+func (bg *BackendGroup) forwardGetLogsChunks(ctx context.Context, backends []*Backend, req *RPCReq, filter map[string]interface{}, chunks []getLogsChunk) *RPCRes {
+	healthy := make([]*Backend, 0, len(backends))
+	for _, back := range backends {
+		if back.IsHealthy() {
+			healthy = append(healthy, back)
+		}
+	}
+	// try all backends if none are healthy, as the regular forwarding does for groups that aren't consensus aware
+	if len(healthy) > 0 {
+		backends = healthy
+	}
+	if len(backends) == 0 {
+		return NewRPCErrorRes(req.ID, ErrNoBackends)
+	}
+	RecordGetLogsSplit(bg, len(chunks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]interface{}, len(chunks))
+	next := make(chan int, len(chunks))
+	for i := range chunks {
+		next <- i
+	}
+	close(next)
+
+	// the first error stops forwarding the remaining chunks, later errors are only a result of the cancellation
+	var errMu sync.Mutex
+	var firstErr error
+
+	workers := len(backends)
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() != nil {
+					return
+				}
+				logs, err := bg.forwardGetLogsChunk(ctx, backends, i, req, filter, chunks[i])
+				if err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+						log.Warn(
+							"error forwarding eth_getLogs chunk",
+							"group", bg.Name,
+							"from", hexutil.Uint64(chunks[i].from),
+							"to", hexutil.Uint64(chunks[i].to),
+							"req_id", GetReqID(ctx),
+							"err", err,
+						)
+					}
+					errMu.Unlock()
+					cancel()
+					return
+				}
+				results[i] = logs
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		// the request may have been cancelled before all chunks were forwarded
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return NewRPCErrorRes(req.ID, firstErr)
+	}
+	logs := make([]interface{}, 0)
+	for _, chunkLogs := range results {
+		logs = append(logs, chunkLogs...)
+	}
+	return NewRPCRes(req.ID, logs)
+}
+
+// forwardGetLogsChunk forwards a single chunk, starting with a different backend for each chunk
+// and failing over to the remaining backends.
+func (bg *BackendGroup) forwardGetLogsChunk(ctx context.Context, backends []*Backend, index int, req *RPCReq, filter map[string]interface{}, chunk getLogsChunk) ([]interface{}, error) {
+	chunkFilter := make(map[string]interface{}, len(filter))
+	for k, v := range filter {
+		chunkFilter[k] = v
+	}
+	chunkFilter["fromBlock"] = hexutil.Uint64(chunk.from).String()
+	chunkFilter["toBlock"] = hexutil.Uint64(chunk.to).String()
+	chunkReq := &RPCReq{
+		JSONRPC: req.JSONRPC,
+		Method:  req.Method,
+		Params:  mustMarshalJSON([]interface{}{chunkFilter}),
+		ID:      req.ID,
+	}
+
+	for i := range backends {
+		back := backends[(index+i)%len(backends)]
+		res, err := back.Forward(ctx, []*RPCReq{chunkReq}, false)
+		if errors.Is(err, ErrMethodNotWhitelisted) {
+			return nil, err
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Warn(
+				"error forwarding eth_getLogs chunk to backend",
+				"name", back.Name,
+				"req_id", GetReqID(ctx),
+				"err", err,
+			)
+			continue
+		}
+		if res[0].IsError() {
+			return nil, res[0].Error
+		}
+		if res[0].Result == nil {
+			return nil, nil
+		}
+		logs, ok := res[0].Result.([]interface{})
+		if !ok {
+			return nil, ErrBackendBadResponse
+		}
+		return logs, nil
+	}
+	return nil, ErrNoBackends
+}
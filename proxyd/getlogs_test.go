@@ -0,0 +1,131 @@
+package proxyd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitGetLogsRange(t *testing.T) {
+	getLogs := func(filter map[string]interface{}) *RPCReq {
+		return &RPCReq{Method: "eth_getLogs", Params: mustMarshalJSON([]map[string]interface{}{filter})}
+	}
+	hex := func(n uint64) string {
+		return hexutil.Uint64(n).String()
+	}
+
+	tests := []struct {
+		name      string
+		req       *RPCReq
+		maxRange  uint64
+		maxChunks int
+		expected  []getLogsChunk
+		err       error
+	}{
+		{
+			name:     "split into full chunks",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": hex(129)}),
+			maxRange: 10,
+			expected: []getLogsChunk{{100, 109}, {110, 119}, {120, 129}},
+		},
+		{
+			name:     "split with partial last chunk",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": hex(121)}),
+			maxRange: 10,
+			expected: []getLogsChunk{{100, 109}, {110, 119}, {120, 121}},
+		},
+		{
+			name:     "split into single blocks",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(0), "toBlock": hex(2)}),
+			maxRange: 1,
+			expected: []getLogsChunk{{0, 0}, {1, 1}, {2, 2}},
+		},
+		{
+			name:      "split up to the chunk limit",
+			req:       getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": hex(129)}),
+			maxRange:  10,
+			maxChunks: 3,
+			expected:  []getLogsChunk{{100, 109}, {110, 119}, {120, 129}},
+		},
+		{
+			name:      "too many chunks",
+			req:       getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": hex(130)}),
+			maxRange:  10,
+			maxChunks: 3,
+			err:       ErrGetLogsRangeTooLarge,
+		},
+		{
+			name:     "too many chunks with the default limit",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(0), "toBlock": hex(defaultGetLogsMaxChunks)}),
+			maxRange: 1,
+			err:      ErrGetLogsRangeTooLarge,
+		},
+		{
+			name:      "maximum block range",
+			req:       getLogs(map[string]interface{}{"fromBlock": hex(0), "toBlock": hex(math.MaxUint64)}),
+			maxRange:  10,
+			maxChunks: 1000,
+			err:       ErrGetLogsRangeTooLarge,
+		},
+		{
+			name:      "chunks up to the maximum block",
+			req:       getLogs(map[string]interface{}{"fromBlock": hex(math.MaxUint64 - 14), "toBlock": hex(math.MaxUint64)}),
+			maxRange:  10,
+			maxChunks: 2,
+			expected:  []getLogsChunk{{math.MaxUint64 - 14, math.MaxUint64 - 5}, {math.MaxUint64 - 4, math.MaxUint64}},
+		},
+		{
+			name:     "range fits in one chunk",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": hex(109)}),
+			maxRange: 10,
+		},
+		{
+			name:     "disabled",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": hex(200)}),
+			maxRange: 0,
+		},
+		{
+			name:     "block tag",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": "latest"}),
+			maxRange: 10,
+		},
+		{
+			name:     "missing fromBlock",
+			req:      getLogs(map[string]interface{}{"toBlock": hex(200)}),
+			maxRange: 10,
+		},
+		{
+			name:     "block hash",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(100), "toBlock": hex(200), "blockHash": "0x1234"}),
+			maxRange: 10,
+		},
+		{
+			name:     "inverted range",
+			req:      getLogs(map[string]interface{}{"fromBlock": hex(200), "toBlock": hex(100)}),
+			maxRange: 10,
+		},
+		{
+			name:     "other method",
+			req:      &RPCReq{Method: "eth_newFilter", Params: mustMarshalJSON([]map[string]interface{}{{"fromBlock": hex(100), "toBlock": hex(200)}})},
+			maxRange: 10,
+		},
+		{
+			name:     "invalid params",
+			req:      &RPCReq{Method: "eth_getLogs", Params: []byte(`"invalid"`)},
+			maxRange: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, chunks, err := splitGetLogsRange(tt.req, tt.maxRange, tt.maxChunks)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.expected, chunks)
+			if tt.expected == nil {
+				return
+			}
+			require.NotNil(t, filter)
+		})
+	}
+}
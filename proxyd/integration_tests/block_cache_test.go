@@ -0,0 +1,87 @@
+package integration_tests
+
+import (
+	"context"
+	"testing"
+
+	ms "github.com/ethereum-optimism/optimism/proxyd/tools/mockserver/handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockCache(t *testing.T) {
+	nodes, bg, client, shutdown := setupWithConfig(t, "block_cache")
+	defer nodes["node1"].mockBackend.Close()
+	defer nodes["node2"].mockBackend.Close()
+	defer shutdown()
+
+	ctx := context.Background()
+	update := func() {
+		for _, be := range bg.Backends {
+			bg.Consensus.UpdateBackend(ctx, be)
+		}
+		bg.Consensus.UpdateBackendGroupConsensus(ctx)
+		// don't count the requests of the consensus poller
+		for _, node := range nodes {
+			node.mockBackend.Reset()
+		}
+	}
+	forwarded := func() int {
+		return len(nodes["node1"].mockBackend.Requests()) + len(nodes["node2"].mockBackend.Requests())
+	}
+	getBlock := func(block string) {
+		_, code, err := client.SendRPC("eth_getBlockByNumber", []interface{}{block, false})
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+	}
+
+	update()
+	require.Equal(t, "0x101", bg.Consensus.GetLatestBlockNumber().String())
+	require.Equal(t, "0xc1", bg.Consensus.GetFinalizedBlockNumber().String())
+
+	t.Run("finalized and latest blocks are cached", func(t *testing.T) {
+		getBlock("finalized")
+		getBlock("0xc1")
+		getBlock("latest")
+		getBlock("0x101")
+		require.Equal(t, 2, forwarded())
+	})
+
+	t.Run("entries above the common ancestor are invalidated when consensus is broken", func(t *testing.T) {
+		for _, node := range []string{"node1", "node2"} {
+			nodes[node].handler.AddOverride(&ms.MethodTemplate{
+				Method:   "eth_getBlockByNumber",
+				Block:    "latest",
+				Response: buildResponse(map[string]string{"number": "0x102", "hash": "hash_0x102"}),
+			})
+		}
+		update()
+		require.Equal(t, "0x102", bg.Consensus.GetLatestBlockNumber().String())
+		getBlock("0x102")
+		require.Equal(t, 1, forwarded())
+		nodes["node1"].mockBackend.Reset()
+		nodes["node2"].mockBackend.Reset()
+
+		nodes["node2"].handler.AddOverride(&ms.MethodTemplate{
+			Method:   "eth_getBlockByNumber",
+			Block:    "0x102",
+			Response: buildResponse(map[string]string{"number": "0x102", "hash": "wrong_hash"}),
+		})
+		update()
+		require.Equal(t, "0x101", bg.Consensus.GetLatestBlockNumber().String())
+
+		getBlock("0xc1")
+		getBlock("0x101")
+		require.Equal(t, 0, forwarded(), "should keep the entries at or below the common ancestor")
+
+		nodes["node2"].handler.ResetOverrides()
+		nodes["node2"].handler.AddOverride(&ms.MethodTemplate{
+			Method:   "eth_getBlockByNumber",
+			Block:    "latest",
+			Response: buildResponse(map[string]string{"number": "0x102", "hash": "hash_0x102"}),
+		})
+		update()
+		require.Equal(t, "0x102", bg.Consensus.GetLatestBlockNumber().String())
+		getBlock("0x102")
+		require.Equal(t, 1, forwarded(), "should drop the entries above the common ancestor")
+	})
+}
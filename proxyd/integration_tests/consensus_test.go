@@ -24,6 +24,10 @@ type nodeContext struct {
 }
 
 func setup(t *testing.T) (map[string]nodeContext, *proxyd.BackendGroup, *ProxydHTTPClient, func()) {
+	return setupWithConfig(t, "consensus")
+}
+
+func setupWithConfig(t *testing.T, configName string) (map[string]nodeContext, *proxyd.BackendGroup, *ProxydHTTPClient, func()) {
 	// setup mock servers
 	node1 := NewMockBackend(nil)
 	node2 := NewMockBackend(nil)
@@ -51,7 +55,7 @@ func setup(t *testing.T) (map[string]nodeContext, *proxyd.BackendGroup, *ProxydH
 	node2.SetHandler(http.HandlerFunc(h2.Handler))
 
 	// setup proxyd
-	config := ReadConfig("consensus")
+	config := ReadConfig(configName)
 	svr, shutdown, err := proxyd.Start(config)
 	require.NoError(t, err)
 
@@ -59,7 +63,7 @@ func setup(t *testing.T) (map[string]nodeContext, *proxyd.BackendGroup, *ProxydH
 	client := NewProxydClient("http://127.0.0.1:8545")
 
 	// expose the backend group
-	bg := svr.BackendGroups["node"]
+	bg := svr.BackendGroups()["node"]
 	require.NotNil(t, bg)
 	require.NotNil(t, bg.Consensus)
 	require.Equal(t, 2, len(bg.Backends)) // should match config
@@ -454,8 +458,10 @@ func TestConsensus(t *testing.T) {
 	t.Run("broken consensus", func(t *testing.T) {
 		reset()
 		listenerCalled := false
-		bg.Consensus.AddListener(func() {
+		var commonAncestor hexutil.Uint64
+		bg.Consensus.AddListener(func(ancestor hexutil.Uint64) {
 			listenerCalled = true
+			commonAncestor = ancestor
 		})
 		update()
 
@@ -485,15 +491,18 @@ func TestConsensus(t *testing.T) {
 		require.False(t, bg.Consensus.IsBanned(nodes["node1"].backend))
 		require.False(t, bg.Consensus.IsBanned(nodes["node2"].backend))
 
-		// onConsensusBroken listener was called
+		// onConsensusBroken listener was called with the common ancestor
 		require.True(t, listenerCalled)
+		require.Equal(t, "0x101", commonAncestor.String())
 	})
 
 	t.Run("broken consensus with depth 2", func(t *testing.T) {
 		reset()
 		listenerCalled := false
-		bg.Consensus.AddListener(func() {
+		var commonAncestor hexutil.Uint64
+		bg.Consensus.AddListener(func(ancestor hexutil.Uint64) {
 			listenerCalled = true
+			commonAncestor = ancestor
 		})
 		update()
 
@@ -533,14 +542,15 @@ func TestConsensus(t *testing.T) {
 		require.False(t, bg.Consensus.IsBanned(nodes["node1"].backend))
 		require.False(t, bg.Consensus.IsBanned(nodes["node2"].backend))
 
-		// onConsensusBroken listener was called
+		// onConsensusBroken listener was called with the common ancestor
 		require.True(t, listenerCalled)
+		require.Equal(t, "0x101", commonAncestor.String())
 	})
 
 	t.Run("fork in advanced block", func(t *testing.T) {
 		reset()
 		listenerCalled := false
-		bg.Consensus.AddListener(func() {
+		bg.Consensus.AddListener(func(hexutil.Uint64) {
 			listenerCalled = true
 		})
 		update()
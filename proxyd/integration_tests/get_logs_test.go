@@ -0,0 +1,129 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// getLogsHandler responds to eth_getLogs with a log for the first and last block of the requested range.
+// Requests for a range including failAt are responded to with an error.
+func getLogsHandler(failAt uint64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			panic(err)
+		}
+		req, err := proxyd.ParseRPCReq(body)
+		if err != nil {
+			panic(err)
+		}
+		var params []struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			panic(err)
+		}
+		from, to := params[0].FromBlock, params[0].ToBlock
+
+		res := &proxyd.RPCRes{JSONRPC: proxyd.JSONRPCVersion, ID: req.ID}
+		if uint64(from) <= failAt && failAt <= uint64(to) {
+			res.Error = &proxyd.RPCErr{Code: -32005, Message: "query returned more than 10000 results"}
+		} else {
+			res.Result = []map[string]hexutil.Uint64{{"blockNumber": from}, {"blockNumber": to}}
+		}
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func TestGetLogsRangeSplitting(t *testing.T) {
+	node1 := NewMockBackend(getLogsHandler(1000))
+	defer node1.Close()
+	node2 := NewMockBackend(getLogsHandler(1000))
+	defer node2.Close()
+
+	require.NoError(t, os.Setenv("NODE1_URL", node1.URL()))
+	require.NoError(t, os.Setenv("NODE2_URL", node2.URL()))
+
+	config := ReadConfig("get_logs")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	getLogs := func(from, to uint64) []interface{} {
+		return []interface{}{map[string]interface{}{
+			"fromBlock": hexutil.Uint64(from).String(),
+			"toBlock":   hexutil.Uint64(to).String(),
+			"address":   "0x0000000000000000000000000000000000000001",
+		}}
+	}
+
+	t.Run("split and merge in order", func(t *testing.T) {
+		node1.Reset()
+		node2.Reset()
+		res, code, err := client.SendRPC("eth_getLogs", getLogs(100, 134))
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":999,"result":[
+			{"blockNumber":"0x64"},{"blockNumber":"0x6d"},
+			{"blockNumber":"0x6e"},{"blockNumber":"0x77"},
+			{"blockNumber":"0x78"},{"blockNumber":"0x81"},
+			{"blockNumber":"0x82"},{"blockNumber":"0x86"}
+		]}`), res)
+		require.Equal(t, 4, len(node1.Requests())+len(node2.Requests()))
+		require.NotEmpty(t, node1.Requests(), "should fan out across backends")
+		require.NotEmpty(t, node2.Requests(), "should fan out across backends")
+		for _, req := range append(node1.Requests(), node2.Requests()...) {
+			require.Contains(t, string(req.Body), "0x0000000000000000000000000000000000000001", "should keep the filter")
+		}
+	})
+
+	t.Run("small range is not split", func(t *testing.T) {
+		node1.Reset()
+		node2.Reset()
+		res, code, err := client.SendRPC("eth_getLogs", getLogs(100, 109))
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":999,"result":[{"blockNumber":"0x64"},{"blockNumber":"0x6d"}]}`), res)
+		require.Equal(t, 1, len(node1.Requests())+len(node2.Requests()))
+	})
+
+	t.Run("chunk error is reported as single error", func(t *testing.T) {
+		res, code, err := client.SendRPC("eth_getLogs", getLogs(980, 1019))
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":999,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`), res)
+	})
+
+	t.Run("too many chunks", func(t *testing.T) {
+		node1.Reset()
+		node2.Reset()
+		res, _, err := client.SendRPC("eth_getLogs", getLogs(0, 100))
+		require.NoError(t, err)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":999,"error":{"code":-32020,"message":"block range is too large"}}`), res)
+		require.Empty(t, node1.Requests())
+		require.Empty(t, node2.Requests())
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		res, code, err := client.SendBatchRPC(
+			NewRPCReq("1", "eth_getLogs", getLogs(100, 119)),
+			NewRPCReq("2", "eth_getLogs", getLogs(5, 5)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`[
+			{"jsonrpc":"2.0","id":1,"result":[{"blockNumber":"0x64"},{"blockNumber":"0x6d"},{"blockNumber":"0x6e"},{"blockNumber":"0x77"}]},
+			{"jsonrpc":"2.0","id":2,"result":[{"blockNumber":"0x5"},{"blockNumber":"0x5"}]}
+		]`), res)
+	})
+}
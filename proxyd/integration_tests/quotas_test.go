@@ -0,0 +1,101 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotas(t *testing.T) {
+	goodBackend := NewMockBackend(BatchedResponseHandler(200, goodResponse))
+	defer goodBackend.Close()
+
+	require.NoError(t, os.Setenv("GOOD_BACKEND_RPC_URL", goodBackend.URL()))
+
+	config := ReadConfig("quotas")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	partner := NewProxydClient("http://127.0.0.1:8545/partner_secret")
+	free := NewProxydClient("http://127.0.0.1:8545/free_secret")
+	admin := NewProxydClientWithHeaders("http://127.0.0.1:8545/admin", http.Header{
+		"Authorization": []string{"Bearer admin_secret"},
+	})
+
+	requireOverQuota := func(t *testing.T, res []byte, code int, msg string) {
+		require.Equal(t, 429, code)
+		var rpcRes proxyd.RPCRes
+		require.NoError(t, json.Unmarshal(res, &rpcRes))
+		require.NotNil(t, rpcRes.Error)
+		require.Equal(t, msg, rpcRes.Error.Message)
+	}
+
+	t.Run("daily requests", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, code, err := free.SendRPC("eth_call", nil)
+			require.NoError(t, err)
+			require.Equal(t, 200, code)
+		}
+		res, code, err := free.SendRPC("eth_chainId", nil)
+		require.NoError(t, err)
+		requireOverQuota(t, res, code, "over daily_requests quota of 2")
+	})
+
+	t.Run("daily compute units", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, code, err := partner.SendRPC("eth_call", nil)
+			require.NoError(t, err)
+			require.Equal(t, 200, code)
+		}
+		res, code, err := partner.SendRPC("eth_call", nil)
+		require.NoError(t, err)
+		requireOverQuota(t, res, code, "over daily_compute_units quota of 11")
+
+		// calls in batches are accounted one by one
+		res, code, err = partner.SendBatchRPC(
+			NewRPCReq("1", "eth_chainId", nil),
+			NewRPCReq("2", "eth_call", nil),
+		)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		var batchRes []proxyd.RPCRes
+		require.NoError(t, json.Unmarshal(res, &batchRes))
+		require.Len(t, batchRes, 2)
+		require.Nil(t, batchRes[0].Error)
+		require.NotNil(t, batchRes[1].Error)
+		require.Equal(t, "over daily_compute_units quota of 11", batchRes[1].Error.Message)
+	})
+
+	t.Run("admin rpc", func(t *testing.T) {
+		res, code, err := admin.SendRPC("proxyd_getUsage", []interface{}{"partner_a"})
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		var usageRes struct {
+			Result proxyd.KeyUsage `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(res, &usageRes))
+		require.Equal(t, "partner", usageRes.Result.Tier)
+		require.Equal(t, proxyd.Usage{Requests: 3, ComputeUnits: 11}, usageRes.Result.Usage)
+		require.Equal(t, int64(11), usageRes.Result.Limits.DailyComputeUnits)
+
+		res, code, err = admin.SendRPC("proxyd_resetUsage", []interface{}{"partner_a"})
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"id":999,"jsonrpc":"2.0","result":true}`), res)
+
+		_, code, err = partner.SendRPC("eth_call", nil)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+	})
+
+	t.Run("admin rpc requires the admin key", func(t *testing.T) {
+		_, code, err := NewProxydClient("http://127.0.0.1:8545/admin").SendRPC("proxyd_getUsage", []interface{}{"partner_a"})
+		require.NoError(t, err)
+		require.Equal(t, 401, code)
+	})
+}
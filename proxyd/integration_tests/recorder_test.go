@@ -0,0 +1,113 @@
+package integration_tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	router := NewBatchRPCResponseRouter()
+	router.SetFallbackRoute("eth_chainId", "0xa")
+	router.SetFallbackRoute("eth_sendRawTransaction", "0x01")
+	goodBackend := NewMockBackend(router)
+	defer goodBackend.Close()
+
+	require.NoError(t, os.Setenv("GOOD_BACKEND_RPC_URL", goodBackend.URL()))
+
+	dir := t.TempDir()
+	config := ReadConfig("recorder")
+	config.Recorder.Path = dir
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+
+	client := NewProxydClient("http://127.0.0.1:8545")
+	_, code, err := client.SendRPC("eth_chainId", nil)
+	require.NoError(t, err)
+	require.Equal(t, 200, code)
+	_, code, err = client.SendRPC("eth_getBalance", nil)
+	require.NoError(t, err)
+	require.Equal(t, 403, code)
+	_, code, err = client.SendBatchRPC(
+		NewRPCReq("1", "eth_chainId", nil),
+		NewRPCReq("2", "eth_chainId", nil),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 200, code)
+	_, _, err = client.SendRPC("eth_sendRawTransaction", []interface{}{"0x00"})
+	require.NoError(t, err)
+	// the recorder is flushed on shutdown
+	shutdown()
+
+	files, err := proxyd.RecordingFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	recs, err := proxyd.ReadRecordings(files[0])
+	require.NoError(t, err)
+	require.Len(t, recs, 4)
+	require.Equal(t, 403, recs[1].Status)
+	require.True(t, recs[3].Redacted)
+
+	config = ReadConfig("recorder")
+	config.Recorder.Enabled = false
+	_, shutdown, err = proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	goodBackend.Reset()
+	summary, err := proxyd.Replay(context.Background(), http.DefaultClient, "http://127.0.0.1:8545", nil, recs, nil)
+	require.NoError(t, err)
+	require.Equal(t, proxyd.ReplaySummary{Replayed: 3, Matched: 3, Skipped: 1}, summary)
+	require.Equal(t, 2, len(goodBackend.Requests()))
+
+	// responses that changed are reported
+	router.SetFallbackRoute("eth_chainId", "0xb")
+	summary, err = proxyd.Replay(context.Background(), http.DefaultClient, "http://127.0.0.1:8545", nil, recs, nil)
+	require.NoError(t, err)
+	require.Equal(t, proxyd.ReplaySummary{Replayed: 3, Matched: 1, Mismatched: 2, Skipped: 1}, summary)
+}
+
+func TestRecordAndReplayAuthenticated(t *testing.T) {
+	goodBackend := NewMockBackend(BatchedResponseHandler(200, goodResponse))
+	defer goodBackend.Close()
+
+	require.NoError(t, os.Setenv("GOOD_BACKEND_RPC_URL", goodBackend.URL()))
+
+	dir := t.TempDir()
+	config := ReadConfig("recorder")
+	config.Recorder.Path = dir
+	config.Authentication = map[string]string{"secret": "alice"}
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+
+	client := NewProxydClient("http://127.0.0.1:8545/secret")
+	_, code, err := client.SendRPC("eth_chainId", nil)
+	require.NoError(t, err)
+	require.Equal(t, 200, code)
+	shutdown()
+
+	files, err := proxyd.RecordingFiles(dir)
+	require.NoError(t, err)
+	recs, err := proxyd.ReadRecordings(files[0])
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+	require.Equal(t, "alice", recs[0].Auth)
+
+	config.Recorder.Enabled = false
+	_, shutdown, err = proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	// without the key of the recorded alias the requests are unauthorized
+	summary, err := proxyd.Replay(context.Background(), http.DefaultClient, "http://127.0.0.1:8545", nil, recs, nil)
+	require.NoError(t, err)
+	require.Equal(t, proxyd.ReplaySummary{Replayed: 1, Mismatched: 1}, summary)
+
+	summary, err = proxyd.Replay(context.Background(), http.DefaultClient, "http://127.0.0.1:8545", map[string]string{"alice": "secret"}, recs, nil)
+	require.NoError(t, err)
+	require.Equal(t, proxyd.ReplaySummary{Replayed: 1, Matched: 1}, summary)
+}
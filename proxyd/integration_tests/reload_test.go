@@ -0,0 +1,103 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	// node1 blocks its first response until released, to keep a request in flight during the reload
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	var blocked bool
+	node1 := NewMockBackend(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !blocked {
+			blocked = true
+			close(inFlight)
+			<-release
+		}
+		SingleResponseHandler(200, `{"id":999,"jsonrpc":"2.0","result":"node1"}`)(w, r)
+	}))
+	defer node1.Close()
+	node2 := NewMockBackend(SingleResponseHandler(200, `{"id":999,"jsonrpc":"2.0","result":"node2"}`))
+	defer node2.Close()
+
+	require.NoError(t, os.Setenv("NODE1_URL", node1.URL()))
+	require.NoError(t, os.Setenv("NODE2_URL", node2.URL()))
+
+	config := ReadConfig("reload")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	svr, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	type result struct {
+		res  []byte
+		code int
+		err  error
+	}
+	inFlightRes := make(chan result, 1)
+	go func() {
+		res, code, err := client.SendRPC("eth_chainId", nil)
+		inFlightRes <- result{res, code, err}
+	}()
+	<-inFlight
+
+	t.Run("invalid config is rejected", func(t *testing.T) {
+		invalid := ReadConfig("reload_updated")
+		invalid.RPCMethodMappings["eth_call"] = "undefined"
+		require.Error(t, svr.Reload(invalid))
+		require.Contains(t, svr.BackendGroups(), "main")
+		require.NotContains(t, svr.BackendGroups(), "other")
+	})
+
+	require.NoError(t, svr.Reload(ReadConfig("reload_updated")))
+	require.Contains(t, svr.BackendGroups(), "other")
+
+	t.Run("in flight request completes on the old config", func(t *testing.T) {
+		close(release)
+		select {
+		case r := <-inFlightRes:
+			require.NoError(t, r.err)
+			require.Equal(t, 200, r.code)
+			RequireEqualJSON(t, []byte(`{"id":999,"jsonrpc":"2.0","result":"node1"}`), r.res)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for in flight request")
+		}
+	})
+
+	t.Run("new requests use the new backend groups", func(t *testing.T) {
+		res, code, err := client.SendRPC("eth_chainId", nil)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"id":999,"jsonrpc":"2.0","result":"node2"}`), res)
+		require.Equal(t, 1, len(node2.Requests()))
+	})
+
+	t.Run("new method mappings and rate limits apply", func(t *testing.T) {
+		res, code, err := client.SendRPC("eth_blockNumber", nil)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"id":999,"jsonrpc":"2.0","result":"node1"}`), res)
+
+		// the message of the error depends on the config of other tests, since it is global
+		res, _, err = client.SendRPC("eth_blockNumber", nil)
+		require.NoError(t, err)
+		var limited proxyd.RPCRes
+		require.NoError(t, json.Unmarshal(res, &limited))
+		require.NotNil(t, limited.Error)
+		require.Equal(t, proxyd.ErrOverRateLimit.Code, limited.Error.Code)
+	})
+
+	t.Run("unchanged backends are reused", func(t *testing.T) {
+		before := svr.BackendGroups()["other"].Backends[0]
+		require.NoError(t, svr.Reload(ReadConfig("reload_updated")))
+		require.Same(t, before, svr.BackendGroups()["other"].Backends[0])
+	})
+}
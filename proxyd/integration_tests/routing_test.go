@@ -0,0 +1,68 @@
+package integration_tests
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingStrategies(t *testing.T) {
+	// node1 is slow for eth_blockNumber and eth_sendRawTransaction, to be hedged by node2
+	node1 := NewMockBackend(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "eth_blockNumber") || strings.Contains(string(body), "eth_sendRawTransaction") {
+			time.Sleep(time.Second)
+		}
+		SingleResponseHandler(200, `{"id":999,"jsonrpc":"2.0","result":"node1"}`)(w, r)
+	}))
+	defer node1.Close()
+	node2 := NewMockBackend(SingleResponseHandler(200, `{"id":999,"jsonrpc":"2.0","result":"node2"}`))
+	defer node2.Close()
+
+	require.NoError(t, os.Setenv("NODE1_URL", node1.URL()))
+	require.NoError(t, os.Setenv("NODE2_URL", node2.URL()))
+
+	config := ReadConfig("routing")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	t.Run("weighted round robin", func(t *testing.T) {
+		for i := 0; i < 8; i++ {
+			_, code, err := client.SendRPC("eth_chainId", nil)
+			require.NoError(t, err)
+			require.Equal(t, 200, code)
+		}
+		require.Equal(t, 6, len(node1.Requests()))
+		require.Equal(t, 2, len(node2.Requests()))
+	})
+
+	t.Run("hedged", func(t *testing.T) {
+		node1.Reset()
+		node2.Reset()
+		start := time.Now()
+		res, code, err := client.SendRPC("eth_blockNumber", nil)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"id":999,"jsonrpc":"2.0","result":"node2"}`), res)
+		require.Less(t, time.Since(start), time.Second, "should not wait for the slow backend")
+		require.Equal(t, 1, len(node2.Requests()))
+	})
+	t.Run("transactions are not hedged", func(t *testing.T) {
+		node1.Reset()
+		node2.Reset()
+		res, code, err := client.SendRPC("eth_sendRawTransaction", []interface{}{"0x00"})
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"id":999,"jsonrpc":"2.0","result":"node1"}`), res)
+		require.Equal(t, 1, len(node1.Requests()))
+		require.Equal(t, 0, len(node2.Requests()))
+	})
+}
@@ -0,0 +1,94 @@
+package integration_tests
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStickyRouting(t *testing.T) {
+	node1 := NewMockBackend(SingleResponseHandler(200, `{"id":1,"jsonrpc":"2.0","result":"node1"}`))
+	defer node1.Close()
+	node2 := NewMockBackend(SingleResponseHandler(200, `{"id":1,"jsonrpc":"2.0","result":"node2"}`))
+	defer node2.Close()
+
+	require.NoError(t, os.Setenv("NODE1_URL", node1.URL()))
+	require.NoError(t, os.Setenv("NODE2_URL", node2.URL()))
+
+	config := ReadConfig("sticky")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	tx := new(types.Transaction)
+	require.NoError(t, tx.UnmarshalBinary(hexutil.MustDecode(txHex1)))
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	require.NoError(t, err)
+
+	res, code, err := client.SendRequest(makeSendRawTransaction(txHex1))
+	require.NoError(t, err)
+	require.Equal(t, 200, code)
+	RequireEqualJSON(t, []byte(`{"id":1,"jsonrpc":"2.0","result":"node1"}`), res)
+
+	// round-robin would send the receipt request to node2
+	res, _, err = client.SendRPC("eth_getTransactionReceipt", []interface{}{tx.Hash().Hex()})
+	require.NoError(t, err)
+	RequireEqualJSON(t, []byte(`{"id":1,"jsonrpc":"2.0","result":"node1"}`), res)
+
+	res, _, err = client.SendRPC("eth_getTransactionCount", []interface{}{from.Hex(), "pending"})
+	require.NoError(t, err)
+	RequireEqualJSON(t, []byte(`{"id":1,"jsonrpc":"2.0","result":"node1"}`), res)
+
+	// other requests keep being spread across the backends
+	res, _, err = client.SendRPC("eth_chainId", nil)
+	require.NoError(t, err)
+	RequireEqualJSON(t, []byte(`{"id":1,"jsonrpc":"2.0","result":"node2"}`), res)
+
+	require.Equal(t, 3, len(node1.Requests()))
+	require.Equal(t, 1, len(node2.Requests()))
+}
+
+func TestStickyRoutingNotHedged(t *testing.T) {
+	// node1 is slow for eth_getTransactionReceipt, which would be hedged by node2 if it wasn't pinned
+	node1 := NewMockBackend(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "eth_getTransactionReceipt") {
+			time.Sleep(500 * time.Millisecond)
+		}
+		SingleResponseHandler(200, `{"id":1,"jsonrpc":"2.0","result":"node1"}`)(w, r)
+	}))
+	defer node1.Close()
+	node2 := NewMockBackend(SingleResponseHandler(200, `{"id":1,"jsonrpc":"2.0","result":"node2"}`))
+	defer node2.Close()
+
+	require.NoError(t, os.Setenv("NODE1_URL", node1.URL()))
+	require.NoError(t, os.Setenv("NODE2_URL", node2.URL()))
+
+	config := ReadConfig("sticky_hedged")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	tx := new(types.Transaction)
+	require.NoError(t, tx.UnmarshalBinary(hexutil.MustDecode(txHex1)))
+
+	res, code, err := client.SendRequest(makeSendRawTransaction(txHex1))
+	require.NoError(t, err)
+	require.Equal(t, 200, code)
+	RequireEqualJSON(t, []byte(`{"id":1,"jsonrpc":"2.0","result":"node1"}`), res)
+
+	res, _, err = client.SendRPC("eth_getTransactionReceipt", []interface{}{tx.Hash().Hex()})
+	require.NoError(t, err)
+	RequireEqualJSON(t, []byte(`{"id":1,"jsonrpc":"2.0","result":"node1"}`), res)
+	require.Equal(t, 0, len(node2.Requests()), "pinned requests should not be hedged")
+}
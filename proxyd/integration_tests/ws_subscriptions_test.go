@@ -0,0 +1,201 @@
+package integration_tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	ms "github.com/ethereum-optimism/optimism/proxyd/tools/mockserver/handler"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribeHandler responds to eth_subscribe and sends the subscribed connection to subs
+func subscribeHandler(subs chan *websocket.Conn) MockWSBackendOnMessage {
+	return func(conn *websocket.Conn, msgType int, data []byte) {
+		req, err := proxyd.ParseRPCReq(data)
+		if err != nil || req.Method != "eth_subscribe" {
+			return
+		}
+		res := `{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":"0xbackendsub"}`
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(res)); err != nil {
+			return
+		}
+		subs <- conn
+	}
+}
+
+func TestWSManagedSubscriptions(t *testing.T) {
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+	responses := path.Join(dir, "testdata/consensus_responses.yml")
+
+	h1 := ms.MockedHandler{Autoload: true, AutoloadFile: responses}
+	h2 := ms.MockedHandler{Autoload: true, AutoloadFile: responses}
+	node1 := NewMockBackend(http.HandlerFunc(h1.Handler))
+	defer node1.Close()
+	node2 := NewMockBackend(http.HandlerFunc(h2.Handler))
+	defer node2.Close()
+
+	subs1 := make(chan *websocket.Conn, 10)
+	subs2 := make(chan *websocket.Conn, 10)
+	ws1 := NewMockWSBackend(nil, subscribeHandler(subs1), nil)
+	defer ws1.Close()
+	ws2 := NewMockWSBackend(nil, subscribeHandler(subs2), nil)
+	defer ws2.Close()
+
+	require.NoError(t, os.Setenv("NODE1_URL", node1.URL()))
+	require.NoError(t, os.Setenv("NODE2_URL", node2.URL()))
+	require.NoError(t, os.Setenv("NODE1_WS_URL", ws1.URL()))
+	require.NoError(t, os.Setenv("NODE2_WS_URL", ws2.URL()))
+
+	config := ReadConfig("ws_subscriptions")
+	svr, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	bg := svr.BackendGroups()["node"]
+	require.NotNil(t, bg.Consensus)
+
+	ctx := context.Background()
+	update := func() {
+		for _, be := range bg.Backends {
+			bg.Consensus.UpdateBackend(ctx, be)
+		}
+		bg.Consensus.UpdateBackendGroupConsensus(ctx)
+	}
+	update()
+	require.Equal(t, "0x101", bg.Consensus.GetLatestBlockNumber().String())
+
+	clientMsgs := make(chan []byte, 10)
+	client, err := NewProxydWSClient("ws://127.0.0.1:8546", func(msgType int, data []byte) {
+		clientMsgs <- data
+	}, nil)
+	require.NoError(t, err)
+	defer client.HardClose()
+
+	receive := func() []byte {
+		select {
+		case msg := <-clientMsgs:
+			return msg
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for client message")
+			return nil
+		}
+	}
+	requireNoMessage := func() {
+		select {
+		case msg := <-clientMsgs:
+			t.Fatalf("unexpected client message: %s", msg)
+		case <-time.After(1500 * time.Millisecond):
+		}
+	}
+	waitSubscribed := func() (*websocket.Conn, *proxyd.Backend) {
+		select {
+		case conn := <-subs1:
+			return conn, bg.Backends[0]
+		case conn := <-subs2:
+			return conn, bg.Backends[1]
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for backend subscription")
+			return nil, nil
+		}
+	}
+	pushHead := func(conn *websocket.Conn, number string, hash string) {
+		msg := `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xbackendsub","result":{"number":"` + number + `","hash":"` + hash + `"}}}`
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+	}
+	requireHead := func(subID string, number string, hash string) {
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"`+subID+`","result":{"number":"`+number+`","hash":"`+hash+`"}}}`), receive())
+	}
+
+	// requests other than subscriptions are forwarded to the backend group
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)))
+	RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":1,"result":"0x101"}`), receive())
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":2,"method":"eth_subscribe","params":["newPendingTransactions"]}`)))
+	RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"unsupported subscription type: newPendingTransactions"}}`), receive())
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":3,"method":"eth_subscribe","params":["newHeads"]}`)))
+	var subRes proxyd.RPCRes
+	require.NoError(t, json.Unmarshal(receive(), &subRes))
+	require.Nil(t, subRes.Error)
+	subID := subRes.Result.(string)
+
+	conn, serving := waitSubscribed()
+
+	t.Run("forward heads up to consensus", func(t *testing.T) {
+		pushHead(conn, "0x100", "hash_0x100")
+		requireHead(subID, "0x100", "hash_0x100")
+	})
+
+	t.Run("hold back heads above consensus", func(t *testing.T) {
+		pushHead(conn, "0x102", "hash_0x102")
+		requireNoMessage()
+
+		for _, h := range []*ms.MockedHandler{&h1, &h2} {
+			h.AddOverride(&ms.MethodTemplate{
+				Method:   "eth_getBlockByNumber",
+				Block:    "latest",
+				Response: buildResponse(map[string]string{"number": "0x102", "hash": "hash_0x102"}),
+			})
+		}
+		update()
+		require.Equal(t, "0x102", bg.Consensus.GetLatestBlockNumber().String())
+		requireHead(subID, "0x102", "hash_0x102")
+	})
+
+	t.Run("resubscribe when backend is banned", func(t *testing.T) {
+		bg.Consensus.Ban(serving)
+		newConn, newServing := waitSubscribed()
+		require.NotEqual(t, serving, newServing)
+
+		// the head already sent from the previous backend is not sent again
+		pushHead(newConn, "0x102", "hash_0x102")
+		requireNoMessage()
+		pushHead(newConn, "0x101", "hash_0x101b")
+		requireHead(subID, "0x101", "hash_0x101b")
+	})
+
+	t.Run("subscription limits", func(t *testing.T) {
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":6,"method":"eth_subscribe","params":["logs",{}]}`)))
+		var res proxyd.RPCRes
+		require.NoError(t, json.Unmarshal(receive(), &res))
+		require.Nil(t, res.Error)
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":7,"method":"eth_subscribe","params":["newHeads"]}`)))
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":7,"error":{"code":-32022,"message":"too many subscriptions on connection"}}`), receive())
+
+		otherMsgs := make(chan []byte, 10)
+		other, err := NewProxydWSClient("ws://127.0.0.1:8546", func(msgType int, data []byte) {
+			otherMsgs <- data
+		}, nil)
+		require.NoError(t, err)
+		defer other.HardClose()
+		receiveOther := func() []byte {
+			select {
+			case msg := <-otherMsgs:
+				return msg
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for client message")
+				return nil
+			}
+		}
+		require.NoError(t, other.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)))
+		require.NoError(t, json.Unmarshal(receiveOther(), &res))
+		require.Nil(t, res.Error)
+		require.NoError(t, other.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":2,"method":"eth_subscribe","params":["logs",{}]}`)))
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":2,"error":{"code":-32023,"message":"too many subscriptions"}}`), receiveOther())
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":4,"method":"eth_unsubscribe","params":["`+subID+`"]}`)))
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":4,"result":true}`), receive())
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":5,"method":"eth_unsubscribe","params":["`+subID+`"]}`)))
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":5,"result":false}`), receive())
+	})
+}
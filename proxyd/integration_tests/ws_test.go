@@ -224,6 +224,46 @@ func TestWS(t *testing.T) {
 	}
 }
 
+func TestWSQuotas(t *testing.T) {
+	backend := NewMockWSBackend(nil, func(conn *websocket.Conn, msgType int, data []byte) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":\"0x1\"}"))
+	}, nil)
+	defer backend.Close()
+
+	require.NoError(t, os.Setenv("GOOD_BACKEND_RPC_URL", backend.URL()))
+
+	config := ReadConfig("ws_quotas")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	resCh := make(chan string, 3)
+	client, err := NewProxydWSClient("ws://127.0.0.1:8546/free_secret", func(msgType int, data []byte) {
+		resCh <- string(data)
+	}, nil)
+	require.NoError(t, err)
+	defer client.HardClose()
+
+	// each message is accounted against the daily budgets of the client
+	expected := []string{
+		"{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":\"0x1\"}",
+		"{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":\"0x1\"}",
+		"{\"jsonrpc\":\"2.0\",\"error\":{\"code\":-32021,\"message\":\"over daily_requests quota of 2\"},\"id\":1}",
+	}
+	for _, exp := range expected {
+		require.NoError(t, client.WriteMessage(
+			websocket.TextMessage,
+			[]byte("{\"id\": 1, \"method\": \"eth_subscribe\", \"params\": [\"newHeads\"]}"),
+		))
+		select {
+		case res := <-resCh:
+			require.Equal(t, exp, res)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out")
+		}
+	}
+}
+
 func TestWSClientClosure(t *testing.T) {
 	backendHdlr := new(backendHandler)
 	clientHdlr := new(clientHandler)
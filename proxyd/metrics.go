@@ -373,6 +373,138 @@ var (
 	}, []string{
 		"backend_name",
 	})
+
+	getLogsSplitRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "get_logs_split_requests_total",
+		Help:      "Count of eth_getLogs requests split into chunks per backend group",
+	}, []string{
+		"backend_group_name",
+	})
+
+	getLogsChunksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "get_logs_chunks_total",
+		Help:      "Count of eth_getLogs chunks forwarded per backend group",
+	}, []string{
+		"backend_group_name",
+	})
+
+	activeWSSubscriptionsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "active_ws_subscriptions",
+		Help:      "Gauge of active upstream WS subscriptions managed by proxyd",
+	}, []string{
+		"backend_group_name",
+	})
+
+	routedRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "routed_requests_total",
+		Help:      "Count of requests served by each backend of a group, by routing strategy",
+	}, []string{
+		"backend_group_name",
+		"strategy",
+		"backend_name",
+	})
+
+	hedgedRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "hedged_requests_total",
+		Help:      "Count of requests sent to a second backend by the hedged strategy",
+	}, []string{
+		"backend_group_name",
+	})
+
+	hedgedRequestWinsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "hedged_request_wins_total",
+		Help:      "Count of hedged requests where the second backend responded first",
+	}, []string{
+		"backend_group_name",
+	})
+
+	hedgeDelayGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "hedge_delay_seconds",
+		Help:      "Current delay before hedging a request",
+	}, []string{
+		"backend_group_name",
+	})
+
+	recordedRequestsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "recorded_requests_total",
+		Help:      "Count of requests written by the recorder",
+	})
+
+	recorderDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "recorder_dropped_total",
+		Help:      "Count of sampled requests that the recorder failed to write",
+	})
+
+	quotaRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "quota_requests_total",
+		Help:      "Count of RPC calls accounted against the quotas of the clients",
+	}, []string{
+		"auth",
+		"tier",
+	})
+
+	quotaComputeUnitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "quota_compute_units_total",
+		Help:      "Count of compute units accounted against the quotas of the clients",
+	}, []string{
+		"auth",
+		"tier",
+	})
+
+	quotaExceededTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "quota_exceeded_total",
+		Help:      "Count of requests rejected for being over a quota",
+	}, []string{
+		"auth",
+		"tier",
+		"limit",
+	})
+
+	blockCacheInvalidatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "block_cache_invalidated_total",
+		Help:      "Count of block cache entries invalidated when the consensus is broken",
+	}, []string{
+		"backend_group_name",
+	})
+
+	stickyRoutingTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "sticky_routing_total",
+		Help:      "Count of sticky sessions created, and of requests routed by them",
+	}, []string{
+		"backend_group_name",
+		"result",
+	})
+
+	configReloadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "config_reloads_total",
+		Help:      "Count of configuration reloads",
+	}, []string{
+		"success",
+	})
+
+	wsResubscriptionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "ws_resubscriptions_total",
+		Help:      "Count of WS subscriptions moved away from a backend",
+	}, []string{
+		"backend_name",
+		"reason",
+	})
 )
 
 func RecordRedisError(source string) {
@@ -508,6 +640,64 @@ func RecordBackendNetworkErrorRateSlidingWindow(b *Backend, rate float64) {
 	networkErrorRateBackend.WithLabelValues(b.Name).Set(rate)
 }
 
+func RecordGetLogsSplit(group *BackendGroup, chunks int) {
+	getLogsSplitRequestsTotal.WithLabelValues(group.Name).Inc()
+	getLogsChunksTotal.WithLabelValues(group.Name).Add(float64(chunks))
+}
+
+func RecordWSResubscription(be *Backend, reason string) {
+	wsResubscriptionsTotal.WithLabelValues(be.Name, reason).Inc()
+}
+
+func RecordRoutedRequest(group *BackendGroup, be *Backend) {
+	strategy := RoutingStrategyFallback
+	if group.Router != nil {
+		strategy = group.Router.Strategy()
+	}
+	routedRequestsTotal.WithLabelValues(group.Name, string(strategy), be.Name).Inc()
+}
+
+func RecordHedgedRequest(group *BackendGroup) {
+	hedgedRequestsTotal.WithLabelValues(group.Name).Inc()
+}
+
+func RecordHedgedRequestWin(group *BackendGroup) {
+	hedgedRequestWinsTotal.WithLabelValues(group.Name).Inc()
+}
+
+func RecordHedgeDelay(group *BackendGroup, delay time.Duration) {
+	hedgeDelayGauge.WithLabelValues(group.Name).Set(delay.Seconds())
+}
+
+func RecordRecordedRequest() {
+	recordedRequestsTotal.Inc()
+}
+
+func RecordRecorderDropped() {
+	recorderDroppedTotal.Inc()
+}
+
+func RecordQuotaUsage(auth string, tier string, usage Usage) {
+	quotaRequestsTotal.WithLabelValues(auth, tier).Add(float64(usage.Requests))
+	quotaComputeUnitsTotal.WithLabelValues(auth, tier).Add(float64(usage.ComputeUnits))
+}
+
+func RecordQuotaExceeded(auth string, tier string, limit string) {
+	quotaExceededTotal.WithLabelValues(auth, tier, limit).Inc()
+}
+
+func RecordBlockCacheInvalidation(group *BackendGroup, removed int) {
+	blockCacheInvalidatedTotal.WithLabelValues(group.Name).Add(float64(removed))
+}
+
+func RecordStickyRouting(group *BackendGroup, result string) {
+	stickyRoutingTotal.WithLabelValues(group.Name, result).Inc()
+}
+
+func RecordConfigReload(success bool) {
+	configReloadsTotal.WithLabelValues(strconv.FormatBool(success)).Inc()
+}
+
 func boolToFloat64(b bool) float64 {
 	if b {
 		return 1
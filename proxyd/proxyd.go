@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/go-redis/redis/v8"
@@ -16,20 +17,8 @@ import (
 )
 
 func Start(config *Config) (*Server, func(), error) {
-	if len(config.Backends) == 0 {
-		return nil, nil, errors.New("must define at least one backend")
-	}
-	if len(config.BackendGroups) == 0 {
-		return nil, nil, errors.New("must define at least one backend group")
-	}
-	if len(config.RPCMethodMappings) == 0 {
-		return nil, nil, errors.New("must define at least one RPC method mapping")
-	}
-
-	for authKey := range config.Authentication {
-		if authKey == "none" {
-			return nil, nil, errors.New("cannot use none as an auth key")
-		}
+	if err := validateConfig(config); err != nil {
+		return nil, nil, err
 	}
 
 	var redisClient *redis.Client
@@ -62,139 +51,19 @@ func Start(config *Config) (*Server, func(), error) {
 		ErrTooManyBatchRequests.Message = config.BatchConfig.ErrorMessage
 	}
 
-	if config.SenderRateLimit.Enabled {
-		if config.SenderRateLimit.Limit <= 0 {
-			return nil, nil, errors.New("limit in sender_rate_limit must be > 0")
-		}
-		if time.Duration(config.SenderRateLimit.Interval) < time.Second {
-			return nil, nil, errors.New("interval in sender_rate_limit must be >= 1s")
-		}
-	}
-
 	maxConcurrentRPCs := config.Server.MaxConcurrentRPCs
 	if maxConcurrentRPCs == 0 {
 		maxConcurrentRPCs = math.MaxInt64
 	}
 	rpcRequestSemaphore := semaphore.NewWeighted(maxConcurrentRPCs)
 
-	backendNames := make([]string, 0)
-	backendsByName := make(map[string]*Backend)
-	for name, cfg := range config.Backends {
-		opts := make([]BackendOpt, 0)
-
-		rpcURL, err := ReadFromEnvOrConfig(cfg.RPCURL)
-		if err != nil {
-			return nil, nil, err
-		}
-		wsURL, err := ReadFromEnvOrConfig(cfg.WSURL)
-		if err != nil {
-			return nil, nil, err
-		}
-		if rpcURL == "" {
-			return nil, nil, fmt.Errorf("must define an RPC URL for backend %s", name)
-		}
-
-		if config.BackendOptions.ResponseTimeoutSeconds != 0 {
-			timeout := secondsToDuration(config.BackendOptions.ResponseTimeoutSeconds)
-			opts = append(opts, WithTimeout(timeout))
-		}
-		if config.BackendOptions.MaxRetries != 0 {
-			opts = append(opts, WithMaxRetries(config.BackendOptions.MaxRetries))
-		}
-		if config.BackendOptions.MaxResponseSizeBytes != 0 {
-			opts = append(opts, WithMaxResponseSize(config.BackendOptions.MaxResponseSizeBytes))
-		}
-		if config.BackendOptions.OutOfServiceSeconds != 0 {
-			opts = append(opts, WithOutOfServiceDuration(secondsToDuration(config.BackendOptions.OutOfServiceSeconds)))
-		}
-		if config.BackendOptions.MaxDegradedLatencyThreshold > 0 {
-			opts = append(opts, WithMaxDegradedLatencyThreshold(time.Duration(config.BackendOptions.MaxDegradedLatencyThreshold)))
-		}
-		if config.BackendOptions.MaxLatencyThreshold > 0 {
-			opts = append(opts, WithMaxLatencyThreshold(time.Duration(config.BackendOptions.MaxLatencyThreshold)))
-		}
-		if config.BackendOptions.MaxErrorRateThreshold > 0 {
-			opts = append(opts, WithMaxErrorRateThreshold(config.BackendOptions.MaxErrorRateThreshold))
-		}
-		if cfg.MaxRPS != 0 {
-			opts = append(opts, WithMaxRPS(cfg.MaxRPS))
-		}
-		if cfg.MaxWSConns != 0 {
-			opts = append(opts, WithMaxWSConns(cfg.MaxWSConns))
-		}
-		if cfg.Password != "" {
-			passwordVal, err := ReadFromEnvOrConfig(cfg.Password)
-			if err != nil {
-				return nil, nil, err
-			}
-			opts = append(opts, WithBasicAuth(cfg.Username, passwordVal))
-		}
-		tlsConfig, err := configureBackendTLS(cfg)
-		if err != nil {
-			return nil, nil, err
-		}
-		if tlsConfig != nil {
-			log.Info("using custom TLS config for backend", "name", name)
-			opts = append(opts, WithTLSConfig(tlsConfig))
-		}
-		if cfg.StripTrailingXFF {
-			opts = append(opts, WithStrippedTrailingXFF())
-		}
-		opts = append(opts, WithProxydIP(os.Getenv("PROXYD_IP")))
-		opts = append(opts, WithConsensusSkipPeerCountCheck(cfg.ConsensusSkipPeerCountCheck))
-
-		receiptsTarget, err := ReadFromEnvOrConfig(cfg.ConsensusReceiptsTarget)
-		if err != nil {
-			return nil, nil, err
-		}
-		receiptsTarget, err = validateReceiptsTarget(receiptsTarget)
-		if err != nil {
-			return nil, nil, err
-		}
-		opts = append(opts, WithConsensusReceiptTarget(receiptsTarget))
-
-		back := NewBackend(name, rpcURL, wsURL, rpcRequestSemaphore, opts...)
-		backendNames = append(backendNames, name)
-		backendsByName[name] = back
-		log.Info("configured backend",
-			"name", name,
-			"backend_names", backendNames,
-			"rpc_url", rpcURL,
-			"ws_url", wsURL)
-	}
-
-	backendGroups := make(map[string]*BackendGroup)
-	for bgName, bg := range config.BackendGroups {
-		backends := make([]*Backend, 0)
-		for _, bName := range bg.Backends {
-			if backendsByName[bName] == nil {
-				return nil, nil, fmt.Errorf("backend %s is not defined", bName)
-			}
-			backends = append(backends, backendsByName[bName])
-		}
-		group := &BackendGroup{
-			Name:     bgName,
-			Backends: backends,
-		}
-		backendGroups[bgName] = group
-	}
-
-	var wsBackendGroup *BackendGroup
-	if config.WSBackendGroup != "" {
-		wsBackendGroup = backendGroups[config.WSBackendGroup]
-		if wsBackendGroup == nil {
-			return nil, nil, fmt.Errorf("ws backend group %s does not exist", config.WSBackendGroup)
-		}
-	}
-
-	if wsBackendGroup == nil && config.Server.WSPort != 0 {
-		return nil, nil, fmt.Errorf("a ws port was defined, but no ws group was defined")
+	backendsByName, err := buildBackends(config, rpcRequestSemaphore, nil, nil)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	for _, bg := range config.RPCMethodMappings {
-		if backendGroups[bg] == nil {
-			return nil, nil, fmt.Errorf("undefined backend group %s", bg)
-		}
+	backendGroups, wsBackendGroup, err := buildBackendGroups(config, backendsByName)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var resolvedAuth map[string]string
@@ -244,6 +113,29 @@ func Start(config *Config) (*Server, func(), error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("error creating server: %w", err)
 	}
+	srv.config = config
+	srv.backendsByName = backendsByName
+	srv.rpcRequestSemaphore = rpcRequestSemaphore
+	if srv.wsSubscriptions != nil {
+		srv.wsSubscriptions.SetLimits(config.Server.MaxWSSubscriptions, config.Server.MaxWSSubscriptionsPerClient)
+	}
+
+	if config.Recorder.Enabled {
+		srv.recorder, err = NewRecorder(config.Recorder)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating recorder: %w", err)
+		}
+	}
+	if config.Quotas.Enabled {
+		srv.quotas, err = NewQuotas(config.Quotas, redisClient, config.Redis.Namespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating quotas: %w", err)
+		}
+		srv.adminKey, err = ReadFromEnvOrConfig(config.Quotas.AdminKey)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
 	if config.Metrics.Enabled {
 		addr := fmt.Sprintf("%s:%d", config.Metrics.Host, config.Metrics.Port)
@@ -286,6 +178,244 @@ func Start(config *Config) (*Server, func(), error) {
 		log.Info("WS server not enabled (ws_port is set to 0)")
 	}
 
+	startConsensusPollers(config, backendGroups)
+
+	<-errTimer.C
+	log.Info("started proxyd")
+
+	shutdownFunc := func() {
+		log.Info("shutting down proxyd")
+		srv.Shutdown()
+		log.Info("goodbye")
+	}
+
+	return srv, shutdownFunc, nil
+}
+
+func validateConfig(config *Config) error {
+	if len(config.Backends) == 0 {
+		return errors.New("must define at least one backend")
+	}
+	if len(config.BackendGroups) == 0 {
+		return errors.New("must define at least one backend group")
+	}
+	if len(config.RPCMethodMappings) == 0 {
+		return errors.New("must define at least one RPC method mapping")
+	}
+
+	for authKey := range config.Authentication {
+		if authKey == "none" {
+			return errors.New("cannot use none as an auth key")
+		}
+		// the admin API is served under /admin, which would shadow the key
+		if authKey == "admin" {
+			return errors.New("cannot use admin as an auth key")
+		}
+	}
+
+	if config.Quotas.Enabled {
+		aliases := make(map[string]bool)
+		for _, alias := range config.Authentication {
+			aliases[alias] = true
+		}
+		for key := range config.Quotas.Keys {
+			if !aliases[key] {
+				return fmt.Errorf("quota key %s is not an alias of the authentication keys", key)
+			}
+		}
+	}
+
+	if err := validateStickyRouting(config.BackendGroups, config.RPCMethodMappings); err != nil {
+		return err
+	}
+
+	if config.SenderRateLimit.Enabled {
+		if config.SenderRateLimit.Limit <= 0 {
+			return errors.New("limit in sender_rate_limit must be > 0")
+		}
+		if time.Duration(config.SenderRateLimit.Interval) < time.Second {
+			return errors.New("interval in sender_rate_limit must be >= 1s")
+		}
+	}
+	return nil
+}
+
+// buildBackends creates the backends of the config. When reloading, backends whose configuration
+// is unchanged from prevConfig are reused from prevBackends so they keep their connections and metrics.
+func buildBackends(config *Config, rpcRequestSemaphore *semaphore.Weighted, prevConfig *Config, prevBackends map[string]*Backend) (map[string]*Backend, error) {
+	backendNames := make([]string, 0)
+	backendsByName := make(map[string]*Backend)
+	for name, cfg := range config.Backends {
+		if prevConfig != nil && !backendConfigChanged(prevConfig, config, name) {
+			backendsByName[name] = prevBackends[name]
+			continue
+		}
+
+		back, err := newBackendFromConfig(config, name, cfg, rpcRequestSemaphore)
+		if err != nil {
+			return nil, err
+		}
+		backendNames = append(backendNames, name)
+		backendsByName[name] = back
+		if prevConfig == nil {
+			log.Info("configured backend",
+				"name", name,
+				"backend_names", backendNames,
+				"rpc_url", back.rpcURL,
+				"ws_url", back.wsURL)
+		} else if _, ok := prevBackends[name]; ok {
+			log.Info("updated backend", "name", name, "rpc_url", back.rpcURL, "ws_url", back.wsURL)
+		} else {
+			log.Info("added backend", "name", name, "rpc_url", back.rpcURL, "ws_url", back.wsURL)
+		}
+	}
+	for name := range prevBackends {
+		if _, ok := backendsByName[name]; !ok {
+			log.Info("removed backend", "name", name)
+		}
+	}
+	return backendsByName, nil
+}
+
+func newBackendFromConfig(config *Config, name string, cfg *BackendConfig, rpcRequestSemaphore *semaphore.Weighted) (*Backend, error) {
+	opts := make([]BackendOpt, 0)
+
+	rpcURL, err := ReadFromEnvOrConfig(cfg.RPCURL)
+	if err != nil {
+		return nil, err
+	}
+	wsURL, err := ReadFromEnvOrConfig(cfg.WSURL)
+	if err != nil {
+		return nil, err
+	}
+	if rpcURL == "" {
+		return nil, fmt.Errorf("must define an RPC URL for backend %s", name)
+	}
+
+	if config.BackendOptions.ResponseTimeoutSeconds != 0 {
+		timeout := secondsToDuration(config.BackendOptions.ResponseTimeoutSeconds)
+		opts = append(opts, WithTimeout(timeout))
+	}
+	if config.BackendOptions.MaxRetries != 0 {
+		opts = append(opts, WithMaxRetries(config.BackendOptions.MaxRetries))
+	}
+	if config.BackendOptions.MaxResponseSizeBytes != 0 {
+		opts = append(opts, WithMaxResponseSize(config.BackendOptions.MaxResponseSizeBytes))
+	}
+	if config.BackendOptions.OutOfServiceSeconds != 0 {
+		opts = append(opts, WithOutOfServiceDuration(secondsToDuration(config.BackendOptions.OutOfServiceSeconds)))
+	}
+	if config.BackendOptions.MaxDegradedLatencyThreshold > 0 {
+		opts = append(opts, WithMaxDegradedLatencyThreshold(time.Duration(config.BackendOptions.MaxDegradedLatencyThreshold)))
+	}
+	if config.BackendOptions.MaxLatencyThreshold > 0 {
+		opts = append(opts, WithMaxLatencyThreshold(time.Duration(config.BackendOptions.MaxLatencyThreshold)))
+	}
+	if config.BackendOptions.MaxErrorRateThreshold > 0 {
+		opts = append(opts, WithMaxErrorRateThreshold(config.BackendOptions.MaxErrorRateThreshold))
+	}
+	if cfg.MaxRPS != 0 {
+		opts = append(opts, WithMaxRPS(cfg.MaxRPS))
+	}
+	if cfg.MaxWSConns != 0 {
+		opts = append(opts, WithMaxWSConns(cfg.MaxWSConns))
+	}
+	if cfg.Password != "" {
+		passwordVal, err := ReadFromEnvOrConfig(cfg.Password)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithBasicAuth(cfg.Username, passwordVal))
+	}
+	tlsConfig, err := configureBackendTLS(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		log.Info("using custom TLS config for backend", "name", name)
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	if cfg.StripTrailingXFF {
+		opts = append(opts, WithStrippedTrailingXFF())
+	}
+	opts = append(opts, WithProxydIP(os.Getenv("PROXYD_IP")))
+	opts = append(opts, WithConsensusSkipPeerCountCheck(cfg.ConsensusSkipPeerCountCheck))
+
+	receiptsTarget, err := ReadFromEnvOrConfig(cfg.ConsensusReceiptsTarget)
+	if err != nil {
+		return nil, err
+	}
+	receiptsTarget, err = validateReceiptsTarget(receiptsTarget)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithConsensusReceiptTarget(receiptsTarget))
+
+	return NewBackend(name, rpcURL, wsURL, rpcRequestSemaphore, opts...), nil
+}
+
+// buildBackendGroups creates the backend groups of the config, without their consensus pollers
+func buildBackendGroups(config *Config, backendsByName map[string]*Backend) (map[string]*BackendGroup, *BackendGroup, error) {
+	backendGroups := make(map[string]*BackendGroup)
+	for bgName, bg := range config.BackendGroups {
+		backends := make([]*Backend, 0)
+		for _, bName := range bg.Backends {
+			if backendsByName[bName] == nil {
+				return nil, nil, fmt.Errorf("backend %s is not defined", bName)
+			}
+			backends = append(backends, backendsByName[bName])
+		}
+		router, err := NewRouter(bg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid routing for backend group %s: %w", bgName, err)
+		}
+		var sticky *StickySessions
+		if bg.StickyRouting != "" {
+			sticky, err = NewStickySessions(bg.StickyRouting, time.Duration(bg.StickyTTL))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid sticky routing for backend group %s: %w", bgName, err)
+			}
+		}
+		group := &BackendGroup{
+			Name:                 bgName,
+			Backends:             backends,
+			Router:               router,
+			Sticky:               sticky,
+			GetLogsMaxBlockRange: bg.GetLogsMaxBlockRange,
+			GetLogsMaxChunks:     bg.GetLogsMaxChunks,
+		}
+		if bg.ConsensusAware && config.Cache.Enabled && config.Cache.BlockAware {
+			group.BlockCache = NewBlockCache(
+				config.Cache.BlockCacheSize,
+				time.Duration(config.Cache.SafeTTL),
+				time.Duration(config.Cache.UnsafeTTL),
+			)
+		}
+		backendGroups[bgName] = group
+	}
+
+	var wsBackendGroup *BackendGroup
+	if config.WSBackendGroup != "" {
+		wsBackendGroup = backendGroups[config.WSBackendGroup]
+		if wsBackendGroup == nil {
+			return nil, nil, fmt.Errorf("ws backend group %s does not exist", config.WSBackendGroup)
+		}
+	}
+
+	if wsBackendGroup == nil && config.Server.WSPort != 0 {
+		return nil, nil, fmt.Errorf("a ws port was defined, but no ws group was defined")
+	}
+
+	for _, bg := range config.RPCMethodMappings {
+		if backendGroups[bg] == nil {
+			return nil, nil, fmt.Errorf("undefined backend group %s", bg)
+		}
+	}
+
+	return backendGroups, wsBackendGroup, nil
+}
+
+func startConsensusPollers(config *Config, backendGroups map[string]*BackendGroup) {
 	for bgName, bg := range backendGroups {
 		bgcfg := config.BackendGroups[bgName]
 		if bgcfg.ConsensusAware {
@@ -311,19 +441,17 @@ func Start(config *Config) (*Server, func(), error) {
 
 			cp := NewConsensusPoller(bg, copts...)
 			bg.Consensus = cp
-		}
-	}
 
-	<-errTimer.C
-	log.Info("started proxyd")
-
-	shutdownFunc := func() {
-		log.Info("shutting down proxyd")
-		srv.Shutdown()
-		log.Info("goodbye")
+			if bg.BlockCache != nil {
+				// entries at or below the common ancestor of the backends are still valid after the reorg
+				blockCache, group := bg.BlockCache, bg
+				cp.AddListener(func(commonAncestor hexutil.Uint64) {
+					removed := blockCache.InvalidateAbove(uint64(commonAncestor))
+					RecordBlockCacheInvalidation(group, removed)
+				})
+			}
+		}
 	}
-
-	return srv, shutdownFunc, nil
 }
 
 func validateReceiptsTarget(val string) (string, error) {
@@ -0,0 +1,363 @@
+package proxyd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	QuotaLimitRPS               = "rps"
+	QuotaLimitDailyRequests     = "daily_requests"
+	QuotaLimitDailyComputeUnits = "daily_compute_units"
+
+	quotaDayFormat = "2006-01-02"
+	// usage is kept for a day after it is over, so that it can still be queried
+	quotaUsageTTL = 48 * time.Hour
+)
+
+func ErrOverQuota(limit string, max int64) *RPCErr {
+	return &RPCErr{
+		Code:          JSONRPCErrorInternal - 21,
+		Message:       fmt.Sprintf("over %s quota of %d", limit, max),
+		HTTPErrorCode: 429,
+	}
+}
+
+// Usage is the number of requests and compute units used by a client during a day
+type Usage struct {
+	Requests     int64 `json:"requests"`
+	ComputeUnits int64 `json:"computeUnits"`
+}
+
+// UsageStore keeps the daily usage of the clients
+type UsageStore interface {
+	// Add atomically increments the usage of the key during the day, unless the usage would go over max.
+	// Zero fields of max are unlimited. It returns the usage after the increment and true, or the current
+	// usage and false if the usage was not incremented.
+	Add(ctx context.Context, key string, day string, usage Usage, max Usage) (Usage, bool, error)
+	Get(ctx context.Context, key string, day string) (Usage, error)
+	Reset(ctx context.Context, key string, day string) error
+}
+
+// MemoryUsageStore keeps the usage in local memory, for the current and the previous day
+type MemoryUsageStore struct {
+	mtx   sync.Mutex
+	usage map[string]map[string]Usage
+}
+
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{
+		usage: make(map[string]map[string]Usage),
+	}
+}
+
+func (m *MemoryUsageStore) Add(ctx context.Context, key string, day string, usage Usage, max Usage) (Usage, bool, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.usage[day] == nil {
+		m.usage[day] = make(map[string]Usage)
+		m.prune(day)
+	}
+	total := m.usage[day][key]
+	if overUsage(total, usage, max) {
+		return total, false, nil
+	}
+	total.Requests += usage.Requests
+	total.ComputeUnits += usage.ComputeUnits
+	m.usage[day][key] = total
+	return total, true, nil
+}
+
+// overUsage returns whether adding usage to total goes over max, where zero fields of max are unlimited
+func overUsage(total Usage, usage Usage, max Usage) bool {
+	return (max.Requests > 0 && total.Requests+usage.Requests > max.Requests) ||
+		(max.ComputeUnits > 0 && total.ComputeUnits+usage.ComputeUnits > max.ComputeUnits)
+}
+
+func (m *MemoryUsageStore) Get(ctx context.Context, key string, day string) (Usage, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.usage[day][key], nil
+}
+
+func (m *MemoryUsageStore) Reset(ctx context.Context, key string, day string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.usage[day], key)
+	return nil
+}
+
+// prune drops the days older than the previous day. Must be called with the lock held.
+func (m *MemoryUsageStore) prune(today string) {
+	current, err := time.Parse(quotaDayFormat, today)
+	if err != nil {
+		return
+	}
+	for day := range m.usage {
+		t, err := time.Parse(quotaDayFormat, day)
+		if err != nil || current.Sub(t) >= quotaUsageTTL {
+			delete(m.usage, day)
+		}
+	}
+}
+
+// RedisUsageStore keeps the usage in a Redis hash per client and day
+type RedisUsageStore struct {
+	r      *redis.Client
+	prefix string
+}
+
+func NewRedisUsageStore(r *redis.Client, prefix string) *RedisUsageStore {
+	return &RedisUsageStore{
+		r:      r,
+		prefix: prefix,
+	}
+}
+
+func (r *RedisUsageStore) key(key string, day string) string {
+	return fmt.Sprintf("quota_usage:%s:%s:%s", r.prefix, key, day)
+}
+
+// redisUsageAddScript increments the usage unless it would go over the limits, in a single step so that
+// concurrent calls of the same client can't go over the limits together
+var redisUsageAddScript = redis.NewScript(`
+local requests = tonumber(redis.call("HGET", KEYS[1], "requests") or "0")
+local computeUnits = tonumber(redis.call("HGET", KEYS[1], "compute_units") or "0")
+local addRequests, addComputeUnits = tonumber(ARGV[1]), tonumber(ARGV[2])
+local maxRequests, maxComputeUnits = tonumber(ARGV[3]), tonumber(ARGV[4])
+if (maxRequests > 0 and requests + addRequests > maxRequests) or
+	(maxComputeUnits > 0 and computeUnits + addComputeUnits > maxComputeUnits) then
+	return {0, requests, computeUnits}
+end
+requests = redis.call("HINCRBY", KEYS[1], "requests", addRequests)
+computeUnits = redis.call("HINCRBY", KEYS[1], "compute_units", addComputeUnits)
+redis.call("EXPIRE", KEYS[1], ARGV[5])
+return {1, requests, computeUnits}
+`)
+
+func (r *RedisUsageStore) Add(ctx context.Context, key string, day string, usage Usage, max Usage) (Usage, bool, error) {
+	res, err := redisUsageAddScript.Run(ctx, r.r, []string{r.key(key, day)},
+		usage.Requests, usage.ComputeUnits, max.Requests, max.ComputeUnits, int64(quotaUsageTTL/time.Second)).Result()
+	if err != nil {
+		RecordRedisError("QuotaAdd")
+		return Usage{}, false, err
+	}
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 3 {
+		return Usage{}, false, fmt.Errorf("unexpected quota script result: %v", res)
+	}
+	added, _ := vals[0].(int64)
+	requests, _ := vals[1].(int64)
+	computeUnits, _ := vals[2].(int64)
+	return Usage{Requests: requests, ComputeUnits: computeUnits}, added == 1, nil
+}
+
+func (r *RedisUsageStore) Get(ctx context.Context, key string, day string) (Usage, error) {
+	vals, err := r.r.HGetAll(ctx, r.key(key, day)).Result()
+	if err != nil {
+		RecordRedisError("QuotaGet")
+		return Usage{}, err
+	}
+	var usage Usage
+	if v, ok := vals["requests"]; ok {
+		if usage.Requests, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return Usage{}, err
+		}
+	}
+	if v, ok := vals["compute_units"]; ok {
+		if usage.ComputeUnits, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return Usage{}, err
+		}
+	}
+	return usage, nil
+}
+
+func (r *RedisUsageStore) Reset(ctx context.Context, key string, day string) error {
+	if err := r.r.Del(ctx, r.key(key, day)).Err(); err != nil {
+		RecordRedisError("QuotaReset")
+		return err
+	}
+	return nil
+}
+
+// Quotas meters the clients by the alias of their authentication key, and enforces the limits of their tier:
+// requests per second, and daily budgets of requests and of compute units, weighted by method.
+type Quotas struct {
+	tiers       map[string]*QuotaTierConfig
+	keys        map[string]string
+	defaultTier string
+	weights     map[string]int
+	rpsLims     map[string]FrontendRateLimiter
+	usage       UsageStore
+	now         func() time.Time
+}
+
+// KeyUsage is the usage of a client during a day, along with the limits of its tier
+type KeyUsage struct {
+	Key    string           `json:"key"`
+	Tier   string           `json:"tier"`
+	Day    string           `json:"day"`
+	Usage  Usage            `json:"usage"`
+	Limits *QuotaTierConfig `json:"limits"`
+}
+
+func NewQuotas(cfg QuotasConfig, redisClient *redis.Client, prefix string) (*Quotas, error) {
+	for name, tier := range cfg.Tiers {
+		if tier.RPS < 0 || tier.DailyRequests < 0 || tier.DailyComputeUnits < 0 {
+			return nil, fmt.Errorf("limits of quota tier %s must be >= 0", name)
+		}
+	}
+	if cfg.DefaultTier != "" && cfg.Tiers[cfg.DefaultTier] == nil {
+		return nil, fmt.Errorf("default quota tier %s is not defined", cfg.DefaultTier)
+	}
+	for key, tier := range cfg.Keys {
+		if cfg.Tiers[tier] == nil {
+			return nil, fmt.Errorf("quota tier %s of key %s is not defined", tier, key)
+		}
+	}
+	for method, weight := range cfg.MethodWeights {
+		if weight <= 0 {
+			return nil, fmt.Errorf("weight of method %s must be > 0", method)
+		}
+	}
+	if cfg.UseRedis && redisClient == nil {
+		return nil, errors.New("must specify a Redis URL if UseRedis is true in quotas config")
+	}
+
+	var usage UsageStore = NewMemoryUsageStore()
+	if cfg.UseRedis {
+		usage = NewRedisUsageStore(redisClient, prefix)
+	}
+	rpsLims := make(map[string]FrontendRateLimiter)
+	for name, tier := range cfg.Tiers {
+		if tier.RPS == 0 {
+			continue
+		}
+		if cfg.UseRedis {
+			rpsLims[name] = NewRedisFrontendRateLimiter(redisClient, time.Second, tier.RPS, "quota_"+name)
+		} else {
+			rpsLims[name] = NewMemoryFrontendRateLimit(time.Second, tier.RPS)
+		}
+	}
+
+	return &Quotas{
+		tiers:       cfg.Tiers,
+		keys:        cfg.Keys,
+		defaultTier: cfg.DefaultTier,
+		weights:     cfg.MethodWeights,
+		rpsLims:     rpsLims,
+		usage:       usage,
+		now:         time.Now,
+	}, nil
+}
+
+func (q *Quotas) tier(key string) (string, *QuotaTierConfig) {
+	name, ok := q.keys[key]
+	if !ok {
+		name = q.defaultTier
+	}
+	return name, q.tiers[name]
+}
+
+func (q *Quotas) weight(method string) int64 {
+	if w, ok := q.weights[method]; ok {
+		return int64(w)
+	}
+	return 1
+}
+
+func (q *Quotas) today() string {
+	return q.now().UTC().Format(quotaDayFormat)
+}
+
+// TakeRequest applies the requests per second limit of the client to an HTTP request
+func (q *Quotas) TakeRequest(ctx context.Context) error {
+	key := GetAuthCtx(ctx)
+	name, _ := q.tier(key)
+	lim := q.rpsLims[name]
+	if lim == nil {
+		return nil
+	}
+	ok, err := lim.Take(ctx, key)
+	if err != nil {
+		log.Warn("error taking quota", "auth", key, "err", err)
+		return ErrInternal
+	}
+	if !ok {
+		RecordQuotaExceeded(key, name, QuotaLimitRPS)
+		return ErrOverQuota(QuotaLimitRPS, int64(q.tiers[name].RPS))
+	}
+	return nil
+}
+
+// TakeCall accounts an RPC call against the daily budgets of the client.
+// Calls over a budget are rejected and not accounted.
+func (q *Quotas) TakeCall(ctx context.Context, method string) error {
+	key := GetAuthCtx(ctx)
+	name, tier := q.tier(key)
+	if tier == nil {
+		return nil
+	}
+
+	day := q.today()
+	call := Usage{Requests: 1, ComputeUnits: q.weight(method)}
+	max := Usage{Requests: tier.DailyRequests, ComputeUnits: tier.DailyComputeUnits}
+	total, ok, err := q.usage.Add(ctx, key, day, call, max)
+	if err != nil {
+		log.Warn("error taking quota", "auth", key, "err", err)
+		return ErrInternal
+	}
+	if ok {
+		RecordQuotaUsage(key, name, call)
+		return nil
+	}
+
+	if max.Requests > 0 && total.Requests+call.Requests > max.Requests {
+		RecordQuotaExceeded(key, name, QuotaLimitDailyRequests)
+		return ErrOverQuota(QuotaLimitDailyRequests, max.Requests)
+	}
+	RecordQuotaExceeded(key, name, QuotaLimitDailyComputeUnits)
+	return ErrOverQuota(QuotaLimitDailyComputeUnits, max.ComputeUnits)
+}
+
+// TakeMessage applies the quotas of the client to a WebSocket message, which counts as a request of a single call
+func (q *Quotas) TakeMessage(ctx context.Context, method string) error {
+	if err := q.TakeRequest(ctx); err != nil {
+		return err
+	}
+	return q.TakeCall(ctx, method)
+}
+
+// Usage returns the usage of the client during the day, or during the current day if empty
+func (q *Quotas) Usage(ctx context.Context, key string, day string) (*KeyUsage, error) {
+	if day == "" {
+		day = q.today()
+	}
+	usage, err := q.usage.Get(ctx, key, day)
+	if err != nil {
+		return nil, err
+	}
+	name, tier := q.tier(key)
+	return &KeyUsage{
+		Key:    key,
+		Tier:   name,
+		Day:    day,
+		Usage:  usage,
+		Limits: tier,
+	}, nil
+}
+
+// ResetUsage resets the usage of the client during the day, or during the current day if empty
+func (q *Quotas) ResetUsage(ctx context.Context, key string, day string) error {
+	if day == "" {
+		day = q.today()
+	}
+	return q.usage.Reset(ctx, key, day)
+}
@@ -0,0 +1,214 @@
+package proxyd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageStore(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	require.NoError(t, err)
+	defer redisServer.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("127.0.0.1:%s", redisServer.Port()),
+	})
+
+	stores := []struct {
+		name  string
+		store UsageStore
+	}{
+		{"memory", NewMemoryUsageStore()},
+		{"redis", NewRedisUsageStore(redisClient, "")},
+	}
+	for _, tt := range stores {
+		store := tt.store
+		ctx := context.Background()
+		t.Run(tt.name, func(t *testing.T) {
+			usage, err := store.Get(ctx, "foo", "2023-01-01")
+			require.NoError(t, err)
+			require.Equal(t, Usage{}, usage)
+
+			_, _, err = store.Add(ctx, "foo", "2023-01-01", Usage{Requests: 1, ComputeUnits: 5}, Usage{})
+			require.NoError(t, err)
+			usage, ok, err := store.Add(ctx, "foo", "2023-01-01", Usage{Requests: 1, ComputeUnits: 2}, Usage{Requests: 2})
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, Usage{Requests: 2, ComputeUnits: 7}, usage)
+			usage, ok, err = store.Add(ctx, "foo", "2023-01-01", Usage{Requests: 1, ComputeUnits: 2}, Usage{Requests: 3, ComputeUnits: 8})
+			require.NoError(t, err)
+			require.False(t, ok, "should not go over the limits")
+			require.Equal(t, Usage{Requests: 2, ComputeUnits: 7}, usage)
+			_, _, err = store.Add(ctx, "bar", "2023-01-01", Usage{Requests: 1, ComputeUnits: 1}, Usage{})
+			require.NoError(t, err)
+			_, _, err = store.Add(ctx, "foo", "2023-01-02", Usage{Requests: 1, ComputeUnits: 1}, Usage{})
+			require.NoError(t, err)
+
+			usage, err = store.Get(ctx, "foo", "2023-01-01")
+			require.NoError(t, err)
+			require.Equal(t, Usage{Requests: 2, ComputeUnits: 7}, usage)
+
+			require.NoError(t, store.Reset(ctx, "foo", "2023-01-01"))
+			usage, err = store.Get(ctx, "foo", "2023-01-01")
+			require.NoError(t, err)
+			require.Equal(t, Usage{}, usage)
+			usage, err = store.Get(ctx, "bar", "2023-01-01")
+			require.NoError(t, err)
+			require.Equal(t, Usage{Requests: 1, ComputeUnits: 1}, usage)
+		})
+	}
+
+	t.Run("memory store drops old days", func(t *testing.T) {
+		store := NewMemoryUsageStore()
+		ctx := context.Background()
+		_, _, _ = store.Add(ctx, "foo", "2023-01-01", Usage{Requests: 1}, Usage{})
+		_, _, _ = store.Add(ctx, "foo", "2023-01-02", Usage{Requests: 1}, Usage{})
+		usage, _ := store.Get(ctx, "foo", "2023-01-01")
+		require.Equal(t, int64(1), usage.Requests)
+		_, _, _ = store.Add(ctx, "foo", "2023-01-03", Usage{Requests: 1}, Usage{})
+		usage, _ = store.Get(ctx, "foo", "2023-01-01")
+		require.Equal(t, Usage{}, usage)
+	})
+}
+
+func TestUsageStoreConcurrentAdd(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	require.NoError(t, err)
+	defer redisServer.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("127.0.0.1:%s", redisServer.Port()),
+	})
+
+	for _, store := range []UsageStore{NewMemoryUsageStore(), NewRedisUsageStore(redisClient, "")} {
+		ctx := context.Background()
+		var wg sync.WaitGroup
+		var added int64
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, ok, err := store.Add(ctx, "foo", "2023-01-01", Usage{Requests: 1}, Usage{Requests: 5})
+				require.NoError(t, err)
+				if ok {
+					atomic.AddInt64(&added, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int64(5), atomic.LoadInt64(&added))
+		usage, err := store.Get(ctx, "foo", "2023-01-01")
+		require.NoError(t, err)
+		require.Equal(t, Usage{Requests: 5}, usage)
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	cfg := QuotasConfig{
+		Enabled:     true,
+		DefaultTier: "free",
+		Tiers: map[string]*QuotaTierConfig{
+			"free":    {RPS: 2, DailyRequests: 3},
+			"partner": {DailyComputeUnits: 10},
+		},
+		Keys:          map[string]string{"partner_a": "partner"},
+		MethodWeights: map[string]int{"eth_call": 4},
+	}
+	quotas, err := NewQuotas(cfg, nil, "")
+	require.NoError(t, err)
+	now := time.Date(2023, 1, 1, 23, 0, 0, 0, time.UTC)
+	quotas.now = func() time.Time { return now }
+
+	clientCtx := func(key string) context.Context {
+		return context.WithValue(context.Background(), ContextKeyAuth, key) // nolint:staticcheck
+	}
+	requireOverQuota := func(t *testing.T, err error, limit string, max int64) {
+		rpcErr, ok := err.(*RPCErr)
+		require.True(t, ok)
+		require.Equal(t, ErrOverQuota(limit, max), rpcErr)
+	}
+
+	t.Run("rps", func(t *testing.T) {
+		ctx := clientCtx("other")
+		require.NoError(t, quotas.TakeRequest(ctx))
+		require.NoError(t, quotas.TakeRequest(ctx))
+		requireOverQuota(t, quotas.TakeRequest(ctx), QuotaLimitRPS, 2)
+		require.NoError(t, quotas.TakeRequest(clientCtx("partner_a")), "partner tier has no rps limit")
+	})
+
+	t.Run("daily requests", func(t *testing.T) {
+		ctx := clientCtx("other")
+		for i := 0; i < 3; i++ {
+			require.NoError(t, quotas.TakeCall(ctx, "eth_call"))
+		}
+		requireOverQuota(t, quotas.TakeCall(ctx, "eth_chainId"), QuotaLimitDailyRequests, 3)
+
+		usage, err := quotas.Usage(ctx, "other", "")
+		require.NoError(t, err)
+		require.Equal(t, &KeyUsage{
+			Key:    "other",
+			Tier:   "free",
+			Day:    "2023-01-01",
+			Usage:  Usage{Requests: 3, ComputeUnits: 12},
+			Limits: cfg.Tiers["free"],
+		}, usage, "rejected calls should not be accounted")
+
+		// budgets reset with the day
+		now = now.Add(time.Hour)
+		require.NoError(t, quotas.TakeCall(ctx, "eth_chainId"))
+		usage, err = quotas.Usage(ctx, "other", "2023-01-01")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), usage.Usage.Requests)
+	})
+
+	t.Run("daily compute units", func(t *testing.T) {
+		ctx := clientCtx("partner_a")
+		require.NoError(t, quotas.TakeCall(ctx, "eth_call"))
+		require.NoError(t, quotas.TakeCall(ctx, "eth_call"))
+		requireOverQuota(t, quotas.TakeCall(ctx, "eth_call"), QuotaLimitDailyComputeUnits, 10)
+		require.NoError(t, quotas.TakeCall(ctx, "eth_chainId"))
+
+		require.NoError(t, quotas.ResetUsage(ctx, "partner_a", ""))
+		require.NoError(t, quotas.TakeCall(ctx, "eth_call"))
+	})
+
+	t.Run("clients without a tier are unlimited", func(t *testing.T) {
+		cfg := cfg
+		cfg.DefaultTier = ""
+		quotas, err := NewQuotas(cfg, nil, "")
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, quotas.TakeRequest(clientCtx("other")))
+			require.NoError(t, quotas.TakeCall(clientCtx("other"), "eth_call"))
+		}
+	})
+}
+
+func TestNewQuotasInvalidConfig(t *testing.T) {
+	tiers := map[string]*QuotaTierConfig{"free": {RPS: 1}}
+	tests := []struct {
+		name string
+		cfg  QuotasConfig
+		err  string
+	}{
+		{"undefined default tier", QuotasConfig{Tiers: tiers, DefaultTier: "paid"}, "default quota tier paid is not defined"},
+		{"undefined key tier", QuotasConfig{Tiers: tiers, Keys: map[string]string{"a": "paid"}}, "quota tier paid of key a is not defined"},
+		{"negative limit", QuotasConfig{Tiers: map[string]*QuotaTierConfig{"free": {RPS: -1}}}, "limits of quota tier free must be >= 0"},
+		{"zero weight", QuotasConfig{MethodWeights: map[string]int{"eth_call": 0}}, "weight of method eth_call must be > 0"},
+		{"redis", QuotasConfig{UseRedis: true}, "must specify a Redis URL if UseRedis is true in quotas config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewQuotas(tt.cfg, nil, "")
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
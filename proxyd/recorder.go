@@ -0,0 +1,321 @@
+package proxyd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultRecorderMaxFileSize = 100 * 1024 * 1024
+	defaultRecorderMaxFiles    = 10
+	recorderQueueSize          = 1000
+	recordingFilePrefix        = "recording-"
+	recordingFileExt           = ".ndjson"
+	recordingFileTimeFormat    = "20060102T150405.000000000"
+	// redactedPlaceholder replaces the requests and responses that can't be redacted
+	redactedPlaceholder = "<redacted>"
+)
+
+// Recording is a request received by proxyd along with the response it sent, as written by the Recorder
+type Recording struct {
+	Time     time.Time       `json:"time"`
+	ReqID    string          `json:"reqId"`
+	Auth     string          `json:"auth"`
+	Status   int             `json:"status"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+	// Redacted is set when the params and results of some calls were removed, so the request can't be replayed
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// Recorder samples the requests received by proxyd and their responses, and writes them to rotating
+// newline-delimited JSON files. Recordings are written in the background and dropped when the writer lags behind.
+type Recorder struct {
+	dir         string
+	sampleRate  float64
+	maxFileSize int64
+	maxFiles    int
+	redacted    map[string]bool
+
+	queue     chan *Recording
+	done      chan struct{}
+	closeOnce sync.Once
+
+	file     *os.File
+	fileSize int64
+}
+
+func NewRecorder(cfg RecorderConfig) (*Recorder, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("must specify a path for the recordings")
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return nil, errors.New("sample_rate must be between 0 and 1")
+	}
+	if err := os.MkdirAll(cfg.Path, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating recordings directory: %w", err)
+	}
+
+	sampleRate := cfg.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+	maxFileSize := cfg.MaxFileSizeBytes
+	if maxFileSize == 0 {
+		maxFileSize = defaultRecorderMaxFileSize
+	}
+	maxFiles := cfg.MaxFiles
+	if maxFiles == 0 {
+		maxFiles = defaultRecorderMaxFiles
+	}
+	redacted := make(map[string]bool)
+	for _, method := range cfg.RedactMethods {
+		redacted[method] = true
+	}
+
+	r := &Recorder{
+		dir:         cfg.Path,
+		sampleRate:  sampleRate,
+		maxFileSize: maxFileSize,
+		maxFiles:    maxFiles,
+		redacted:    redacted,
+		queue:       make(chan *Recording, recorderQueueSize),
+		done:        make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Sample decides whether a request is recorded
+func (r *Recorder) Sample() bool {
+	return r.sampleRate >= 1 || rand.Float64() < r.sampleRate
+}
+
+// Record queues the recording of a request and of the response captured by the writer
+func (r *Recorder) Record(ctx context.Context, req []byte, w *recordingWriter) {
+	if req == nil {
+		return
+	}
+	rec := &Recording{
+		Time:     time.Now().UTC(),
+		ReqID:    GetReqID(ctx),
+		Auth:     GetAuthCtx(ctx),
+		Status:   w.status,
+		Request:  json.RawMessage(bytes.TrimSpace(req)),
+		Response: json.RawMessage(bytes.TrimSpace(w.body.Bytes())),
+	}
+	// redaction only relies on the request, so that it also applies when the response can't be parsed
+	if len(r.redacted) > 0 {
+		r.redact(rec)
+	}
+	// requests that can't be parsed are replayed as strings
+	if !json.Valid(rec.Request) {
+		rec.Request = mustMarshalJSON(string(rec.Request))
+	}
+	if !json.Valid(rec.Response) {
+		rec.Response = mustMarshalJSON(string(rec.Response))
+	}
+
+	select {
+	case r.queue <- rec:
+	default:
+		RecordRecorderDropped()
+	}
+}
+
+// redact removes the params and the results of the calls to the redacted methods.
+// Requests that can't be parsed may contain redacted calls, so they are replaced by a placeholder,
+// as are responses that can't be parsed to requests with redacted calls.
+func (r *Recorder) redact(rec *Recording) {
+	batch := IsBatch(rec.Request)
+	var reqs []map[string]json.RawMessage
+	if batch {
+		if json.Unmarshal(rec.Request, &reqs) != nil {
+			redactRequest(rec)
+			return
+		}
+	} else {
+		var req map[string]json.RawMessage
+		if json.Unmarshal(rec.Request, &req) != nil {
+			redactRequest(rec)
+			return
+		}
+		reqs = append(reqs, req)
+	}
+
+	redactedIDs := make(map[string]bool)
+	for _, req := range reqs {
+		var method string
+		if json.Unmarshal(req["method"], &method) != nil || !r.redacted[method] {
+			continue
+		}
+		delete(req, "params")
+		redactedIDs[string(req["id"])] = true
+	}
+	if len(redactedIDs) == 0 {
+		return
+	}
+	rec.Redacted = true
+	if batch {
+		rec.Request = mustMarshalJSON(reqs)
+	} else {
+		rec.Request = mustMarshalJSON(reqs[0])
+	}
+
+	var res []map[string]json.RawMessage
+	if batch {
+		if json.Unmarshal(rec.Response, &res) != nil {
+			rec.Response = mustMarshalJSON(redactedPlaceholder)
+			return
+		}
+	} else {
+		var single map[string]json.RawMessage
+		if json.Unmarshal(rec.Response, &single) != nil {
+			rec.Response = mustMarshalJSON(redactedPlaceholder)
+			return
+		}
+		res = append(res, single)
+	}
+	for _, res := range res {
+		if redactedIDs[string(res["id"])] {
+			delete(res, "result")
+			delete(res, "error")
+		}
+	}
+	if batch {
+		rec.Response = mustMarshalJSON(res)
+	} else {
+		rec.Response = mustMarshalJSON(res[0])
+	}
+}
+
+// redactRequest replaces the whole request by a placeholder
+func redactRequest(rec *Recording) {
+	rec.Redacted = true
+	rec.Request = mustMarshalJSON(redactedPlaceholder)
+}
+
+// Close writes the queued recordings and closes the current file
+func (r *Recorder) Close() {
+	r.closeOnce.Do(func() {
+		close(r.queue)
+		<-r.done
+	})
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	for rec := range r.queue {
+		if err := r.write(rec); err != nil {
+			log.Error("error writing recording", "req_id", rec.ReqID, "err", err)
+			RecordRecorderDropped()
+		}
+	}
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			log.Error("error closing recording file", "err", err)
+		}
+	}
+}
+
+func (r *Recorder) write(rec *Recording) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if r.file == nil || r.fileSize+int64(len(line)) > r.maxFileSize {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.file.Write(line)
+	r.fileSize += int64(n)
+	if err != nil {
+		return err
+	}
+	RecordRecordedRequest()
+	return nil
+}
+
+// rotate opens a new file, and removes the oldest files beyond the maximum number of files
+func (r *Recorder) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			log.Error("error closing recording file", "err", err)
+		}
+		r.file = nil
+	}
+
+	name := filepath.Join(r.dir, recordingFilePrefix+time.Now().UTC().Format(recordingFileTimeFormat)+recordingFileExt)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.fileSize = 0
+
+	files, err := RecordingFiles(r.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > r.maxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// RecordingFiles returns the recording files of a directory, from the oldest to the newest
+func RecordingFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, recordingFilePrefix) && strings.HasSuffix(name, recordingFileExt) {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// recordingWriter captures the response written to the client
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newRecordingWriter(w http.ResponseWriter) *recordingWriter {
+	return &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,116 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func recordResponse(t *testing.T, r *Recorder, ctx context.Context, req string, res string) {
+	rw := newRecordingWriter(httptest.NewRecorder())
+	_, err := rw.Write([]byte(res))
+	require.NoError(t, err)
+	r.Record(ctx, []byte(req), rw)
+}
+
+func readAllRecordings(t *testing.T, dir string) []*Recording {
+	files, err := RecordingFiles(dir)
+	require.NoError(t, err)
+	recs := make([]*Recording, 0)
+	for _, file := range files {
+		fileRecs, err := ReadRecordings(file)
+		require.NoError(t, err)
+		recs = append(recs, fileRecs...)
+	}
+	return recs
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(RecorderConfig{Path: dir, RedactMethods: []string{"eth_sendRawTransaction"}})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), ContextKeyReqID, "req1") // nolint:staticcheck
+	recordResponse(t, r, ctx,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`,
+		`{"jsonrpc":"2.0","id":1,"result":"0xa"}`+"\n")
+	recordResponse(t, r, ctx,
+		`[{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x01"]},{"jsonrpc":"2.0","id":2,"method":"eth_chainId"}]`,
+		`[{"jsonrpc":"2.0","id":1,"result":"0x02"},{"jsonrpc":"2.0","id":2,"result":"0xa"}]`)
+	recordResponse(t, r, ctx, `not json`, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`)
+	recordResponse(t, r, ctx, `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x01"]}`, `internal error: 0x01`)
+	// requests rejected before their body is read are not recorded
+	r.Record(ctx, nil, newRecordingWriter(httptest.NewRecorder()))
+	r.Close()
+
+	recs := readAllRecordings(t, dir)
+	require.Len(t, recs, 4)
+	require.Equal(t, "req1", recs[0].ReqID)
+	require.Equal(t, "none", recs[0].Auth)
+	require.Equal(t, 200, recs[0].Status)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`, string(recs[0].Request))
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":"0xa"}`, string(recs[0].Response))
+	require.False(t, recs[0].Redacted)
+
+	require.True(t, recs[1].Redacted)
+	require.JSONEq(t, `[{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction"},{"jsonrpc":"2.0","id":2,"method":"eth_chainId"}]`, string(recs[1].Request))
+	require.JSONEq(t, `[{"jsonrpc":"2.0","id":1},{"jsonrpc":"2.0","id":2,"result":"0xa"}]`, string(recs[1].Response))
+
+	// requests that can't be parsed may contain redacted calls
+	require.True(t, recs[2].Redacted)
+	var body string
+	require.NoError(t, json.Unmarshal(recs[2].Request, &body))
+	require.Equal(t, redactedPlaceholder, body)
+
+	// responses that can't be parsed are redacted as a whole
+	require.True(t, recs[3].Redacted)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction"}`, string(recs[3].Request))
+	require.NoError(t, json.Unmarshal(recs[3].Response, &body))
+	require.Equal(t, redactedPlaceholder, body)
+}
+
+func TestRecorderRotation(t *testing.T) {
+	dir := t.TempDir()
+	// each recording is larger than the files, so every recording starts a new file
+	r, err := NewRecorder(RecorderConfig{Path: dir, MaxFileSizeBytes: 10, MaxFiles: 2})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		recordResponse(t, r, context.Background(), `{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`, `{"jsonrpc":"2.0","id":1,"result":"0xa"}`)
+	}
+	r.Close()
+
+	files, err := RecordingFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Len(t, readAllRecordings(t, dir), 2)
+
+	// other files are left alone
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0o644))
+	files, err = RecordingFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestRecorderSampling(t *testing.T) {
+	r, err := NewRecorder(RecorderConfig{Path: t.TempDir(), SampleRate: 0.5})
+	require.NoError(t, err)
+	defer r.Close()
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if r.Sample() {
+			sampled++
+		}
+	}
+	require.Greater(t, sampled, 350)
+	require.Less(t, sampled, 650)
+
+	_, err = NewRecorder(RecorderConfig{Path: t.TempDir(), SampleRate: 2})
+	require.EqualError(t, err, "sample_rate must be between 0 and 1")
+	_, err = NewRecorder(RecorderConfig{})
+	require.EqualError(t, err, "must specify a path for the recordings")
+}
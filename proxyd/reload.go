@@ -0,0 +1,132 @@
+package proxyd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const reloadConsensusTimeout = 30 * time.Second
+
+// Reload applies a new configuration to the running server without dropping client connections.
+// Backends, backend groups, method mappings, the WS method whitelist, rate limiters and consensus
+// pollers are replaced atomically, while requests in flight complete with the previous configuration.
+// Changes to the other sections require a restart and are ignored.
+func (s *Server) Reload(config *Config) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if err := s.reload(config); err != nil {
+		RecordConfigReload(false)
+		return err
+	}
+	RecordConfigReload(true)
+	return nil
+}
+
+func (s *Server) reload(config *Config) error {
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+	if s.redisClient == nil && config.RateLimit.UseRedis {
+		return errors.New("must specify a Redis URL if UseRedis is true in rate limit config")
+	}
+	if (config.WSBackendGroup == "") != (s.config.WSBackendGroup == "") {
+		return errors.New("enabling or disabling the ws backend group requires a restart")
+	}
+	warnRestartRequired(s.config, config)
+
+	backendsByName, err := buildBackends(config, s.rpcRequestSemaphore, s.config, s.backendsByName)
+	if err != nil {
+		return err
+	}
+	backendGroups, wsBackendGroup, err := buildBackendGroups(config, backendsByName)
+	if err != nil {
+		return err
+	}
+	routes, err := newServerRoutes(
+		backendGroups,
+		wsBackendGroup,
+		NewStringSetFromStrings(config.WSMethodWhitelist),
+		config.RPCMethodMappings,
+		config.RateLimit,
+		config.SenderRateLimit,
+		s.redisClient,
+	)
+	if err != nil {
+		return err
+	}
+	for name, bg := range backendGroups {
+		log.Info("configured backend group", "name", name, "backends", backendNames(bg.Backends))
+	}
+
+	// the new consensus pollers start without any state, so they are updated once
+	// before the swap to avoid serving consensus aware groups without a consensus
+	startConsensusPollers(config, backendGroups)
+	ctx, cancel := context.WithTimeout(context.Background(), reloadConsensusTimeout)
+	defer cancel()
+	for _, bg := range backendGroups {
+		if bg.Consensus == nil {
+			continue
+		}
+		for _, be := range bg.Backends {
+			bg.Consensus.UpdateBackend(ctx, be)
+		}
+		bg.Consensus.UpdateBackendGroupConsensus(ctx)
+	}
+
+	prev := s.getRoutes()
+	s.routes.Store(routes)
+	if s.wsSubscriptions != nil && wsBackendGroup != nil {
+		s.wsSubscriptions.SetBackendGroup(wsBackendGroup)
+	}
+	s.config = config
+	s.backendsByName = backendsByName
+
+	// requests in flight may still use the previous groups, which keep working without their pollers
+	for _, bg := range prev.backendGroups {
+		bg.Shutdown()
+	}
+	log.Info("reloaded config")
+	return nil
+}
+
+func backendConfigChanged(prev *Config, next *Config, name string) bool {
+	prevCfg, ok := prev.Backends[name]
+	if !ok {
+		return true
+	}
+	return !reflect.DeepEqual(prevCfg, next.Backends[name]) ||
+		!reflect.DeepEqual(prev.BackendOptions, next.BackendOptions)
+}
+
+func warnRestartRequired(prev *Config, next *Config) {
+	sections := map[string][2]interface{}{
+		"server":                   {prev.Server, next.Server},
+		"cache.enabled":            {prev.Cache.Enabled, next.Cache.Enabled},
+		"redis":                    {prev.Redis, next.Redis},
+		"metrics":                  {prev.Metrics, next.Metrics},
+		"batch":                    {prev.BatchConfig, next.BatchConfig},
+		"authentication":           {prev.Authentication, next.Authentication},
+		"quotas":                   {prev.Quotas, next.Quotas},
+		"recorder":                 {prev.Recorder, next.Recorder},
+		"whitelist_error_message":  {prev.WhitelistErrorMessage, next.WhitelistErrorMessage},
+		"rate_limit.error_message": {prev.RateLimit.ErrorMessage, next.RateLimit.ErrorMessage},
+	}
+	for name, values := range sections {
+		if !reflect.DeepEqual(values[0], values[1]) {
+			log.Warn("ignoring config change that requires a restart", "section", name)
+		}
+	}
+}
+
+func backendNames(backends []*Backend) []string {
+	names := make([]string, 0, len(backends))
+	for _, be := range backends {
+		names = append(names, be.Name)
+	}
+	return names
+}
@@ -0,0 +1,140 @@
+package proxyd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const maxRecordingLineSize = 64 * 1024 * 1024
+
+// ReplayResult is the outcome of replaying a recording
+type ReplayResult struct {
+	Recording *Recording
+	Status    int
+	Response  json.RawMessage
+	Match     bool
+}
+
+// ReplaySummary counts the outcomes of a replay
+type ReplaySummary struct {
+	Replayed   int
+	Matched    int
+	Mismatched int
+	Skipped    int
+}
+
+// ReadRecordings reads the recordings of a file written by the Recorder
+func ReadRecordings(path string) ([]*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	recs := make([]*Recording, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordingLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		rec := new(Recording)
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return nil, fmt.Errorf("error parsing recording at %s:%d: %w", path, line, err)
+		}
+		recs = append(recs, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// Replay sends the recorded requests to the URL one after the other, in the recorded order, and compares
+// the responses with the recorded ones. Redacted recordings can't be replayed and are skipped.
+// The recordings only hold the alias of the authentication key, so requests recorded with an alias in authKeys
+// are sent to the authenticated path of the key it maps to, and the other requests to the URL itself.
+// onResult is called with the outcome of each replayed recording.
+func Replay(ctx context.Context, client *http.Client, url string, authKeys map[string]string, recs []*Recording, onResult func(*ReplayResult)) (ReplaySummary, error) {
+	var summary ReplaySummary
+	for _, rec := range recs {
+		if rec.Redacted {
+			summary.Skipped++
+			continue
+		}
+		target := url
+		if key := authKeys[rec.Auth]; key != "" {
+			target = strings.TrimSuffix(url, "/") + "/" + key
+		}
+		status, body, err := replayRequest(ctx, client, target, recordedRequestBody(rec))
+		if err != nil {
+			return summary, fmt.Errorf("error replaying request %s: %w", rec.ReqID, err)
+		}
+		res := &ReplayResult{
+			Recording: rec,
+			Status:    status,
+			Response:  body,
+			Match:     status == rec.Status && EqualResponses(rec.Response, body),
+		}
+		summary.Replayed++
+		if res.Match {
+			summary.Matched++
+		} else {
+			summary.Mismatched++
+		}
+		if onResult != nil {
+			onResult(res)
+		}
+	}
+	return summary, nil
+}
+
+// recordedRequestBody returns the body of the recorded request, which is recorded as a string if it isn't JSON
+func recordedRequestBody(rec *Recording) []byte {
+	var s string
+	if json.Unmarshal(rec.Request, &s) == nil {
+		return []byte(s)
+	}
+	return rec.Request
+}
+
+func replayRequest(ctx context.Context, client *http.Client, url string, body []byte) (int, json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	resBody = bytes.TrimSpace(resBody)
+	if !json.Valid(resBody) {
+		resBody = mustMarshalJSON(string(resBody))
+	}
+	return res.StatusCode, resBody, nil
+}
+
+// EqualResponses compares JSON-RPC responses regardless of the formatting and of the order of the object keys
+func EqualResponses(expected json.RawMessage, actual json.RawMessage) bool {
+	var e, a interface{}
+	if json.Unmarshal(expected, &e) != nil || json.Unmarshal(actual, &a) != nil {
+		return bytes.Equal(expected, actual)
+	}
+	return reflect.DeepEqual(e, a)
+}
@@ -0,0 +1,89 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqualResponses(t *testing.T) {
+	require.True(t, EqualResponses(
+		json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{"a":1,"b":[1,2]}}`),
+		json.RawMessage(`{ "result": {"b":[1,2],"a":1}, "id":1, "jsonrpc":"2.0" }`),
+	))
+	require.False(t, EqualResponses(
+		json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{"b":[1,2]}}`),
+		json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{"b":[2,1]}}`),
+	))
+	require.False(t, EqualResponses(json.RawMessage(`"a"`), json.RawMessage(`"b"`)))
+}
+
+func TestReplay(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		var req RPCReq
+		if json.Unmarshal(body, &req) != nil {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + req.Method + `"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "recording.ndjson")
+	lines := `{"reqId":"1","status":200,"request":{"jsonrpc":"2.0","id":1,"method":"eth_chainId"},"response":{"jsonrpc":"2.0","id":1,"result":"eth_chainId"}}
+{"reqId":"2","status":200,"request":{"jsonrpc":"2.0","id":1,"method":"eth_call"},"response":{"jsonrpc":"2.0","id":1,"result":"0x"}}
+
+{"reqId":"3","status":200,"request":{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction"},"response":{"jsonrpc":"2.0","id":1},"redacted":true}
+{"reqId":"4","status":400,"request":"not json","response":{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}}
+`
+	require.NoError(t, os.WriteFile(path, []byte(lines), 0o644))
+	recs, err := ReadRecordings(path)
+	require.NoError(t, err)
+	require.Len(t, recs, 4)
+
+	mismatches := make([]string, 0)
+	summary, err := Replay(context.Background(), http.DefaultClient, server.URL, nil, recs, func(res *ReplayResult) {
+		if !res.Match {
+			mismatches = append(mismatches, res.Recording.ReqID)
+			require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":"eth_call"}`, string(res.Response))
+		}
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReplaySummary{Replayed: 3, Matched: 2, Mismatched: 1, Skipped: 1}, summary)
+	require.Equal(t, []string{"2"}, mismatches)
+	require.Len(t, received, 3)
+	require.Equal(t, "not json", received[2])
+}
+
+func TestReplayAuthenticated(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/secret" {
+			w.WriteHeader(401)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	recs := []*Recording{
+		{ReqID: "1", Auth: "alice", Status: 200, Request: json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`), Response: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`)},
+		{ReqID: "2", Auth: "bob", Status: 200, Request: json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`), Response: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`)},
+	}
+	summary, err := Replay(context.Background(), http.DefaultClient, server.URL+"/", map[string]string{"alice": "secret"}, recs, nil)
+	require.NoError(t, err)
+	require.Equal(t, ReplaySummary{Replayed: 2, Matched: 1, Mismatched: 1}, summary)
+	require.Equal(t, []string{"/secret", "/"}, paths)
+}
@@ -0,0 +1,346 @@
+package proxyd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type RoutingStrategy string
+
+const (
+	// RoutingStrategyFallback tries the backends in the configured order,
+	// or in random order for consensus aware groups
+	RoutingStrategyFallback RoutingStrategy = "fallback"
+	// RoutingStrategyWeightedRoundRobin spreads requests across the backends in proportion to their weights
+	RoutingStrategyWeightedRoundRobin RoutingStrategy = "weighted_round_robin"
+	// RoutingStrategyLeastLatency tries the backends with the lowest average latency first
+	RoutingStrategyLeastLatency RoutingStrategy = "least_latency"
+	// RoutingStrategyHedged sends the request to a second backend
+	// when the first one hasn't responded within a latency percentile
+	RoutingStrategyHedged RoutingStrategy = "hedged"
+)
+
+const (
+	defaultHedgePercentile = 0.95
+	defaultHedgeDelay      = 100 * time.Millisecond
+	hedgeLatencySamples    = 1000
+	hedgeMinSamples        = 20
+)
+
+// hedgedMethods are the read-only methods that may be sent to two backends by the hedged strategy.
+// Requests for other methods, such as eth_sendRawTransaction, use the regular failover.
+var hedgedMethods = map[string]bool{
+	"eth_blockNumber":                         true,
+	"eth_chainId":                             true,
+	"net_version":                             true,
+	"eth_gasPrice":                            true,
+	"eth_maxPriorityFeePerGas":                true,
+	"eth_feeHistory":                          true,
+	"eth_estimateGas":                         true,
+	"eth_call":                                true,
+	"eth_getBalance":                          true,
+	"eth_getCode":                             true,
+	"eth_getStorageAt":                        true,
+	"eth_getTransactionCount":                 true,
+	"eth_getProof":                            true,
+	"eth_getLogs":                             true,
+	"eth_getBlockByNumber":                    true,
+	"eth_getBlockByHash":                      true,
+	"eth_getBlockTransactionCountByNumber":    true,
+	"eth_getBlockTransactionCountByHash":      true,
+	"eth_getTransactionByHash":                true,
+	"eth_getTransactionByBlockNumberAndIndex": true,
+	"eth_getTransactionByBlockHashAndIndex":   true,
+	"eth_getTransactionReceipt":               true,
+	"eth_getUncleByBlockHashAndIndex":         true,
+	"eth_getUncleByBlockNumberAndIndex":       true,
+	"eth_getUncleCountByBlockHash":            true,
+	"eth_getUncleCountByBlockNumber":          true,
+	"debug_getRawReceipts":                    true,
+	"alchemy_getTransactionReceipts":          true,
+}
+
+// canHedge returns whether all the requests are for read-only methods that can be hedged
+func canHedge(rpcReqs []*RPCReq) bool {
+	for _, req := range rpcReqs {
+		if !hedgedMethods[req.Method] {
+			return false
+		}
+	}
+	return true
+}
+
+// Router decides in which order the backends of a group are tried
+type Router interface {
+	Strategy() RoutingStrategy
+	Order(backends []*Backend) []*Backend
+}
+
+// NewRouter creates the router for a backend group.
+// A nil router is returned for the fallback strategy, which keeps the order of the backends.
+func NewRouter(cfg *BackendGroupConfig) (Router, error) {
+	for name, weight := range cfg.Weights {
+		if weight <= 0 {
+			return nil, fmt.Errorf("weight of backend %s must be > 0", name)
+		}
+		if !containsString(cfg.Backends, name) {
+			return nil, fmt.Errorf("weight defined for backend %s, which is not in the group", name)
+		}
+	}
+
+	switch cfg.RoutingStrategy {
+	case "", RoutingStrategyFallback:
+		return nil, nil
+	case RoutingStrategyWeightedRoundRobin:
+		return newWeightedRoundRobinRouter(cfg.Weights), nil
+	case RoutingStrategyLeastLatency:
+		return &leastLatencyRouter{}, nil
+	case RoutingStrategyHedged:
+		percentile := cfg.HedgePercentile
+		if percentile == 0 {
+			percentile = defaultHedgePercentile
+		}
+		if percentile <= 0 || percentile >= 1 {
+			return nil, errors.New("hedge_percentile must be between 0 and 1")
+		}
+		delay := time.Duration(cfg.HedgeDelay)
+		if delay == 0 {
+			delay = defaultHedgeDelay
+		}
+		return newHedgedRouter(percentile, delay), nil
+	default:
+		return nil, fmt.Errorf("invalid routing strategy: %s", cfg.RoutingStrategy)
+	}
+}
+
+// weightedRoundRobinRouter picks the first backend with smooth weighted round-robin,
+// so that requests are spread evenly in proportion to the weights. The other backends follow
+// in their original order for failover. Backends without a weight have a weight of 1.
+type weightedRoundRobinRouter struct {
+	weights map[string]int
+
+	mtx     sync.Mutex
+	current map[string]int
+}
+
+func newWeightedRoundRobinRouter(weights map[string]int) *weightedRoundRobinRouter {
+	return &weightedRoundRobinRouter{
+		weights: weights,
+		current: make(map[string]int),
+	}
+}
+
+func (r *weightedRoundRobinRouter) Strategy() RoutingStrategy {
+	return RoutingStrategyWeightedRoundRobin
+}
+
+func (r *weightedRoundRobinRouter) weight(be *Backend) int {
+	if w, ok := r.weights[be.Name]; ok {
+		return w
+	}
+	return 1
+}
+
+func (r *weightedRoundRobinRouter) Order(backends []*Backend) []*Backend {
+	if len(backends) < 2 {
+		return backends
+	}
+	r.mtx.Lock()
+	total := 0
+	best := 0
+	for i, be := range backends {
+		w := r.weight(be)
+		total += w
+		r.current[be.Name] += w
+		if r.current[be.Name] > r.current[backends[best].Name] {
+			best = i
+		}
+	}
+	r.current[backends[best].Name] -= total
+	r.mtx.Unlock()
+
+	ordered := make([]*Backend, 0, len(backends))
+	ordered = append(ordered, backends[best])
+	ordered = append(ordered, backends[:best]...)
+	return append(ordered, backends[best+1:]...)
+}
+
+// leastLatencyRouter orders the backends by their average latency over the sliding window.
+// Backends without recent requests have no latency and are tried first, so their latency gets measured.
+type leastLatencyRouter struct{}
+
+func (r *leastLatencyRouter) Strategy() RoutingStrategy {
+	return RoutingStrategyLeastLatency
+}
+
+func (r *leastLatencyRouter) Order(backends []*Backend) []*Backend {
+	latencies := make(map[*Backend]float64, len(backends))
+	for _, be := range backends {
+		latencies[be] = be.latencySlidingWindow.Avg()
+	}
+	ordered := make([]*Backend, len(backends))
+	copy(ordered, backends)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return latencies[ordered[i]] < latencies[ordered[j]]
+	})
+	return ordered
+}
+
+// hedgedRouter keeps the order of the backends. Requests are forwarded by forwardHedged,
+// which sends the request to the next backend if the first one is slower than the latency percentile
+// of the recent requests of the group.
+type hedgedRouter struct {
+	percentile   float64
+	initialDelay time.Duration
+
+	mtx       sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+func newHedgedRouter(percentile float64, initialDelay time.Duration) *hedgedRouter {
+	return &hedgedRouter{
+		percentile:   percentile,
+		initialDelay: initialDelay,
+		latencies:    make([]time.Duration, 0, hedgeLatencySamples),
+	}
+}
+
+func (r *hedgedRouter) Strategy() RoutingStrategy {
+	return RoutingStrategyHedged
+}
+
+func (r *hedgedRouter) Order(backends []*Backend) []*Backend {
+	return backends
+}
+
+func (r *hedgedRouter) observe(latency time.Duration) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if len(r.latencies) < hedgeLatencySamples {
+		r.latencies = append(r.latencies, latency)
+		return
+	}
+	r.latencies[r.next] = latency
+	r.next = (r.next + 1) % hedgeLatencySamples
+}
+
+// delay returns how long to wait for a backend before hedging the request,
+// or the initial delay until enough latencies have been observed
+func (r *hedgedRouter) delay() time.Duration {
+	r.mtx.Lock()
+	if len(r.latencies) < hedgeMinSamples {
+		r.mtx.Unlock()
+		return r.initialDelay
+	}
+	sorted := make([]time.Duration, len(r.latencies))
+	copy(sorted, r.latencies)
+	r.mtx.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(r.percentile*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+type hedgedAttempt struct {
+	back     *Backend
+	res      []*RPCRes
+	err      error
+	hedge    bool
+	duration time.Duration
+}
+
+// forwardHedged forwards the requests to the first backend, and to the next one if the first hasn't
+// responded after the hedge delay. The first successful response is returned and the other request is cancelled.
+// Backends failing are replaced by the next backend, like with the regular failover.
+// It must only be used for requests that canHedge, as they may be processed by both backends.
+func (bg *BackendGroup) forwardHedged(ctx context.Context, router *hedgedRouter, backends []*Backend, rpcReqs []*RPCReq, isBatch bool) ([]*RPCRes, *Backend, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgedAttempt, len(backends))
+	next := 0
+	pending := 0
+	var primaryStart time.Time
+	launch := func(hedge bool) {
+		back := backends[next]
+		next++
+		pending++
+		start := time.Now()
+		if !hedge {
+			primaryStart = start
+		}
+		go func() {
+			res, err := back.Forward(ctx, rpcReqs, isBatch)
+			results <- hedgedAttempt{back: back, res: res, err: err, hedge: hedge, duration: time.Since(start)}
+		}()
+	}
+
+	delay := router.delay()
+	RecordHedgeDelay(bg, delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	launch(false)
+	hedged := false
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !hedged && next < len(backends) {
+				hedged = true
+				RecordHedgedRequest(bg)
+				log.Debug("hedging request", "group", bg.Name, "name", backends[next].Name, "delay", delay, "req_id", GetReqID(ctx))
+				launch(true)
+			}
+		case a := <-results:
+			pending--
+			if errors.Is(a.err, ErrConsensusGetReceiptsCantBeBatched) ||
+				errors.Is(a.err, ErrConsensusGetReceiptsInvalidTarget) ||
+				errors.Is(a.err, ErrMethodNotWhitelisted) {
+				return nil, nil, a.err
+			}
+			if a.err != nil {
+				log.Warn(
+					"error forwarding hedged request to backend",
+					"name", a.back.Name,
+					"req_id", GetReqID(ctx),
+					"auth", GetAuthCtx(ctx),
+					"err", a.err,
+				)
+				if next < len(backends) {
+					launch(a.hedge)
+				}
+				continue
+			}
+			if a.hedge {
+				// The primary backend is cancelled, but it has been at least this slow. Observing only
+				// the winners would lower the delay the more requests are hedged
+				router.observe(time.Since(primaryStart))
+				RecordHedgedRequestWin(bg)
+			} else {
+				router.observe(a.duration)
+			}
+			return a.res, a.back, nil
+		}
+	}
+	return nil, nil, ErrNoBackends
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
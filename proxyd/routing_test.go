@@ -0,0 +1,167 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+)
+
+func newTestBackends(names ...string) []*Backend {
+	sem := semaphore.NewWeighted(100)
+	backends := make([]*Backend, 0, len(names))
+	for _, name := range names {
+		backends = append(backends, NewBackend(name, "http://"+name, "", sem))
+	}
+	return backends
+}
+
+func backendOrder(backends []*Backend) []string {
+	names := make([]string, 0, len(backends))
+	for _, be := range backends {
+		names = append(names, be.Name)
+	}
+	return names
+}
+
+func TestNewRouter(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      BackendGroupConfig
+		strategy RoutingStrategy
+		err      string
+	}{
+		{name: "default", cfg: BackendGroupConfig{}},
+		{name: "fallback", cfg: BackendGroupConfig{RoutingStrategy: RoutingStrategyFallback}},
+		{name: "least latency", cfg: BackendGroupConfig{RoutingStrategy: RoutingStrategyLeastLatency}, strategy: RoutingStrategyLeastLatency},
+		{name: "hedged", cfg: BackendGroupConfig{RoutingStrategy: RoutingStrategyHedged}, strategy: RoutingStrategyHedged},
+		{
+			name:     "weighted",
+			cfg:      BackendGroupConfig{Backends: []string{"a"}, RoutingStrategy: RoutingStrategyWeightedRoundRobin, Weights: map[string]int{"a": 2}},
+			strategy: RoutingStrategyWeightedRoundRobin,
+		},
+		{name: "invalid strategy", cfg: BackendGroupConfig{RoutingStrategy: "random"}, err: "invalid routing strategy: random"},
+		{
+			name: "weight of unknown backend",
+			cfg:  BackendGroupConfig{Backends: []string{"a"}, Weights: map[string]int{"b": 1}},
+			err:  "weight defined for backend b, which is not in the group",
+		},
+		{
+			name: "zero weight",
+			cfg:  BackendGroupConfig{Backends: []string{"a"}, Weights: map[string]int{"a": 0}},
+			err:  "weight of backend a must be > 0",
+		},
+		{
+			name: "invalid percentile",
+			cfg:  BackendGroupConfig{RoutingStrategy: RoutingStrategyHedged, HedgePercentile: 1.5},
+			err:  "hedge_percentile must be between 0 and 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := NewRouter(&tt.cfg)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.strategy == "" {
+				require.Nil(t, router)
+			} else {
+				require.Equal(t, tt.strategy, router.Strategy())
+			}
+		})
+	}
+}
+
+func TestWeightedRoundRobinRouter(t *testing.T) {
+	backends := newTestBackends("a", "b", "c")
+	router := newWeightedRoundRobinRouter(map[string]int{"a": 3, "b": 2})
+
+	firsts := make([]string, 0)
+	for i := 0; i < 6; i++ {
+		ordered := router.Order(backends)
+		require.Len(t, ordered, 3)
+		firsts = append(firsts, ordered[0].Name)
+	}
+	// smooth weighted round-robin interleaves the backends instead of sending bursts to each
+	require.Equal(t, []string{"a", "b", "a", "c", "b", "a"}, firsts)
+
+	// the cycle repeats, with the other backends following in their original order
+	require.Equal(t, []string{"a", "b", "c"}, backendOrder(router.Order(backends)))
+	require.Equal(t, []string{"b", "a", "c"}, backendOrder(router.Order(backends)))
+}
+
+func TestLeastLatencyRouter(t *testing.T) {
+	backends := newTestBackends("a", "b", "c", "d")
+	backends[0].latencySlidingWindow.Add(float64(300 * time.Millisecond))
+	backends[1].latencySlidingWindow.Add(float64(100 * time.Millisecond))
+	backends[2].latencySlidingWindow.Add(float64(200 * time.Millisecond))
+
+	router := &leastLatencyRouter{}
+	// d has no latency yet, so it is tried first
+	require.Equal(t, []string{"d", "b", "c", "a"}, backendOrder(router.Order(backends)))
+	require.Equal(t, []string{"a", "b", "c", "d"}, backendOrder(backends), "should not modify the group")
+}
+
+func TestHedgedRouterDelay(t *testing.T) {
+	router := newHedgedRouter(0.9, 50*time.Millisecond)
+	for i := 1; i < hedgeMinSamples; i++ {
+		router.observe(time.Duration(i) * time.Millisecond)
+	}
+	require.Equal(t, 50*time.Millisecond, router.delay(), "should use the initial delay without enough samples")
+
+	router = newHedgedRouter(0.9, 50*time.Millisecond)
+	for i := 100; i > 0; i-- {
+		router.observe(time.Duration(i) * time.Millisecond)
+	}
+	require.Equal(t, 90*time.Millisecond, router.delay())
+
+	// old samples are replaced once the buffer is full
+	for i := 0; i < hedgeLatencySamples; i++ {
+		router.observe(time.Second)
+	}
+	require.Equal(t, time.Second, router.delay())
+}
+
+func TestForwardHedgedObservesPrimaryLatency(t *testing.T) {
+	newServer := func(latency time.Duration) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":"0x1","id":1}`))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	slow := newServer(time.Second)
+	fast := newServer(0)
+
+	sem := semaphore.NewWeighted(100)
+	backends := []*Backend{NewBackend("slow", slow.URL, "", sem), NewBackend("fast", fast.URL, "", sem)}
+	bg := &BackendGroup{Name: "hedged", Backends: backends}
+	router := newHedgedRouter(0.9, 50*time.Millisecond)
+
+	req := &RPCReq{JSONRPC: "2.0", Method: "eth_blockNumber", ID: json.RawMessage("1")}
+	_, back, err := bg.forwardHedged(context.Background(), router, backends, []*RPCReq{req}, false)
+	require.NoError(t, err)
+	require.Equal(t, "fast", back.Name)
+
+	// The latency of the losing primary backend is observed rather than the one of the hedge
+	require.Len(t, router.latencies, 1)
+	require.GreaterOrEqual(t, router.latencies[0], 50*time.Millisecond)
+}
+
+func TestCanHedge(t *testing.T) {
+	require.True(t, canHedge([]*RPCReq{{Method: "eth_call"}, {Method: "eth_getBalance"}}))
+	require.False(t, canHedge([]*RPCReq{{Method: "eth_sendRawTransaction"}}))
+	require.False(t, canHedge([]*RPCReq{{Method: "eth_call"}, {Method: "eth_sendRawTransaction"}}), "should not hedge batches with writes")
+}
@@ -15,8 +15,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -28,6 +30,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"golang.org/x/sync/semaphore"
 )
 
 const (
@@ -48,18 +51,39 @@ const (
 var emptyArrayResponse = json.RawMessage("[]")
 
 type Server struct {
-	BackendGroups          map[string]*BackendGroup
+	routes               atomic.Value // *serverRoutes
+	wsSubscriptions      *SubscriptionManager
+	maxBodySize          int64
+	enableRequestLog     bool
+	maxRequestBodyLogLen int
+	authenticatedPaths   map[string]string
+	timeout              time.Duration
+	maxUpstreamBatchSize int
+	maxBatchSize         int
+	upgrader             *websocket.Upgrader
+	redisClient          *redis.Client
+	rpcServer            *http.Server
+	wsServer             *http.Server
+	cache                RPCCache
+	quotas               *Quotas
+	recorder             *Recorder
+	adminKey             string
+	srvMu                sync.Mutex
+
+	// state needed to reload the configuration, see Reload
+	reloadMu            sync.Mutex
+	config              *Config
+	backendsByName      map[string]*Backend
+	rpcRequestSemaphore *semaphore.Weighted
+}
+
+// serverRoutes is the part of the server state that is replaced when the configuration is reloaded.
+// Requests keep using the routes they started with until they complete.
+type serverRoutes struct {
+	backendGroups          map[string]*BackendGroup
 	wsBackendGroup         *BackendGroup
 	wsMethodWhitelist      *StringSet
 	rpcMethodMappings      map[string]string
-	maxBodySize            int64
-	enableRequestLog       bool
-	maxRequestBodyLogLen   int
-	authenticatedPaths     map[string]string
-	timeout                time.Duration
-	maxUpstreamBatchSize   int
-	maxBatchSize           int
-	upgrader               *websocket.Upgrader
 	mainLim                FrontendRateLimiter
 	overrideLims           map[string]FrontendRateLimiter
 	senderLim              FrontendRateLimiter
@@ -67,10 +91,6 @@ type Server struct {
 	limExemptOrigins       []*regexp.Regexp
 	limExemptUserAgents    []*regexp.Regexp
 	globallyLimitedMethods map[string]bool
-	rpcServer              *http.Server
-	wsServer               *http.Server
-	cache                  RPCCache
-	srvMu                  sync.Mutex
 }
 
 type limiterFunc func(method string) bool
@@ -112,6 +132,52 @@ func NewServer(
 		maxBatchSize = MaxBatchRPCCallsHardLimit
 	}
 
+	routes, err := newServerRoutes(
+		backendGroups,
+		wsBackendGroup,
+		wsMethodWhitelist,
+		rpcMethodMappings,
+		rateLimitConfig,
+		senderRateLimitConfig,
+		redisClient,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var wsSubscriptions *SubscriptionManager
+	if wsBackendGroup != nil {
+		wsSubscriptions = NewSubscriptionManager(wsBackendGroup)
+	}
+
+	srv := &Server{
+		wsSubscriptions:      wsSubscriptions,
+		maxBodySize:          maxBodySize,
+		authenticatedPaths:   authenticatedPaths,
+		timeout:              timeout,
+		maxUpstreamBatchSize: maxUpstreamBatchSize,
+		cache:                cache,
+		enableRequestLog:     enableRequestLog,
+		maxRequestBodyLogLen: maxRequestBodyLogLen,
+		maxBatchSize:         maxBatchSize,
+		upgrader: &websocket.Upgrader{
+			HandshakeTimeout: defaultWSHandshakeTimeout,
+		},
+		redisClient: redisClient,
+	}
+	srv.routes.Store(routes)
+	return srv, nil
+}
+
+func newServerRoutes(
+	backendGroups map[string]*BackendGroup,
+	wsBackendGroup *BackendGroup,
+	wsMethodWhitelist *StringSet,
+	rpcMethodMappings map[string]string,
+	rateLimitConfig RateLimitConfig,
+	senderRateLimitConfig SenderRateLimitConfig,
+	redisClient *redis.Client,
+) (*serverRoutes, error) {
 	limiterFactory := func(dur time.Duration, max int, prefix string) FrontendRateLimiter {
 		if rateLimitConfig.UseRedis {
 			return NewRedisFrontendRateLimiter(redisClient, dur, max, prefix)
@@ -161,22 +227,11 @@ func NewServer(
 		senderLim = limiterFactory(time.Duration(senderRateLimitConfig.Interval), senderRateLimitConfig.Limit, "senders")
 	}
 
-	return &Server{
-		BackendGroups:        backendGroups,
-		wsBackendGroup:       wsBackendGroup,
-		wsMethodWhitelist:    wsMethodWhitelist,
-		rpcMethodMappings:    rpcMethodMappings,
-		maxBodySize:          maxBodySize,
-		authenticatedPaths:   authenticatedPaths,
-		timeout:              timeout,
-		maxUpstreamBatchSize: maxUpstreamBatchSize,
-		cache:                cache,
-		enableRequestLog:     enableRequestLog,
-		maxRequestBodyLogLen: maxRequestBodyLogLen,
-		maxBatchSize:         maxBatchSize,
-		upgrader: &websocket.Upgrader{
-			HandshakeTimeout: defaultWSHandshakeTimeout,
-		},
+	return &serverRoutes{
+		backendGroups:          backendGroups,
+		wsBackendGroup:         wsBackendGroup,
+		wsMethodWhitelist:      wsMethodWhitelist,
+		rpcMethodMappings:      rpcMethodMappings,
 		mainLim:                mainLim,
 		overrideLims:           overrideLims,
 		globallyLimitedMethods: globalMethodLims,
@@ -187,11 +242,23 @@ func NewServer(
 	}, nil
 }
 
+func (s *Server) getRoutes() *serverRoutes {
+	return s.routes.Load().(*serverRoutes)
+}
+
+// BackendGroups returns the backend groups of the current configuration
+func (s *Server) BackendGroups() map[string]*BackendGroup {
+	return s.getRoutes().backendGroups
+}
+
 func (s *Server) RPCListenAndServe(host string, port int) error {
 	s.srvMu.Lock()
 	hdlr := mux.NewRouter()
 	hdlr.HandleFunc("/healthz", s.HandleHealthz).Methods("GET")
 	hdlr.HandleFunc("/", s.HandleRPC).Methods("POST")
+	if s.adminKey != "" {
+		hdlr.HandleFunc("/admin", s.HandleAdminRPC).Methods("POST")
+	}
 	hdlr.HandleFunc("/{authorization}", s.HandleRPC).Methods("POST")
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
@@ -233,7 +300,13 @@ func (s *Server) Shutdown() {
 	if s.wsServer != nil {
 		_ = s.wsServer.Shutdown(context.Background())
 	}
-	for _, bg := range s.BackendGroups {
+	if s.wsSubscriptions != nil {
+		s.wsSubscriptions.Shutdown()
+	}
+	if s.recorder != nil {
+		s.recorder.Close()
+	}
+	for _, bg := range s.getRoutes().backendGroups {
 		bg.Shutdown()
 	}
 }
@@ -251,12 +324,22 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel = context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
+	var body []byte
+	if s.recorder != nil && s.recorder.Sample() {
+		rw := newRecordingWriter(w)
+		w = rw
+		defer func() {
+			s.recorder.Record(ctx, body, rw)
+		}()
+	}
+
+	routes := s.getRoutes()
 	origin := r.Header.Get("Origin")
 	userAgent := r.Header.Get("User-Agent")
 	// Use XFF in context since it will automatically be replaced by the remote IP
 	xff := stripXFF(GetXForwardedFor(ctx))
-	isUnlimitedOrigin := s.isUnlimitedOrigin(origin)
-	isUnlimitedUserAgent := s.isUnlimitedUserAgent(userAgent)
+	isUnlimitedOrigin := routes.isUnlimitedOrigin(origin)
+	isUnlimitedUserAgent := routes.isUnlimitedUserAgent(userAgent)
 
 	if xff == "" {
 		writeRPCError(ctx, w, nil, ErrInvalidRequest("request does not include a remote IP"))
@@ -264,16 +347,16 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 	}
 
 	isLimited := func(method string) bool {
-		isGloballyLimitedMethod := s.isGlobalLimit(method)
+		isGloballyLimitedMethod := routes.isGlobalLimit(method)
 		if !isGloballyLimitedMethod && (isUnlimitedOrigin || isUnlimitedUserAgent) {
 			return false
 		}
 
 		var lim FrontendRateLimiter
 		if method == "" {
-			lim = s.mainLim
+			lim = routes.mainLim
 		} else {
-			lim = s.overrideLims[method]
+			lim = routes.overrideLims[method]
 		}
 
 		if lim == nil {
@@ -302,6 +385,20 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.quotas != nil {
+		if err := s.quotas.TakeRequest(ctx); err != nil {
+			RecordRPCError(ctx, BackendProxyd, "unknown", err)
+			log.Warn(
+				"request over quota",
+				"req_id", GetReqID(ctx),
+				"auth", GetAuthCtx(ctx),
+				"err", err,
+			)
+			writeRPCError(ctx, w, nil, err)
+			return
+		}
+	}
+
 	log.Info(
 		"received RPC request",
 		"req_id", GetReqID(ctx),
@@ -311,7 +408,8 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 		"remote_ip", xff,
 	)
 
-	body, err := io.ReadAll(io.LimitReader(r.Body, s.maxBodySize))
+	var err error
+	body, err = io.ReadAll(io.LimitReader(r.Body, s.maxBodySize))
 	if err != nil {
 		log.Error("error reading request body", "err", err)
 		writeRPCError(ctx, w, nil, ErrInternal)
@@ -349,7 +447,7 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		batchRes, batchContainsCached, err := s.handleBatchRPC(ctx, reqs, isLimited, true)
+		batchRes, batchContainsCached, err := s.handleBatchRPC(ctx, routes, reqs, isLimited, true)
 		if err == context.DeadlineExceeded {
 			writeRPCError(ctx, w, nil, ErrGatewayTimeout)
 			return
@@ -370,7 +468,7 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rawBody := json.RawMessage(body)
-	backendRes, cached, err := s.handleBatchRPC(ctx, []json.RawMessage{rawBody}, isLimited, false)
+	backendRes, cached, err := s.handleBatchRPC(ctx, routes, []json.RawMessage{rawBody}, isLimited, false)
 	if err != nil {
 		if errors.Is(err, ErrConsensusGetReceiptsCantBeBatched) ||
 			errors.Is(err, ErrConsensusGetReceiptsInvalidTarget) {
@@ -384,7 +482,7 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 	writeRPCRes(ctx, w, backendRes[0])
 }
 
-func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isLimited limiterFunc, isBatch bool) ([]*RPCRes, bool, error) {
+func (s *Server) handleBatchRPC(ctx context.Context, routes *serverRoutes, reqs []json.RawMessage, isLimited limiterFunc, isBatch bool) ([]*RPCRes, bool, error) {
 	// A request set is transformed into groups of batches.
 	// Each batch group maps to a forwarded JSON-RPC batch request (subject to maxUpstreamBatchSize constraints)
 	// A groupID is used to decouple Requests that have duplicate ID so they're not part of the same batch that's
@@ -420,7 +518,7 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 			continue
 		}
 
-		group := s.rpcMethodMappings[parsedReq.Method]
+		group := routes.rpcMethodMappings[parsedReq.Method]
 		if group == "" {
 			// use unknown below to prevent DOS vector that fills up memory
 			// with arbitrary method names.
@@ -439,7 +537,7 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 		// NOTE: eventually, this should apply to all batch requests. However,
 		// since we don't have data right now on the size of each batch, we
 		// only apply this to the methods that have an additional rate limit.
-		if _, ok := s.overrideLims[parsedReq.Method]; ok && isLimited(parsedReq.Method) {
+		if _, ok := routes.overrideLims[parsedReq.Method]; ok && isLimited(parsedReq.Method) {
 			log.Info(
 				"rate limited specific RPC",
 				"source", "rpc",
@@ -454,8 +552,24 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 		// Apply a sender-based rate limit if it is enabled. Note that sender-based rate
 		// limits apply regardless of origin or user-agent. As such, they don't use the
 		// isLimited method.
-		if parsedReq.Method == "eth_sendRawTransaction" && s.senderLim != nil {
-			if err := s.rateLimitSender(ctx, parsedReq); err != nil {
+		if parsedReq.Method == "eth_sendRawTransaction" && routes.senderLim != nil {
+			if err := routes.rateLimitSender(ctx, parsedReq); err != nil {
+				RecordRPCError(ctx, BackendProxyd, parsedReq.Method, err)
+				responses[i] = NewRPCErrorRes(parsedReq.ID, err)
+				continue
+			}
+		}
+
+		if s.quotas != nil {
+			if err := s.quotas.TakeCall(ctx, parsedReq.Method); err != nil {
+				log.Info(
+					"RPC call over quota",
+					"source", "rpc",
+					"req_id", GetReqID(ctx),
+					"auth", GetAuthCtx(ctx),
+					"method", parsedReq.Method,
+					"err", err,
+				)
 				RecordRPCError(ctx, BackendProxyd, parsedReq.Method, err)
 				responses[i] = NewRPCErrorRes(parsedReq.ID, err)
 				continue
@@ -500,7 +614,7 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 			start := i * s.maxUpstreamBatchSize
 			end := int(math.Min(float64(start+s.maxUpstreamBatchSize), float64(len(cacheMisses))))
 			elems := cacheMisses[start:end]
-			res, err := s.BackendGroups[group.backendGroup].Forward(ctx, createBatchRequest(elems), isBatch)
+			res, err := routes.backendGroups[group.backendGroup].Forward(ctx, createBatchRequest(elems), isBatch)
 			if err != nil {
 				if errors.Is(err, ErrConsensusGetReceiptsCantBeBatched) ||
 					errors.Is(err, ErrConsensusGetReceiptsInvalidTarget) {
@@ -554,14 +668,25 @@ func (s *Server) HandleWS(w http.ResponseWriter, r *http.Request) {
 	}
 	clientConn.SetReadLimit(s.maxBodySize)
 
-	proxier, err := s.wsBackendGroup.ProxyWS(ctx, clientConn, s.wsMethodWhitelist)
-	if err != nil {
-		if errors.Is(err, ErrNoBackends) {
-			RecordUnserviceableRequest(ctx, RPCRequestSourceWS)
+	routes := s.getRoutes()
+	var proxier interface {
+		Proxy(ctx context.Context) error
+	}
+	if routes.wsBackendGroup.Consensus != nil {
+		// consensus aware groups serve subscriptions from the consensus group instead of a single backend
+		proxier = NewManagedWSProxier(s.wsSubscriptions, clientConn, routes.wsMethodWhitelist, s.quotas, s.timeout)
+	} else {
+		backendProxier, err := routes.wsBackendGroup.ProxyWS(ctx, clientConn, routes.wsMethodWhitelist)
+		if err != nil {
+			if errors.Is(err, ErrNoBackends) {
+				RecordUnserviceableRequest(ctx, RPCRequestSourceWS)
+			}
+			log.Error("error dialing ws backend", "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx), "err", err)
+			clientConn.Close()
+			return
 		}
-		log.Error("error dialing ws backend", "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx), "err", err)
-		clientConn.Close()
-		return
+		backendProxier.quotas = s.quotas
+		proxier = backendProxier
 	}
 
 	activeClientWsConnsGauge.WithLabelValues(GetAuthCtx(ctx)).Inc()
@@ -614,8 +739,8 @@ func randStr(l int) string {
 	return hex.EncodeToString(b)
 }
 
-func (s *Server) isUnlimitedOrigin(origin string) bool {
-	for _, pat := range s.limExemptOrigins {
+func (r *serverRoutes) isUnlimitedOrigin(origin string) bool {
+	for _, pat := range r.limExemptOrigins {
 		if pat.MatchString(origin) {
 			return true
 		}
@@ -624,8 +749,8 @@ func (s *Server) isUnlimitedOrigin(origin string) bool {
 	return false
 }
 
-func (s *Server) isUnlimitedUserAgent(origin string) bool {
-	for _, pat := range s.limExemptUserAgents {
+func (r *serverRoutes) isUnlimitedUserAgent(origin string) bool {
+	for _, pat := range r.limExemptUserAgents {
 		if pat.MatchString(origin) {
 			return true
 		}
@@ -633,69 +758,87 @@ func (s *Server) isUnlimitedUserAgent(origin string) bool {
 	return false
 }
 
-func (s *Server) isGlobalLimit(method string) bool {
-	return s.globallyLimitedMethods[method]
+func (r *serverRoutes) isGlobalLimit(method string) bool {
+	return r.globallyLimitedMethods[method]
 }
 
-func (s *Server) rateLimitSender(ctx context.Context, req *RPCReq) error {
+func (r *serverRoutes) rateLimitSender(ctx context.Context, req *RPCReq) error {
+	tx, err := decodeRawTransaction(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	// Check if the transaction is for the expected chain,
+	// otherwise reject before rate limiting to avoid replay attacks.
+	if !r.isAllowedChainId(tx.ChainId()) {
+		log.Debug("chain id is not allowed", "req_id", GetReqID(ctx))
+		return txpool.ErrInvalidSender
+	}
+
+	from, err := recoverSender(ctx, tx)
+	if err != nil {
+		return err
+	}
+	ok, err := r.senderLim.Take(ctx, fmt.Sprintf("%s:%d", from.Hex(), tx.Nonce()))
+	if err != nil {
+		log.Error("error taking from sender limiter", "err", err, "req_id", GetReqID(ctx))
+		return ErrInternal
+	}
+	if !ok {
+		log.Debug("sender rate limit exceeded", "sender", from.Hex(), "req_id", GetReqID(ctx))
+		return ErrOverSenderRateLimit
+	}
+
+	return nil
+}
+
+// decodeRawTransaction decodes the transaction of an eth_sendRawTransaction request
+func decodeRawTransaction(ctx context.Context, req *RPCReq) (*types.Transaction, error) {
 	var params []string
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		log.Debug("error unmarshaling raw transaction params", "err", err, "req_Id", GetReqID(ctx))
-		return ErrParseErr
+		return nil, ErrParseErr
 	}
 
 	if len(params) != 1 {
 		log.Debug("raw transaction request has invalid number of params", "req_id", GetReqID(ctx))
 		// The error below is identical to the one Geth responds with.
-		return ErrInvalidParams("missing value for required argument 0")
+		return nil, ErrInvalidParams("missing value for required argument 0")
 	}
 
 	var data hexutil.Bytes
 	if err := data.UnmarshalText([]byte(params[0])); err != nil {
 		log.Debug("error decoding raw tx data", "err", err, "req_id", GetReqID(ctx))
 		// Geth returns the raw error from UnmarshalText.
-		return ErrInvalidParams(err.Error())
+		return nil, ErrInvalidParams(err.Error())
 	}
 
 	// Inflates a types.Transaction object from the transaction's raw bytes.
 	tx := new(types.Transaction)
 	if err := tx.UnmarshalBinary(data); err != nil {
 		log.Debug("could not unmarshal transaction", "err", err, "req_id", GetReqID(ctx))
-		return ErrInvalidParams(err.Error())
-	}
-
-	// Check if the transaction is for the expected chain,
-	// otherwise reject before rate limiting to avoid replay attacks.
-	if !s.isAllowedChainId(tx.ChainId()) {
-		log.Debug("chain id is not allowed", "req_id", GetReqID(ctx))
-		return txpool.ErrInvalidSender
+		return nil, ErrInvalidParams(err.Error())
 	}
+	return tx, nil
+}
 
-	// Convert the transaction into a Message object so that we can get the
-	// sender. This method performs an ecrecover, which can be expensive.
+// recoverSender returns the sender of the transaction.
+// This method performs an ecrecover, which can be expensive.
+func recoverSender(ctx context.Context, tx *types.Transaction) (common.Address, error) {
+	// Convert the transaction into a Message object so that we can get the sender.
 	msg, err := core.TransactionToMessage(tx, types.LatestSignerForChainID(tx.ChainId()), nil)
 	if err != nil {
 		log.Debug("could not get message from transaction", "err", err, "req_id", GetReqID(ctx))
-		return ErrInvalidParams(err.Error())
+		return common.Address{}, ErrInvalidParams(err.Error())
 	}
-	ok, err := s.senderLim.Take(ctx, fmt.Sprintf("%s:%d", msg.From.Hex(), tx.Nonce()))
-	if err != nil {
-		log.Error("error taking from sender limiter", "err", err, "req_id", GetReqID(ctx))
-		return ErrInternal
-	}
-	if !ok {
-		log.Debug("sender rate limit exceeded", "sender", msg.From.Hex(), "req_id", GetReqID(ctx))
-		return ErrOverSenderRateLimit
-	}
-
-	return nil
+	return msg.From, nil
 }
 
-func (s *Server) isAllowedChainId(chainId *big.Int) bool {
-	if s.allowedChainIds == nil || len(s.allowedChainIds) == 0 {
+func (r *serverRoutes) isAllowedChainId(chainId *big.Int) bool {
+	if r.allowedChainIds == nil || len(r.allowedChainIds) == 0 {
 		return true
 	}
-	for _, id := range s.allowedChainIds {
+	for _, id := range r.allowedChainIds {
 		if chainId.Cmp(id) == 0 {
 			return true
 		}
@@ -0,0 +1,224 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type StickyMode string
+
+const (
+	// StickyModeSender keys sessions by the sender of the transaction and by the transaction hash
+	StickyModeSender StickyMode = "sender"
+	// StickyModeClient keys sessions by the client, using its auth and remote IP
+	StickyModeClient StickyMode = "client"
+)
+
+const (
+	defaultStickyTTL      = time.Minute
+	stickySweepInterval   = time.Minute
+	stickyResultHit       = "hit"
+	stickyResultCreated   = "created"
+	stickyResultUnhealthy = "unhealthy"
+)
+
+// stickyReadMethods are the reads routed by the sticky sessions
+var stickyReadMethods = map[string]bool{
+	"eth_getTransactionCount":   true,
+	"eth_getTransactionReceipt": true,
+	"eth_getTransactionByHash":  true,
+}
+
+// StickySessions routes the reads that depend on a submitted transaction to the backend that received it,
+// so that clients don't observe backends that haven't seen the transaction yet.
+// A session is created by a successful eth_sendRawTransaction and lasts for the TTL.
+// Sessions are kept per backend group, so eth_sendRawTransaction and the reads must be mapped to the same group,
+// see validateStickyRouting.
+type StickySessions struct {
+	mode StickyMode
+	ttl  time.Duration
+	now  func() time.Time
+
+	mtx       sync.Mutex
+	sessions  map[string]stickySession
+	lastSweep time.Time
+}
+
+type stickySession struct {
+	backend *Backend
+	expiry  time.Time
+}
+
+func NewStickySessions(mode StickyMode, ttl time.Duration) (*StickySessions, error) {
+	switch mode {
+	case StickyModeSender, StickyModeClient:
+	default:
+		return nil, fmt.Errorf("invalid sticky routing mode: %s", mode)
+	}
+	if ttl == 0 {
+		ttl = defaultStickyTTL
+	}
+	return &StickySessions{
+		mode:     mode,
+		ttl:      ttl,
+		now:      time.Now,
+		sessions: make(map[string]stickySession),
+	}, nil
+}
+
+// validateStickyRouting checks that the sticky reads are mapped to the same backend group as eth_sendRawTransaction
+// when any of them is mapped to a group with sticky routing, as sessions are not shared across groups
+func validateStickyRouting(groups BackendGroupsConfig, mappings map[string]string) error {
+	sendGroup, ok := mappings["eth_sendRawTransaction"]
+	if !ok {
+		return nil
+	}
+	sticky := groups[sendGroup] != nil && groups[sendGroup].StickyRouting != ""
+	for method := range stickyReadMethods {
+		group, ok := mappings[method]
+		if ok && groups[group] != nil && groups[group].StickyRouting != "" {
+			sticky = true
+		}
+	}
+	if !sticky {
+		return nil
+	}
+	for method := range stickyReadMethods {
+		if group, ok := mappings[method]; ok && group != sendGroup {
+			return fmt.Errorf("sticky routing requires %s to be mapped to backend group %s like eth_sendRawTransaction, not %s", method, sendGroup, group)
+		}
+	}
+	return nil
+}
+
+func clientStickyKey(ctx context.Context) string {
+	return fmt.Sprintf("client:%s:%s", GetAuthCtx(ctx), stripXFF(GetXForwardedFor(ctx)))
+}
+
+// lookupKey returns the session key of a request that depends on a submitted transaction
+func (s *StickySessions) lookupKey(ctx context.Context, req *RPCReq) (string, bool) {
+	if !stickyReadMethods[req.Method] {
+		return "", false
+	}
+	if s.mode == StickyModeClient {
+		return clientStickyKey(ctx), true
+	}
+
+	var params []interface{}
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) == 0 {
+		return "", false
+	}
+	param, ok := params[0].(string)
+	if !ok {
+		return "", false
+	}
+	if req.Method == "eth_getTransactionCount" {
+		return "sender:" + strings.ToLower(param), true
+	}
+	return "tx:" + strings.ToLower(param), true
+}
+
+// recordKeys returns the session keys created by a submitted transaction
+func (s *StickySessions) recordKeys(ctx context.Context, req *RPCReq) []string {
+	if req.Method != "eth_sendRawTransaction" {
+		return nil
+	}
+	if s.mode == StickyModeClient {
+		return []string{clientStickyKey(ctx)}
+	}
+
+	tx, err := decodeRawTransaction(ctx, req)
+	if err != nil {
+		return nil
+	}
+	from, err := recoverSender(ctx, tx)
+	if err != nil {
+		return nil
+	}
+	return []string{
+		"sender:" + strings.ToLower(from.Hex()),
+		"tx:" + strings.ToLower(tx.Hash().Hex()),
+	}
+}
+
+// Order moves the backend of the session of the requests first, as long as it is healthy and one of the candidates.
+// The returned flag reports whether the first backend was selected by a session.
+func (s *StickySessions) Order(ctx context.Context, group *BackendGroup, reqs []*RPCReq, backends []*Backend) ([]*Backend, bool) {
+	var pinned *Backend
+	s.mtx.Lock()
+	now := s.now()
+	for _, req := range reqs {
+		key, ok := s.lookupKey(ctx, req)
+		if !ok {
+			continue
+		}
+		if session, ok := s.sessions[key]; ok && now.Before(session.expiry) {
+			pinned = session.backend
+			break
+		}
+	}
+	s.mtx.Unlock()
+	if pinned == nil {
+		return backends, false
+	}
+
+	idx := -1
+	for i, be := range backends {
+		if be == pinned {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 || !pinned.IsHealthy() {
+		log.Debug("sticky backend is unavailable", "name", pinned.Name, "req_id", GetReqID(ctx))
+		RecordStickyRouting(group, stickyResultUnhealthy)
+		return backends, false
+	}
+	RecordStickyRouting(group, stickyResultHit)
+
+	ordered := make([]*Backend, 0, len(backends))
+	ordered = append(ordered, pinned)
+	ordered = append(ordered, backends[:idx]...)
+	return append(ordered, backends[idx+1:]...), true
+}
+
+// Record creates sessions on the backend for the transactions it accepted
+func (s *StickySessions) Record(ctx context.Context, group *BackendGroup, reqs []*RPCReq, res []*RPCRes, back *Backend) {
+	for i, req := range reqs {
+		if i >= len(res) || res[i].IsError() {
+			continue
+		}
+		keys := s.recordKeys(ctx, req)
+		if len(keys) == 0 {
+			continue
+		}
+		RecordStickyRouting(group, stickyResultCreated)
+
+		s.mtx.Lock()
+		now := s.now()
+		for _, key := range keys {
+			s.sessions[key] = stickySession{backend: back, expiry: now.Add(s.ttl)}
+		}
+		s.sweep(now)
+		s.mtx.Unlock()
+	}
+}
+
+// sweep drops the expired sessions, at most once per sweep interval. Must be called with the lock held.
+func (s *StickySessions) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < stickySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, session := range s.sessions {
+		if !now.Before(session.expiry) {
+			delete(s.sessions, key)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newStickyTestTx(t *testing.T) (string, common.Address, common.Hash) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(10))
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(10),
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+	})
+	require.NoError(t, err)
+	data, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return hexutil.Encode(data), crypto.PubkeyToAddress(key.PublicKey), tx.Hash()
+}
+
+func stickyReq(method string, params ...interface{}) *RPCReq {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		panic(err)
+	}
+	return &RPCReq{JSONRPC: JSONRPCVersion, Method: method, Params: raw, ID: json.RawMessage("1")}
+}
+
+// stickyOrder returns the names of the backends ordered by StickySessions.Order
+func stickyOrder(backends []*Backend, _ bool) []string {
+	return backendOrder(backends)
+}
+
+func TestStickySessionsSender(t *testing.T) {
+	backends := newTestBackends("a", "b", "c")
+	group := &BackendGroup{Name: "test", Backends: backends}
+	sticky, err := NewStickySessions(StickyModeSender, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	sticky.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	rawTx, from, hash := newStickyTestTx(t)
+	send := stickyReq("eth_sendRawTransaction", rawTx)
+	receipt := stickyReq("eth_getTransactionReceipt", hash.Hex())
+	nonce := stickyReq("eth_getTransactionCount", from.Hex(), "pending")
+
+	require.Equal(t, []string{"a", "b", "c"}, stickyOrder(sticky.Order(ctx, group, []*RPCReq{receipt}, backends)))
+
+	// failed submissions don't create a session
+	failed := NewRPCErrorRes(send.ID, ErrInternal)
+	sticky.Record(ctx, group, []*RPCReq{send}, []*RPCRes{failed}, backends[2])
+	require.Equal(t, []string{"a", "b", "c"}, stickyOrder(sticky.Order(ctx, group, []*RPCReq{receipt}, backends)))
+
+	sticky.Record(ctx, group, []*RPCReq{send}, []*RPCRes{NewRPCRes(send.ID, hash.Hex())}, backends[1])
+	require.Equal(t, []string{"b", "a", "c"}, stickyOrder(sticky.Order(ctx, group, []*RPCReq{receipt}, backends)))
+	require.Equal(t, []string{"b", "a", "c"}, stickyOrder(sticky.Order(ctx, group, []*RPCReq{nonce}, backends)))
+	require.Equal(t, []string{"a", "b", "c"}, backendOrder(backends), "should not modify the group")
+	_, pinned := sticky.Order(ctx, group, []*RPCReq{receipt}, backends)
+	require.True(t, pinned)
+
+	t.Run("other methods are not pinned", func(t *testing.T) {
+		req := stickyReq("eth_getBalance", from.Hex(), "latest")
+		require.Equal(t, []string{"a", "b", "c"}, stickyOrder(sticky.Order(ctx, group, []*RPCReq{req}, backends)))
+	})
+
+	t.Run("other senders are not pinned", func(t *testing.T) {
+		req := stickyReq("eth_getTransactionCount", common.Address{}.Hex(), "pending")
+		require.Equal(t, []string{"a", "b", "c"}, stickyOrder(sticky.Order(ctx, group, []*RPCReq{req}, backends)))
+	})
+
+	t.Run("pinned backend not in the candidates", func(t *testing.T) {
+		candidates := []*Backend{backends[0], backends[2]}
+		ordered, pinned := sticky.Order(ctx, group, []*RPCReq{receipt}, candidates)
+		require.Equal(t, []string{"a", "c"}, backendOrder(ordered))
+		require.False(t, pinned)
+	})
+
+	t.Run("sessions expire", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		require.Equal(t, []string{"a", "b", "c"}, stickyOrder(sticky.Order(ctx, group, []*RPCReq{receipt}, backends)))
+
+		now = now.Add(stickySweepInterval)
+		sticky.Record(ctx, group, []*RPCReq{send}, []*RPCRes{NewRPCRes(send.ID, hash.Hex())}, backends[2])
+		require.Len(t, sticky.sessions, 2, "expired sessions should be swept")
+	})
+}
+
+func TestStickySessionsClient(t *testing.T) {
+	backends := newTestBackends("a", "b")
+	group := &BackendGroup{Name: "test", Backends: backends}
+	sticky, err := NewStickySessions(StickyModeClient, 0)
+	require.NoError(t, err)
+	require.Equal(t, defaultStickyTTL, sticky.ttl)
+
+	clientCtx := func(xff string) context.Context {
+		ctx := context.WithValue(context.Background(), ContextKeyAuth, "key") // nolint:staticcheck
+		return context.WithValue(ctx, ContextKeyXForwardedFor, xff)           // nolint:staticcheck
+	}
+
+	// the transaction isn't decoded in client mode
+	send := stickyReq("eth_sendRawTransaction", "0x00")
+	sticky.Record(clientCtx("1.1.1.1, 2.2.2.2"), group, []*RPCReq{send}, []*RPCRes{NewRPCRes(send.ID, "0x")}, backends[1])
+
+	receipt := stickyReq("eth_getTransactionReceipt", common.Hash{}.Hex())
+	require.Equal(t, []string{"b", "a"}, stickyOrder(sticky.Order(clientCtx("1.1.1.1"), group, []*RPCReq{receipt}, backends)))
+	require.Equal(t, []string{"a", "b"}, stickyOrder(sticky.Order(clientCtx("3.3.3.3"), group, []*RPCReq{receipt}, backends)))
+}
+
+func TestNewStickySessionsInvalidMode(t *testing.T) {
+	_, err := NewStickySessions("random", 0)
+	require.EqualError(t, err, "invalid sticky routing mode: random")
+}
+
+func TestValidateStickyRouting(t *testing.T) {
+	groups := BackendGroupsConfig{
+		"sticky": {StickyRouting: StickyModeSender},
+		"other":  {},
+	}
+	require.NoError(t, validateStickyRouting(groups, map[string]string{
+		"eth_sendRawTransaction":    "sticky",
+		"eth_getTransactionReceipt": "sticky",
+		"eth_chainId":               "other",
+	}))
+	require.NoError(t, validateStickyRouting(groups, map[string]string{
+		"eth_sendRawTransaction":    "other",
+		"eth_getTransactionReceipt": "other",
+	}))
+	require.Error(t, validateStickyRouting(groups, map[string]string{
+		"eth_sendRawTransaction":    "sticky",
+		"eth_getTransactionReceipt": "other",
+	}))
+	require.Error(t, validateStickyRouting(groups, map[string]string{
+		"eth_sendRawTransaction":  "other",
+		"eth_getTransactionCount": "sticky",
+	}))
+}
@@ -0,0 +1,645 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+// Managed WebSocket subscriptions
+//
+// For consensus aware WebSocket backend groups, proxyd serves eth_subscribe itself instead of pinning each client
+// connection to a single backend. Client subscriptions with the same parameters are multiplexed onto a single
+// upstream subscription on a backend of the consensus group. The upstream subscription is moved to another backend
+// when its backend is banned or leaves the consensus group, or when its connection fails or stalls.
+// Notifications for blocks above the consensus latest block are held back until the consensus catches up.
+//
+// Failover is best effort: notifications emitted by the previous backend before it failed and not yet received are
+// lost, as the new upstream subscription only streams from its current head. Only newHeads notifications are
+// de-duplicated, and only against the last head sent, so logs notifications may be delivered again after a failover.
+
+const (
+	wsSubscriptionCheckInterval = PollerInterval
+	wsSubscriptionMaxPending    = 1024
+	wsClientSendBufferSize      = 256
+)
+
+var managedSubscriptionKinds = map[string]bool{
+	"newHeads": true,
+	"logs":     true,
+}
+
+var errWSClientTooSlow = errors.New("client is not reading messages fast enough")
+
+var (
+	ErrTooManyClientSubscriptions = &RPCErr{
+		Code:          JSONRPCErrorInternal - 22,
+		Message:       "too many subscriptions on connection",
+		HTTPErrorCode: 429,
+	}
+	ErrTooManySubscriptions = &RPCErr{
+		Code:          JSONRPCErrorInternal - 23,
+		Message:       "too many subscriptions",
+		HTTPErrorCode: 429,
+	}
+)
+
+// SubscriptionManager multiplexes client subscriptions onto upstream subscriptions on the consensus group
+type SubscriptionManager struct {
+	checkInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mtx       sync.Mutex
+	bg        *BackendGroup
+	upstreams map[string]*upstreamSubscription
+
+	// maxSubscriptions and maxClientSubscriptions limit the client subscriptions in total and per connection.
+	// Zero means unlimited.
+	maxSubscriptions       int
+	maxClientSubscriptions int
+	subscriptions          int
+}
+
+func NewSubscriptionManager(bg *BackendGroup) *SubscriptionManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SubscriptionManager{
+		bg:            bg,
+		checkInterval: wsSubscriptionCheckInterval,
+		ctx:           ctx,
+		cancel:        cancel,
+		upstreams:     make(map[string]*upstreamSubscription),
+	}
+}
+
+func (m *SubscriptionManager) Shutdown() {
+	m.cancel()
+}
+
+// SetBackendGroup replaces the backend group after a configuration reload.
+// Upstream subscriptions are moved to another backend if their backend is no longer usable in the new group.
+func (m *SubscriptionManager) SetBackendGroup(bg *BackendGroup) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.bg = bg
+}
+
+// SetLimits sets the maximum number of client subscriptions in total and per connection. Zero means unlimited.
+func (m *SubscriptionManager) SetLimits(maxSubscriptions int, maxClientSubscriptions int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.maxSubscriptions = maxSubscriptions
+	m.maxClientSubscriptions = maxClientSubscriptions
+}
+
+func (m *SubscriptionManager) backendGroup() *BackendGroup {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.bg
+}
+
+// subscribe adds the client subscription to the upstream subscription with the same parameters,
+// starting the upstream subscription if there is none yet.
+// clientSubscriptions is the number of subscriptions the client already has.
+func (m *SubscriptionManager) subscribe(sub *clientSubscription, clientSubscriptions int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.maxClientSubscriptions > 0 && clientSubscriptions >= m.maxClientSubscriptions {
+		return ErrTooManyClientSubscriptions
+	}
+	if m.maxSubscriptions > 0 && m.subscriptions >= m.maxSubscriptions {
+		return ErrTooManySubscriptions
+	}
+	u, ok := m.upstreams[sub.key]
+	if !ok {
+		ctx, cancel := context.WithCancel(m.ctx)
+		u = &upstreamSubscription{
+			manager:       m,
+			key:           sub.key,
+			params:        sub.params,
+			checkInterval: m.checkInterval,
+			cancel:        cancel,
+			subscribers:   make(map[string]*clientSubscription),
+		}
+		m.upstreams[sub.key] = u
+		go u.run(ctx)
+	}
+	u.addSubscriber(sub)
+	m.subscriptions++
+	return nil
+}
+
+// unsubscribe removes the client subscription, stopping the upstream subscription once it has no subscribers left
+func (m *SubscriptionManager) unsubscribe(sub *clientSubscription) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	u, ok := m.upstreams[sub.key]
+	if !ok {
+		return
+	}
+	m.subscriptions--
+	if u.removeSubscriber(sub) == 0 {
+		u.cancel()
+		delete(m.upstreams, sub.key)
+	}
+}
+
+type clientSubscription struct {
+	id     string
+	key    string
+	params json.RawMessage
+	client *ManagedWSProxier
+}
+
+// subscriptionNotification is the block information of newHeads and logs notifications
+type subscriptionNotification struct {
+	Number      *hexutil.Uint64 `json:"number"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+	Hash        string          `json:"hash"`
+}
+
+func parseSubscriptionNotification(result json.RawMessage) (blockNumber uint64, hash string, ok bool) {
+	var n subscriptionNotification
+	if err := json.Unmarshal(result, &n); err != nil {
+		return 0, "", false
+	}
+	switch {
+	case n.Number != nil: // newHeads
+		return uint64(*n.Number), n.Hash, true
+	case n.BlockNumber != nil: // logs
+		return uint64(*n.BlockNumber), "", true
+	}
+	return 0, "", false
+}
+
+type upstreamSubscription struct {
+	manager       *SubscriptionManager
+	key           string
+	params        json.RawMessage
+	checkInterval time.Duration
+	cancel        context.CancelFunc
+
+	mtx         sync.Mutex
+	subscribers map[string]*clientSubscription
+
+	// only accessed by the run loop
+	pending  []json.RawMessage
+	lastHash string
+}
+
+func (u *upstreamSubscription) addSubscriber(sub *clientSubscription) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	u.subscribers[sub.id] = sub
+}
+
+func (u *upstreamSubscription) removeSubscriber(sub *clientSubscription) int {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	delete(u.subscribers, sub.id)
+	return len(u.subscribers)
+}
+
+func (u *upstreamSubscription) run(ctx context.Context) {
+	groupName := u.manager.backendGroup().Name
+	activeWSSubscriptionsGauge.WithLabelValues(groupName).Inc()
+	defer activeWSSubscriptionsGauge.WithLabelValues(groupName).Dec()
+	for i := 0; ctx.Err() == nil; {
+		back, conn, err := u.subscribeUpstream(ctx)
+		if err != nil {
+			log.Warn("error subscribing to backends", "group", groupName, "params", string(u.params), "err", err)
+			sleepContext(ctx, calcBackoff(i))
+			i++
+			continue
+		}
+		i = 0
+		activeBackendWsConnsGauge.WithLabelValues(back.Name).Inc()
+		reason := u.stream(ctx, back, conn)
+		conn.Close()
+		activeBackendWsConnsGauge.WithLabelValues(back.Name).Dec()
+		if ctx.Err() == nil {
+			log.Warn("moving subscription to another backend", "group", groupName, "name", back.Name, "params", string(u.params), "reason", reason)
+			RecordWSResubscription(back, reason)
+		}
+	}
+}
+
+// subscribeUpstream subscribes on the first backend of the consensus group that accepts the subscription
+func (u *upstreamSubscription) subscribeUpstream(ctx context.Context) (*Backend, *websocket.Conn, error) {
+	bg := u.manager.backendGroup()
+	backends := bg.Backends
+	if bg.Consensus != nil {
+		backends = bg.loadBalancedConsensusGroup()
+	}
+	for _, back := range backends {
+		if back.wsURL == "" || !canServeSubscription(bg, back) {
+			continue
+		}
+		conn, err := subscribeBackend(ctx, back, u.params)
+		if err != nil {
+			log.Warn("error subscribing to backend", "name", back.Name, "params", string(u.params), "err", err)
+			continue
+		}
+		log.Info("subscribed to backend", "group", bg.Name, "name", back.Name, "params", string(u.params))
+		return back, conn, nil
+	}
+	return nil, nil, ErrNoBackends
+}
+
+func subscribeBackend(ctx context.Context, back *Backend, params json.RawMessage) (*websocket.Conn, error) {
+	conn, _, err := back.dialer.DialContext(ctx, back.wsURL, nil) // nolint:bodyclose
+	if err != nil {
+		return nil, wrapErr(err, "error dialing backend")
+	}
+	req := &RPCReq{
+		JSONRPC: JSONRPCVersion,
+		Method:  "eth_subscribe",
+		Params:  params,
+		ID:      json.RawMessage("1"),
+	}
+	if err := conn.SetWriteDeadline(time.Now().Add(defaultWSWriteTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, mustMarshalJSON(req)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(defaultWSHandshakeTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		var res RPCRes
+		if err := json.Unmarshal(msg, &res); err != nil || string(res.ID) != "1" {
+			// ignore anything but the subscription response
+			continue
+		}
+		if res.IsError() {
+			conn.Close()
+			return nil, res.Error
+		}
+		return conn, nil
+	}
+}
+
+type wsNotification struct {
+	Method string `json:"method"`
+	Params struct {
+		Result json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+// stream forwards notifications from the backend until the subscription has to be moved to another backend,
+// returning the reason for it
+func (u *upstreamSubscription) stream(ctx context.Context, back *Backend, conn *websocket.Conn) string {
+	done := make(chan struct{})
+	defer close(done)
+	msgs := make(chan json.RawMessage)
+	errC := make(chan error, 1)
+	go func() {
+		for {
+			if err := conn.SetReadDeadline(time.Now().Add(defaultWSReadTimeout)); err != nil {
+				errC <- err
+				return
+			}
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				errC <- err
+				return
+			}
+			var n wsNotification
+			if err := json.Unmarshal(msg, &n); err != nil || n.Method != "eth_subscription" {
+				log.Warn("ignoring unexpected message from backend subscription", "name", back.Name, "msg", truncate(string(msg), 200))
+				continue
+			}
+			select {
+			case msgs <- n.Params.Result:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(u.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "shutdown"
+		case err := <-errC:
+			log.Warn("error reading backend subscription", "name", back.Name, "err", err)
+			return "connection error"
+		case result := <-msgs:
+			u.pending = append(u.pending, result)
+			u.flushPending()
+		case <-ticker.C:
+			if !canServeSubscription(u.manager.backendGroup(), back) {
+				return "out of consensus"
+			}
+			u.flushPending()
+		}
+	}
+}
+
+// flushPending emits the pending notifications in order, up to the first one above the consensus latest block
+func (u *upstreamSubscription) flushPending() {
+	bg := u.manager.backendGroup()
+	latest := uint64(math.MaxUint64)
+	if bg.Consensus != nil {
+		latest = uint64(bg.Consensus.GetLatestBlockNumber())
+	}
+	i := 0
+	for ; i < len(u.pending); i++ {
+		blockNumber, hash, ok := parseSubscriptionNotification(u.pending[i])
+		if ok && blockNumber > latest {
+			break
+		}
+		// the same head may be sent again by the backend the subscription moved to
+		if hash != "" {
+			if hash == u.lastHash {
+				continue
+			}
+			u.lastHash = hash
+		}
+		u.emit(u.pending[i])
+	}
+	u.pending = u.pending[i:]
+	if len(u.pending) > wsSubscriptionMaxPending {
+		log.Warn("dropping pending subscription notifications", "group", bg.Name, "count", len(u.pending)-wsSubscriptionMaxPending)
+		u.pending = u.pending[len(u.pending)-wsSubscriptionMaxPending:]
+	}
+}
+
+func (u *upstreamSubscription) emit(result json.RawMessage) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	for _, sub := range u.subscribers {
+		sub.client.notify(sub.id, result)
+	}
+}
+
+// detachedContext keeps the values of its parent but is never cancelled
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// canServeSubscription returns whether the backend can keep serving subscriptions for the group.
+// The group may no longer be consensus aware after a configuration reload.
+func canServeSubscription(bg *BackendGroup, back *Backend) bool {
+	if bg.Consensus == nil {
+		return containsBackend(bg.Backends, back)
+	}
+	// the consensus group is only updated on the next poll after a ban
+	return !bg.Consensus.IsBanned(back) && containsBackend(bg.Consensus.GetConsensusGroup(), back)
+}
+
+func containsBackend(backends []*Backend, back *Backend) bool {
+	for _, b := range backends {
+		if b == back {
+			return true
+		}
+	}
+	return false
+}
+
+// ManagedWSProxier serves a client WebSocket connection on top of the consensus group.
+// Subscriptions are managed by the SubscriptionManager and all other requests are forwarded to the backend group.
+type ManagedWSProxier struct {
+	subs            *SubscriptionManager
+	clientConn      *websocket.Conn
+	methodWhitelist *StringSet
+	quotas          *Quotas
+	timeout         time.Duration
+	writeTimeout    time.Duration
+
+	send      chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mtx           sync.Mutex
+	subscriptions map[string]*clientSubscription
+}
+
+func NewManagedWSProxier(subs *SubscriptionManager, clientConn *websocket.Conn, methodWhitelist *StringSet, quotas *Quotas, timeout time.Duration) *ManagedWSProxier {
+	return &ManagedWSProxier{
+		subs:            subs,
+		clientConn:      clientConn,
+		methodWhitelist: methodWhitelist,
+		quotas:          quotas,
+		timeout:         timeout,
+		writeTimeout:    defaultWSWriteTimeout,
+		send:            make(chan []byte, wsClientSendBufferSize),
+		closed:          make(chan struct{}),
+		subscriptions:   make(map[string]*clientSubscription),
+	}
+}
+
+func (w *ManagedWSProxier) Proxy(ctx context.Context) error {
+	// the context of the upgrade request is done once the connection has been hijacked,
+	// so only its values are kept for the lifetime of the connection
+	ctx = detachedContext{ctx}
+	errC := make(chan error, 2)
+	go w.clientPump(ctx, errC)
+	go w.writePump(errC)
+	err := <-errC
+	w.close()
+	return err
+}
+
+func (w *ManagedWSProxier) close() {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+		w.clientConn.Close()
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+		for _, sub := range w.subscriptions {
+			w.subs.unsubscribe(sub)
+		}
+		w.subscriptions = nil
+	})
+}
+
+func (w *ManagedWSProxier) clientPump(ctx context.Context, errC chan error) {
+	for {
+		msgType, msg, err := w.clientConn.ReadMessage()
+		if err != nil {
+			errC <- err
+			return
+		}
+
+		RecordWSMessage(ctx, BackendProxyd, SourceClient)
+
+		if msgType != websocket.TextMessage && msgType != websocket.BinaryMessage {
+			continue
+		}
+
+		rpcRequestsTotal.Inc()
+
+		res := w.handleClientMsg(ctx, msg)
+		if !w.enqueue(mustMarshalJSON(res)) {
+			errC <- errWSClientTooSlow
+			return
+		}
+	}
+}
+
+func (w *ManagedWSProxier) handleClientMsg(ctx context.Context, msg []byte) *RPCRes {
+	req, err := ParseRPCReq(msg)
+	if err != nil {
+		log.Info("error parsing client message", "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx), "err", err)
+		RecordRPCError(ctx, BackendProxyd, MethodUnknown, err)
+		return NewRPCErrorRes(nil, err)
+	}
+	if !w.methodWhitelist.Has(req.Method) {
+		RecordRPCError(ctx, BackendProxyd, req.Method, ErrMethodNotWhitelisted)
+		return NewRPCErrorRes(req.ID, ErrMethodNotWhitelisted)
+	}
+	if w.quotas != nil {
+		if err := w.quotas.TakeMessage(ctx, req.Method); err != nil {
+			log.Info("WS message over quota", "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx), "method", req.Method, "err", err)
+			RecordRPCError(ctx, BackendProxyd, req.Method, err)
+			return NewRPCErrorRes(req.ID, err)
+		}
+	}
+
+	switch req.Method {
+	case "eth_accounts":
+		RecordRPCForward(ctx, BackendProxyd, req.Method, RPCRequestSourceWS)
+		return NewRPCRes(req.ID, emptyArrayResponse)
+	case "eth_subscribe":
+		RecordRPCForward(ctx, BackendProxyd, req.Method, RPCRequestSourceWS)
+		id, err := w.subscribe(req.Params)
+		if err != nil {
+			return NewRPCErrorRes(req.ID, err)
+		}
+		log.Info("created managed subscription", "id", id, "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx))
+		return NewRPCRes(req.ID, id)
+	case "eth_unsubscribe":
+		RecordRPCForward(ctx, BackendProxyd, req.Method, RPCRequestSourceWS)
+		var params []string
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+			return NewRPCErrorRes(req.ID, ErrInvalidParams("expected a single subscription id"))
+		}
+		return NewRPCRes(req.ID, w.unsubscribe(params[0]))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	res, err := w.subs.backendGroup().Forward(ctx, []*RPCReq{req}, false)
+	if err != nil {
+		log.Info("error forwarding WS request", "method", req.Method, "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx), "err", err)
+		return NewRPCErrorRes(req.ID, err)
+	}
+	return res[0]
+}
+
+func (w *ManagedWSProxier) subscribe(rawParams json.RawMessage) (string, error) {
+	var params []interface{}
+	if err := json.Unmarshal(rawParams, &params); err != nil || len(params) == 0 {
+		return "", ErrInvalidParams("missing subscription type")
+	}
+	kind, ok := params[0].(string)
+	if !ok || !managedSubscriptionKinds[kind] {
+		return "", ErrInvalidParams(fmt.Sprintf("unsupported subscription type: %v", params[0]))
+	}
+	// re-encode the params so equivalent subscriptions share the same key
+	canonical := mustMarshalJSON(params)
+	sub := &clientSubscription{
+		id:     "0x" + randStr(16),
+		key:    string(canonical),
+		params: canonical,
+		client: w,
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.subscriptions == nil { // closed
+		return "", ErrInternal
+	}
+	if err := w.subs.subscribe(sub, len(w.subscriptions)); err != nil {
+		return "", err
+	}
+	w.subscriptions[sub.id] = sub
+	return sub.id, nil
+}
+
+func (w *ManagedWSProxier) unsubscribe(id string) bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	sub, ok := w.subscriptions[id]
+	if !ok {
+		return false
+	}
+	delete(w.subscriptions, id)
+	w.subs.unsubscribe(sub)
+	return true
+}
+
+func (w *ManagedWSProxier) notify(id string, result json.RawMessage) {
+	msg := mustMarshalJSON(map[string]interface{}{
+		"jsonrpc": JSONRPCVersion,
+		"method":  "eth_subscription",
+		"params": map[string]interface{}{
+			"subscription": id,
+			"result":       result,
+		},
+	})
+	if !w.enqueue(msg) {
+		// notifications are sent from the upstream subscription, which must not block on slow clients
+		log.Warn("closing slow WS client")
+		go w.close()
+	}
+}
+
+func (w *ManagedWSProxier) enqueue(msg []byte) bool {
+	select {
+	case <-w.closed:
+		return true
+	default:
+	}
+	select {
+	case w.send <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *ManagedWSProxier) writePump(errC chan error) {
+	for {
+		select {
+		case <-w.closed:
+			return
+		case msg := <-w.send:
+			if err := w.clientConn.SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil {
+				errC <- err
+				return
+			}
+			if err := w.clientConn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				errC <- err
+				return
+			}
+			RecordWSMessage(context.Background(), BackendProxyd, SourceBackend)
+		}
+	}
+}